@@ -4,8 +4,14 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"math"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -13,27 +19,100 @@ import (
 	"time"
 
 	"github.com/docker/docker/api/types/container"
+	units "github.com/docker/go-units"
 	"github.com/localops/devops-manager/internal/adapters/cicd"
 	"github.com/localops/devops-manager/internal/adapters/docker"
 	"github.com/localops/devops-manager/internal/adapters/kubernetes"
 	"github.com/localops/devops-manager/internal/adapters/monitoring"
+	"github.com/localops/devops-manager/internal/audit"
 )
 
+// cliPageSize сколько элементов списка выводится на одной странице
+const cliPageSize = 20
+
+// pipelineWaitTimeout сколько CLI ждет завершения запущенной сборки
+const pipelineWaitTimeout = time.Hour
+
 type Menu struct {
 	dockerAdapter     *docker.DockerAdapter
 	k8sAdapter        *kubernetes.K8sAdapter
 	cicdAdapter       *cicd.CICDAdapter
 	monitoringAdapter *monitoring.MonitoringAdapter
 	scanner           *bufio.Scanner
-}
-
-func NewMenu() (*Menu, error) {
+	// auditLog журнал аудита из AUDIT_LOG, nil если журнал не ведется
+	auditLog *audit.JSONLogger
+	// dockerSettings настройки Docker адаптера из окружения, применяются
+	// заново при выборе другого Docker context
+	dockerSettings dockerSettings
+
+	// assumeYes отвечает "да" на подтверждения (--yes)
+	assumeYes bool
+	// dryRun выводит разрушающие действия вместо выполнения (--dry-run)
+	dryRun bool
+	// output формат вывода данных для сторонних инструментов: text или json (--output)
+	output string
+	// out получает результаты команд (по умолчанию os.Stdout). Меню и
+	// запросы ввода выводятся в stdout, запрос следующей страницы - в stderr.
+	out io.Writer
+	// quiet скрывает заголовки в результатах команд и заменяет разделитель
+	// записей пустой строкой (--quiet)
+	quiet bool
+	// pendingScan строка, чтение которой началось в фоне (сессия attach) и
+	// еще не передано readInput; nil - фонового чтения нет
+	pendingScan <-chan scannedLine
+}
+
+// envPositiveInt читает положительное целое из переменной окружения name.
+// Для пустой переменной возвращает 0, для некорректного значения
+// предупреждает и тоже возвращает 0, то есть значение по умолчанию.
+func envPositiveInt(name string) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		fmt.Printf("Предупреждение: %s=%q не положительное целое число, используется значение по умолчанию\n", name, value)
+		return 0
+	}
+	return n
+}
+
+// dockerSettings настройки DockerAdapter, не зависящие от daemon
+type dockerSettings struct {
+	registry         *docker.RegistryConfig
+	batchConcurrency int
+	auditLogger      audit.Logger
+}
+
+// apply передает настройки адаптеру
+func (s dockerSettings) apply(adapter *docker.DockerAdapter) {
+	adapter.SetBatchConcurrency(s.batchConcurrency)
+	adapter.SetAuditLogger(s.auditLogger)
+}
+
+func NewMenu(logger *slog.Logger) (*Menu, error) {
 	// Инициализация Docker Registry конфигурации
+	catalogPageSize := envPositiveInt("DOCKER_REGISTRY_CATALOG_PAGE_SIZE")
 	registryConfig := &docker.RegistryConfig{
-		URL:      os.Getenv("DOCKER_REGISTRY_URL"),
-		Username: os.Getenv("DOCKER_REGISTRY_USERNAME"),
-		Password: os.Getenv("DOCKER_REGISTRY_PASSWORD"),
-		Insecure: os.Getenv("DOCKER_REGISTRY_INSECURE") == "true",
+		URL:             os.Getenv("DOCKER_REGISTRY_URL"),
+		Username:        os.Getenv("DOCKER_REGISTRY_USERNAME"),
+		Password:        os.Getenv("DOCKER_REGISTRY_PASSWORD"),
+		Insecure:        os.Getenv("DOCKER_REGISTRY_INSECURE") == "true",
+		CatalogPageSize: catalogPageSize,
+		Headers:         parseRegistryHeaders(os.Getenv("DOCKER_REGISTRY_HEADERS")),
+	}
+
+	// Журнал аудита изменяющих операций ведется, только если задан AUDIT_LOG
+	var auditLog *audit.JSONLogger
+	var auditLogger audit.Logger
+	if path := os.Getenv("AUDIT_LOG"); path != "" {
+		var err error
+		auditLog, err = audit.OpenFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при открытии журнала аудита: %v", err)
+		}
+		auditLogger = auditLog
 	}
 
 	// Инициализация Monitoring адаптера
@@ -41,39 +120,63 @@ func NewMenu() (*Menu, error) {
 		Namespace: "devops",
 		Subsystem: "manager",
 		Port:      9090,
+		Logger:    logger,
 	})
 
-	// Инициализация Docker адаптера
+	// Инициализация Docker адаптера: daemon выбирается по DOCKER_CONTEXT
+	// или текущему context docker CLI
 	dockerAdapter, err := docker.NewDockerAdapter(registryConfig, monitoringAdapter)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при инициализации Docker адаптера: %v", err)
 	}
+	batchConcurrency, _ := strconv.Atoi(os.Getenv("DOCKER_BATCH_CONCURRENCY"))
+	settings := dockerSettings{
+		registry:         registryConfig,
+		batchConcurrency: batchConcurrency,
+		auditLogger:      auditLogger,
+	}
+	settings.apply(dockerAdapter)
 
-	// Инициализация Kubernetes адаптера
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении домашней директории: %v", err)
+	// Инициализация Kubernetes адаптера: внутри пода используется
+	// service account, иначе ~/.kube/config
+	var k8sAdapter *kubernetes.K8sAdapter
+	if kubernetes.InCluster() {
+		k8sAdapter, err = kubernetes.NewK8sAdapterInCluster()
+	} else {
+		var homeDir string
+		homeDir, err = os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при получении домашней директории: %v", err)
+		}
+		k8sAdapter, err = kubernetes.NewK8sAdapter(filepath.Join(homeDir, ".kube", "config"))
 	}
-	kubeconfigPath := filepath.Join(homeDir, ".kube", "config")
-	k8sAdapter, err := kubernetes.NewK8sAdapter(kubeconfigPath)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при инициализации Kubernetes адаптера: %v", err)
 	}
+	k8sAdapter.SetAuditLogger(auditLogger)
 
 	// Инициализация CI/CD адаптера с проверкой переменных окружения
 	cicdBaseURL := os.Getenv("CICD_BASE_URL")
-	if cicdBaseURL == "" {
-		cicdBaseURL = "https://gitlab.com" // Значение по умолчанию
-	}
-
 	cicdToken := os.Getenv("CICD_TOKEN")
-	if cicdToken == "" {
+	cicdInstances := parseCICDInstances(os.Getenv("CICD_INSTANCES"))
+	if cicdToken == "" && len(cicdInstances) == 0 {
 		fmt.Println("Предупреждение: CICD_TOKEN не установлен. CI/CD функции будут недоступны.")
 	}
 
+	cicdBaseURL, cicdInstances = defaultCICDInstance(cicdBaseURL, cicdToken, cicdInstances)
+
+	// Некорректные значения таймаутов заменяются значениями по умолчанию
+	requestTimeout, _ := time.ParseDuration(os.Getenv("CICD_REQUEST_TIMEOUT"))
+	downloadTimeout, _ := time.ParseDuration(os.Getenv("CICD_DOWNLOAD_TIMEOUT"))
+
 	cicdAdapter := cicd.NewCICDAdapter(cicd.Config{
-		BaseURL: cicdBaseURL,
-		Token:   cicdToken,
+		BaseURL:         cicdBaseURL,
+		Token:           cicdToken,
+		Instances:       cicdInstances,
+		Logger:          logger,
+		RequestTimeout:  requestTimeout,
+		DownloadTimeout: downloadTimeout,
+		Audit:           auditLogger,
 	})
 
 	return &Menu{
@@ -82,14 +185,284 @@ func NewMenu() (*Menu, error) {
 		cicdAdapter:       cicdAdapter,
 		monitoringAdapter: monitoringAdapter,
 		scanner:           bufio.NewScanner(os.Stdin),
+		auditLog:          auditLog,
+		dockerSettings:    settings,
+		out:               os.Stdout,
 	}, nil
 }
 
+// defaultCICDInstance определяет основной инстанс GitLab. Если заданы
+// только CICD_INSTANCES, возвращается пустой baseURL: тогда NewCICDAdapter
+// берет первый инстанс вместе с его токеном. Иначе основной инстанс из
+// CICD_BASE_URL (по умолчанию gitlab.com) и CICD_TOKEN идет первым.
+func defaultCICDInstance(baseURL, token string, instances []cicd.Instance) (string, []cicd.Instance) {
+	if baseURL == "" && token == "" && len(instances) > 0 {
+		return "", instances
+	}
+	if baseURL == "" {
+		baseURL = "https://gitlab.com" // Значение по умолчанию
+	}
+	if token != "" && len(instances) > 0 {
+		instances = append([]cicd.Instance{{Name: "default", BaseURL: baseURL, Token: token}}, instances...)
+	}
+	return baseURL, instances
+}
+
+// parseCICDInstances разбирает CICD_INSTANCES вида "name=url,name2=url2".
+// Токен каждого инстанса берется из CICD_TOKEN_<NAME>.
+func parseCICDInstances(spec string) []cicd.Instance {
+	var instances []cicd.Instance
+	for _, item := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			continue
+		}
+
+		envName := strings.ToUpper(strings.Map(func(r rune) rune {
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return '_'
+		}, parts[0]))
+
+		instances = append(instances, cicd.Instance{
+			Name:    parts[0],
+			BaseURL: parts[1],
+			Token:   os.Getenv("CICD_TOKEN_" + envName),
+		})
+	}
+	return instances
+}
+
+// parseRegistryHeaders разбирает заголовки registry в формате
+// "Имя=значение,Имя2=значение2"; элементы без имени пропускаются
+func parseRegistryHeaders(spec string) map[string]string {
+	var headers map[string]string
+	for _, item := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || name == "" {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[name] = value
+	}
+	return headers
+}
+
+// cicdError дополняет ошибку CI/CD подсказкой, как ее исправить
+func cicdError(err error) error {
+	if errors.Is(err, cicd.ErrNoToken) {
+		return fmt.Errorf("%w: установите CICD_TOKEN или CICD_INSTANCES", err)
+	}
+	return err
+}
+
+// selectCICDInstance предлагает выбрать инстанс GitLab, если их несколько
+func (m *Menu) selectCICDInstance() *cicd.CICDAdapter {
+	names := m.cicdAdapter.InstanceNames()
+	if len(names) <= 1 {
+		return m.cicdAdapter
+	}
+
+	fmt.Println("Инстансы CI/CD:")
+	for i, name := range names {
+		fmt.Printf("%d. %s\n", i+1, name)
+	}
+	fmt.Print("Выберите инстанс (Enter - первый): ")
+	choice := m.readInput()
+	if choice == "" {
+		return m.cicdAdapter
+	}
+
+	num, err := strconv.Atoi(choice)
+	if err != nil || num < 1 || num > len(names) {
+		fmt.Println("Неверный номер инстанса")
+		return nil
+	}
+
+	adapter, err := m.cicdAdapter.ForInstance(names[num-1])
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return nil
+	}
+	return adapter
+}
+
+// printf выводит результат команды в m.out
+func (m *Menu) printf(format string, args ...any) {
+	fmt.Fprintf(m.out, format, args...)
+}
+
+// println выводит строку результата команды в m.out
+func (m *Menu) println(args ...any) {
+	fmt.Fprintln(m.out, args...)
+}
+
+// decorln выводит оформление результата (заголовок), которое скрывает --quiet
+func (m *Menu) decorln(args ...any) {
+	if !m.quiet {
+		fmt.Fprintln(m.out, args...)
+	}
+}
+
+// separator завершает запись списка: "---", а с --quiet пустая строка,
+// чтобы границы записей оставались и в выводе для скриптов
+func (m *Menu) separator() {
+	if m.quiet {
+		fmt.Fprintln(m.out)
+		return
+	}
+	fmt.Fprintln(m.out, "---")
+}
+
+// scannedLine результат фонового чтения строки; ok false - ввод закончился
+type scannedLine struct {
+	text string
+	ok   bool
+}
+
 func (m *Menu) readInput() string {
+	if scan := m.pendingScan; scan != nil {
+		m.pendingScan = nil
+		return strings.TrimSpace((<-scan).text)
+	}
 	m.scanner.Scan()
 	return strings.TrimSpace(m.scanner.Text())
 }
 
+// readInt запрашивает целое число от min до max и повторяет запрос, пока
+// ввод некорректен. Пустой ввод или q отменяют ввод, тогда ok равен false.
+func (m *Menu) readInt(prompt string, min, max int) (int, bool) {
+	return m.readIntInput(prompt, min, max, nil, false)
+}
+
+// readIntDefault работает как readInt, но пустой ввод возвращает def без
+// проверки диапазона. Отменить ввод можно только q.
+func (m *Menu) readIntDefault(prompt string, def, min, max int) (int, bool) {
+	return m.readIntInput(prompt, min, max, &def, false)
+}
+
+// readIntOrAll работает как readIntDefault со значением по умолчанию 0,
+// которое возвращается и для ввода all
+func (m *Menu) readIntOrAll(prompt string, min, max int) (int, bool) {
+	def := 0
+	return m.readIntInput(prompt, min, max, &def, true)
+}
+
+func (m *Menu) readIntInput(prompt string, min, max int, def *int, all bool) (int, bool) {
+	for {
+		fmt.Print(prompt)
+		value := m.readInput()
+		switch {
+		case value == "" && def != nil, all && strings.EqualFold(value, "all"):
+			return *def, true
+		case value == "" || strings.EqualFold(value, "q"):
+			fmt.Println("Ввод отменен")
+			return 0, false
+		}
+
+		num, err := strconv.Atoi(value)
+		if err == nil && num >= min && num <= max {
+			return num, true
+		}
+		fmt.Printf("Введите целое число от %d до %d (q - отмена)\n", min, max)
+	}
+}
+
+// confirm запрашивает подтверждение. С флагом --yes ответ не читается.
+func (m *Menu) confirm(prompt string) bool {
+	fmt.Printf("%s (y/N): ", prompt)
+	if m.assumeYes {
+		fmt.Println("y")
+		return true
+	}
+	return strings.ToLower(m.readInput()) == "y"
+}
+
+// confirmDestructive подтверждает разрушающее действие. В режиме
+// --dry-run действие только выводится и не выполняется.
+func (m *Menu) confirmDestructive(action string) bool {
+	if m.dryRun {
+		fmt.Printf("[dry-run] %s\n", action)
+		return false
+	}
+	if !m.confirm(action + "?") {
+		fmt.Println("Операция отменена")
+		return false
+	}
+	return true
+}
+
+// scanAsync читает следующую строку ввода в фоне
+func (m *Menu) scanAsync() <-chan scannedLine {
+	scan := make(chan scannedLine, 1)
+	go func() {
+		ok := m.scanner.Scan()
+		scan <- scannedLine{text: m.scanner.Text(), ok: ok}
+	}()
+	return scan
+}
+
+// scannedChan возвращает уже прочитанную строку в виде результата scanAsync
+func scannedChan(line scannedLine) <-chan scannedLine {
+	scan := make(chan scannedLine, 1)
+	scan <- line
+	return scan
+}
+
+// sessionInput возвращает поток ввода для интерактивной сессии: строки
+// читаются из того же scanner, что и меню. stop завершает поток; строка,
+// набранная после конца сессии, достанется следующему readInput, а не
+// пропадет в фоновом чтении.
+func (m *Menu) sessionInput() (in io.Reader, stop func()) {
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		for {
+			scan := m.pendingScan
+			if scan == nil {
+				scan = m.scanAsync()
+			}
+			m.pendingScan = nil
+
+			select {
+			case line := <-scan:
+				if !line.ok {
+					writer.Close()
+					return
+				}
+				// Строку, которую сессия уже не прочитает, получит меню
+				if _, err := io.WriteString(writer, line.text+"\n"); err != nil {
+					m.pendingScan = scannedChan(line)
+					return
+				}
+			case <-done:
+				m.pendingScan = scan
+				return
+			}
+		}
+	}()
+
+	return reader, func() {
+		close(done)
+		// Разблокирует запись, если сессия уже не читает ввод
+		reader.Close()
+		<-finished
+	}
+}
+
+// askYesNo задает вопрос о параметре операции. Ответ читается всегда:
+// --yes подтверждает только разрушающие действия и не меняет параметры.
+func (m *Menu) askYesNo(prompt string) bool {
+	fmt.Printf("%s (y/N): ", prompt)
+	return strings.ToLower(m.readInput()) == "y"
+}
+
 func (m *Menu) printMainMenu() {
 	fmt.Println("\n=== DevOps Manager CLI ===")
 	fmt.Println("1. Управление Docker-образами")
@@ -97,6 +470,8 @@ func (m *Menu) printMainMenu() {
 	fmt.Println("3. Управление Kubernetes")
 	fmt.Println("4. Управление CI/CD")
 	fmt.Println("5. Мониторинг")
+	fmt.Println("6. Управление сетями")
+	fmt.Println("7. Системное обслуживание")
 	fmt.Println("0. Выход")
 	fmt.Print("Выберите пункт меню: ")
 }
@@ -107,19 +482,31 @@ func (m *Menu) printImageMenu() {
 	fmt.Println("2. Список образов")
 	fmt.Println("3. Удалить образ")
 	fmt.Println("4. Информация об образе")
+	fmt.Println("5. Размер слоев образа")
+	fmt.Println("6. Сравнить два образа")
+	fmt.Println("7. Список репозиториев в registry")
+	fmt.Println("8. Собрать образ из git репозитория")
 	fmt.Println("0. Назад")
 	fmt.Print("Выберите пункт меню: ")
 }
 
 func (m *Menu) printContainerMenu() {
 	fmt.Println("\n=== Управление контейнерами ===")
-	fmt.Println("1. Создать контейнер")
+	fmt.Println("1. Создать и запустить контейнер")
 	fmt.Println("2. Список контейнеров")
 	fmt.Println("3. Запустить контейнер")
 	fmt.Println("4. Остановить контейнер")
 	fmt.Println("5. Удалить контейнер")
 	fmt.Println("6. Логи контейнера")
 	fmt.Println("7. Перезапустить контейнер")
+	fmt.Println("8. Запустить стек из файла")
+	fmt.Println("9. Массовая операция по метке")
+	fmt.Println("10. Подключиться к контейнеру")
+	fmt.Println("11. Создать контейнер (без запуска)")
+	fmt.Println("12. Изменить лимиты контейнера")
+	fmt.Println("13. Изменения в файловой системе контейнера")
+	fmt.Println("14. Информация о контейнере")
+	fmt.Println("15. Логи нескольких контейнеров")
 	fmt.Println("0. Назад")
 	fmt.Print("Выберите пункт меню: ")
 }
@@ -130,6 +517,7 @@ func (m *Menu) printNetworkMenu() {
 	fmt.Println("2. Список сетей")
 	fmt.Println("3. Подключить контейнер к сети")
 	fmt.Println("4. Отключить контейнер от сети")
+	fmt.Println("5. Удалить сеть")
 	fmt.Println("0. Назад")
 	fmt.Print("Выберите пункт меню: ")
 }
@@ -138,6 +526,11 @@ func (m *Menu) printMaintenanceMenu() {
 	fmt.Println("\n=== Системное обслуживание ===")
 	fmt.Println("1. Очистка неиспользуемых ресурсов")
 	fmt.Println("2. Системная информация")
+	fmt.Println("3. Удалить остановленные контейнеры старше N часов")
+	fmt.Println("4. Удалить старые теги образов, оставив N последних")
+	fmt.Println("5. Использование диска")
+	fmt.Println("6. Описание объекта Docker")
+	fmt.Println("7. Выбрать Docker context")
 	fmt.Println("0. Назад")
 	fmt.Print("Выберите пункт меню: ")
 }
@@ -152,10 +545,33 @@ func (m *Menu) printKubernetesMenu() {
 	fmt.Println("6. Удалить ресурс")
 	fmt.Println("7. Управление конфигурацией")
 	fmt.Println("8. Управление секретами")
+	fmt.Println("9. Применить манифесты из директории")
+	fmt.Println("10. Применить шаблон манифеста с переменными")
+	fmt.Println("11. Потребление ресурсов подами")
+	fmt.Println("12. Потребление ресурсов узлами")
+	fmt.Println("13. Обновить образ деплоймента")
+	fmt.Println("14. Управление узлами")
+	fmt.Println("15. Перезапустить все деплойменты namespace")
+	fmt.Println("16. Показать diff манифеста")
+	fmt.Println("17. Дождаться завершения Job")
+	fmt.Println("18. Сводка по namespace")
+	fmt.Println("19. Создать Ingress")
+	fmt.Println("20. Добавить метки")
+	fmt.Println("21. Здоровье деплойментов")
 	fmt.Println("0. Назад")
 	fmt.Print("Выберите пункт меню: ")
 }
 
+func (m *Menu) printNodeMenu() {
+	fmt.Println("\n=== Управление узлами ===")
+	fmt.Println("1. Вывести ноду на обслуживание")
+	fmt.Println("2. Список узлов")
+	fmt.Println("3. Запретить планирование на узел (cordon)")
+	fmt.Println("4. Разрешить планирование на узел (uncordon)")
+	fmt.Println("0. Назад")
+	fmt.Print("Выберите действие: ")
+}
+
 func (m *Menu) printCICDMenu() {
 	fmt.Println("\n=== Управление CI/CD ===")
 	fmt.Println("1. Запустить сборку")
@@ -166,6 +582,9 @@ func (m *Menu) printCICDMenu() {
 	fmt.Println("6. Перезапустить сборку")
 	fmt.Println("7. Скачать артефакты")
 	fmt.Println("8. Создать/настроить .gitlab-ci.yml")
+	fmt.Println("9. Последняя сборка для ветки/коммита")
+	fmt.Println("10. Просмотреть артефакты задачи")
+	fmt.Println("11. Скачать файл из артефактов")
 	fmt.Println("0. Назад")
 	fmt.Print("Выберите пункт меню: ")
 }
@@ -176,25 +595,37 @@ func (m *Menu) printMonitoringMenu() {
 	fmt.Println("2. Запрос метрики")
 	fmt.Println("3. Список метрик")
 	fmt.Println("4. Проверка здоровья")
+	fmt.Println("5. Экспорт дашборда Grafana")
+	fmt.Println("6. Следить за метрикой")
 	fmt.Println("0. Назад")
 	fmt.Print("Выберите пункт меню: ")
 }
 
 func (m *Menu) printConfigMenu() {
 	fmt.Println("\n=== Управление конфигурацией ===")
-	fmt.Println("1. Создать/обновить ConfigMap")
+	fmt.Println("1. Создать/заменить ConfigMap целиком")
 	fmt.Println("2. Просмотреть ConfigMap")
 	fmt.Println("3. Настроить конфигурацию nginx")
 	fmt.Println("4. Список всех ConfigMap")
+	fmt.Println("5. Изменить один ключ ConfigMap")
+	fmt.Println("6. Удалить один ключ ConfigMap")
+	fmt.Println("7. Экспорт в файлы")
+	fmt.Println("8. Создать ConfigMap из директории")
+	fmt.Println("9. Копировать между namespace")
 	fmt.Println("0. Назад")
 	fmt.Print("Выберите действие: ")
 }
 
 func (m *Menu) printSecretMenu() {
 	fmt.Println("\n=== Управление секретами ===")
-	fmt.Println("1. Создать/обновить секрет")
+	fmt.Println("1. Создать/заменить секрет целиком")
 	fmt.Println("2. Просмотреть секрет")
 	fmt.Println("3. Список всех секретов")
+	fmt.Println("4. Изменить один ключ секрета")
+	fmt.Println("5. Удалить один ключ секрета")
+	fmt.Println("6. Экспорт в файлы")
+	fmt.Println("7. Создать секрет из директории")
+	fmt.Println("8. Копировать между namespace")
 	fmt.Println("0. Назад")
 	fmt.Print("Выберите действие: ")
 }
@@ -213,6 +644,14 @@ func (m *Menu) handleImageMenu() {
 			m.removeImage()
 		case "4":
 			m.inspectImage()
+		case "5":
+			m.showImageLayers()
+		case "6":
+			m.diffImages()
+		case "7":
+			m.listRegistryRepositories()
+		case "8":
+			m.buildImageFromGit()
 		case "0":
 			return
 		default:
@@ -241,6 +680,22 @@ func (m *Menu) handleContainerMenu() {
 			m.containerLogs()
 		case "7":
 			m.restartContainer()
+		case "8":
+			m.runStack()
+		case "9":
+			m.batchContainerOperation()
+		case "10":
+			m.attachContainer()
+		case "11":
+			m.createStoppedContainer()
+		case "12":
+			m.updateContainerResources()
+		case "13":
+			m.showContainerChanges()
+		case "14":
+			m.inspectContainer()
+		case "15":
+			m.tailContainers()
 		case "0":
 			return
 		default:
@@ -263,6 +718,8 @@ func (m *Menu) handleNetworkMenu() {
 			m.connectContainerToNetwork()
 		case "4":
 			m.disconnectContainerFromNetwork()
+		case "5":
+			m.removeNetwork()
 		case "0":
 			return
 		default:
@@ -281,6 +738,16 @@ func (m *Menu) handleMaintenanceMenu() {
 			m.pruneSystem()
 		case "2":
 			m.systemInfo()
+		case "3":
+			m.pruneStoppedContainers()
+		case "4":
+			m.pruneOldImageTags()
+		case "5":
+			m.showDiskUsage()
+		case "6":
+			m.describeDockerObject()
+		case "7":
+			m.selectDockerContext()
 		case "0":
 			return
 		default:
@@ -311,6 +778,54 @@ func (m *Menu) handleKubernetesMenu() {
 			m.handleConfigMenu()
 		case "8":
 			m.handleSecretMenu()
+		case "9":
+			m.deployDirectory()
+		case "10":
+			m.deployManifestTemplate()
+		case "11":
+			m.topPods()
+		case "12":
+			m.topNodes()
+		case "13":
+			m.setDeploymentImage()
+		case "14":
+			m.handleNodeMenu()
+		case "15":
+			m.restartAllDeployments()
+		case "16":
+			m.diffManifest()
+		case "17":
+			m.waitForJob()
+		case "18":
+			m.namespaceSummary()
+		case "19":
+			m.createIngress()
+		case "20":
+			m.addResourceLabels()
+		case "21":
+			m.deploymentsHealth()
+		case "0":
+			return
+		default:
+			fmt.Println("Неверный выбор")
+		}
+	}
+}
+
+func (m *Menu) handleNodeMenu() {
+	for {
+		m.printNodeMenu()
+		choice := m.readInput()
+
+		switch choice {
+		case "1":
+			m.drainNode()
+		case "2":
+			m.listNodes()
+		case "3":
+			m.cordonNode()
+		case "4":
+			m.uncordonNode()
 		case "0":
 			return
 		default:
@@ -341,6 +856,12 @@ func (m *Menu) handleCICDMenu() {
 			m.downloadArtifacts()
 		case "8":
 			m.configureGitLabCI()
+		case "9":
+			m.getLatestPipeline()
+		case "10":
+			m.browseArtifacts()
+		case "11":
+			m.downloadArtifactFile()
 		case "0":
 			return
 		default:
@@ -363,6 +884,10 @@ func (m *Menu) handleMonitoringMenu() {
 			m.listMetrics()
 		case "4":
 			m.showServiceHealth()
+		case "5":
+			m.exportGrafanaDashboard()
+		case "6":
+			m.watchMetric()
 		case "0":
 			return
 		default:
@@ -385,6 +910,16 @@ func (m *Menu) handleConfigMenu() {
 			m.configureNginx()
 		case "4":
 			m.listConfigMaps()
+		case "5":
+			m.setConfigMapKey()
+		case "6":
+			m.deleteConfigMapKey()
+		case "7":
+			m.exportConfigMap()
+		case "8":
+			m.createConfigMapFromDir()
+		case "9":
+			m.copyConfigObject("ConfigMap", m.k8sAdapter.ConfigMapExists, m.k8sAdapter.CopyConfigMap)
 		case "0":
 			return
 		default:
@@ -405,6 +940,16 @@ func (m *Menu) handleSecretMenu() {
 			m.viewSecret()
 		case "3":
 			m.listSecrets()
+		case "4":
+			m.setSecretKey()
+		case "5":
+			m.deleteSecretKey()
+		case "6":
+			m.exportSecret()
+		case "7":
+			m.createSecretFromDir()
+		case "8":
+			m.copyConfigObject("Secret", m.k8sAdapter.SecretExists, m.k8sAdapter.CopySecret)
 		case "0":
 			return
 		default:
@@ -429,8 +974,13 @@ func (m *Menu) buildImage() {
 		return
 	}
 
-	fmt.Print("Введите тег образа (например, calculator:latest): ")
-	tag := m.readInput()
+	fmt.Print("Введите теги образа через запятую (например, calculator:latest,calculator:1.2): ")
+	var tags []string
+	for _, tag := range strings.Split(m.readInput(), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
 
 	buildArgs := make(map[string]*string)
 	fmt.Print("Введите build-аргументы (формат: KEY=VALUE, пустая строка для завершения): ")
@@ -445,8 +995,31 @@ func (m *Menu) buildImage() {
 		}
 	}
 
-	fmt.Printf("Начинаем сборку образа %s из директории %s...\n", tag, path)
-	err := m.dockerAdapter.BuildImage(path, tag, buildArgs)
+	var labels map[string]string
+	if m.askYesNo("Добавить метки с коммитом и адресом git репозитория?") {
+		gitLabels, err := docker.GitImageLabels(path)
+		if err != nil {
+			fmt.Printf("Ошибка при получении сведений git: %v\n", err)
+			return
+		}
+		labels = gitLabels
+	}
+
+	fmt.Print("Введите целевую стадию Dockerfile (пусто - собрать весь Dockerfile): ")
+	target := m.readInput()
+
+	cacheFrom := m.readCacheFrom()
+	buildKit := m.askYesNo("Использовать BuildKit?")
+
+	fmt.Printf("Начинаем сборку образа %s из директории %s...\n", strings.Join(tags, ", "), path)
+	err := m.dockerAdapter.BuildImageWithOptions(path, docker.BuildOptions{
+		Tags:      tags,
+		BuildArgs: buildArgs,
+		Labels:    labels,
+		Target:    target,
+		CacheFrom: cacheFrom,
+		BuildKit:  buildKit,
+	})
 	if err != nil {
 		fmt.Printf("Ошибка при сборке образа: %v\n", err)
 		return
@@ -454,25 +1027,189 @@ func (m *Menu) buildImage() {
 	fmt.Println("Образ успешно собран")
 }
 
+func (m *Menu) buildImageFromGit() {
+	fmt.Print("Введите адрес git репозитория (например, https://github.com/org/app.git#main): ")
+	gitURL := m.readInput()
+
+	fmt.Print("Введите тег образа: ")
+	tag := m.readInput()
+
+	buildArgs := make(map[string]*string)
+	fmt.Print("Введите build-аргументы (формат: KEY=VALUE, пустая строка для завершения): ")
+	for {
+		arg := m.readInput()
+		if arg == "" {
+			break
+		}
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) == 2 {
+			buildArgs[parts[0]] = &parts[1]
+		}
+	}
+
+	fmt.Print("Введите целевую стадию Dockerfile (пусто - собрать весь Dockerfile): ")
+	target := m.readInput()
+
+	cacheFrom := m.readCacheFrom()
+
+	fmt.Printf("Начинаем сборку образа %s из %s...\n", tag, gitURL)
+	opts := docker.BuildOptions{Tags: []string{tag}, BuildArgs: buildArgs, Target: target, CacheFrom: cacheFrom}
+	if err := m.dockerAdapter.BuildImageFromGitWithOptions(context.Background(), gitURL, opts, os.Stdout); err != nil {
+		fmt.Printf("Ошибка при сборке образа: %v\n", err)
+		return
+	}
+	fmt.Println("Образ успешно собран")
+}
+
+// readCacheFrom запрашивает образы-источники кеша сборки через запятую
+func (m *Menu) readCacheFrom() []string {
+	fmt.Print("Введите образы для кеша сборки через запятую (пусто - без кеша): ")
+	var images []string
+	for _, image := range strings.Split(m.readInput(), ",") {
+		if image = strings.TrimSpace(image); image != "" {
+			images = append(images, image)
+		}
+	}
+	return images
+}
+
 func (m *Menu) listImages() {
 	images, err := m.dockerAdapter.ListImages()
 	if err != nil {
-		fmt.Printf("Ошибка при получении списка образов: %v\n", err)
+		m.printf("Ошибка при получении списка образов: %v\n", err)
+		return
+	}
+
+	// По умолчанию новые образы идут первыми
+	order, pageSize, ok := m.readListOptions([]string{"name", "created", "size"}, listOrder{key: "created", desc: true})
+	if !ok {
 		return
 	}
+	sortList(images, imageCompare(order.key), order.desc)
+	m.printImages(images, pageSize)
+}
 
-	// Сортировка образов по дате создания в обратном порядке
-	sort.Slice(images, func(i, j int) bool {
-		return images[i].Created.After(images[j].Created)
+// printImages выводит список образов в m.out страницами по pageSize
+func (m *Menu) printImages(images []docker.ImageInfo, pageSize int) {
+	m.decorln("\nСписок образов:")
+	m.printPaged(len(images), pageSize, func(i int) {
+		img := images[i]
+		m.printf("ID: %s\n", img.ID)
+		m.printf("Теги: %v\n", img.RepoTags)
+		m.printf("Размер: %d байт\n", img.Size)
+		m.printf("Создан: %s\n", img.Created)
+		m.separator()
 	})
+}
 
-	fmt.Println("\nСписок образов:")
-	for _, img := range images {
-		fmt.Printf("ID: %s\n", img.ID)
-		fmt.Printf("Теги: %v\n", img.RepoTags)
-		fmt.Printf("Размер: %d байт\n", img.Size)
-		fmt.Printf("Создан: %s\n", img.Created)
-		fmt.Println("---")
+// listOrder порядок сортировки списка: поле и направление
+type listOrder struct {
+	key  string
+	desc bool
+}
+
+// parseListOrder разбирает порядок сортировки: имя поля из keys, с
+// префиксом - для обратного порядка. Пустая строка дает def.
+func parseListOrder(value string, keys []string, def listOrder) (listOrder, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return def, nil
+	}
+
+	key, desc := strings.CutPrefix(value, "-")
+	for _, allowed := range keys {
+		if key == allowed {
+			return listOrder{key: key, desc: desc}, nil
+		}
+	}
+	return listOrder{}, fmt.Errorf("неизвестное поле сортировки %s, доступны: %s", key, strings.Join(keys, ", "))
+}
+
+// sortList сортирует items по cmp, при desc в обратном порядке.
+// Равные элементы сохраняют исходный порядок.
+func sortList[T any](items []T, cmp func(a, b T) int, desc bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			return cmp(items[j], items[i]) < 0
+		}
+		return cmp(items[i], items[j]) < 0
+	})
+}
+
+// imageCompare возвращает сравнение образов по полю key
+func imageCompare(key string) func(a, b docker.ImageInfo) int {
+	switch key {
+	case "name":
+		return func(a, b docker.ImageInfo) int { return strings.Compare(imageName(a), imageName(b)) }
+	case "size":
+		return func(a, b docker.ImageInfo) int { return cmpInt64(a.Size, b.Size) }
+	default:
+		return func(a, b docker.ImageInfo) int { return a.Created.Compare(b.Created) }
+	}
+}
+
+// imageName имя образа для сортировки: первый тег или ID для образов без тегов
+func imageName(img docker.ImageInfo) string {
+	if len(img.RepoTags) > 0 {
+		return img.RepoTags[0]
+	}
+	return img.ID
+}
+
+// containerCompare возвращает сравнение контейнеров по полю key
+func containerCompare(key string) func(a, b docker.ContainerInfo) int {
+	switch key {
+	case "name":
+		return func(a, b docker.ContainerInfo) int { return strings.Compare(a.Name, b.Name) }
+	default:
+		return func(a, b docker.ContainerInfo) int { return a.Created.Compare(b.Created) }
+	}
+}
+
+func cmpInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// readListOptions запрашивает порядок сортировки и размер страницы списка
+func (m *Menu) readListOptions(keys []string, def listOrder) (listOrder, int, bool) {
+	defName := def.key
+	if def.desc {
+		defName = "-" + defName
+	}
+	fmt.Printf("Сортировка (%s; - в начале для обратного порядка, Enter - %s): ", strings.Join(keys, ", "), defName)
+	order, err := parseListOrder(m.readInput(), keys, def)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return listOrder{}, 0, false
+	}
+
+	fmt.Printf("Элементов на странице (Enter - %d, 0 - все): ", cliPageSize)
+	pageSize := cliPageSize
+	if value := m.readInput(); value != "" {
+		pageSize, err = strconv.Atoi(value)
+		if err != nil || pageSize < 0 {
+			fmt.Println("Ошибка: введите неотрицательное число")
+			return listOrder{}, 0, false
+		}
+	}
+	return order, pageSize, true
+}
+
+// printPaged выводит n элементов через print страницами по pageSize,
+// спрашивая перед каждой следующей. Нулевой pageSize выводит все сразу.
+func (m *Menu) printPaged(n, pageSize int, print func(i int)) {
+	for i := 0; i < n; i++ {
+		if pageSize > 0 && i > 0 && i%pageSize == 0 && !m.nextPage(strconv.Itoa(i)) {
+			return
+		}
+		print(i)
 	}
 }
 
@@ -480,6 +1217,10 @@ func (m *Menu) removeImage() {
 	fmt.Print("Введите имя образа (например, myapp:latest): ")
 	imageName := m.readInput()
 
+	if !m.confirmDestructive(fmt.Sprintf("Удалить образ %s", imageName)) {
+		return
+	}
+
 	err := m.dockerAdapter.RemoveImage(imageName)
 	if err != nil {
 		fmt.Printf("Ошибка при удалении образа: %v\n", err)
@@ -492,6 +1233,37 @@ func (m *Menu) inspectImage() {
 	fmt.Print("Введите имя образа (например, myapp:latest): ")
 	imageName := m.readInput()
 
+	summary, err := m.dockerAdapter.GetImageSummary(imageName)
+	if err != nil {
+		fmt.Printf("Ошибка при получении информации об образе: %v\n", err)
+		return
+	}
+
+	fmt.Println("\nИнформация об образе:")
+	fmt.Printf("ID: %s\n", summary.ID)
+	fmt.Printf("Теги: %v\n", summary.RepoTags)
+	if summary.Digest != "" {
+		fmt.Printf("Digest: %s\n", summary.Digest)
+	}
+	fmt.Printf("Размер: %d байт\n", summary.Size)
+	fmt.Printf("Создан: %s\n", summary.Created.Format(time.RFC3339))
+	fmt.Printf("Платформа: %s/%s\n", summary.OS, summary.Architecture)
+	fmt.Printf("Entrypoint: %v\n", summary.Entrypoint)
+	fmt.Printf("Cmd: %v\n", summary.Cmd)
+	fmt.Printf("Открытые порты: %v\n", summary.ExposedPorts)
+	fmt.Printf("Слоев: %d\n", summary.LayerCount)
+	if len(summary.Env) > 0 {
+		fmt.Println("Переменные окружения:")
+		for _, env := range summary.Env {
+			fmt.Printf("  %s\n", env)
+		}
+	}
+
+	fmt.Print("\nПоказать полный JSON? (y/N): ")
+	if strings.ToLower(m.readInput()) != "y" {
+		return
+	}
+
 	inspect, err := m.dockerAdapter.GetImageInspect(imageName)
 	if err != nil {
 		fmt.Printf("Ошибка при получении информации об образе: %v\n", err)
@@ -503,30 +1275,143 @@ func (m *Menu) inspectImage() {
 		fmt.Printf("Ошибка при форматировании информации: %v\n", err)
 		return
 	}
-	fmt.Printf("\nИнформация об образе:\n%s\n", string(jsonData))
+	fmt.Printf("\n%s\n", string(jsonData))
 }
 
-func (m *Menu) createContainer() {
-	fmt.Print("Введите имя образа: ")
-	image := m.readInput()
-	fmt.Print("Введите имя контейнера: ")
-	name := m.readInput()
+func (m *Menu) showImageLayers() {
+	fmt.Print("Введите имя образа (например, myapp:latest): ")
+	imageName := m.readInput()
 
-	ports := make(map[string]string)
-	fmt.Print("Введите маппинг портов (формат: containerPort:hostPort, пустая строка для завершения): ")
-	for {
-		port := m.readInput()
-		if port == "" {
+	layers, err := m.dockerAdapter.GetImageLayers(imageName)
+	if err != nil {
+		fmt.Printf("Ошибка при получении слоев образа: %v\n", err)
+		return
+	}
+
+	if len(layers) == 0 {
+		fmt.Println("У образа нет слоев")
+		return
+	}
+	total := layers[len(layers)-1].CumulativeSize
+
+	docker.SortLayersBySize(layers)
+
+	const topLayers = 5
+	fmt.Printf("\nОбщий размер: %d байт. Самые большие слои:\n", total)
+	for i, layer := range layers {
+		if i == topLayers {
 			break
 		}
-		parts := strings.Split(port, ":")
-		if len(parts) == 2 {
-			ports[parts[0]] = parts[1]
-		}
+		fmt.Printf("%d. %d байт — %s\n", i+1, layer.Size, layer.CreatedBy)
+	}
+}
+
+func (m *Menu) diffImages() {
+	fmt.Print("Введите имя первого образа: ")
+	imageA := m.readInput()
+	fmt.Print("Введите имя второго образа: ")
+	imageB := m.readInput()
+
+	diff, err := m.dockerAdapter.DiffImages(imageA, imageB)
+	if err != nil {
+		fmt.Printf("Ошибка при сравнении образов: %v\n", err)
+		return
+	}
+
+	fmt.Printf("\nИзменение размера: %+d байт\n", diff.SizeDelta)
+
+	fmt.Println("\nДобавленные слои:")
+	for _, layer := range diff.Added {
+		fmt.Printf("+ %d байт — %s\n", layer.Size, layer.CreatedBy)
+	}
+
+	fmt.Println("\nУдаленные слои:")
+	for _, layer := range diff.Removed {
+		fmt.Printf("- %d байт — %s\n", layer.Size, layer.CreatedBy)
+	}
+
+	fmt.Println("\nИзмененные слои:")
+	for _, change := range diff.Changed {
+		fmt.Printf("~ %d -> %d байт — %s\n", change.OldSize, change.NewSize, change.CreatedBy)
+	}
+}
+
+func (m *Menu) listRegistryRepositories() {
+	repositories, err := m.dockerAdapter.ListRegistryRepositories()
+	if err != nil {
+		fmt.Printf("Ошибка при получении списка репозиториев: %v\n", err)
+		return
+	}
+
+	if len(repositories) == 0 {
+		fmt.Println("В registry нет репозиториев")
+		return
+	}
+
+	fmt.Println("\nРепозитории:")
+	for _, repository := range repositories {
+		fmt.Printf("- %s\n", repository)
+	}
+}
+
+func (m *Menu) createContainer() {
+	opts := m.readContainerOptions()
+	container, err := m.dockerAdapter.RunContainer(opts)
+	if err != nil {
+		fmt.Printf("Ошибка при создании контейнера: %v\n", err)
+		return
+	}
+	fmt.Printf("Контейнер успешно создан. ID: %s\n", container.ID)
+
+	if len(opts.Ports) == 0 || !m.askYesNo("Дождаться, пока опубликованные порты начнут принимать соединения?") {
+		return
+	}
+	for _, hostPort := range opts.Ports {
+		port, err := strconv.Atoi(hostPort)
+		if err != nil {
+			fmt.Printf("Некорректный порт %s\n", hostPort)
+			continue
+		}
+		fmt.Printf("Ожидание порта %d...\n", port)
+		if err := m.dockerAdapter.WaitForPort(context.Background(), port, time.Minute); err != nil {
+			fmt.Printf("Ошибка: %v\n", err)
+			continue
+		}
+		fmt.Printf("Порт %d принимает соединения\n", port)
+	}
+}
+
+func (m *Menu) createStoppedContainer() {
+	container, err := m.dockerAdapter.CreateContainer(m.readContainerOptions())
+	if err != nil {
+		fmt.Printf("Ошибка при создании контейнера: %v\n", err)
+		return
+	}
+	fmt.Printf("Контейнер создан без запуска. ID: %s\n", container.ID)
+}
+
+// readContainerOptions запрашивает параметры нового контейнера
+func (m *Menu) readContainerOptions() docker.ContainerOptions {
+	fmt.Print("Введите имя образа: ")
+	image := m.readInput()
+	fmt.Print("Введите имя контейнера: ")
+	name := m.readInput()
+
+	ports := make(map[string]string)
+	fmt.Print("Введите маппинг портов (формат: containerPort:hostPort, пустая строка для завершения): ")
+	for {
+		port := m.readInput()
+		if port == "" {
+			break
+		}
+		parts := strings.Split(port, ":")
+		if len(parts) == 2 {
+			ports[parts[0]] = parts[1]
+		}
 	}
 
 	env := make(map[string]string)
-	fmt.Print("Введите переменные окружения (формат: KEY=VALUE, пустая строка для завершения): ")
+	fmt.Print("Введите переменные окружения (формат: KEY=VALUE, значение может ссылаться на ${HOST_VAR}, пустая строка для завершения): ")
 	for {
 		envVar := m.readInput()
 		if envVar == "" {
@@ -538,22 +1423,245 @@ func (m *Menu) createContainer() {
 		}
 	}
 
+	fmt.Print("Путь к env файлу (KEY=VALUE, переменные выше важнее; пустая строка - без файла): ")
+	envFile := m.readInput()
+
+	fmt.Print("Скачать образ, если его нет локально? (y/N): ")
+	pullIfMissing := strings.ToLower(m.readInput()) == "y"
+
+	fmt.Print("Удалить контейнер после завершения (--rm)? (y/N): ")
+	autoRemove := strings.ToLower(m.readInput()) == "y"
+
+	fmt.Print("Ожидаемый digest образа (sha256:..., пустая строка - без проверки): ")
+	expectedDigest := m.readInput()
+
 	opts := docker.ContainerOptions{
-		Image:       image,
-		Name:        name,
-		Ports:       ports,
-		Environment: env,
-		RestartPolicy: container.RestartPolicy{
-			Name: "always",
-		},
+		Image:          image,
+		Name:           name,
+		Ports:          ports,
+		Environment:    env,
+		EnvFile:        envFile,
+		PullIfMissing:  pullIfMissing,
+		AutoRemove:     autoRemove,
+		ExpectedDigest: expectedDigest,
+	}
+	// Политика перезапуска несовместима с автоудалением
+	if !autoRemove {
+		opts.RestartPolicy = container.RestartPolicy{Name: "always"}
 	}
 
-	container, err := m.dockerAdapter.RunContainer(opts)
+	return opts
+}
+
+func (m *Menu) inspectContainer() {
+	fmt.Print("Введите ID или имя контейнера: ")
+	containerID := m.readInput()
+
+	summary, err := m.dockerAdapter.GetContainerSummary(containerID)
 	if err != nil {
-		fmt.Printf("Ошибка при создании контейнера: %v\n", err)
+		fmt.Printf("Ошибка при получении информации о контейнере: %v\n", err)
 		return
 	}
-	fmt.Printf("Контейнер успешно создан. ID: %s\n", container.ID)
+
+	fmt.Println("\nИнформация о контейнере:")
+	fmt.Printf("ID: %s\n", summary.ID)
+	fmt.Printf("Имя: %s\n", summary.Name)
+	fmt.Printf("Образ: %s\n", summary.Image)
+	fmt.Printf("Состояние: %s\n", summary.State)
+	if !summary.StartedAt.IsZero() {
+		fmt.Printf("Запущен: %s\n", summary.StartedAt.Format(time.RFC3339))
+	}
+	fmt.Printf("Перезапусков: %d\n", summary.RestartCount)
+	if summary.RestartPolicy != "" {
+		fmt.Printf("Политика перезапуска: %s\n", summary.RestartPolicy)
+	}
+	fmt.Printf("Порты: %v\n", summary.Ports)
+	fmt.Printf("Сети: %v\n", summary.Networks)
+	if len(summary.Mounts) > 0 {
+		fmt.Println("Тома:")
+		for _, mount := range summary.Mounts {
+			fmt.Printf("  %s\n", mount)
+		}
+	}
+	if len(summary.Env) > 0 {
+		fmt.Println("Переменные окружения:")
+		for _, env := range summary.Env {
+			fmt.Printf("  %s\n", env)
+		}
+	}
+	if len(summary.Labels) > 0 {
+		keys := make([]string, 0, len(summary.Labels))
+		for key := range summary.Labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fmt.Println("Метки:")
+		for _, key := range keys {
+			fmt.Printf("  %s=%s\n", key, summary.Labels[key])
+		}
+	}
+}
+
+func (m *Menu) tailContainers() {
+	fmt.Print("Введите ID или имена контейнеров через запятую: ")
+	var containerIDs []string
+	for _, id := range strings.Split(m.readInput(), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			containerIDs = append(containerIDs, id)
+		}
+	}
+
+	// Ctrl+C завершает слежение и возвращает в меню
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Println("Логи контейнеров (Ctrl+C для выхода):")
+	if err := m.dockerAdapter.MultiTail(ctx, containerIDs, os.Stdout); err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+	}
+}
+
+func (m *Menu) showContainerChanges() {
+	fmt.Print("Введите ID или имя контейнера: ")
+	containerID := m.readInput()
+
+	changes, err := m.dockerAdapter.GetContainerChangesByKind(containerID)
+	if err != nil {
+		fmt.Printf("Ошибка при получении изменений: %v\n", err)
+		return
+	}
+
+	groups := []struct {
+		title string
+		kind  string
+		paths []string
+	}{
+		{"Добавлено", docker.ChangeKindLetter(docker.ChangeAdded), changes.Added},
+		{"Изменено", docker.ChangeKindLetter(docker.ChangeModified), changes.Modified},
+		{"Удалено", docker.ChangeKindLetter(docker.ChangeDeleted), changes.Deleted},
+	}
+
+	empty := true
+	for _, group := range groups {
+		if len(group.paths) == 0 {
+			continue
+		}
+		empty = false
+		fmt.Printf("\n%s (%d):\n", group.title, len(group.paths))
+		for _, path := range group.paths {
+			fmt.Printf("%s %s\n", group.kind, path)
+		}
+	}
+	if empty {
+		fmt.Println("Файловая система контейнера не изменялась")
+	}
+}
+
+func (m *Menu) runStack() {
+	fmt.Print("Введите путь к YAML файлу стека: ")
+	path := m.readInput()
+
+	spec, err := docker.LoadStackSpec(path)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	plan, err := m.dockerAdapter.PlanStack(*spec)
+	if err != nil {
+		fmt.Printf("Ошибка при проверке стека: %v\n", err)
+		return
+	}
+
+	fmt.Printf("\nПлан запуска стека %s:\n", spec.Name)
+	fmt.Printf("- сеть %s\n", plan.Network)
+	for _, c := range plan.Containers {
+		fmt.Printf("- контейнер %s (образ %s)\n", c.Name, c.Image)
+	}
+	if len(plan.Conflicts) > 0 {
+		fmt.Println("\nКонфликты с существующими ресурсами:")
+		for _, conflict := range plan.Conflicts {
+			fmt.Printf("- %s\n", conflict)
+		}
+		fmt.Println("Устраните конфликты перед запуском стека")
+		return
+	}
+	if !m.confirmDestructive("Запустить стек " + spec.Name) {
+		return
+	}
+
+	containers, err := m.dockerAdapter.RunStack(*spec)
+	if err != nil {
+		fmt.Printf("Ошибка при запуске стека: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Стек %s успешно запущен:\n", spec.Name)
+	for _, c := range containers {
+		fmt.Printf("- %s (ID: %s)\n", strings.TrimPrefix(c.Name, "/"), c.ID)
+	}
+}
+
+func (m *Menu) batchContainerOperation() {
+	fmt.Print("Введите метки (формат: key=value через запятую): ")
+	labels := make(map[string]string)
+	for _, item := range strings.Split(m.readInput(), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) == 2 {
+			labels[parts[0]] = parts[1]
+		} else {
+			labels[parts[0]] = ""
+		}
+	}
+	if len(labels) == 0 {
+		fmt.Println("Ошибка: необходимо указать хотя бы одну метку")
+		return
+	}
+
+	fmt.Println("1. Запустить")
+	fmt.Println("2. Остановить")
+	fmt.Println("3. Удалить")
+	fmt.Print("Выберите операцию: ")
+
+	var done []string
+	var err error
+	switch m.readInput() {
+	case "1":
+		done, err = m.dockerAdapter.StartContainersByLabel(labels)
+	case "2":
+		done, err = m.dockerAdapter.StopContainersByLabel(labels)
+	case "3":
+		if !m.confirmDestructive(fmt.Sprintf("Удалить все контейнеры с метками %v", labels)) {
+			return
+		}
+		done, err = m.dockerAdapter.RemoveContainersByLabel(labels)
+	default:
+		fmt.Println("Неверный выбор")
+		return
+	}
+
+	for _, id := range done {
+		fmt.Printf("+ %s\n", id)
+	}
+
+	var batchErr *docker.BatchError
+	if errors.As(err, &batchErr) {
+		for id, failure := range batchErr.Failed {
+			fmt.Printf("- %s: %v\n", id, failure)
+		}
+		fmt.Printf("Успешно: %d, с ошибкой: %d\n", len(done), len(batchErr.Failed))
+		return
+	}
+	if err != nil {
+		fmt.Printf("Ошибка при выполнении операции: %v\n", err)
+		return
+	}
+	fmt.Printf("Операция выполнена для %d контейнеров\n", len(done))
 }
 
 func (m *Menu) startContainer() {
@@ -577,19 +1685,31 @@ func (m *Menu) startContainer() {
 func (m *Menu) listContainers() {
 	containers, err := m.dockerAdapter.ListContainers()
 	if err != nil {
-		fmt.Printf("Ошибка при получении списка контейнеров: %v\n", err)
+		m.printf("Ошибка при получении списка контейнеров: %v\n", err)
 		return
 	}
 
-	fmt.Println("\nСписок контейнеров:")
-	for _, c := range containers {
-		fmt.Printf("ID: %s\n", c.ID)
-		fmt.Printf("Имя: %s\n", c.Name)
-		fmt.Printf("Образ: %s\n", c.Image)
-		fmt.Printf("Статус: %s\n", c.Status)
-		fmt.Printf("Создан: %s\n", c.Created)
-		fmt.Println("---")
+	// Размер контейнеров не входит в список, сортировка только по имени и времени
+	order, pageSize, ok := m.readListOptions([]string{"name", "created"}, listOrder{key: "name"})
+	if !ok {
+		return
 	}
+	sortList(containers, containerCompare(order.key), order.desc)
+	m.printContainers(containers, pageSize)
+}
+
+// printContainers выводит список контейнеров в m.out страницами по pageSize
+func (m *Menu) printContainers(containers []docker.ContainerInfo, pageSize int) {
+	m.decorln("\nСписок контейнеров:")
+	m.printPaged(len(containers), pageSize, func(i int) {
+		c := containers[i]
+		m.printf("ID: %s\n", c.ID)
+		m.printf("Имя: %s\n", c.Name)
+		m.printf("Образ: %s\n", c.Image)
+		m.printf("Статус: %s\n", c.Status)
+		m.printf("Создан: %s\n", c.Created)
+		m.separator()
+	})
 }
 
 func (m *Menu) stopContainer() {
@@ -602,7 +1722,15 @@ func (m *Menu) stopContainer() {
 		return
 	}
 
-	err = m.dockerAdapter.StopContainer(containerID)
+	fmt.Print("Сигнал остановки (Enter - SIGTERM): ")
+	signal := m.readInput()
+	seconds, ok := m.readIntDefault("Время на завершение в секундах (Enter - 10): ", 10, 0, math.MaxInt32)
+	if !ok {
+		return
+	}
+	timeout := time.Duration(seconds) * time.Second
+
+	err = m.dockerAdapter.StopContainerWithOptions(containerID, signal, timeout)
 	if err != nil {
 		fmt.Printf("Ошибка при остановке контейнера: %v\n", err)
 		return
@@ -620,6 +1748,10 @@ func (m *Menu) removeContainer() {
 		return
 	}
 
+	if !m.confirmDestructive(fmt.Sprintf("Удалить контейнер %s (%s)", containerName, containerID)) {
+		return
+	}
+
 	err = m.dockerAdapter.RemoveContainer(containerID)
 	if err != nil {
 		fmt.Printf("Ошибка при удалении контейнера: %v\n", err)
@@ -631,8 +1763,20 @@ func (m *Menu) removeContainer() {
 func (m *Menu) containerLogs() {
 	fmt.Print("Введите имя контейнера: ")
 	containerName := m.readInput()
-	fmt.Print("Введите количество последних строк (или 'all'): ")
-	tail := m.readInput()
+	lines, ok := m.readIntOrAll("Введите количество последних строк (Enter или 'all' - все): ", 1, math.MaxInt32)
+	if !ok {
+		return
+	}
+	tail := "all"
+	if lines > 0 {
+		tail = strconv.Itoa(lines)
+	}
+	fmt.Print("Показать логи за период (например, 10m, 1h), с момента (RFC3339) или 'all': ")
+	since, err := parseSince(m.readInput())
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
 
 	containerID, err := m.dockerAdapter.GetContainerIDByName(containerName)
 	if err != nil {
@@ -640,7 +1784,7 @@ func (m *Menu) containerLogs() {
 		return
 	}
 
-	logs, err := m.dockerAdapter.GetContainerLogs(containerID, time.Time{}, tail)
+	logs, err := m.dockerAdapter.GetContainerLogs(containerID, since, tail)
 	if err != nil {
 		fmt.Printf("Ошибка при получении логов: %v\n", err)
 		return
@@ -653,6 +1797,92 @@ func (m *Menu) containerLogs() {
 	}
 }
 
+// parseSince разбирает начало периода логов: длительность назад от текущего
+// момента (10m, 1h), время в формате RFC3339 или all (пустая строка) - все логи
+func parseSince(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "all") {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("период должен быть положительным: %s", value)
+		}
+		return time.Now().Add(-d), nil
+	}
+
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ожидается длительность (10m, 1h), время RFC3339 или all: %s", value)
+	}
+	return since, nil
+}
+
+func (m *Menu) attachContainer() {
+	fmt.Print("Введите имя контейнера: ")
+	containerName := m.readInput()
+
+	containerID, err := m.dockerAdapter.GetContainerIDByName(containerName)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	fmt.Println("Подключение к контейнеру. Ввод передается построчно, сессия завершится вместе с процессом контейнера.")
+	in, stop := m.sessionInput()
+	err = m.dockerAdapter.AttachInteractive(context.Background(), containerID, in, os.Stdout, os.Stderr)
+	stop()
+	if err != nil {
+		fmt.Printf("\nОшибка при подключении к контейнеру: %v\n", err)
+		return
+	}
+	fmt.Println("\nСессия завершена")
+}
+
+func (m *Menu) updateContainerResources() {
+	fmt.Print("Введите имя контейнера: ")
+	containerName := m.readInput()
+	fmt.Print("Введите лимит памяти (например 512m, 2g; пусто - без изменений): ")
+	memoryStr := m.readInput()
+	fmt.Print("Введите лимит CPU (например 1.5; пусто - без изменений): ")
+	cpusStr := m.readInput()
+
+	var memory, nanoCPUs int64
+	if memoryStr != "" {
+		value, err := units.RAMInBytes(memoryStr)
+		if err != nil {
+			fmt.Printf("Ошибка: неверный лимит памяти: %v\n", err)
+			return
+		}
+		memory = value
+	}
+	if cpusStr != "" {
+		cpus, err := strconv.ParseFloat(cpusStr, 64)
+		if err != nil || cpus <= 0 {
+			fmt.Println("Ошибка: введите положительное число CPU")
+			return
+		}
+		nanoCPUs = int64(cpus * 1e9)
+	}
+	if memory == 0 && nanoCPUs == 0 {
+		fmt.Println("Ошибка: укажите хотя бы один лимит")
+		return
+	}
+
+	containerID, err := m.dockerAdapter.GetContainerIDByName(containerName)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	if err := m.dockerAdapter.UpdateContainerResources(containerID, memory, nanoCPUs); err != nil {
+		fmt.Printf("Ошибка при изменении лимитов: %v\n", err)
+		return
+	}
+	fmt.Println("Лимиты контейнера успешно изменены")
+}
+
 func (m *Menu) containerStats() {
 	fmt.Print("Введите ID контейнера: ")
 	containerID := m.readInput()
@@ -665,173 +1895,733 @@ func (m *Menu) containerStats() {
 
 	jsonData, err := json.MarshalIndent(stats, "", "  ")
 	if err != nil {
-		fmt.Printf("Ошибка при форматировании статистики: %v\n", err)
+		fmt.Printf("Ошибка при форматировании статистики: %v\n", err)
+		return
+	}
+	fmt.Printf("\nСтатистика контейнера:\n%s\n", string(jsonData))
+}
+
+func (m *Menu) containerProcesses() {
+	fmt.Print("Введите ID контейнера: ")
+	containerID := m.readInput()
+
+	processes, err := m.dockerAdapter.GetContainerProcesses(containerID)
+	if err != nil {
+		fmt.Printf("Ошибка при получении списка процессов: %v\n", err)
+		return
+	}
+
+	fmt.Println("\nПроцессы в контейнере:")
+	for _, proc := range processes {
+		fmt.Println(strings.Join(proc, "\t"))
+	}
+}
+
+func (m *Menu) createNetwork() {
+	fmt.Print("Введите имя сети: ")
+	name := m.readInput()
+	fmt.Print("Введите драйвер сети (bridge/host/none): ")
+	driver := m.readInput()
+
+	options := make(map[string]string)
+	fmt.Print("Введите опции сети (формат: KEY=VALUE, пустая строка для завершения): ")
+	for {
+		opt := m.readInput()
+		if opt == "" {
+			break
+		}
+		parts := strings.SplitN(opt, "=", 2)
+		if len(parts) == 2 {
+			options[parts[0]] = parts[1]
+		}
+	}
+
+	networkID, err := m.dockerAdapter.CreateNetwork(name, driver, options)
+	if err != nil {
+		fmt.Printf("Ошибка при создании сети: %v\n", err)
+		return
+	}
+	fmt.Printf("Сеть успешно создана. ID: %s\n", networkID)
+}
+
+func (m *Menu) listNetworks() {
+	networks, err := m.dockerAdapter.ListNetworks()
+	if err != nil {
+		fmt.Printf("Ошибка при получении списка сетей: %v\n", err)
+		return
+	}
+
+	fmt.Println("\nСписок сетей:")
+	for _, network := range networks {
+		fmt.Printf("ID: %s\n", network.ID)
+		fmt.Printf("Имя: %s\n", network.Name)
+		fmt.Printf("Драйвер: %s\n", network.Driver)
+		fmt.Printf("Область: %s\n", network.Scope)
+		fmt.Println("---")
+	}
+}
+
+func (m *Menu) connectContainerToNetwork() {
+	fmt.Print("Введите ID контейнера: ")
+	containerID := m.readInput()
+	fmt.Print("Введите ID сети: ")
+	networkID := m.readInput()
+
+	err := m.dockerAdapter.ConnectContainerToNetwork(containerID, networkID)
+	if err != nil {
+		fmt.Printf("Ошибка при подключении контейнера к сети: %v\n", err)
+		return
+	}
+	fmt.Println("Контейнер успешно подключен к сети")
+}
+
+func (m *Menu) disconnectContainerFromNetwork() {
+	fmt.Print("Введите ID контейнера: ")
+	containerID := m.readInput()
+	fmt.Print("Введите ID сети: ")
+	networkID := m.readInput()
+
+	err := m.dockerAdapter.DisconnectContainerFromNetwork(containerID, networkID)
+	if err != nil {
+		fmt.Printf("Ошибка при отключении контейнера от сети: %v\n", err)
+		return
+	}
+	fmt.Println("Контейнер успешно отключен от сети")
+}
+
+func (m *Menu) removeNetwork() {
+	fmt.Print("Введите ID или имя сети: ")
+	networkID := m.readInput()
+	fmt.Print("Отключить подключенные контейнеры перед удалением? (y/N): ")
+	disconnect := strings.ToLower(m.readInput()) == "y"
+
+	action := fmt.Sprintf("Удалить сеть %s", networkID)
+	if disconnect {
+		action += " с отключением подключенных контейнеров"
+	}
+	if !m.confirmDestructive(action) {
+		return
+	}
+
+	err := m.dockerAdapter.RemoveNetwork(networkID, disconnect)
+	if err != nil {
+		fmt.Printf("Ошибка при удалении сети: %v\n", err)
+		return
+	}
+	fmt.Println("Сеть успешно удалена")
+}
+
+func (m *Menu) pruneSystem() {
+	if !m.confirmDestructive("Удалить остановленные контейнеры, неиспользуемые образы и сети") {
+		return
+	}
+
+	err := m.dockerAdapter.PruneSystem()
+	if err != nil {
+		fmt.Printf("Ошибка при очистке системы: %v\n", err)
+		return
+	}
+	fmt.Println("Система успешно очищена")
+}
+
+func (m *Menu) pruneStoppedContainers() {
+	hours, ok := m.readInt("Удалить остановленные контейнеры старше скольких часов: ", 0, math.MaxInt32)
+	if !ok {
+		return
+	}
+
+	if !m.confirmDestructive(fmt.Sprintf("Удалить остановленные контейнеры старше %d ч", hours)) {
+		return
+	}
+
+	removed, reclaimed, err := m.dockerAdapter.PruneStoppedContainers(time.Duration(hours) * time.Hour)
+	if err != nil {
+		fmt.Printf("Ошибка при удалении контейнеров: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Удалено контейнеров: %d, освобождено %d байт\n", len(removed), reclaimed)
+	for _, id := range removed {
+		fmt.Printf("- %s\n", id)
+	}
+}
+
+func (m *Menu) pruneOldImageTags() {
+	perRepo, ok := m.readInt("Сколько последних тегов оставить в каждом репозитории: ", 0, math.MaxInt32)
+	if !ok {
+		return
+	}
+
+	if !m.confirmDestructive(fmt.Sprintf("Удалить теги образов, кроме %d последних в каждом репозитории", perRepo)) {
+		return
+	}
+
+	removed, reclaimed, err := m.dockerAdapter.PruneImagesKeepLatest(perRepo)
+	if err != nil {
+		fmt.Printf("Ошибка при удалении образов: %v\n", err)
+	}
+
+	fmt.Printf("Удалено тегов: %d, освобождено %d байт\n", len(removed), reclaimed)
+	for _, ref := range removed {
+		fmt.Printf("- %s\n", ref)
+	}
+}
+
+func (m *Menu) describeDockerObject() {
+	fmt.Print("Введите вид объекта (container, image, network, volume): ")
+	kind := m.readInput()
+	fmt.Print("Введите ID или имя: ")
+	id := m.readInput()
+
+	text, err := m.dockerAdapter.Describe(kind, id)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+	fmt.Printf("\n%s", text)
+}
+
+func (m *Menu) selectDockerContext() {
+	contexts, err := docker.ListDockerContexts()
+	if err != nil {
+		fmt.Printf("Ошибка при получении списка Docker context: %v\n", err)
+		return
+	}
+
+	fmt.Println("\nDocker context (* - текущий):")
+	for i, dockerContext := range contexts {
+		marker := " "
+		if dockerContext.Current {
+			marker = "*"
+		}
+		host := dockerContext.Host
+		if host == "" {
+			host = "локальный daemon"
+		}
+		fmt.Printf("%d. %s %s (%s) %s\n", i+1, marker, dockerContext.Name, host, dockerContext.Description)
+	}
+
+	n, ok := m.readInt("Выберите context: ", 1, len(contexts))
+	if !ok {
+		return
+	}
+	name := contexts[n-1].Name
+
+	adapter, err := docker.NewDockerAdapterForContext(name, m.dockerSettings.registry, m.monitoringAdapter)
+	if err != nil {
+		fmt.Printf("Ошибка при подключении к Docker context %s: %v\n", name, err)
+		return
+	}
+	m.dockerSettings.apply(adapter)
+
+	if err := m.dockerAdapter.Close(); err != nil {
+		fmt.Printf("Предупреждение: ошибка при закрытии прежнего Docker клиента: %v\n", err)
+	}
+	m.dockerAdapter = adapter
+	fmt.Printf("Используется Docker context %s\n", name)
+}
+
+func (m *Menu) showDiskUsage() {
+	usage, err := m.dockerAdapter.GetDiskUsage()
+	if err != nil {
+		fmt.Printf("Ошибка при получении использования диска: %v\n", err)
+		return
+	}
+
+	categories := []struct {
+		name     string
+		category docker.DiskUsageCategory
+	}{
+		{"Образы", usage.Images},
+		{"Контейнеры", usage.Containers},
+		{"Тома", usage.Volumes},
+		{"Кеш сборки", usage.BuildCache},
+	}
+
+	fmt.Printf("\n%-12s %8s %8s %12s %14s\n", "ТИП", "ВСЕГО", "АКТИВНО", "РАЗМЕР", "ОСВОБОДИМО")
+	for _, c := range categories {
+		reclaimable := units.HumanSize(float64(c.category.Reclaimable))
+		if c.category.Size > 0 {
+			reclaimable += fmt.Sprintf(" (%d%%)", c.category.Reclaimable*100/c.category.Size)
+		}
+		fmt.Printf("%-12s %8d %8d %12s %14s\n", c.name, c.category.Count, c.category.Active,
+			units.HumanSize(float64(c.category.Size)), reclaimable)
+	}
+}
+
+func (m *Menu) systemInfo() {
+	info, err := m.dockerAdapter.GetSystemInfo()
+	if err != nil {
+		fmt.Printf("Ошибка при получении системной информации: %v\n", err)
+		return
+	}
+
+	jsonData, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		fmt.Printf("Ошибка при форматировании информации: %v\n", err)
+		return
+	}
+	fmt.Printf("\nСистемная информация:\n%s\n", string(jsonData))
+}
+
+// Kubernetes методы
+func (m *Menu) deployManifest() {
+	fmt.Print("Введите путь к YAML файлу манифеста: ")
+	manifestPath := m.readInput()
+
+	if m.askYesNo("Дождаться готовности Deployment, StatefulSet и DaemonSet?") {
+		if err := m.k8sAdapter.ApplyAndWait(manifestPath, 5*time.Minute); err != nil {
+			fmt.Printf("Ошибка: %v\n", err)
+			return
+		}
+		fmt.Println("Манифест применен, все рабочие нагрузки готовы")
+		return
+	}
+
+	err := m.k8sAdapter.ApplyManifest(manifestPath)
+	if err != nil {
+		fmt.Printf("Ошибка при применении манифеста: %v\n", err)
+		return
+	}
+	fmt.Println("Манифест успешно применен")
+}
+
+func (m *Menu) addResourceLabels() {
+	fmt.Print("Введите вид ресурса (например, deployment): ")
+	kind := m.readInput()
+	fmt.Print("Введите имя ресурса: ")
+	name := m.readInput()
+	fmt.Print("Введите namespace (пусто - default): ")
+	namespace := m.readInput()
+
+	fmt.Println("1. Метки")
+	fmt.Println("2. Аннотации")
+	fmt.Print("Что добавить: ")
+	choice := m.readInput()
+	if choice != "1" && choice != "2" {
+		fmt.Println("Неверный выбор")
+		return
+	}
+
+	fmt.Print("Введите пары key=value через запятую: ")
+	values := make(map[string]string)
+	for _, item := range strings.Split(m.readInput(), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(item, "=")
+		values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	var err error
+	if choice == "1" {
+		err = m.k8sAdapter.AddLabels(namespace, kind, name, values)
+	} else {
+		err = m.k8sAdapter.AddAnnotations(namespace, kind, name, values)
+	}
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+	fmt.Printf("Ресурс %s/%s обновлен\n", kind, name)
+}
+
+func (m *Menu) diffManifest() {
+	fmt.Print("Введите путь к YAML файлу манифеста: ")
+	manifestPath := m.readInput()
+
+	diff, err := m.k8sAdapter.DiffManifest(manifestPath)
+	if err != nil {
+		fmt.Printf("Ошибка при сравнении манифеста: %v\n", err)
+		return
+	}
+	if diff == "" {
+		fmt.Println("Манифест совпадает с состоянием кластера")
+		return
+	}
+	fmt.Print(diff)
+}
+
+func (m *Menu) deployDirectory() {
+	fmt.Print("Введите путь к директории с манифестами: ")
+	dir := m.readInput()
+	fmt.Print("Продолжать при ошибках? (y/N): ")
+	m.k8sAdapter.SetContinueOnError(strings.ToLower(m.readInput()) == "y")
+
+	results, err := m.k8sAdapter.ApplyDirectory(dir)
+	for _, res := range results {
+		if res.Error != nil {
+			fmt.Printf("- %s/%s (%s): ошибка: %v\n", res.Kind, res.Name, res.Source, res.Error)
+			continue
+		}
+		fmt.Printf("- %s/%s (%s): %s\n", res.Kind, res.Name, res.Source, res.Action)
+	}
+	if err != nil {
+		fmt.Printf("Ошибка при применении манифестов: %v\n", err)
+		return
+	}
+	fmt.Println("Манифесты успешно применены")
+}
+
+func (m *Menu) deployManifestTemplate() {
+	fmt.Print("Введите путь к шаблону манифеста: ")
+	manifestPath := m.readInput()
+
+	vars := make(map[string]string)
+	fmt.Println("Введите переменные (формат: VAR=VALUE, пустая строка для завершения):")
+	for {
+		line := m.readInput()
+		if line == "" {
+			break
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) == 2 {
+			vars[parts[0]] = parts[1]
+		}
+	}
+
+	err := m.k8sAdapter.ApplyManifestTemplate(manifestPath, vars)
+	if err != nil {
+		fmt.Printf("Ошибка при применении шаблона: %v\n", err)
+		return
+	}
+	fmt.Println("Манифест успешно применен")
+}
+
+func (m *Menu) scaleDeployment() {
+	fmt.Print("Введите имя деплоймента: ")
+	name := m.readInput()
+	replicas, ok := m.readInt("Введите новое количество реплик: ", 0, math.MaxInt32)
+	if !ok {
+		return
+	}
+
+	err := m.k8sAdapter.Scale("default", name, int32(replicas))
+	if err != nil {
+		fmt.Printf("Ошибка при масштабировании деплоймента: %v\n", err)
+		return
+	}
+	fmt.Println("Деплоймент успешно масштабирован")
+}
+
+func (m *Menu) setDeploymentImage() {
+	fmt.Print("Введите имя деплоймента: ")
+	name := m.readInput()
+	fmt.Print("Введите имя контейнера: ")
+	container := m.readInput()
+	fmt.Print("Введите новый образ: ")
+	image := m.readInput()
+
+	err := m.k8sAdapter.SetDeploymentImage("default", name, container, image)
+	if err != nil {
+		fmt.Printf("Ошибка при обновлении образа: %v\n", err)
+		return
+	}
+	fmt.Println("Образ обновлен, rollout запущен")
+
+	fmt.Print("Дождаться завершения rollout? (y/N): ")
+	if strings.ToLower(m.readInput()) != "y" {
+		return
+	}
+
+	fmt.Println("Ожидание завершения rollout...")
+	if err := m.k8sAdapter.WaitForRollout("default", name, 5*time.Minute); err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+	fmt.Println("Rollout успешно завершен")
+}
+
+func (m *Menu) restartAllDeployments() {
+	fmt.Print("Введите namespace: ")
+	namespace := m.readInput()
+
+	target := namespace
+	if target == "" {
+		target = "all"
+	}
+	if !m.confirmDestructive(fmt.Sprintf("Перезапустить все деплойменты в namespace %s", target)) {
+		return
+	}
+
+	restart := m.k8sAdapter.RestartAllDeployments
+	if namespace == "" || namespace == "kube-system" {
+		fmt.Println("Внимание: перезапуск в системном namespace или во всех namespace сразу может нарушить работу кластера")
+		fmt.Print("Для продолжения введите force: ")
+		if m.readInput() != "force" {
+			fmt.Println("Операция отменена")
+			return
+		}
+		restart = m.k8sAdapter.ForceRestartAllDeployments
+	}
+
+	fmt.Printf("Все деплойменты будут перезапущены. Для подтверждения введите имя namespace (%s): ", target)
+	if m.readInput() != target {
+		fmt.Println("Операция отменена")
+		return
+	}
+
+	restarted, err := restart(namespace)
+	for _, name := range restarted {
+		fmt.Printf("- %s: перезапущен\n", name)
+	}
+	if err != nil {
+		fmt.Printf("Ошибка при перезапуске деплойментов: %v\n", err)
 		return
 	}
-	fmt.Printf("\nСтатистика контейнера:\n%s\n", string(jsonData))
+	fmt.Printf("Перезапущено деплойментов: %d\n", len(restarted))
 }
 
-func (m *Menu) containerProcesses() {
-	fmt.Print("Введите ID контейнера: ")
-	containerID := m.readInput()
+func (m *Menu) waitForJob() {
+	fmt.Print("Введите namespace (Enter - default): ")
+	namespace := m.readInput()
+	if namespace == "" {
+		namespace = "default"
+	}
+	fmt.Print("Введите имя Job: ")
+	name := m.readInput()
+	seconds, ok := m.readIntDefault("Время ожидания в секундах (Enter - 600): ", 600, 1, math.MaxInt32)
+	if !ok {
+		return
+	}
+	timeout := time.Duration(seconds) * time.Second
 
-	processes, err := m.dockerAdapter.GetContainerProcesses(containerID)
+	fmt.Printf("Ожидание завершения Job %s...\n", name)
+	succeeded, err := m.k8sAdapter.WaitForJob(context.Background(), namespace, name, timeout)
 	if err != nil {
-		fmt.Printf("Ошибка при получении списка процессов: %v\n", err)
+		fmt.Printf("Ошибка при ожидании Job: %v\n", err)
 		return
 	}
-
-	fmt.Println("\nПроцессы в контейнере:")
-	for _, proc := range processes {
-		fmt.Println(strings.Join(proc, "\t"))
+	if !succeeded {
+		fmt.Printf("Job %s завершился с ошибкой\n", name)
+		return
 	}
+	fmt.Printf("Job %s успешно завершен\n", name)
 }
 
-func (m *Menu) createNetwork() {
-	fmt.Print("Введите имя сети: ")
-	name := m.readInput()
-	fmt.Print("Введите драйвер сети (bridge/host/none): ")
-	driver := m.readInput()
-
-	options := make(map[string]string)
-	fmt.Print("Введите опции сети (формат: KEY=VALUE, пустая строка для завершения): ")
+func (m *Menu) getPodStatuses() {
+	namespace := m.readListNamespace()
+	page := kubernetes.ListPage{Limit: cliPageSize}
+	fmt.Println("\nСписок подов:")
 	for {
-		opt := m.readInput()
-		if opt == "" {
-			break
+		pods, next, err := m.k8sAdapter.GetPodStatusesPage(namespace, page)
+		if err != nil {
+			fmt.Printf("Ошибка при получении списка подов: %v\n", err)
+			return
 		}
-		parts := strings.SplitN(opt, "=", 2)
-		if len(parts) == 2 {
-			options[parts[0]] = parts[1]
+
+		for _, pod := range pods {
+			fmt.Printf("Имя: %s\n", pod.Name)
+			fmt.Printf("Namespace: %s\n", pod.Namespace)
+			fmt.Printf("Статус: %s\n", pod.Status)
+			fmt.Printf("IP: %s\n", pod.IP)
+			fmt.Printf("Готов: %v\n", pod.Ready)
+			fmt.Printf("Рестарты: %d\n", pod.Restarts)
+			fmt.Println("---")
+		}
+
+		if !m.nextPage(next) {
+			return
 		}
+		page.Continue = next
 	}
+}
 
-	networkID, err := m.dockerAdapter.CreateNetwork(name, driver, options)
-	if err != nil {
-		fmt.Printf("Ошибка при создании сети: %v\n", err)
-		return
+// readListNamespace запрашивает namespace для списка ресурсов.
+// all выбирает все namespaces кластера.
+func (m *Menu) readListNamespace() string {
+	fmt.Print("Введите namespace (Enter - default, all - все namespaces): ")
+	switch namespace := m.readInput(); namespace {
+	case "":
+		return "default"
+	case "all":
+		return kubernetes.AllNamespaces
+	default:
+		return namespace
 	}
-	fmt.Printf("Сеть успешно создана. ID: %s\n", networkID)
 }
 
-func (m *Menu) listNetworks() {
-	networks, err := m.dockerAdapter.ListNetworks()
-	if err != nil {
-		fmt.Printf("Ошибка при получении списка сетей: %v\n", err)
-		return
+// namespaceLabel возвращает подпись namespace для сообщений
+func namespaceLabel(namespace string) string {
+	if namespace == kubernetes.AllNamespaces {
+		return "всех namespaces"
 	}
+	return "namespace " + namespace
+}
 
-	fmt.Println("\nСписок сетей:")
-	for _, network := range networks {
-		fmt.Printf("ID: %s\n", network.ID)
-		fmt.Printf("Имя: %s\n", network.Name)
-		fmt.Printf("Драйвер: %s\n", network.Driver)
-		fmt.Printf("Область: %s\n", network.Scope)
-		fmt.Println("---")
+// nextPage спрашивает, показывать ли следующую страницу списка. Запрос
+// выводится в stderr, чтобы не попасть в перенаправленный вывод списка.
+func (m *Menu) nextPage(next string) bool {
+	if next == "" {
+		return false
 	}
+	fmt.Fprint(os.Stderr, "Показать следующую страницу? (y/N): ")
+	return strings.ToLower(m.readInput()) == "y"
 }
 
-func (m *Menu) connectContainerToNetwork() {
-	fmt.Print("Введите ID контейнера: ")
-	containerID := m.readInput()
-	fmt.Print("Введите ID сети: ")
-	networkID := m.readInput()
+func (m *Menu) namespaceSummary() {
+	fmt.Print("Введите namespace (Enter - default): ")
+	namespace := m.readInput()
+	if namespace == "" {
+		namespace = "default"
+	}
 
-	err := m.dockerAdapter.ConnectContainerToNetwork(containerID, networkID)
+	summary, err := m.k8sAdapter.GetNamespaceSummary(namespace)
 	if err != nil {
-		fmt.Printf("Ошибка при подключении контейнера к сети: %v\n", err)
+		fmt.Printf("Ошибка при получении сводки: %v\n", err)
 		return
 	}
-	fmt.Println("Контейнер успешно подключен к сети")
+
+	fmt.Printf("\nNamespace: %s\n", summary.Namespace)
+	fmt.Printf("Деплойменты: %d (готовы: %d)\n", summary.Deployments, summary.ReadyDeployments)
+	fmt.Printf("Поды: %d (работают: %d)\n", summary.Pods, summary.RunningPods)
+	fmt.Printf("Сервисы: %d\n", summary.Services)
+
+	if len(summary.NotReadyPods) > 0 {
+		fmt.Println("\nНеготовые поды:")
+		for _, pod := range summary.NotReadyPods {
+			fmt.Printf("- %s: %s, рестарты: %d\n", pod.Name, pod.Status, pod.Restarts)
+		}
+	}
+
+	if len(summary.Warnings) > 0 {
+		fmt.Println("\nПоследние предупреждения:")
+		for _, event := range summary.Warnings {
+			fmt.Printf("- [%s] %s %s: %s (x%d)\n", event.LastSeen.Local().Format("15:04:05"), event.Object, event.Reason, event.Message, event.Count)
+		}
+	}
 }
 
-func (m *Menu) disconnectContainerFromNetwork() {
-	fmt.Print("Введите ID контейнера: ")
-	containerID := m.readInput()
-	fmt.Print("Введите ID сети: ")
-	networkID := m.readInput()
+func (m *Menu) deploymentsHealth() {
+	fmt.Print("Введите namespace (Enter - default): ")
+	namespace := m.readInput()
+	if namespace == "" {
+		namespace = "default"
+	}
 
-	err := m.dockerAdapter.DisconnectContainerFromNetwork(containerID, networkID)
+	health, err := m.k8sAdapter.GetDeploymentsHealth(namespace)
 	if err != nil {
-		fmt.Printf("Ошибка при отключении контейнера от сети: %v\n", err)
+		fmt.Printf("Ошибка при получении здоровья деплойментов: %v\n", err)
 		return
 	}
-	fmt.Println("Контейнер успешно отключен от сети")
+	if len(health) == 0 {
+		fmt.Printf("В namespace %s нет деплойментов\n", namespace)
+		return
+	}
+
+	degraded := 0
+	fmt.Printf("\n%-40s %10s %s\n", "ДЕПЛОЙМЕНТ", "ГОТОВО", "СОСТОЯНИЕ")
+	for _, deployment := range health {
+		state := "здоров"
+		if !deployment.Healthy {
+			state = "деградировал"
+			degraded++
+		}
+		fmt.Printf("%-40s %10s %s\n", deployment.Name, fmt.Sprintf("%d/%d", deployment.Ready, deployment.Desired), state)
+	}
+	fmt.Printf("\nДеградировали: %d из %d\n", degraded, len(health))
 }
 
-func (m *Menu) pruneSystem() {
-	err := m.dockerAdapter.PruneSystem()
+func (m *Menu) topPods() {
+	pods, err := m.k8sAdapter.TopPods("default")
 	if err != nil {
-		fmt.Printf("Ошибка при очистке системы: %v\n", err)
+		fmt.Printf("Ошибка при получении метрик подов: %v\n", err)
 		return
 	}
-	fmt.Println("Система успешно очищена")
+
+	fmt.Printf("\n%-40s %10s %12s\n", "ПОД", "CPU", "ПАМЯТЬ")
+	for _, pod := range pods {
+		fmt.Printf("%-40s %9dm %10dMi\n", pod.Name, pod.CPUMilli, pod.MemoryBytes/(1024*1024))
+	}
 }
 
-func (m *Menu) systemInfo() {
-	info, err := m.dockerAdapter.GetSystemInfo()
+func (m *Menu) topNodes() {
+	nodes, err := m.k8sAdapter.TopNodes()
 	if err != nil {
-		fmt.Printf("Ошибка при получении системной информации: %v\n", err)
+		fmt.Printf("Ошибка при получении метрик узлов: %v\n", err)
 		return
 	}
 
-	jsonData, err := json.MarshalIndent(info, "", "  ")
-	if err != nil {
-		fmt.Printf("Ошибка при форматировании информации: %v\n", err)
-		return
+	fmt.Printf("\n%-30s %10s %6s %12s %6s\n", "УЗЕЛ", "CPU", "CPU%", "ПАМЯТЬ", "ПАМ%")
+	for _, node := range nodes {
+		fmt.Printf("%-30s %9dm %5.0f%% %10dMi %5.0f%%\n",
+			node.Name, node.CPUMilli, node.CPUPercent, node.MemoryBytes/(1024*1024), node.MemoryPercent)
 	}
-	fmt.Printf("\nСистемная информация:\n%s\n", string(jsonData))
 }
 
-// Kubernetes методы
-func (m *Menu) deployManifest() {
-	fmt.Print("Введите путь к YAML файлу манифеста: ")
-	manifestPath := m.readInput()
-
-	err := m.k8sAdapter.ApplyManifest(manifestPath)
+func (m *Menu) listNodes() {
+	nodes, err := m.k8sAdapter.ListNodes()
 	if err != nil {
-		fmt.Printf("Ошибка при применении манифеста: %v\n", err)
+		fmt.Printf("Ошибка при получении списка узлов: %v\n", err)
 		return
 	}
-	fmt.Println("Манифест успешно применен")
+
+	fmt.Printf("\n%-30s %-10s %-22s %-20s %s\n", "УЗЕЛ", "СТАТУС", "ПЛАНИРОВАНИЕ", "РОЛИ", "ВЕРСИЯ")
+	for _, node := range nodes {
+		status := "NotReady"
+		if node.Ready {
+			status = "Ready"
+		}
+		scheduling := "разрешено"
+		if !node.Schedulable {
+			scheduling = "запрещено (cordon)"
+		}
+		roles := strings.Join(node.Roles, ",")
+		if roles == "" {
+			roles = "<none>"
+		}
+		fmt.Printf("%-30s %-10s %-22s %-20s %s\n", node.Name, status, scheduling, roles, node.KubeletVersion)
+	}
 }
 
-func (m *Menu) scaleDeployment() {
-	fmt.Print("Введите имя деплоймента: ")
+func (m *Menu) cordonNode() {
+	fmt.Print("Введите имя узла: ")
 	name := m.readInput()
-	fmt.Print("Введите новое количество реплик: ")
-	replicas := m.readInput()
 
-	replicasInt, err := strconv.Atoi(replicas)
-	if err != nil {
-		fmt.Println("Ошибка: введите корректное число реплик")
+	if err := m.k8sAdapter.CordonNode(name); err != nil {
+		fmt.Printf("Ошибка при запрете планирования: %v\n", err)
 		return
 	}
+	fmt.Printf("Планирование на узел %s запрещено\n", name)
+}
 
-	err = m.k8sAdapter.Scale("default", name, int32(replicasInt))
-	if err != nil {
-		fmt.Printf("Ошибка при масштабировании деплоймента: %v\n", err)
+func (m *Menu) uncordonNode() {
+	fmt.Print("Введите имя узла: ")
+	name := m.readInput()
+
+	if err := m.k8sAdapter.UncordonNode(name); err != nil {
+		fmt.Printf("Ошибка при разрешении планирования: %v\n", err)
 		return
 	}
-	fmt.Println("Деплоймент успешно масштабирован")
+	fmt.Printf("Планирование на узел %s разрешено\n", name)
 }
 
-func (m *Menu) getPodStatuses() {
-	pods, err := m.k8sAdapter.GetPodStatuses("default")
-	if err != nil {
-		fmt.Printf("Ошибка при получении списка подов: %v\n", err)
+func (m *Menu) drainNode() {
+	fmt.Print("Введите имя узла: ")
+	name := m.readInput()
+
+	if !m.confirmDestructive(fmt.Sprintf("Закрыть узел %s для планирования и вытеснить его поды", name)) {
 		return
 	}
 
-	fmt.Println("\nСписок подов:")
-	for _, pod := range pods {
-		fmt.Printf("Имя: %s\n", pod.Name)
-		fmt.Printf("Статус: %s\n", pod.Status)
-		fmt.Printf("IP: %s\n", pod.IP)
-		fmt.Printf("Готов: %v\n", pod.Ready)
-		fmt.Printf("Рестарты: %d\n", pod.Restarts)
-		fmt.Println("---")
+	err := m.k8sAdapter.DrainNode(context.Background(), name, kubernetes.DrainOptions{
+		Progress: func(status kubernetes.PodDrainStatus) {
+			switch status.Action {
+			case "evicted":
+				fmt.Printf("- %s/%s: вытеснен\n", status.Namespace, status.Name)
+			case "skipped":
+				fmt.Printf("- %s/%s: пропущен (%s)\n", status.Namespace, status.Name, status.Reason)
+			default:
+				fmt.Printf("- %s/%s: ошибка: %v\n", status.Namespace, status.Name, status.Err)
+			}
+		},
+	})
+	if err != nil {
+		fmt.Printf("Ошибка при выводе узла на обслуживание: %v\n", err)
+		return
 	}
+	fmt.Printf("Узел %s выведен на обслуживание\n", name)
 }
 
 func (m *Menu) getDeploymentStatus() {
@@ -861,7 +2651,8 @@ func (m *Menu) getDeploymentStatus() {
 }
 
 func (m *Menu) listServicesAndIngresses() {
-	services, ingresses, err := m.k8sAdapter.GetServicesAndIngresses("default")
+	namespace := m.readListNamespace()
+	services, ingresses, err := m.k8sAdapter.GetServicesAndIngresses(namespace)
 	if err != nil {
 		fmt.Printf("Ошибка при получении списка сервисов и ингрессов: %v\n", err)
 		return
@@ -870,6 +2661,7 @@ func (m *Menu) listServicesAndIngresses() {
 	fmt.Println("\nСервисы:")
 	for _, svc := range services {
 		fmt.Printf("Имя: %s\n", svc.Name)
+		fmt.Printf("Namespace: %s\n", svc.Namespace)
 		fmt.Printf("Тип: %s\n", svc.Type)
 		fmt.Printf("Cluster IP: %s\n", svc.ClusterIP)
 		if svc.ExternalIP != "" {
@@ -883,6 +2675,7 @@ func (m *Menu) listServicesAndIngresses() {
 	fmt.Println("\nИнгрессы:")
 	for _, ing := range ingresses {
 		fmt.Printf("Имя: %s\n", ing.Name)
+		fmt.Printf("Namespace: %s\n", ing.Namespace)
 		fmt.Printf("Хосты: %v\n", ing.Hosts)
 		if len(ing.Addresses) > 0 {
 			fmt.Printf("Адреса: %v\n", ing.Addresses)
@@ -892,6 +2685,34 @@ func (m *Menu) listServicesAndIngresses() {
 	}
 }
 
+func (m *Menu) createIngress() {
+	fmt.Print("Введите namespace (Enter - default): ")
+	namespace := m.readInput()
+	if namespace == "" {
+		namespace = "default"
+	}
+	fmt.Print("Введите имя Ingress: ")
+	name := m.readInput()
+	fmt.Print("Введите хост (например, app.example.com): ")
+	host := m.readInput()
+	fmt.Print("Введите имя сервиса: ")
+	serviceName := m.readInput()
+	port, ok := m.readInt("Введите порт сервиса: ", 1, 65535)
+	if !ok {
+		return
+	}
+	fmt.Print("Префикс пути (Enter - /): ")
+	pathPrefix := m.readInput()
+	fmt.Print("Секрет с TLS сертификатом (Enter - без TLS): ")
+	tlsSecret := m.readInput()
+
+	if err := m.k8sAdapter.CreateIngress(namespace, name, host, serviceName, port, pathPrefix, tlsSecret); err != nil {
+		fmt.Printf("Ошибка при создании Ingress: %v\n", err)
+		return
+	}
+	fmt.Printf("Ingress %s создан: %s -> %s:%d\n", name, host, serviceName, port)
+}
+
 func (m *Menu) deleteResource() {
 	fmt.Println("\nДоступные типы ресурсов:")
 	fmt.Println("1. Pod")
@@ -951,10 +2772,7 @@ func (m *Menu) deleteResource() {
 	}
 
 	// Запрашиваем подтверждение
-	fmt.Printf("\nВы уверены, что хотите удалить %s '%s'? (y/N): ", resourceType, name)
-	confirm := m.readInput()
-	if strings.ToLower(confirm) != "y" {
-		fmt.Println("Удаление отменено")
+	if !m.confirmDestructive(fmt.Sprintf("Удалить %s '%s' в namespace default", resourceType, name)) {
 		return
 	}
 
@@ -986,33 +2804,39 @@ func (m *Menu) manageSecret() {
 	}
 }
 
-func (m *Menu) createOrUpdateSecret() {
-	fmt.Print("Введите имя секрета: ")
-	name := m.readInput()
-
+// selectSecretType предлагает выбрать тип секрета
+func (m *Menu) selectSecretType() (string, bool) {
 	fmt.Println("\nДоступные типы секретов:")
 	fmt.Println("1. Opaque (обычный секрет)")
 	fmt.Println("2. kubernetes.io/tls (TLS сертификат)")
 	fmt.Println("3. kubernetes.io/dockerconfigjson (Docker Registry)")
 	fmt.Print("Выберите тип секрета (1-3): ")
 
-	choice := m.readInput()
-	var secretType string
-
-	switch choice {
+	switch m.readInput() {
 	case "1":
-		secretType = "Opaque"
+		return "Opaque", true
 	case "2":
-		secretType = "kubernetes.io/tls"
+		return "kubernetes.io/tls", true
 	case "3":
-		secretType = "kubernetes.io/dockerconfigjson"
+		return "kubernetes.io/dockerconfigjson", true
 	default:
 		fmt.Println("Неверный выбор")
+		return "", false
+	}
+}
+
+func (m *Menu) createOrUpdateSecret() {
+	fmt.Print("Введите имя секрета: ")
+	name := m.readInput()
+
+	secretType, ok := m.selectSecretType()
+	if !ok {
 		return
 	}
 
 	data := make(map[string][]byte)
-	fmt.Println("\nВведите данные (формат: KEY=VALUE, пустая строка для завершения):")
+	fmt.Println("\nВнимание: ключи, которые не будут введены, будут удалены из секрета")
+	fmt.Println("Введите данные (формат: KEY=VALUE, пустая строка для завершения):")
 	for {
 		line := m.readInput()
 		if line == "" {
@@ -1024,7 +2848,23 @@ func (m *Menu) createOrUpdateSecret() {
 		}
 	}
 
-	err := m.k8sAdapter.CreateOrUpdateSecret("default", name, secretType, data)
+	diff, err := m.k8sAdapter.DiffSecret("default", name, data)
+	if err != nil {
+		fmt.Printf("Ошибка при сравнении секрета: %v\n", err)
+		return
+	}
+	if diff.Exists && !diff.HasChanges() {
+		fmt.Println("Изменений нет")
+		return
+	}
+	printSecretDiff(diff)
+	fmt.Print("Применить изменения? (y/N): ")
+	if strings.ToLower(m.readInput()) != "y" {
+		fmt.Println("Изменения отменены")
+		return
+	}
+
+	err = m.k8sAdapter.CreateOrUpdateSecret("default", name, secretType, data)
 	if err != nil {
 		fmt.Printf("Ошибка при создании/обновлении секрета: %v\n", err)
 		return
@@ -1032,6 +2872,89 @@ func (m *Menu) createOrUpdateSecret() {
 	fmt.Println("Секрет успешно создан/обновлен")
 }
 
+// printSecretDiff выводит изменения ключей секрета без значений
+func printSecretDiff(diff *kubernetes.SecretDiff) {
+	if !diff.Exists {
+		fmt.Println("\nСекрет будет создан")
+	}
+	fmt.Println("\nИзменения:")
+	for _, key := range diff.Added {
+		fmt.Printf("+ %s\n", key)
+	}
+	for _, key := range diff.Removed {
+		fmt.Printf("- %s\n", key)
+	}
+	for _, key := range diff.Changed {
+		fmt.Printf("~ %s (значение изменено)\n", key)
+	}
+}
+
+func (m *Menu) setSecretKey() {
+	fmt.Print("Введите имя секрета: ")
+	name := m.readInput()
+	fmt.Print("Введите ключ: ")
+	key := m.readInput()
+	fmt.Print("Введите значение: ")
+	value := m.readInput()
+
+	err := m.k8sAdapter.SetSecretKey("default", name, key, []byte(value))
+	if err != nil {
+		fmt.Printf("Ошибка при изменении ключа: %v\n", err)
+		return
+	}
+	fmt.Printf("Ключ %s успешно изменен, остальные ключи сохранены\n", key)
+}
+
+func (m *Menu) deleteSecretKey() {
+	fmt.Print("Введите имя секрета: ")
+	name := m.readInput()
+	fmt.Print("Введите ключ: ")
+	key := m.readInput()
+
+	if !m.confirmDestructive(fmt.Sprintf("Удалить ключ %s из секрета %s", key, name)) {
+		return
+	}
+
+	err := m.k8sAdapter.DeleteSecretKey("default", name, key)
+	if err != nil {
+		fmt.Printf("Ошибка при удалении ключа: %v\n", err)
+		return
+	}
+	fmt.Printf("Ключ %s успешно удален\n", key)
+}
+
+func (m *Menu) exportSecret() {
+	fmt.Print("Введите имя секрета: ")
+	name := m.readInput()
+	fmt.Print("Введите директорию для экспорта: ")
+	dir := m.readInput()
+
+	err := m.k8sAdapter.ExportSecret("default", name, dir)
+	if err != nil {
+		fmt.Printf("Ошибка при экспорте секрета: %v\n", err)
+		return
+	}
+	fmt.Printf("Секрет %s экспортирован в %s (права 0600)\n", name, dir)
+}
+
+func (m *Menu) createSecretFromDir() {
+	fmt.Print("Введите имя секрета: ")
+	name := m.readInput()
+	secretType, ok := m.selectSecretType()
+	if !ok {
+		return
+	}
+	fmt.Print("Введите путь к директории с файлами: ")
+	dir := m.readInput()
+
+	err := m.k8sAdapter.CreateSecretFromDir("default", name, secretType, dir)
+	if err != nil {
+		fmt.Printf("Ошибка при создании секрета: %v\n", err)
+		return
+	}
+	fmt.Printf("Секрет %s успешно создан\n", name)
+}
+
 func (m *Menu) viewSecret() {
 	// Сначала показываем список секретов
 	secrets, err := m.k8sAdapter.ListSecrets("default")
@@ -1073,25 +2996,36 @@ func (m *Menu) viewSecret() {
 }
 
 func (m *Menu) listSecrets() {
-	secrets, err := m.k8sAdapter.ListSecrets("default")
-	if err != nil {
-		fmt.Printf("Ошибка при получении списка секретов: %v\n", err)
-		return
-	}
+	namespace := m.readListNamespace()
+	page := kubernetes.ListPage{Limit: cliPageSize}
+	for {
+		secrets, next, err := m.k8sAdapter.ListSecretsPage(namespace, page)
+		if err != nil {
+			fmt.Printf("Ошибка при получении списка секретов: %v\n", err)
+			return
+		}
 
-	if len(secrets) == 0 {
-		fmt.Println("Секреты не найдены в namespace default")
-		return
-	}
+		if len(secrets) == 0 && page.Continue == "" {
+			fmt.Printf("Секреты не найдены в %s\n", namespaceLabel(namespace))
+			return
+		}
 
-	fmt.Println("\nСписок секретов:")
-	for _, secret := range secrets {
-		fmt.Printf("\nИмя: %s\n", secret.Name)
-		fmt.Printf("Namespace: %s\n", secret.Namespace)
-		fmt.Printf("Тип: %s\n", secret.Type)
-		fmt.Printf("Возраст: %s\n", secret.Age.Round(time.Second))
-		fmt.Printf("Ключи: %v\n", secret.Keys)
-		fmt.Println("---")
+		if page.Continue == "" {
+			fmt.Println("\nСписок секретов:")
+		}
+		for _, secret := range secrets {
+			fmt.Printf("\nИмя: %s\n", secret.Name)
+			fmt.Printf("Namespace: %s\n", secret.Namespace)
+			fmt.Printf("Тип: %s\n", secret.Type)
+			fmt.Printf("Возраст: %s\n", secret.Age.Round(time.Second))
+			fmt.Printf("Ключи: %v\n", secret.Keys)
+			fmt.Println("---")
+		}
+
+		if !m.nextPage(next) {
+			return
+		}
+		page.Continue = next
 	}
 }
 
@@ -1101,33 +3035,79 @@ func (m *Menu) triggerPipeline() {
 		fmt.Println("Ошибка: CI/CD адаптер не инициализирован")
 		return
 	}
-
-	fmt.Print("Введите ID проекта: ")
-	projectID := m.readInput()
-	fmt.Print("Введите ветку или тег: ")
-	ref := m.readInput()
-
-	pipeline, err := m.cicdAdapter.TriggerPipeline(context.Background(), projectID, ref)
-	if err != nil {
-		fmt.Printf("Ошибка при запуске сборки: %v\n", err)
+
+	adapter := m.selectCICDInstance()
+	if adapter == nil {
+		return
+	}
+
+	fmt.Print("Введите ID проекта: ")
+	projectID := m.readInput()
+	fmt.Print("Введите ветку или тег: ")
+	ref := m.readInput()
+
+	pipeline, err := adapter.TriggerPipeline(context.Background(), projectID, ref)
+	if err != nil {
+		fmt.Printf("Ошибка при запуске сборки: %v\n", cicdError(err))
+		return
+	}
+	fmt.Printf("Сборка успешно запущена. ID: %s\n", pipeline.ID)
+
+	if !m.askYesNo("Дождаться завершения?") {
+		return
+	}
+
+	// Ctrl+C прекращает ожидание и возвращает в меню, сама сборка продолжается
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, pipelineWaitTimeout)
+	defer cancel()
+
+	fmt.Println("Ожидание завершения сборки (Ctrl+C для выхода)...")
+	updates, errs := adapter.WatchPipeline(ctx, projectID, pipeline.ID, 5*time.Second)
+	var status cicd.PipelineStatus
+	for status = range updates {
+		fmt.Printf("[%s] Статус сборки: %s\n", time.Now().Format("15:04:05"), status.Status)
+	}
+	if err := <-errs; err != nil {
+		switch {
+		case errors.Is(err, context.Canceled):
+			fmt.Println("Ожидание прервано, сборка продолжает выполняться")
+		case errors.Is(err, context.DeadlineExceeded):
+			fmt.Printf("Сборка не завершилась за %s, проверьте статус позже\n", pipelineWaitTimeout)
+		default:
+			fmt.Printf("Ошибка при ожидании сборки: %v\n", cicdError(err))
+		}
+		return
+	}
+	if !status.State.IsTerminal() {
+		fmt.Printf("Сборка остановилась со статусом %s и ждет действия в GitLab\n", status.Status)
 		return
 	}
-	fmt.Printf("Сборка успешно запущена. ID: %s\n", pipeline.ID)
+	fmt.Printf("Сборка завершена со статусом %s за %s\n", status.Status, status.Duration.Round(time.Second))
 }
 
 func (m *Menu) getPipelineStatus() {
+	adapter := m.selectCICDInstance()
+	if adapter == nil {
+		return
+	}
+
 	fmt.Print("Введите ID проекта: ")
 	projectID := m.readInput()
 	fmt.Print("Введите ID сборки: ")
 	pipelineID := m.readInput()
 
-	status, err := m.cicdAdapter.GetPipelineStatus(context.Background(), projectID, pipelineID)
+	status, err := adapter.GetPipelineStatus(context.Background(), projectID, pipelineID)
 	if err != nil {
-		fmt.Printf("Ошибка при получении статуса сборки: %v\n", err)
+		fmt.Printf("Ошибка при получении статуса сборки: %v\n", cicdError(err))
 		return
 	}
 
 	fmt.Printf("\nСтатус сборки: %s\n", status.Status)
+	if !status.State.IsTerminal() {
+		fmt.Println("Сборка еще не завершена")
+	}
 	fmt.Printf("Начало: %s\n", status.StartedAt.Format(time.RFC3339))
 	if !status.EndedAt.IsZero() {
 		fmt.Printf("Окончание: %s\n", status.EndedAt.Format(time.RFC3339))
@@ -1137,15 +3117,49 @@ func (m *Menu) getPipelineStatus() {
 	fmt.Printf("Сообщение: %s\n", status.Message)
 }
 
+func (m *Menu) getLatestPipeline() {
+	adapter := m.selectCICDInstance()
+	if adapter == nil {
+		return
+	}
+
+	fmt.Print("Введите ID проекта: ")
+	projectID := m.readInput()
+	fmt.Print("Введите ветку, тег или SHA коммита: ")
+	ref := m.readInput()
+
+	status, err := adapter.GetLatestPipelineForRef(context.Background(), projectID, ref)
+	if err != nil {
+		if errors.Is(err, cicd.ErrNoPipeline) {
+			fmt.Printf("Для %s не найдено ни одной сборки\n", ref)
+			return
+		}
+		fmt.Printf("Ошибка при поиске сборки: %v\n", cicdError(err))
+		return
+	}
+
+	fmt.Printf("\nПоследняя сборка: %s\n", status.ID)
+	fmt.Printf("Статус: %s\n", status.Status)
+	fmt.Printf("Ветка: %s\n", status.Branch)
+	fmt.Printf("Начало: %s\n", status.StartedAt.Format(time.RFC3339))
+	fmt.Printf("Автор: %s\n", status.Author)
+	fmt.Printf("Сообщение: %s\n", status.Message)
+}
+
 func (m *Menu) listPipelineJobs() {
+	adapter := m.selectCICDInstance()
+	if adapter == nil {
+		return
+	}
+
 	fmt.Print("Введите ID проекта: ")
 	projectID := m.readInput()
 	fmt.Print("Введите ID сборки: ")
 	pipelineID := m.readInput()
 
-	jobs, err := m.cicdAdapter.ListPipelineJobs(context.Background(), projectID, pipelineID)
+	jobs, err := adapter.ListPipelineJobs(context.Background(), projectID, pipelineID)
 	if err != nil {
-		fmt.Printf("Ошибка при получении списка задач: %v\n", err)
+		fmt.Printf("Ошибка при получении списка задач: %v\n", cicdError(err))
 		return
 	}
 
@@ -1165,50 +3179,86 @@ func (m *Menu) listPipelineJobs() {
 }
 
 func (m *Menu) viewJobLogs() {
+	adapter := m.selectCICDInstance()
+	if adapter == nil {
+		return
+	}
+
 	fmt.Print("Введите ID проекта: ")
 	projectID := m.readInput()
 	fmt.Print("Введите ID задачи: ")
 	jobID := m.readInput()
 
-	logs, err := m.cicdAdapter.GetJobLogs(context.Background(), projectID, jobID)
+	logs, err := adapter.GetJobLogs(context.Background(), projectID, jobID)
 	if err != nil {
-		fmt.Printf("Ошибка при получении логов: %v\n", err)
+		fmt.Printf("Ошибка при получении логов: %v\n", cicdError(err))
 		return
 	}
 
 	fmt.Println("\nЛоги задачи:")
 	fmt.Println(logs)
+
+	summary, err := cicd.ParseJobTestSummary(logs)
+	if err != nil {
+		return
+	}
+	fmt.Printf("\nИтоги тестов (%s): пройдено %d, упало %d, пропущено %d\n", summary.Format, summary.Passed, summary.Failed, summary.Skipped)
+	if summary.Success() {
+		fmt.Println("Все тесты прошли")
+		return
+	}
+	for _, name := range summary.FailedTests {
+		fmt.Printf("  FAIL %s\n", name)
+	}
+	for _, pkg := range summary.FailedPackages {
+		fmt.Printf("  FAIL пакет %s\n", pkg)
+	}
 }
 
 func (m *Menu) cancelPipeline() {
+	adapter := m.selectCICDInstance()
+	if adapter == nil {
+		return
+	}
+
 	fmt.Print("Введите ID проекта: ")
 	projectID := m.readInput()
 	fmt.Print("Введите ID сборки: ")
 	pipelineID := m.readInput()
 
-	err := m.cicdAdapter.CancelPipeline(context.Background(), projectID, pipelineID)
+	err := adapter.CancelPipeline(context.Background(), projectID, pipelineID)
 	if err != nil {
-		fmt.Printf("Ошибка при отмене сборки: %v\n", err)
+		fmt.Printf("Ошибка при отмене сборки: %v\n", cicdError(err))
 		return
 	}
 	fmt.Println("Сборка успешно отменена")
 }
 
 func (m *Menu) retryPipeline() {
+	adapter := m.selectCICDInstance()
+	if adapter == nil {
+		return
+	}
+
 	fmt.Print("Введите ID проекта: ")
 	projectID := m.readInput()
 	fmt.Print("Введите ID сборки: ")
 	pipelineID := m.readInput()
 
-	err := m.cicdAdapter.RetryPipeline(context.Background(), projectID, pipelineID)
+	err := adapter.RetryPipeline(context.Background(), projectID, pipelineID)
 	if err != nil {
-		fmt.Printf("Ошибка при перезапуске сборки: %v\n", err)
+		fmt.Printf("Ошибка при перезапуске сборки: %v\n", cicdError(err))
 		return
 	}
 	fmt.Println("Сборка успешно перезапущена")
 }
 
 func (m *Menu) downloadArtifacts() {
+	adapter := m.selectCICDInstance()
+	if adapter == nil {
+		return
+	}
+
 	fmt.Print("Введите ID проекта: ")
 	projectID := m.readInput()
 	fmt.Print("Введите ID задачи: ")
@@ -1216,24 +3266,242 @@ func (m *Menu) downloadArtifacts() {
 	fmt.Print("Введите путь для сохранения артефактов: ")
 	outputPath := m.readInput()
 
-	err := m.cicdAdapter.DownloadArtifacts(context.Background(), projectID, jobID, outputPath)
+	err := adapter.DownloadArtifacts(context.Background(), projectID, jobID, outputPath)
 	if err != nil {
-		fmt.Printf("Ошибка при скачивании артефактов: %v\n", err)
+		fmt.Printf("Ошибка при скачивании артефактов: %v\n", cicdError(err))
 		return
 	}
 	fmt.Printf("Артефакты успешно скачаны в %s\n", outputPath)
 }
 
+func (m *Menu) downloadArtifactFile() {
+	adapter := m.selectCICDInstance()
+	if adapter == nil {
+		return
+	}
+
+	fmt.Print("Введите ID проекта: ")
+	projectID := m.readInput()
+	fmt.Print("Введите ID задачи: ")
+	jobID := m.readInput()
+	fmt.Print("Введите путь файла в артефактах (например, coverage.xml): ")
+	artifactPath := m.readInput()
+	fmt.Printf("Введите путь для сохранения (по умолчанию %s): ", filepath.Base(artifactPath))
+	destPath := m.readInput()
+	if destPath == "" {
+		destPath = filepath.Base(artifactPath)
+	}
+
+	err := adapter.DownloadArtifactFile(context.Background(), projectID, jobID, artifactPath, destPath)
+	if errors.Is(err, cicd.ErrArtifactNotFound) {
+		fmt.Printf("В артефактах задачи нет файла %s\n", artifactPath)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Ошибка при скачивании файла: %v\n", cicdError(err))
+		return
+	}
+	fmt.Printf("Файл %s сохранен в %s\n", artifactPath, destPath)
+}
+
+func (m *Menu) browseArtifacts() {
+	adapter := m.selectCICDInstance()
+	if adapter == nil {
+		return
+	}
+
+	fmt.Print("Введите ID проекта: ")
+	projectID := m.readInput()
+	fmt.Print("Введите ID задачи: ")
+	jobID := m.readInput()
+
+	entries, err := adapter.ListArtifacts(context.Background(), projectID, jobID)
+	if err != nil {
+		fmt.Printf("Ошибка при получении списка артефактов: %v\n", cicdError(err))
+		return
+	}
+	if len(entries) == 0 {
+		fmt.Println("Архив артефактов пуст")
+		return
+	}
+
+	fmt.Println("\nАртефакты:")
+	for i, entry := range entries {
+		fmt.Printf("%d. %s (%d байт)\n", i+1, entry.Name, entry.Size)
+	}
+
+	fmt.Print("Введите номер файла для извлечения (пустая строка - выход): ")
+	choice := m.readInput()
+	if choice == "" {
+		return
+	}
+	index, err := strconv.Atoi(choice)
+	if err != nil || index < 1 || index > len(entries) {
+		fmt.Println("Неверный номер файла")
+		return
+	}
+	entry := entries[index-1]
+
+	fmt.Printf("Введите путь для сохранения (по умолчанию %s): ", filepath.Base(entry.Name))
+	outputPath := m.readInput()
+	if outputPath == "" {
+		outputPath = filepath.Base(entry.Name)
+	}
+
+	if err := adapter.DownloadArtifactFile(context.Background(), projectID, jobID, entry.Name, outputPath); err != nil {
+		fmt.Printf("Ошибка при извлечении артефакта: %v\n", cicdError(err))
+		return
+	}
+	fmt.Printf("Файл %s сохранен в %s\n", entry.Name, outputPath)
+}
+
 // Monitoring методы
 func (m *Menu) showRawMetrics() {
 	metrics, err := m.monitoringAdapter.GetRawMetrics(context.Background())
 	if err != nil {
-		fmt.Printf("Ошибка при получении метрик: %v\n", err)
+		m.printf("Ошибка при получении метрик: %v\n", err)
+		return
+	}
+
+	if m.output == "json" {
+		families, err := monitoring.ParseMetrics(metrics)
+		if err != nil {
+			m.printf("Ошибка при разборе метрик: %v\n", err)
+			return
+		}
+		data, err := json.MarshalIndent(families, "", "  ")
+		if err != nil {
+			m.printf("Ошибка при формировании JSON: %v\n", err)
+			return
+		}
+		m.println(string(data))
 		return
 	}
 
-	fmt.Println("\nМетрики:")
-	fmt.Println(metrics)
+	m.decorln("\nМетрики:")
+	m.println(metrics)
+}
+
+// watchHistory число последних значений, отображаемых в sparkline
+const watchHistory = 30
+
+func (m *Menu) watchMetric() {
+	fmt.Print("Введите имя метрики: ")
+	name := m.readInput()
+	fmt.Print("Интервал обновления (по умолчанию 2s): ")
+	interval := 2 * time.Second
+	if input := m.readInput(); input != "" {
+		parsed, err := time.ParseDuration(input)
+		if err != nil || parsed <= 0 {
+			fmt.Println("Ошибка: введите положительную длительность, например 5s")
+			return
+		}
+		interval = parsed
+	}
+
+	// Ctrl+C завершает наблюдение и возвращает в меню
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	history := make(map[string][]float64)
+	for {
+		values, err := m.monitoringAdapter.QueryMetric(ctx, name, time.Now().Add(-interval), time.Now())
+		// Очищаем экран и выводим текущее состояние
+		fmt.Print("\033[H\033[2J")
+		fmt.Printf("Метрика %s, обновление каждые %s (Ctrl+C для выхода)\n\n", name, interval)
+		if err != nil {
+			fmt.Printf("Ошибка при запросе метрики: %v\n", err)
+		}
+
+		for _, v := range values {
+			// QueryMetric ищет метрики по префиксу имени, поэтому ряды разных
+			// метрик с одинаковыми метками различаются по имени
+			series := v.Name + formatLabels(v.Labels)
+			points := append(history[series], v.Value)
+			if len(points) > watchHistory {
+				points = points[len(points)-watchHistory:]
+			}
+			history[series] = points
+		}
+		for _, series := range sortedSeries(history) {
+			points := history[series]
+			fmt.Printf("%-70s %12s  %s\n", series, formatMetricValue(points[len(points)-1]), sparkline(points))
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sparklineLevels символы sparkline от минимального значения к максимальному
+var sparklineLevels = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline рисует values строкой символов, масштабируя их между минимумом
+// и максимумом. Постоянный ряд рисуется нижним уровнем.
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low = math.Min(low, v)
+		high = math.Max(high, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if high > low {
+			level = int((v - low) / (high - low) * float64(len(sparklineLevels)-1))
+		}
+		b.WriteRune(sparklineLevels[level])
+	}
+	return b.String()
+}
+
+// formatMetricValue выводит целые значения без дробной части,
+// остальные - с точностью до трех знаков
+func formatMetricValue(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+// formatLabels выводит метки ряда в виде {k="v",...} с сортировкой по имени
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "{}"
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, labels[k]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// sortedSeries возвращает ряды history по возрастанию меток
+func sortedSeries(history map[string][]float64) []string {
+	series := make([]string, 0, len(history))
+	for s := range history {
+		series = append(series, s)
+	}
+	sort.Strings(series)
+	return series
 }
 
 func (m *Menu) queryMetric() {
@@ -1359,11 +3627,34 @@ func (m *Menu) showServiceHealth() {
 	}
 }
 
+func (m *Menu) exportGrafanaDashboard() {
+	fmt.Print("Введите путь к файлу (по умолчанию grafana-dashboard.json): ")
+	path := m.readInput()
+	if path == "" {
+		path = "grafana-dashboard.json"
+	}
+
+	data, err := m.monitoringAdapter.GenerateGrafanaDashboard()
+	if err != nil {
+		fmt.Printf("Ошибка при формировании дашборда: %v\n", err)
+		return
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		fmt.Printf("Ошибка при записи файла: %v\n", err)
+		return
+	}
+	fmt.Printf("Дашборд сохранен в %s, импортируйте его в Grafana (Dashboards -> Import)\n", path)
+}
+
 func (m *Menu) restartContainer() {
 	fmt.Print("Введите имя контейнера: ")
 	containerName := m.readInput()
-	fmt.Print("Введите таймаут в секундах (или оставьте пустым для значения по умолчанию): ")
-	timeoutStr := m.readInput()
+	// -1 означает таймаут остановки из настроек контейнера
+	seconds, ok := m.readIntDefault("Введите таймаут в секундах (или оставьте пустым для значения по умолчанию): ", -1, 0, math.MaxInt32)
+	if !ok {
+		return
+	}
 
 	containerID, err := m.dockerAdapter.GetContainerIDByName(containerName)
 	if err != nil {
@@ -1372,12 +3663,7 @@ func (m *Menu) restartContainer() {
 	}
 
 	var timeout *time.Duration
-	if timeoutStr != "" {
-		seconds, err := strconv.Atoi(timeoutStr)
-		if err != nil {
-			fmt.Println("Ошибка: введите корректное число секунд")
-			return
-		}
+	if seconds >= 0 {
 		duration := time.Duration(seconds) * time.Second
 		timeout = &duration
 	}
@@ -1457,6 +3743,7 @@ func (m *Menu) createOrUpdateConfigMap() {
 	name := m.readInput()
 
 	data := make(map[string]string)
+	fmt.Println("Внимание: ключи, которые не будут введены, будут удалены из ConfigMap")
 	fmt.Println("Введите данные (формат: KEY=VALUE, пустая строка для завершения):")
 	for {
 		line := m.readInput()
@@ -1469,7 +3756,23 @@ func (m *Menu) createOrUpdateConfigMap() {
 		}
 	}
 
-	err := m.k8sAdapter.CreateOrUpdateConfigMap("default", name, data)
+	diff, err := m.k8sAdapter.DiffConfigMap("default", name, data)
+	if err != nil {
+		fmt.Printf("Ошибка при сравнении ConfigMap: %v\n", err)
+		return
+	}
+	if diff.Exists && !diff.HasChanges() {
+		fmt.Println("Изменений нет")
+		return
+	}
+	printConfigMapDiff(diff)
+	fmt.Print("Применить изменения? (y/N): ")
+	if strings.ToLower(m.readInput()) != "y" {
+		fmt.Println("Изменения отменены")
+		return
+	}
+
+	err = m.k8sAdapter.CreateOrUpdateConfigMap("default", name, data)
 	if err != nil {
 		fmt.Printf("Ошибка при создании/обновлении ConfigMap: %v\n", err)
 		return
@@ -1477,6 +3780,129 @@ func (m *Menu) createOrUpdateConfigMap() {
 	fmt.Println("ConfigMap успешно создан/обновлен")
 }
 
+// printConfigMapDiff выводит добавленные, удаленные и измененные ключи
+func printConfigMapDiff(diff *kubernetes.ConfigMapDiff) {
+	if !diff.Exists {
+		fmt.Println("\nConfigMap будет создан")
+	}
+	fmt.Println("\nИзменения:")
+	for _, change := range diff.Added {
+		fmt.Printf("+ %s: %s\n", change.Key, change.NewValue)
+	}
+	for _, change := range diff.Removed {
+		fmt.Printf("- %s: %s\n", change.Key, change.OldValue)
+	}
+	for _, change := range diff.Changed {
+		fmt.Printf("~ %s: %s -> %s\n", change.Key, change.OldValue, change.NewValue)
+	}
+}
+
+func (m *Menu) setConfigMapKey() {
+	fmt.Print("Введите имя ConfigMap: ")
+	name := m.readInput()
+	fmt.Print("Введите ключ: ")
+	key := m.readInput()
+	fmt.Print("Введите значение: ")
+	value := m.readInput()
+
+	err := m.k8sAdapter.SetConfigMapKey("default", name, key, value)
+	if err != nil {
+		fmt.Printf("Ошибка при изменении ключа: %v\n", err)
+		return
+	}
+	fmt.Printf("Ключ %s успешно изменен, остальные ключи сохранены\n", key)
+}
+
+func (m *Menu) deleteConfigMapKey() {
+	fmt.Print("Введите имя ConfigMap: ")
+	name := m.readInput()
+	fmt.Print("Введите ключ: ")
+	key := m.readInput()
+
+	if !m.confirmDestructive(fmt.Sprintf("Удалить ключ %s из ConfigMap %s", key, name)) {
+		return
+	}
+
+	err := m.k8sAdapter.DeleteConfigMapKey("default", name, key)
+	if err != nil {
+		fmt.Printf("Ошибка при удалении ключа: %v\n", err)
+		return
+	}
+	fmt.Printf("Ключ %s успешно удален\n", key)
+}
+
+func (m *Menu) exportConfigMap() {
+	fmt.Print("Введите имя ConfigMap: ")
+	name := m.readInput()
+	fmt.Print("Введите директорию для экспорта: ")
+	dir := m.readInput()
+
+	err := m.k8sAdapter.ExportConfigMap("default", name, dir)
+	if err != nil {
+		fmt.Printf("Ошибка при экспорте ConfigMap: %v\n", err)
+		return
+	}
+	fmt.Printf("ConfigMap %s экспортирован в %s\n", name, dir)
+}
+
+// copyConfigObject копирует ConfigMap или Secret (kind) в другой namespace
+// функцией copyFn. Замена существующего объекта назначения (existsFn)
+// подтверждается как разрушающее действие.
+func (m *Menu) copyConfigObject(kind string, existsFn func(namespace, name string) (bool, error), copyFn func(srcNamespace, name, dstNamespace, newName string, overwrite bool) error) {
+	fmt.Printf("Введите имя %s: ", kind)
+	name := m.readInput()
+	fmt.Print("Введите исходный namespace (Enter - default): ")
+	srcNamespace := m.readInput()
+	if srcNamespace == "" {
+		srcNamespace = "default"
+	}
+	fmt.Print("Введите namespace назначения: ")
+	dstNamespace := m.readInput()
+	fmt.Print("Введите новое имя (Enter - то же): ")
+	newName := m.readInput()
+	if newName == "" {
+		newName = name
+	}
+	if name == "" || dstNamespace == "" {
+		fmt.Println("Ошибка: имя и namespace назначения обязательны")
+		return
+	}
+
+	var overwrite bool
+	exists, err := existsFn(dstNamespace, newName)
+	switch {
+	case err != nil:
+		fmt.Printf("Не удалось проверить %s %s/%s: %v\n", kind, dstNamespace, newName, err)
+		// В режиме --dry-run существующий объект не заменяется
+		overwrite = !m.dryRun && m.askYesNo(fmt.Sprintf("Заменить %s %s/%s, если он уже существует?", kind, dstNamespace, newName))
+	case exists:
+		if !m.confirmDestructive(fmt.Sprintf("Заменить существующий %s %s/%s", kind, dstNamespace, newName)) {
+			return
+		}
+		overwrite = true
+	}
+
+	if err := copyFn(srcNamespace, name, dstNamespace, newName, overwrite); err != nil {
+		fmt.Printf("Ошибка при копировании %s: %v\n", kind, err)
+		return
+	}
+	fmt.Printf("%s %s/%s скопирован в %s/%s\n", kind, srcNamespace, name, dstNamespace, newName)
+}
+
+func (m *Menu) createConfigMapFromDir() {
+	fmt.Print("Введите имя ConfigMap: ")
+	name := m.readInput()
+	fmt.Print("Введите путь к директории с файлами: ")
+	dir := m.readInput()
+
+	err := m.k8sAdapter.CreateConfigMapFromDir("default", name, dir)
+	if err != nil {
+		fmt.Printf("Ошибка при создании ConfigMap: %v\n", err)
+		return
+	}
+	fmt.Printf("ConfigMap %s успешно создан\n", name)
+}
+
 func (m *Menu) viewConfigMap() {
 	fmt.Print("Введите имя ConfigMap: ")
 	name := m.readInput()
@@ -1497,24 +3923,35 @@ func (m *Menu) viewConfigMap() {
 }
 
 func (m *Menu) listConfigMaps() {
-	configMaps, err := m.k8sAdapter.ListConfigMaps("default")
-	if err != nil {
-		fmt.Printf("Ошибка при получении списка ConfigMap: %v\n", err)
-		return
-	}
+	namespace := m.readListNamespace()
+	page := kubernetes.ListPage{Limit: cliPageSize}
+	for {
+		configMaps, next, err := m.k8sAdapter.ListConfigMapsPage(namespace, page)
+		if err != nil {
+			fmt.Printf("Ошибка при получении списка ConfigMap: %v\n", err)
+			return
+		}
 
-	if len(configMaps) == 0 {
-		fmt.Println("ConfigMap не найдены в namespace default")
-		return
-	}
+		if len(configMaps) == 0 && page.Continue == "" {
+			fmt.Printf("ConfigMap не найдены в %s\n", namespaceLabel(namespace))
+			return
+		}
 
-	fmt.Println("\nСписок ConfigMap:")
-	for _, cm := range configMaps {
-		fmt.Printf("\nИмя: %s\n", cm.Name)
-		fmt.Printf("Namespace: %s\n", cm.Namespace)
-		fmt.Printf("Возраст: %s\n", cm.Age.Round(time.Second))
-		fmt.Printf("Ключи: %v\n", cm.Keys)
-		fmt.Println("---")
+		if page.Continue == "" {
+			fmt.Println("\nСписок ConfigMap:")
+		}
+		for _, cm := range configMaps {
+			fmt.Printf("\nИмя: %s\n", cm.Name)
+			fmt.Printf("Namespace: %s\n", cm.Namespace)
+			fmt.Printf("Возраст: %s\n", cm.Age.Round(time.Second))
+			fmt.Printf("Ключи: %v\n", cm.Keys)
+			fmt.Println("---")
+		}
+
+		if !m.nextPage(next) {
+			return
+		}
+		page.Continue = next
 	}
 }
 
@@ -1543,6 +3980,7 @@ func (m *Menu) createOrUpdateGitLabCI() {
 	name := m.readInput()
 
 	data := make(map[string]string)
+	fmt.Println("Внимание: ключи, которые не будут введены, будут удалены из ConfigMap")
 	fmt.Println("Введите данные (формат: KEY=VALUE, пустая строка для завершения):")
 	for {
 		line := m.readInput()
@@ -1575,12 +4013,47 @@ func (m *Menu) viewGitLabCI() {
 }
 
 func main() {
-	menu, err := NewMenu()
+	contextTimeout := flag.Duration("context-timeout", kubernetes.DefaultTimeout, "таймаут одной операции с Kubernetes API (0 - без таймаута)")
+	logLevel := flag.String("log-level", "warn", "уровень логирования в stderr: debug, info, warn, error")
+	assumeYes := flag.Bool("yes", false, "не запрашивать подтверждение разрушающих действий")
+	dryRun := flag.Bool("dry-run", false, "выводить разрушающие действия вместо выполнения")
+	output := flag.String("output", "text", "формат вывода метрик: text или json")
+	quiet := flag.Bool("quiet", false, "не выводить заголовки в результатах команд, разделять записи пустой строкой")
+	flag.Parse()
+
+	if *output != "text" && *output != "json" {
+		fmt.Printf("Неверный формат вывода: %s\n", *output)
+		os.Exit(1)
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+		fmt.Printf("Неверный уровень логирования: %s\n", *logLevel)
+		os.Exit(1)
+	}
+	// Логи пишутся в stderr в JSON, чтобы не смешиваться с меню
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	menu, err := NewMenu(logger)
 	if err != nil {
 		fmt.Printf("Ошибка при инициализации меню: %v\n", err)
 		os.Exit(1)
 	}
-	defer menu.dockerAdapter.Close()
+	// Адаптер заменяется при выборе другого Docker context
+	defer func() { menu.dockerAdapter.Close() }()
+	defer menu.cicdAdapter.Close()
+	if menu.auditLog != nil {
+		defer func() {
+			if err := menu.auditLog.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Ошибка при записи журнала аудита: %v\n", err)
+			}
+		}()
+	}
+	menu.k8sAdapter.SetTimeout(*contextTimeout)
+	menu.assumeYes = *assumeYes
+	menu.dryRun = *dryRun
+	menu.output = *output
+	menu.quiet = *quiet
 
 	for {
 		menu.printMainMenu()
@@ -1597,6 +4070,10 @@ func main() {
 			menu.handleCICDMenu()
 		case "5":
 			menu.handleMonitoringMenu()
+		case "6":
+			menu.handleNetworkMenu()
+		case "7":
+			menu.handleMaintenanceMenu()
 		case "0":
 			fmt.Println("Выход из программы")
 			return
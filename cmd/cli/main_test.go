@@ -0,0 +1,523 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localops/devops-manager/internal/adapters/cicd"
+	"github.com/localops/devops-manager/internal/adapters/docker"
+)
+
+// newTestMenu создает меню, читающее ответы пользователя из input
+func newTestMenu(input string) *Menu {
+	return &Menu{scanner: bufio.NewScanner(strings.NewReader(input)), out: io.Discard}
+}
+
+func TestDefaultCICDInstance(t *testing.T) {
+	corp := cicd.Instance{Name: "corp", BaseURL: "https://git.example.local", Token: "corp-token"}
+
+	t.Run("только CICD_INSTANCES", func(t *testing.T) {
+		baseURL, instances := defaultCICDInstance("", "", []cicd.Instance{corp})
+		if baseURL != "" || len(instances) != 1 {
+			t.Errorf("defaultCICDInstance() = %q, %v, want empty base URL and corp only", baseURL, instances)
+		}
+	})
+
+	t.Run("CICD_TOKEN добавляет основной инстанс первым", func(t *testing.T) {
+		baseURL, instances := defaultCICDInstance("", "token", []cicd.Instance{corp})
+		if baseURL != "https://gitlab.com" {
+			t.Errorf("base URL = %q, want https://gitlab.com", baseURL)
+		}
+		if len(instances) != 2 || instances[0].Name != "default" || instances[0].Token != "token" {
+			t.Errorf("instances = %v, want default instance first", instances)
+		}
+	})
+
+	t.Run("без инстансов", func(t *testing.T) {
+		baseURL, instances := defaultCICDInstance("https://git.local", "token", nil)
+		if baseURL != "https://git.local" || len(instances) != 0 {
+			t.Errorf("defaultCICDInstance() = %q, %v", baseURL, instances)
+		}
+	})
+}
+
+func TestSessionInputReturnsUnreadLineToMenu(t *testing.T) {
+	m := newTestMenu("ls\n3\n")
+
+	in, stop := m.sessionInput()
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil || line != "ls\n" {
+		t.Fatalf("session read = %q, %v, want \"ls\\n\"", line, err)
+	}
+
+	// Сессия закончилась, следующая строка предназначена меню
+	stop()
+	if got := m.readInput(); got != "3" {
+		t.Errorf("readInput() after session = %q, want \"3\"", got)
+	}
+	if got := m.readInput(); got != "" {
+		t.Errorf("readInput() at EOF = %q, want empty", got)
+	}
+}
+
+func TestEnvPositiveInt(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{value: "", want: 0},
+		{value: "50", want: 50},
+		{value: "abc", want: 0},
+		{value: "-5", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("TEST_PAGE_SIZE", tt.value)
+		if got := envPositiveInt("TEST_PAGE_SIZE"); got != tt.want {
+			t.Errorf("envPositiveInt(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		assumeYes bool
+		want      bool
+	}{
+		{name: "y подтверждает", input: "y\n", want: true},
+		{name: "Y подтверждает", input: " Y \n", want: true},
+		{name: "n отменяет", input: "n\n", want: false},
+		{name: "пустой ответ отменяет", input: "\n", want: false},
+		{name: "конец ввода отменяет", input: "", want: false},
+		{name: "другой ответ отменяет", input: "yes\n", want: false},
+		{name: "--yes не читает ответ", input: "n\n", assumeYes: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			menu := newTestMenu(tt.input)
+			menu.assumeYes = tt.assumeYes
+
+			if got := menu.confirm("Удалить образ"); got != tt.want {
+				t.Errorf("confirm() = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmDestructiveDryRun(t *testing.T) {
+	menu := newTestMenu("y\nnext\n")
+	menu.dryRun = true
+	menu.assumeYes = true
+
+	if menu.confirmDestructive("Удалить образ app:latest") {
+		t.Fatal("в режиме dry-run действие не должно выполняться")
+	}
+	// Ответ на подтверждение не прочитан и остался для следующего вопроса
+	if got := menu.readInput(); got != "y" {
+		t.Errorf("readInput() = %q, ожидалось %q", got, "y")
+	}
+}
+
+func TestCopyConfigObjectOverwrite(t *testing.T) {
+	tests := []struct {
+		name          string
+		exists        bool
+		dryRun        bool
+		input         string
+		wantCopied    bool
+		wantOverwrite bool
+	}{
+		{name: "новый объект копируется без вопроса", input: "app\n\nprod\n\n", wantCopied: true},
+		{name: "замена подтверждена", exists: true, input: "app\n\nprod\n\ny\n", wantCopied: true, wantOverwrite: true},
+		{name: "замена отклонена", exists: true, input: "app\n\nprod\n\nn\n"},
+		{name: "dry-run не заменяет", exists: true, dryRun: true, input: "app\n\nprod\n\ny\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			menu := newTestMenu(tt.input)
+			menu.dryRun = tt.dryRun
+
+			var copied, overwrite bool
+			menu.copyConfigObject("ConfigMap",
+				func(namespace, name string) (bool, error) { return tt.exists, nil },
+				func(srcNamespace, name, dstNamespace, newName string, replace bool) error {
+					copied, overwrite = true, replace
+					return nil
+				})
+
+			if copied != tt.wantCopied || overwrite != tt.wantOverwrite {
+				t.Errorf("copied = %v, overwrite = %v, ожидалось %v, %v", copied, overwrite, tt.wantCopied, tt.wantOverwrite)
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	t.Run("длительность", func(t *testing.T) {
+		before := time.Now()
+		since, err := parseSince("10m")
+		if err != nil {
+			t.Fatalf("parseSince вернул ошибку: %v", err)
+		}
+		if since.Before(before.Add(-10*time.Minute)) || since.After(time.Now().Add(-10*time.Minute)) {
+			t.Errorf("ожидалось время 10 минут назад, получено %s", since)
+		}
+	})
+
+	t.Run("время RFC3339", func(t *testing.T) {
+		since, err := parseSince("2024-05-01T12:00:00+03:00")
+		if err != nil {
+			t.Fatalf("parseSince вернул ошибку: %v", err)
+		}
+		if want := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC); !since.Equal(want) {
+			t.Errorf("ожидалось %s, получено %s", want, since)
+		}
+	})
+
+	for _, value := range []string{"all", "ALL", ""} {
+		t.Run("все логи "+value, func(t *testing.T) {
+			since, err := parseSince(value)
+			if err != nil || !since.IsZero() {
+				t.Errorf("parseSince(%q) = %s, %v, ожидалось нулевое время", value, since, err)
+			}
+		})
+	}
+
+	for _, value := range []string{"-5m", "0s", "yesterday", "10"} {
+		t.Run("ошибка "+value, func(t *testing.T) {
+			if _, err := parseSince(value); err == nil {
+				t.Errorf("parseSince(%q) должен вернуть ошибку", value)
+			}
+		})
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{name: "рост", values: []float64{0, 1, 2, 3, 4, 5, 6, 7}, want: "▁▂▃▄▅▆▇█"},
+		{name: "спад и рост", values: []float64{10, 0, 5, 10}, want: "█▁▄█"},
+		{name: "постоянное значение", values: []float64{3, 3, 3}, want: "▁▁▁"},
+		{name: "нет значений", values: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sparkline(tt.values); got != tt.want {
+				t.Errorf("sparkline(%v) = %q, ожидалось %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatMetricValue(t *testing.T) {
+	tests := map[float64]string{
+		42:      "42",
+		0:       "0",
+		-3:      "-3",
+		0.12345: "0.123",
+		1.5:     "1.500",
+	}
+	for value, want := range tests {
+		if got := formatMetricValue(value); got != want {
+			t.Errorf("formatMetricValue(%v) = %q, ожидалось %q", value, got, want)
+		}
+	}
+
+	if got := formatLabels(map[string]string{"status": "ok", "operation": "build"}); got != `{operation="build",status="ok"}` {
+		t.Errorf("неожиданный вывод меток: %s", got)
+	}
+}
+
+func TestParseListOrder(t *testing.T) {
+	keys := []string{"name", "created", "size"}
+	def := listOrder{key: "created", desc: true}
+
+	tests := []struct {
+		input   string
+		want    listOrder
+		wantErr bool
+	}{
+		{input: "", want: def},
+		{input: "name", want: listOrder{key: "name"}},
+		{input: "-size", want: listOrder{key: "size", desc: true}},
+		{input: " Created ", want: listOrder{key: "created"}},
+		{input: "status", wantErr: true},
+		{input: "-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseListOrder(tt.input, keys, def)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("parseListOrder(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseListOrder(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSortImages(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	images := []docker.ImageInfo{
+		{ID: "sha256:b", RepoTags: []string{"nginx:latest"}, Size: 300, Created: base.Add(time.Hour)},
+		{ID: "sha256:a", Size: 100, Created: base.Add(2 * time.Hour)},
+		{ID: "sha256:c", RepoTags: []string{"alpine:3.19"}, Size: 200, Created: base},
+	}
+
+	tests := []struct {
+		key  string
+		desc bool
+		want []string
+	}{
+		{key: "name", want: []string{"sha256:c", "sha256:b", "sha256:a"}},
+		{key: "name", desc: true, want: []string{"sha256:a", "sha256:b", "sha256:c"}},
+		{key: "created", want: []string{"sha256:c", "sha256:b", "sha256:a"}},
+		{key: "created", desc: true, want: []string{"sha256:a", "sha256:b", "sha256:c"}},
+		{key: "size", want: []string{"sha256:a", "sha256:c", "sha256:b"}},
+		{key: "size", desc: true, want: []string{"sha256:b", "sha256:c", "sha256:a"}},
+	}
+
+	for _, tt := range tests {
+		sorted := append([]docker.ImageInfo(nil), images...)
+		sortList(sorted, imageCompare(tt.key), tt.desc)
+
+		got := make([]string, len(sorted))
+		for i, img := range sorted {
+			got[i] = img.ID
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("key %s desc %v: got %v, want %v", tt.key, tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestSortContainers(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	containers := []docker.ContainerInfo{
+		{Name: "web", Created: base},
+		{Name: "api", Created: base.Add(time.Hour)},
+		{Name: "db", Created: base.Add(2 * time.Hour)},
+	}
+
+	tests := []struct {
+		key  string
+		desc bool
+		want []string
+	}{
+		{key: "name", want: []string{"api", "db", "web"}},
+		{key: "name", desc: true, want: []string{"web", "db", "api"}},
+		{key: "created", want: []string{"web", "api", "db"}},
+		{key: "created", desc: true, want: []string{"db", "api", "web"}},
+	}
+
+	for _, tt := range tests {
+		sorted := append([]docker.ContainerInfo(nil), containers...)
+		sortList(sorted, containerCompare(tt.key), tt.desc)
+
+		got := make([]string, len(sorted))
+		for i, c := range sorted {
+			got[i] = c.Name
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("key %s desc %v: got %v, want %v", tt.key, tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestPrintPaged(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		pageSize int
+		want     int
+	}{
+		{name: "все сразу", pageSize: 0, want: 5},
+		{name: "одна страница", input: "n\n", pageSize: 2, want: 2},
+		{name: "все страницы", input: "y\ny\n", pageSize: 2, want: 5},
+		{name: "страница больше списка", pageSize: 10, want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestMenu(tt.input)
+			printed := 0
+			m.printPaged(5, tt.pageSize, func(int) { printed++ })
+			if printed != tt.want {
+				t.Errorf("printed %d items, want %d", printed, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintImagesOutput(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	images := []docker.ImageInfo{
+		{ID: "sha256:a", RepoTags: []string{"nginx:latest"}, Size: 100, Created: created},
+	}
+
+	var buf bytes.Buffer
+	m := newTestMenu("")
+	m.out = &buf
+	m.printImages(images, 0)
+
+	want := "\nСписок образов:\n" +
+		"ID: sha256:a\n" +
+		"Теги: [nginx:latest]\n" +
+		"Размер: 100 байт\n" +
+		"Создан: 2024-01-01 00:00:00 +0000 UTC\n" +
+		"---\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrintContainersQuiet(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	containers := []docker.ContainerInfo{
+		{ID: "web-id", Name: "web", Image: "nginx", Status: "Up 1 minute", Created: created},
+		{ID: "db-id", Name: "db", Image: "postgres", Status: "Exited (0)", Created: created},
+	}
+
+	var buf bytes.Buffer
+	m := newTestMenu("")
+	m.out = &buf
+	m.quiet = true
+	m.printContainers(containers, 0)
+
+	output := buf.String()
+	if strings.Contains(output, "Список контейнеров") || strings.Contains(output, "---") {
+		t.Errorf("quiet output contains decorations: %q", output)
+	}
+	for _, want := range []string{"ID: web-id\n", "Имя: db\n", "Статус: Exited (0)\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output %q does not contain %q", output, want)
+		}
+	}
+	// Записи по-прежнему разделены, пустой строкой
+	if records := strings.Split(strings.TrimSuffix(output, "\n\n"), "\n\n"); len(records) != 2 {
+		t.Errorf("quiet output has %d records, want 2: %q", len(records), output)
+	}
+}
+
+func TestAskYesNoIgnoresAssumeYes(t *testing.T) {
+	menu := newTestMenu("n\ny\n")
+	menu.assumeYes = true
+
+	// --yes не отвечает на вопросы о параметрах операции
+	if menu.askYesNo("Использовать BuildKit?") {
+		t.Error("askYesNo() = true для ответа n")
+	}
+	if !menu.askYesNo("Использовать BuildKit?") {
+		t.Error("askYesNo() = false для ответа y")
+	}
+}
+
+func TestDestructiveFlowsDryRun(t *testing.T) {
+	flows := map[string]struct {
+		input string
+		run   func(m *Menu)
+	}{
+		"drainNode":             {input: "node-1\n", run: (*Menu).drainNode},
+		"restartAllDeployments": {input: "\n", run: (*Menu).restartAllDeployments},
+		"deleteSecretKey":       {input: "app\npassword\n", run: (*Menu).deleteSecretKey},
+		"deleteConfigMapKey":    {input: "app\nconfig\n", run: (*Menu).deleteConfigMapKey},
+	}
+
+	for name, flow := range flows {
+		t.Run(name, func(t *testing.T) {
+			// Адаптеры не заданы: обращение к Kubernetes завершит тест паникой
+			menu := newTestMenu(flow.input + "force\nall\n")
+			menu.dryRun = true
+			menu.assumeYes = true
+			flow.run(menu)
+
+			// Дополнительные подтверждения после dry-run не запрашиваются
+			if got := menu.readInput(); got != "force" {
+				t.Errorf("readInput() = %q, ожидалось %q", got, "force")
+			}
+		})
+	}
+}
+
+func TestReadInt(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   int
+		wantOK bool
+	}{
+		{name: "корректное число", input: "3\n", want: 3, wantOK: true},
+		{name: "повтор после некорректного ввода", input: "abc\n-1\n11\n7\n", want: 7, wantOK: true},
+		{name: "границы диапазона", input: "10\n", want: 10, wantOK: true},
+		{name: "q отменяет", input: "x\nq\n", wantOK: false},
+		{name: "пустой ввод отменяет", input: "\n", wantOK: false},
+		{name: "конец ввода отменяет", input: "abc\n", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := newTestMenu(tt.input).readInt("> ", 0, 10)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("readInt() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestReadIntDefault(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   int
+		wantOK bool
+	}{
+		{name: "пустой ввод дает значение по умолчанию", input: "\n", want: 600, wantOK: true},
+		{name: "повтор после некорректного ввода", input: "0\nten\n30\n", want: 30, wantOK: true},
+		{name: "значение по умолчанию после ошибки", input: "-5\n\n", want: 600, wantOK: true},
+		{name: "q отменяет", input: "Q\n", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := newTestMenu(tt.input).readIntDefault("> ", 600, 1, 3600)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("readIntDefault() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestReadIntOrAll(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   int
+		wantOK bool
+	}{
+		{name: "пустой ввод - все", input: "\n", want: 0, wantOK: true},
+		{name: "all - все", input: "ALL\n", want: 0, wantOK: true},
+		{name: "число", input: "all100\n100\n", want: 100, wantOK: true},
+		{name: "q отменяет", input: "q\n", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := newTestMenu(tt.input).readIntOrAll("> ", 1, 1000)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("readIntOrAll() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
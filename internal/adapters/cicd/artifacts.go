@@ -0,0 +1,210 @@
+package cicd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// artifactChunkSize минимальный размер фрагмента архива, запрашиваемого за раз.
+// Его хватает, чтобы первым запросом получить конец архива с центральным
+// каталогом небольшого zip.
+const artifactChunkSize = 64 << 10
+
+// ArtifactEntry описывает файл в архиве артефактов задачи
+type ArtifactEntry struct {
+	Name     string
+	Size     int64
+	Modified time.Time
+}
+
+// ListArtifacts возвращает список файлов в архиве артефактов задачи, не
+// скачивая архив целиком: если сервер поддерживает Range, читается только
+// центральный каталог zip в конце архива. Иначе архив загружается в память.
+func (c *CICDAdapter) ListArtifacts(ctx context.Context, projectID, jobID string) (_ []ArtifactEntry, err error) {
+	ctx, span := tracing.Start(ctx, "cicd", "list_artifacts", attribute.String("project.id", projectID), attribute.String("job.id", jobID))
+	defer func() { tracing.End(span, err) }()
+
+	path := fmt.Sprintf("/projects/%s/jobs/%s/artifacts", projectID, jobID)
+	reader, size, err := c.openArtifacts(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения архива артефактов: %w", err)
+	}
+
+	entries := make([]ArtifactEntry, 0, len(archive.File))
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		entries = append(entries, ArtifactEntry{
+			Name:     file.Name,
+			Size:     int64(file.UncompressedSize64),
+			Modified: file.Modified,
+		})
+	}
+
+	return entries, nil
+}
+
+// DownloadArtifactFile скачивает из артефактов задачи один файл artifactPath
+// и сохраняет его в destPath. Если архив артефактов есть, но файла в нем
+// нет, возвращает ошибку, оборачивающую ErrArtifactNotFound. Отсутствие
+// задачи или архива возвращается как ошибка API.
+func (c *CICDAdapter) DownloadArtifactFile(ctx context.Context, projectID, jobID, artifactPath, destPath string) (err error) {
+	ctx, span := tracing.Start(ctx, "cicd", "download_artifact_file", attribute.String("project.id", projectID), attribute.String("job.id", jobID), attribute.String("artifact.path", artifactPath), attribute.String("output.path", destPath))
+	defer func() { tracing.End(span, err) }()
+
+	segments := strings.Split(strings.TrimPrefix(artifactPath, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	path := fmt.Sprintf("/projects/%s/jobs/%s/artifacts/%s", projectID, jobID, strings.Join(segments, "/"))
+	resp, err := c.doDownload(ctx, path, nil)
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		// GitLab отвечает 404 и на отсутствующую задачу или архив, поэтому
+		// ErrArtifactNotFound возвращается, только если архив у задачи есть
+		exists, jobErr := c.hasArtifactsArchive(ctx, projectID, jobID)
+		if jobErr == nil && exists {
+			return fmt.Errorf("%w: %s", ErrArtifactNotFound, artifactPath)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return saveToFile(resp.Body, destPath)
+}
+
+// hasArtifactsArchive сообщает, загружен ли у задачи архив артефактов
+func (c *CICDAdapter) hasArtifactsArchive(ctx context.Context, projectID, jobID string) (bool, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, fmt.Sprintf("/projects/%s/jobs/%s", projectID, jobID), nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	var job struct {
+		ArtifactsFile *struct {
+			Filename string `json:"filename"`
+		} `json:"artifacts_file"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return false, fmt.Errorf("ошибка разбора ответа: %w", err)
+	}
+	return job.ArtifactsFile != nil && job.ArtifactsFile.Filename != "", nil
+}
+
+// openArtifacts возвращает доступ к архиву по path и его размер. Первый
+// запрос сразу получает конец архива, где zip хранит центральный каталог.
+func (c *CICDAdapter) openArtifacts(ctx context.Context, path string) (io.ReaderAt, int64, error) {
+	header := http.Header{"Range": {fmt.Sprintf("bytes=-%d", artifactChunkSize)}}
+	resp, err := c.doDownload(ctx, path, header)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	// Сервер не поддерживает Range и отдал архив целиком
+	if resp.StatusCode != http.StatusPartialContent {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, 0, fmt.Errorf("ошибка при скачивании артефактов: %w", err)
+		}
+		return bytes.NewReader(data), int64(len(data)), nil
+	}
+
+	start, _, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		return nil, 0, err
+	}
+	tail, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка при чтении артефактов: %w", err)
+	}
+
+	return &artifactReader{ctx: ctx, adapter: c, path: path, size: size, bufOff: start, buf: tail}, size, nil
+}
+
+// parseContentRange разбирает заголовок Content-Range вида bytes 0-99/1000
+func parseContentRange(value string) (start, end, size int64, err error) {
+	if _, err := fmt.Sscanf(value, "bytes %d-%d/%d", &start, &end, &size); err != nil {
+		return 0, 0, 0, fmt.Errorf("некорректный заголовок Content-Range %q: %w", value, err)
+	}
+	return start, end, size, nil
+}
+
+// artifactReader читает архив артефактов Range запросами. Последний
+// полученный фрагмент кешируется, так как archive/zip читает центральный
+// каталог небольшими последовательными порциями.
+type artifactReader struct {
+	ctx     context.Context
+	adapter *CICDAdapter
+	path    string
+	size    int64
+
+	bufOff int64
+	buf    []byte
+}
+
+// ReadAt реализует io.ReaderAt
+func (r *artifactReader) ReadAt(p []byte, off int64) (int, error) {
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	if off < r.bufOff || off+int64(len(p)) > r.bufOff+int64(len(r.buf)) {
+		end := off + max(int64(len(p)), artifactChunkSize)
+		if end > r.size {
+			end = r.size
+		}
+		if err := r.fetch(off, end); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.buf[off-r.bufOff:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// fetch загружает в кеш байты архива [start, end)
+func (r *artifactReader) fetch(start, end int64) error {
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", start, end-1)}}
+	resp, err := r.adapter.doDownload(r.ctx, r.path, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("сервер не вернул запрошенный фрагмент артефактов (статус %d)", resp.StatusCode)
+	}
+
+	buf := make([]byte, end-start)
+	if _, err := io.ReadFull(resp.Body, buf); err != nil {
+		return fmt.Errorf("ошибка при чтении артефактов: %w", err)
+	}
+	r.bufOff, r.buf = start, buf
+	return nil
+}
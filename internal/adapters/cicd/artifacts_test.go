@@ -0,0 +1,165 @@
+package cicd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// cannedArtifacts собирает zip с большим несжатым файлом, чтобы чтение
+// архива целиком было заметно по объему ответа
+func cannedArtifacts(t *testing.T) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	large := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(large)
+	files := []struct {
+		name   string
+		data   []byte
+		method uint16
+	}{
+		{name: "build/app.bin", data: large, method: zip.Store},
+		{name: "reports/junit.xml", data: []byte("<testsuites/>"), method: zip.Deflate},
+	}
+
+	if _, err := archive.Create("build/"); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: file.method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(file.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// countingWriter считает байты тела ответа
+type countingWriter struct {
+	http.ResponseWriter
+	written *int
+}
+
+func (w countingWriter) Write(p []byte) (int, error) {
+	*w.written += len(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func TestListArtifacts(t *testing.T) {
+	archive := cannedArtifacts(t)
+
+	tests := []struct {
+		name         string
+		rangeSupport bool
+	}{
+		{name: "сервер поддерживает Range", rangeSupport: true},
+		{name: "сервер без Range", rangeSupport: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var written int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v4/projects/1/jobs/7/artifacts" {
+					t.Errorf("неожиданный запрос %s", r.URL.Path)
+					return
+				}
+				w = countingWriter{ResponseWriter: w, written: &written}
+				if tt.rangeSupport {
+					http.ServeContent(w, r, "artifacts.zip", time.Time{}, bytes.NewReader(archive))
+					return
+				}
+				w.Write(archive)
+			}))
+			defer server.Close()
+
+			adapter := NewCICDAdapter(Config{BaseURL: server.URL, Token: "test-token"})
+
+			entries, err := adapter.ListArtifacts(context.Background(), "1", "7")
+			if err != nil {
+				t.Fatalf("ListArtifacts вернул ошибку: %v", err)
+			}
+
+			if len(entries) != 2 {
+				t.Fatalf("ожидалось 2 файла, получено %d: %+v", len(entries), entries)
+			}
+			if entries[0].Name != "build/app.bin" || entries[0].Size != 1<<20 {
+				t.Errorf("неожиданный первый файл: %+v", entries[0])
+			}
+			if entries[1].Name != "reports/junit.xml" || entries[1].Size != int64(len("<testsuites/>")) {
+				t.Errorf("неожиданный второй файл: %+v", entries[1])
+			}
+
+			if tt.rangeSupport && written >= len(archive)/2 {
+				t.Errorf("при поддержке Range скачано %d байт из %d", written, len(archive))
+			}
+		})
+	}
+}
+
+func TestDownloadArtifactFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/1/jobs/7/artifacts/reports/junit%20report.xml":
+			w.Write([]byte("<testsuites/>"))
+		case "/api/v4/projects/1/jobs/7":
+			w.Write([]byte(`{"id":7,"artifacts_file":{"filename":"artifacts.zip","size":1024}}`))
+		case "/api/v4/projects/1/jobs/8":
+			w.Write([]byte(`{"id":8}`))
+		default:
+			http.Error(w, `{"message":"404 Not Found"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	adapter := NewCICDAdapter(Config{BaseURL: server.URL, Token: "test-token"})
+
+	t.Run("файл есть в артефактах", func(t *testing.T) {
+		destPath := filepath.Join(t.TempDir(), "out", "junit.xml")
+		if err := adapter.DownloadArtifactFile(context.Background(), "1", "7", "reports/junit report.xml", destPath); err != nil {
+			t.Fatalf("DownloadArtifactFile вернул ошибку: %v", err)
+		}
+
+		data, err := os.ReadFile(destPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "<testsuites/>" {
+			t.Errorf("неожиданное содержимое файла: %q", data)
+		}
+	})
+
+	t.Run("файла нет в артефактах", func(t *testing.T) {
+		destPath := filepath.Join(t.TempDir(), "coverage.xml")
+		err := adapter.DownloadArtifactFile(context.Background(), "1", "7", "coverage.xml", destPath)
+		if !errors.Is(err, ErrArtifactNotFound) {
+			t.Fatalf("ожидалась ошибка ErrArtifactNotFound, получено %v", err)
+		}
+		if _, err := os.Stat(destPath); !os.IsNotExist(err) {
+			t.Errorf("файл %s не должен создаваться", destPath)
+		}
+	})
+	t.Run("нет архива или задачи", func(t *testing.T) {
+		for _, jobID := range []string{"8", "9"} {
+			err := adapter.DownloadArtifactFile(context.Background(), "1", jobID, "coverage.xml", filepath.Join(t.TempDir(), "coverage.xml"))
+			if err == nil || errors.Is(err, ErrArtifactNotFound) {
+				t.Errorf("задача %s: ожидалась ошибка API, получено %v", jobID, err)
+			}
+		}
+	})
+}
@@ -4,27 +4,91 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/localops/devops-manager/internal/audit"
+	"github.com/localops/devops-manager/internal/clock"
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// ErrNoPipeline возвращается, когда для ветки или коммита нет ни одного пайплайна
+var ErrNoPipeline = errors.New("пайплайн не найден")
+
+// ErrArtifactNotFound возвращается, когда в артефактах задачи нет запрошенного файла
+var ErrArtifactNotFound = errors.New("файл не найден в артефактах")
+
+// ErrNoToken возвращается до отправки запроса, если токен доступа не задан
+var ErrNoToken = errors.New("токен доступа не установлен")
+
+// apiError ответ API GitLab с кодом ошибки
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("ошибка API (статус %d): %s", e.StatusCode, e.Body)
+}
+
+// commitSHAPattern распознает полный или сокращенный SHA коммита
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
 // Config содержит конфигурацию для CICD адаптера
 type Config struct {
 	// BaseURL базовый URL для API CICD системы
 	BaseURL string
 	// Token токен для аутентификации
 	Token string
+	// Logger логгер для отладочных сообщений (по умолчанию логирование отключено)
+	Logger *slog.Logger
+	// Instances именованные инстансы GitLab для работы с несколькими серверами
+	Instances []Instance
+	// Clock источник текущего времени (по умолчанию системное время)
+	Clock clock.Clock
+	// RequestTimeout ограничивает запрос к API, если в контексте вызывающего
+	// нет дедлайна (по умолчанию 30 секунд)
+	RequestTimeout time.Duration
+	// DownloadTimeout ограничивает скачивание артефактов, если в контексте
+	// вызывающего нет дедлайна (по умолчанию 10 минут)
+	DownloadTimeout time.Duration
+	// Transport транспорт HTTP клиента (по умолчанию собственная копия
+	// http.DefaultTransport, чтобы Close не затрагивал другие клиенты процесса)
+	Transport http.RoundTripper
+	// Audit журнал аудита запуска, отмены и перезапуска пайплайнов
+	// (по умолчанию журнал не ведется)
+	Audit audit.Logger
+}
+
+// Таймауты по умолчанию для Config.RequestTimeout и Config.DownloadTimeout
+const (
+	defaultRequestTimeout  = 30 * time.Second
+	defaultDownloadTimeout = 10 * time.Minute
+)
+
+// Instance описывает один инстанс GitLab со своим токеном
+type Instance struct {
+	Name    string
+	BaseURL string
+	Token   string
 }
 
 // PipelineStatus представляет статус пайплайна
 type PipelineStatus struct {
 	ID        string
 	Status    string
+	State     PipelineState
 	StartedAt time.Time
 	EndedAt   time.Time
 	Duration  time.Duration
@@ -70,32 +134,162 @@ type PipelineJob struct {
 type CICDAdapter struct {
 	config Config
 	client *http.Client
+	logger *slog.Logger
+	clock  clock.Clock
 }
 
 // NewCICDAdapter создает новый экземпляр CICDAdapter
 func NewCICDAdapter(config Config) *CICDAdapter {
+	// Без явного BaseURL используем первый из именованных инстансов
+	if config.BaseURL == "" && len(config.Instances) > 0 {
+		config.BaseURL = config.Instances[0].BaseURL
+		config.Token = config.Instances[0].Token
+	}
 	if config.BaseURL == "" {
 		config.BaseURL = "https://gitlab.com" // Устанавливаем значение по умолчанию
 	}
 
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	clk := config.Clock
+	if clk == nil {
+		clk = clock.Real{}
+	}
+
+	if config.RequestTimeout <= 0 {
+		config.RequestTimeout = defaultRequestTimeout
+	}
+	if config.DownloadTimeout <= 0 {
+		config.DownloadTimeout = defaultDownloadTimeout
+	}
+	if config.Transport == nil {
+		config.Transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	// Таймаут задается через контекст каждого запроса: общий таймаут клиента
+	// обрывал бы скачивание больших артефактов
 	return &CICDAdapter{
 		config: config,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client: &http.Client{Transport: config.Transport},
+		logger: logger,
+		clock:  clk,
 	}
 }
 
+// Close закрывает простаивающие keep-alive соединения HTTP клиента.
+// Клиент общий с адаптерами из ForInstance, поэтому соединения
+// закрываются и для них.
+func (a *CICDAdapter) Close() {
+	a.client.CloseIdleConnections()
+}
+
+// recordAudit записывает результат изменяющей операции в журнал аудита
+func (a *CICDAdapter) recordAudit(ctx context.Context, operation, target string, err error) {
+	audit.Record(ctx, a.config.Audit, "cicd", operation, target, err)
+}
+
+// InstanceNames возвращает имена настроенных инстансов GitLab
+func (a *CICDAdapter) InstanceNames() []string {
+	names := make([]string, 0, len(a.config.Instances))
+	for _, instance := range a.config.Instances {
+		names = append(names, instance.Name)
+	}
+	return names
+}
+
+// ForInstance возвращает адаптер, привязанный к инстансу с указанным именем.
+// HTTP клиент и логгер общие с исходным адаптером.
+func (a *CICDAdapter) ForInstance(name string) (*CICDAdapter, error) {
+	for _, instance := range a.config.Instances {
+		if instance.Name != name {
+			continue
+		}
+
+		config := a.config
+		config.BaseURL = strings.TrimSuffix(instance.BaseURL, "/")
+		config.Token = instance.Token
+
+		return &CICDAdapter{
+			config: config,
+			client: a.client,
+			logger: a.logger.With("instance", name),
+			clock:  a.clock,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("инстанс CI/CD %s не настроен", name)
+}
+
+// redactHeaders возвращает копию заголовков со скрытым токеном доступа
+func redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	if redacted.Get("PRIVATE-TOKEN") != "" {
+		redacted.Set("PRIVATE-TOKEN", "[REDACTED]")
+	}
+	return redacted
+}
+
+// withTimeout ограничивает ctx таймаутом timeout, если у него еще нет дедлайна
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// cancelOnClose освобождает контекст запроса при закрытии тела ответа
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
 // doRequest выполняет HTTP запрос с обработкой ошибок и retry
 func (a *CICDAdapter) doRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	return a.doRequestWithTimeout(ctx, method, path, body, nil, a.config.RequestTimeout)
+}
+
+// doDownload выполняет GET запрос на скачивание с заголовками header
+// и таймаутом Config.DownloadTimeout
+func (a *CICDAdapter) doDownload(ctx context.Context, path string, header http.Header) (*http.Response, error) {
+	return a.doRequestWithTimeout(ctx, http.MethodGet, path, nil, header, a.config.DownloadTimeout)
+}
+
+// doRequestWithTimeout выполняет запрос как doRequest, добавляя заголовки
+// header. Если в ctx нет дедлайна, запрос вместе с чтением тела ответа
+// ограничивается timeout.
+func (a *CICDAdapter) doRequestWithTimeout(ctx context.Context, method, path string, body io.Reader, header http.Header, timeout time.Duration) (_ *http.Response, err error) {
+	if a.config.Token == "" {
+		return nil, ErrNoToken
+	}
+
+	ctx, cancel := withTimeout(ctx, timeout)
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+
 	url := fmt.Sprintf("%s/api/v4%s", a.config.BaseURL, path)
 	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
 	}
 
+	for name, values := range header {
+		req.Header[name] = values
+	}
 	req.Header.Set("PRIVATE-TOKEN", a.config.Token)
 	req.Header.Set("Content-Type", "application/json")
+	a.logger.Debug("запрос к API", "method", method, "url", url, "headers", redactHeaders(req.Header))
 
 	var resp *http.Response
 	maxRetries := 3
@@ -104,6 +298,7 @@ func (a *CICDAdapter) doRequest(ctx context.Context, method, path string, body i
 		if err != nil {
 			return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
 		}
+		a.logger.Debug("ответ API", "method", method, "url", url, "status", resp.StatusCode)
 
 		if resp.StatusCode == http.StatusTooManyRequests {
 			retryAfter := resp.Header.Get("Retry-After")
@@ -117,9 +312,10 @@ func (a *CICDAdapter) doRequest(ctx context.Context, method, path string, body i
 		if resp.StatusCode >= 400 {
 			body, _ := io.ReadAll(resp.Body)
 			resp.Body.Close()
-			return nil, fmt.Errorf("ошибка API (статус %d): %s", resp.StatusCode, string(body))
+			return nil, &apiError{StatusCode: resp.StatusCode, Body: string(body)}
 		}
 
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
 		return resp, nil
 	}
 
@@ -127,13 +323,18 @@ func (a *CICDAdapter) doRequest(ctx context.Context, method, path string, body i
 }
 
 // TriggerPipeline запускает новый пайплайн
-func (a *CICDAdapter) TriggerPipeline(ctx context.Context, projectID, ref string) (*Pipeline, error) {
+func (a *CICDAdapter) TriggerPipeline(ctx context.Context, projectID, ref string) (_ *Pipeline, err error) {
+	ctx, span := tracing.Start(ctx, "cicd", "trigger_pipeline", attribute.String("project.id", projectID), attribute.String("ref", ref))
+	defer func() {
+		tracing.End(span, err)
+		a.recordAudit(ctx, "trigger_pipeline", projectID+"@"+ref, err)
+	}()
+
 	if a.config.Token == "" {
-		return nil, fmt.Errorf("токен доступа не установлен")
+		return nil, ErrNoToken
 	}
 
 	url := fmt.Sprintf("%s/api/v4/projects/%s/pipeline", a.config.BaseURL, projectID)
-	fmt.Printf("Отправка запроса на URL: %s\n", url)
 
 	// Создаем тело запроса
 	body := map[string]string{
@@ -143,7 +344,9 @@ func (a *CICDAdapter) TriggerPipeline(ctx context.Context, projectID, ref string
 	if err != nil {
 		return nil, fmt.Errorf("ошибка сериализации запроса: %v", err)
 	}
-	fmt.Printf("Тело запроса: %s\n", string(jsonBody))
+
+	ctx, cancel := withTimeout(ctx, a.config.RequestTimeout)
+	defer cancel()
 
 	// Создаем запрос
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
@@ -154,7 +357,11 @@ func (a *CICDAdapter) TriggerPipeline(ctx context.Context, projectID, ref string
 	// Добавляем заголовки
 	req.Header.Set("PRIVATE-TOKEN", a.config.Token)
 	req.Header.Set("Content-Type", "application/json")
-	fmt.Printf("Заголовки запроса: %v\n", req.Header)
+	a.logger.Debug("запуск пайплайна",
+		"url", url,
+		"body", string(jsonBody),
+		"headers", redactHeaders(req.Header),
+	)
 
 	// Отправляем запрос
 	resp, err := a.client.Do(req)
@@ -163,15 +370,16 @@ func (a *CICDAdapter) TriggerPipeline(ctx context.Context, projectID, ref string
 	}
 	defer resp.Body.Close()
 
-	// Читаем тело ответа для отладки
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка чтения ответа: %v", err)
 	}
-	fmt.Printf("Статус ответа: %d\n", resp.StatusCode)
-	fmt.Printf("Тело ответа: %s\n", string(respBody))
+	a.logger.Debug("ответ на запуск пайплайна",
+		"status", resp.StatusCode,
+		"body", string(respBody),
+	)
 
-	if resp.StatusCode != http.StatusCreated {
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("ошибка API GitLab (статус %d): %s", resp.StatusCode, string(respBody))
 	}
 
@@ -206,6 +414,9 @@ func (a *CICDAdapter) TriggerPipeline(ctx context.Context, projectID, ref string
 		pipeline.Duration = time.Duration(*glPipeline.Duration) * time.Second
 	} else if !pipeline.StartedAt.IsZero() && !pipeline.EndedAt.IsZero() {
 		pipeline.Duration = pipeline.EndedAt.Sub(pipeline.StartedAt)
+	} else if !pipeline.StartedAt.IsZero() {
+		// Пайплайн еще выполняется
+		pipeline.Duration = a.clock.Since(pipeline.StartedAt)
 	}
 
 	// Безопасно обрабатываем автора
@@ -226,7 +437,10 @@ func (a *CICDAdapter) TriggerPipeline(ctx context.Context, projectID, ref string
 }
 
 // GetPipelineStatus возвращает статус пайплайна по его ID
-func (a *CICDAdapter) GetPipelineStatus(ctx context.Context, project string, pipelineID string) (*PipelineStatus, error) {
+func (a *CICDAdapter) GetPipelineStatus(ctx context.Context, project string, pipelineID string) (_ *PipelineStatus, err error) {
+	ctx, span := tracing.Start(ctx, "cicd", "get_pipeline_status", attribute.String("project", project), attribute.String("pipeline.id", pipelineID))
+	defer func() { tracing.End(span, err) }()
+
 	path := fmt.Sprintf("/projects/%s/pipelines/%s", project, pipelineID)
 	resp, err := a.doRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
@@ -256,6 +470,7 @@ func (a *CICDAdapter) GetPipelineStatus(ctx context.Context, project string, pip
 	status := &PipelineStatus{
 		ID:      strconv.Itoa(glPipeline.ID),
 		Status:  glPipeline.Status,
+		State:   ParsePipelineState(glPipeline.Status),
 		Branch:  glPipeline.Ref,
 		Author:  glPipeline.User.Name,
 		Message: glPipeline.Commit.Message,
@@ -280,6 +495,9 @@ func (a *CICDAdapter) GetPipelineStatus(ctx context.Context, project string, pip
 		status.Duration = time.Duration(*glPipeline.Duration) * time.Second
 	} else if !status.StartedAt.IsZero() && !status.EndedAt.IsZero() {
 		status.Duration = status.EndedAt.Sub(status.StartedAt)
+	} else if !status.StartedAt.IsZero() && !status.State.IsTerminal() {
+		// Пайплайн еще выполняется
+		status.Duration = a.clock.Since(status.StartedAt)
 	}
 
 	// Если автор не указан, используем имя из коммита
@@ -295,8 +513,135 @@ func (a *CICDAdapter) GetPipelineStatus(ctx context.Context, project string, pip
 	return status, nil
 }
 
+// GetLatestPipelineForRef возвращает самый новый пайплайн для ветки, тега или SHA коммита
+func (a *CICDAdapter) GetLatestPipelineForRef(ctx context.Context, projectID, ref string) (_ *PipelineStatus, err error) {
+	ctx, span := tracing.Start(ctx, "cicd", "get_latest_pipeline_for_ref", attribute.String("project.id", projectID), attribute.String("ref", ref))
+	defer func() { tracing.End(span, err) }()
+
+	query := url.Values{}
+	if commitSHAPattern.MatchString(ref) {
+		query.Set("sha", ref)
+	} else {
+		query.Set("ref", ref)
+	}
+	query.Set("order_by", "id")
+	query.Set("sort", "desc")
+
+	path := fmt.Sprintf("/projects/%s/pipelines?%s", projectID, query.Encode())
+	resp, err := a.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var pipelines []struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&pipelines); err != nil {
+		return nil, fmt.Errorf("ошибка разбора ответа: %w", err)
+	}
+
+	// Не полагаемся на порядок ответа и выбираем максимальный ID
+	latestID := 0
+	for _, p := range pipelines {
+		if p.ID > latestID {
+			latestID = p.ID
+		}
+	}
+	if latestID == 0 {
+		return nil, fmt.Errorf("%w для %s", ErrNoPipeline, ref)
+	}
+
+	// Список содержит только краткую информацию, поэтому запрашиваем полную
+	return a.GetPipelineStatus(ctx, projectID, strconv.Itoa(latestID))
+}
+
+// WaitForPipeline опрашивает статус пайплайна каждые pollInterval, пока он
+// не завершится или не остановится на ручном шаге, и возвращает последний
+// статус. При отмене ctx возвращает ошибку контекста.
+func (a *CICDAdapter) WaitForPipeline(ctx context.Context, projectID, pipelineID string, pollInterval time.Duration) (_ *PipelineStatus, err error) {
+	ctx, span := tracing.Start(ctx, "cicd", "wait_for_pipeline", attribute.String("project.id", projectID), attribute.String("pipeline.id", pipelineID))
+	defer func() { tracing.End(span, err) }()
+
+	updates, errs := a.WatchPipeline(ctx, projectID, pipelineID, pollInterval)
+
+	var last *PipelineStatus
+	for status := range updates {
+		status := status
+		last = &status
+		a.logger.Debug("статус пайплайна", "pipeline", pipelineID, "status", status.Status)
+	}
+	if err := <-errs; err != nil {
+		if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+			return nil, fmt.Errorf("пайплайн %s не завершился: %w", pipelineID, err)
+		}
+		return nil, err
+	}
+	return last, nil
+}
+
+// WatchPipeline опрашивает статус пайплайна каждые interval и отправляет
+// его в первый канал при каждом изменении статуса, начиная с текущего.
+// Канал статусов закрывается, когда пайплайн завершился, остановился на
+// ручном шаге (manual) или перешел в статус, неизвестный адаптеру: такие
+// статусы не меняются без участия пользователя. Канал закрывается и когда
+// опрос вернул ошибку или ctx отменен; тогда ошибка опроса или контекста
+// отправляется в канал ошибок. Канал ошибок закрывается вслед за каналом
+// статусов, поэтому его можно прочитать после range по статусам.
+func (a *CICDAdapter) WatchPipeline(ctx context.Context, projectID, pipelineID string, interval time.Duration) (<-chan PipelineStatus, <-chan error) {
+	statuses := make(chan PipelineStatus)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(statuses)
+
+		if interval <= 0 {
+			errs <- fmt.Errorf("интервал опроса должен быть положительным")
+			return
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var last string
+		for {
+			status, err := a.GetPipelineStatus(ctx, projectID, pipelineID)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			if status.Status != last {
+				last = status.Status
+				select {
+				case statuses <- *status:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+			if status.State.settled() {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return statuses, errs
+}
+
 // ListPipelineJobs возвращает список задач в пайплайне
-func (c *CICDAdapter) ListPipelineJobs(ctx context.Context, projectID, pipelineID string) ([]PipelineJob, error) {
+func (c *CICDAdapter) ListPipelineJobs(ctx context.Context, projectID, pipelineID string) (_ []PipelineJob, err error) {
+	ctx, span := tracing.Start(ctx, "cicd", "list_pipeline_jobs", attribute.String("project.id", projectID), attribute.String("pipeline.id", pipelineID))
+	defer func() { tracing.End(span, err) }()
+
 	path := fmt.Sprintf("/projects/%s/pipelines/%s/jobs", projectID, pipelineID)
 	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
@@ -335,7 +680,10 @@ func (c *CICDAdapter) ListPipelineJobs(ctx context.Context, projectID, pipelineI
 }
 
 // GetJobLogs возвращает логи задачи
-func (c *CICDAdapter) GetJobLogs(ctx context.Context, projectID, jobID string) (string, error) {
+func (c *CICDAdapter) GetJobLogs(ctx context.Context, projectID, jobID string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "cicd", "get_job_logs", attribute.String("project.id", projectID), attribute.String("job.id", jobID))
+	defer func() { tracing.End(span, err) }()
+
 	path := fmt.Sprintf("/projects/%s/jobs/%s/trace", projectID, jobID)
 	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
@@ -352,7 +700,13 @@ func (c *CICDAdapter) GetJobLogs(ctx context.Context, projectID, jobID string) (
 }
 
 // CancelPipeline отменяет выполняющийся пайплайн
-func (c *CICDAdapter) CancelPipeline(ctx context.Context, projectID, pipelineID string) error {
+func (c *CICDAdapter) CancelPipeline(ctx context.Context, projectID, pipelineID string) (err error) {
+	ctx, span := tracing.Start(ctx, "cicd", "cancel_pipeline", attribute.String("project.id", projectID), attribute.String("pipeline.id", pipelineID))
+	defer func() {
+		tracing.End(span, err)
+		c.recordAudit(ctx, "cancel_pipeline", projectID+"/"+pipelineID, err)
+	}()
+
 	path := fmt.Sprintf("/projects/%s/pipelines/%s/cancel", projectID, pipelineID)
 	resp, err := c.doRequest(ctx, http.MethodPost, path, nil)
 	if err != nil {
@@ -364,7 +718,13 @@ func (c *CICDAdapter) CancelPipeline(ctx context.Context, projectID, pipelineID
 }
 
 // RetryPipeline перезапускает упавший пайплайн
-func (c *CICDAdapter) RetryPipeline(ctx context.Context, projectID, pipelineID string) error {
+func (c *CICDAdapter) RetryPipeline(ctx context.Context, projectID, pipelineID string) (err error) {
+	ctx, span := tracing.Start(ctx, "cicd", "retry_pipeline", attribute.String("project.id", projectID), attribute.String("pipeline.id", pipelineID))
+	defer func() {
+		tracing.End(span, err)
+		c.recordAudit(ctx, "retry_pipeline", projectID+"/"+pipelineID, err)
+	}()
+
 	path := fmt.Sprintf("/projects/%s/pipelines/%s/retry", projectID, pipelineID)
 	resp, err := c.doRequest(ctx, http.MethodPost, path, nil)
 	if err != nil {
@@ -376,14 +736,22 @@ func (c *CICDAdapter) RetryPipeline(ctx context.Context, projectID, pipelineID s
 }
 
 // DownloadArtifacts скачивает артефакты сборки
-func (c *CICDAdapter) DownloadArtifacts(ctx context.Context, projectID, jobID, outputPath string) error {
+func (c *CICDAdapter) DownloadArtifacts(ctx context.Context, projectID, jobID, outputPath string) (err error) {
+	ctx, span := tracing.Start(ctx, "cicd", "download_artifacts", attribute.String("project.id", projectID), attribute.String("job.id", jobID), attribute.String("output.path", outputPath))
+	defer func() { tracing.End(span, err) }()
+
 	path := fmt.Sprintf("/projects/%s/jobs/%s/artifacts", projectID, jobID)
-	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
+	resp, err := c.doDownload(ctx, path, nil)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	return saveToFile(resp.Body, outputPath)
+}
+
+// saveToFile сохраняет содержимое r в файл outputPath, создавая директорию
+func saveToFile(r io.Reader, outputPath string) error {
 	// Создаем директорию если не существует
 	dir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -398,7 +766,7 @@ func (c *CICDAdapter) DownloadArtifacts(ctx context.Context, projectID, jobID, o
 	defer file.Close()
 
 	// Копируем данные из ответа в файл
-	if _, err := io.Copy(file, resp.Body); err != nil {
+	if _, err := io.Copy(file, r); err != nil {
 		return fmt.Errorf("ошибка при сохранении артефактов: %w", err)
 	}
 
@@ -1,12 +1,20 @@
 package cicd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/localops/devops-manager/internal/clock"
 )
 
 func TestNewCICDAdapter(t *testing.T) {
@@ -28,6 +36,98 @@ func TestNewCICDAdapter(t *testing.T) {
 	if adapter.config.Token != cfg.Token {
 		t.Errorf("ожидался Token %s, получен %s", cfg.Token, adapter.config.Token)
 	}
+
+	if adapter.config.RequestTimeout != defaultRequestTimeout || adapter.config.DownloadTimeout != defaultDownloadTimeout {
+		t.Errorf("ожидались таймауты по умолчанию, получены %s и %s", adapter.config.RequestTimeout, adapter.config.DownloadTimeout)
+	}
+}
+
+func TestForInstance(t *testing.T) {
+	// Для каждого инстанса свой сервер, который проверяет свой токен
+	newServer := func(token string, hits *int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*hits++
+			if r.Header.Get("PRIVATE-TOKEN") != token {
+				t.Errorf("ожидался токен %s, получен %s", token, r.Header.Get("PRIVATE-TOKEN"))
+			}
+			json.NewEncoder(w).Encode(gitlabPipeline{ID: 1, Status: "success"})
+		}))
+	}
+
+	var publicHits, internalHits int
+	public := newServer("public-token", &publicHits)
+	defer public.Close()
+	internal := newServer("internal-token", &internalHits)
+	defer internal.Close()
+
+	adapter := NewCICDAdapter(Config{
+		Instances: []Instance{
+			{Name: "gitlab.com", BaseURL: public.URL, Token: "public-token"},
+			{Name: "internal", BaseURL: internal.URL + "/", Token: "internal-token"},
+		},
+	})
+
+	if names := adapter.InstanceNames(); len(names) != 2 || names[0] != "gitlab.com" || names[1] != "internal" {
+		t.Errorf("ожидались инстансы [gitlab.com internal], получено %v", names)
+	}
+
+	// По умолчанию адаптер привязан к первому инстансу
+	if _, err := adapter.GetPipelineStatus(context.Background(), "1", "1"); err != nil {
+		t.Fatalf("GetPipelineStatus вернул ошибку: %v", err)
+	}
+
+	internalAdapter, err := adapter.ForInstance("internal")
+	if err != nil {
+		t.Fatalf("ForInstance вернул ошибку: %v", err)
+	}
+	if _, err := internalAdapter.GetPipelineStatus(context.Background(), "1", "1"); err != nil {
+		t.Fatalf("GetPipelineStatus вернул ошибку: %v", err)
+	}
+
+	if publicHits != 1 {
+		t.Errorf("ожидался 1 запрос к gitlab.com, получено %d", publicHits)
+	}
+	if internalHits != 1 {
+		t.Errorf("ожидался 1 запрос к internal, получено %d", internalHits)
+	}
+
+	if _, err := adapter.ForInstance("unknown"); err == nil {
+		t.Error("ожидалась ошибка для неизвестного инстанса")
+	}
+}
+
+// idleTrackingTransport отмечает вызовы CloseIdleConnections
+type idleTrackingTransport struct {
+	http.RoundTripper
+	closed int
+}
+
+func (t *idleTrackingTransport) CloseIdleConnections() {
+	t.closed++
+}
+
+func TestClose(t *testing.T) {
+	transport := &idleTrackingTransport{RoundTripper: http.DefaultTransport}
+	adapter := NewCICDAdapter(Config{
+		BaseURL:   "https://gitlab.example.com",
+		Token:     "test-token",
+		Transport: transport,
+	})
+
+	adapter.Close()
+
+	if transport.closed != 1 {
+		t.Errorf("ожидался 1 вызов CloseIdleConnections, получено %d", transport.closed)
+	}
+}
+
+func TestDefaultTransportIsNotShared(t *testing.T) {
+	adapter := NewCICDAdapter(Config{BaseURL: "https://gitlab.example.com"})
+
+	// Close адаптера не должен закрывать соединения других клиентов процесса
+	if adapter.client.Transport == nil || adapter.client.Transport == http.DefaultTransport {
+		t.Error("адаптер без Transport использует общий http.DefaultTransport")
+	}
 }
 
 func TestTriggerPipeline(t *testing.T) {
@@ -92,6 +192,82 @@ func TestTriggerPipeline(t *testing.T) {
 	}
 }
 
+func TestOperationsWithoutToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("неожиданный запрос без токена: %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	adapter := NewCICDAdapter(Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	operations := map[string]func() error{
+		"TriggerPipeline": func() error {
+			_, err := adapter.TriggerPipeline(ctx, "123", "main")
+			return err
+		},
+		"GetPipelineStatus": func() error {
+			_, err := adapter.GetPipelineStatus(ctx, "123", "1")
+			return err
+		},
+		"ListPipelineJobs": func() error {
+			_, err := adapter.ListPipelineJobs(ctx, "123", "1")
+			return err
+		},
+		"GetJobLogs": func() error {
+			_, err := adapter.GetJobLogs(ctx, "123", "1")
+			return err
+		},
+		"CancelPipeline": func() error { return adapter.CancelPipeline(ctx, "123", "1") },
+		"DownloadArtifacts": func() error {
+			return adapter.DownloadArtifacts(ctx, "123", "1", filepath.Join(t.TempDir(), "artifacts.zip"))
+		},
+	}
+
+	for name, op := range operations {
+		if err := op(); !errors.Is(err, ErrNoToken) {
+			t.Errorf("%s: ожидалась ErrNoToken, получено %v", name, err)
+		}
+	}
+}
+
+func TestTriggerPipelineDoesNotLogToken(t *testing.T) {
+	const token = "super-secret-token"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(gitlabPipeline{ID: 1, Status: "pending"})
+	}))
+	defer server.Close()
+
+	// Логируем на уровне debug в буфер
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	adapter := NewCICDAdapter(Config{
+		BaseURL: server.URL,
+		Token:   token,
+		Logger:  logger,
+	})
+
+	if _, err := adapter.TriggerPipeline(context.Background(), "123", "main"); err != nil {
+		t.Fatalf("TriggerPipeline вернул ошибку: %v", err)
+	}
+	if _, err := adapter.GetJobLogs(context.Background(), "123", "456"); err != nil {
+		t.Fatalf("GetJobLogs вернул ошибку: %v", err)
+	}
+
+	if logs.Len() == 0 {
+		t.Fatal("ожидались отладочные сообщения в логе")
+	}
+	if strings.Contains(logs.String(), token) {
+		t.Errorf("токен попал в лог: %s", logs.String())
+	}
+	if !strings.Contains(logs.String(), "[REDACTED]") {
+		t.Errorf("ожидался скрытый токен в логе, получено: %s", logs.String())
+	}
+}
+
 func TestGetPipelineStatus(t *testing.T) {
 	// Создаем тестовый сервер
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -151,6 +327,142 @@ func TestGetPipelineStatus(t *testing.T) {
 	if status.Status != "success" {
 		t.Errorf("ожидался статус success, получен %s", status.Status)
 	}
+	if status.State != PipelineSuccess {
+		t.Errorf("ожидалось состояние %s, получено %s", PipelineSuccess, status.State)
+	}
+}
+
+func TestGetPipelineStatusRunningDuration(t *testing.T) {
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gitlabPipeline{ID: 123, Status: "running", StartedAt: &started})
+	}))
+	defer server.Close()
+
+	adapter := NewCICDAdapter(Config{
+		BaseURL: server.URL,
+		Token:   "test-token",
+		Clock:   clock.NewFake(started.Add(5 * time.Minute)),
+	})
+
+	status, err := adapter.GetPipelineStatus(context.Background(), "123", "123")
+	if err != nil {
+		t.Fatalf("GetPipelineStatus вернул ошибку: %v", err)
+	}
+
+	if status.Duration != 5*time.Minute {
+		t.Errorf("ожидалась длительность 5m, получена %s", status.Duration)
+	}
+}
+
+func TestWaitForPipeline(t *testing.T) {
+	statuses := []string{"running", "running", "success"}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/projects/123/pipelines/456" {
+			t.Errorf("неожиданный запрос %s", r.URL.Path)
+		}
+		status := statuses[len(statuses)-1]
+		if requests < len(statuses) {
+			status = statuses[requests]
+		}
+		requests++
+		json.NewEncoder(w).Encode(gitlabPipeline{ID: 456, Status: status})
+	}))
+	defer server.Close()
+
+	adapter := NewCICDAdapter(Config{BaseURL: server.URL, Token: "test-token"})
+
+	status, err := adapter.WaitForPipeline(context.Background(), "123", "456", time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForPipeline вернул ошибку: %v", err)
+	}
+	if status.State != PipelineSuccess {
+		t.Errorf("ожидалось состояние %s, получено %s", PipelineSuccess, status.State)
+	}
+	if requests != 3 {
+		t.Errorf("ожидалось 3 запроса статуса, выполнено %d", requests)
+	}
+}
+
+func TestWaitForPipelineContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gitlabPipeline{ID: 456, Status: "running"})
+	}))
+	defer server.Close()
+
+	adapter := NewCICDAdapter(Config{BaseURL: server.URL, Token: "test-token"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := adapter.WaitForPipeline(ctx, "123", "456", time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ожидалась ошибка истечения контекста, получено %v", err)
+	}
+}
+
+func TestGetLatestPipelineForRef(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/projects/123/pipelines":
+			if r.URL.Query().Get("ref") != "main" {
+				t.Errorf("ожидался фильтр ref=main, получен %s", r.URL.RawQuery)
+			}
+			if r.URL.Query().Get("order_by") != "id" || r.URL.Query().Get("sort") != "desc" {
+				t.Errorf("ожидалась сортировка по id desc, получено %s", r.URL.RawQuery)
+			}
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": 42, "status": "running"},
+				{"id": 41, "status": "success"},
+				{"id": 40, "status": "failed"},
+			})
+		case "/api/v4/projects/123/pipelines/42":
+			json.NewEncoder(w).Encode(gitlabPipeline{ID: 42, Status: "running", Ref: "main"})
+		default:
+			t.Errorf("неожиданный запрос %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	adapter := NewCICDAdapter(Config{
+		BaseURL: server.URL,
+		Token:   "test-token",
+	})
+
+	status, err := adapter.GetLatestPipelineForRef(context.Background(), "123", "main")
+	if err != nil {
+		t.Fatalf("GetLatestPipelineForRef вернул ошибку: %v", err)
+	}
+
+	if status.ID != "42" {
+		t.Errorf("ожидался ID 42, получен %s", status.ID)
+	}
+
+	if status.Status != "running" {
+		t.Errorf("ожидался статус running, получен %s", status.Status)
+	}
+}
+
+func TestGetLatestPipelineForRefBySHA(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sha") != "a1b2c3d4" {
+			t.Errorf("ожидался фильтр sha=a1b2c3d4, получен %s", r.URL.RawQuery)
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	adapter := NewCICDAdapter(Config{
+		BaseURL: server.URL,
+		Token:   "test-token",
+	})
+
+	_, err := adapter.GetLatestPipelineForRef(context.Background(), "123", "a1b2c3d4")
+	if !errors.Is(err, ErrNoPipeline) {
+		t.Errorf("ожидалась ошибка ErrNoPipeline, получено %v", err)
+	}
 }
 
 func TestListPipelineJobs(t *testing.T) {
@@ -288,3 +600,127 @@ func TestRetryPipeline(t *testing.T) {
 		t.Errorf("RetryPipeline вернул ошибку: %v", err)
 	}
 }
+
+func TestRequestTimeouts(t *testing.T) {
+	// Сервер отвечает дольше таймаута обычного запроса, но быстрее таймаута скачивания
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		if strings.HasSuffix(r.URL.Path, "/artifacts") {
+			w.Write([]byte("artifacts"))
+			return
+		}
+		json.NewEncoder(w).Encode(gitlabPipeline{ID: 456, Status: "success"})
+	}))
+	defer server.Close()
+
+	adapter := NewCICDAdapter(Config{
+		BaseURL:         server.URL,
+		Token:           "test-token",
+		RequestTimeout:  50 * time.Millisecond,
+		DownloadTimeout: 5 * time.Second,
+	})
+
+	_, err := adapter.GetPipelineStatus(context.Background(), "123", "456")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ожидалось превышение таймаута запроса, получено %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "artifacts.zip")
+	if err := adapter.DownloadArtifacts(context.Background(), "123", "789", outputPath); err != nil {
+		t.Fatalf("скачивание должно использовать таймаут скачивания: %v", err)
+	}
+	if data, _ := os.ReadFile(outputPath); string(data) != "artifacts" {
+		t.Errorf("ожидалось содержимое artifacts, получено %q", data)
+	}
+
+	// Дедлайн вызывающего важнее таймаута по умолчанию
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := adapter.GetPipelineStatus(ctx, "123", "456"); err != nil {
+		t.Errorf("запрос с дедлайном вызывающего вернул ошибку: %v", err)
+	}
+}
+
+func TestWatchPipeline(t *testing.T) {
+	statuses := []string{"running", "running", "success"}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[len(statuses)-1]
+		if requests < len(statuses) {
+			status = statuses[requests]
+		}
+		requests++
+		json.NewEncoder(w).Encode(gitlabPipeline{ID: 456, Status: status})
+	}))
+	defer server.Close()
+
+	adapter := NewCICDAdapter(Config{BaseURL: server.URL, Token: "test-token"})
+
+	updates, errs := adapter.WatchPipeline(context.Background(), "123", "456", time.Millisecond)
+
+	var states []PipelineState
+	for status := range updates {
+		states = append(states, status.State)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("WatchPipeline вернул ошибку: %v", err)
+	}
+
+	// Повторный running не отправляется
+	if len(states) != 2 || states[0] != PipelineRunning || states[1] != PipelineSuccess {
+		t.Errorf("ожидались состояния [running success], получены %v", states)
+	}
+	if requests != 3 {
+		t.Errorf("ожидалось 3 запроса статуса, выполнено %d", requests)
+	}
+}
+
+func TestWatchPipelineContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gitlabPipeline{ID: 456, Status: "running"})
+	}))
+	defer server.Close()
+
+	adapter := NewCICDAdapter(Config{BaseURL: server.URL, Token: "test-token"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates, errs := adapter.WatchPipeline(ctx, "123", "456", time.Millisecond)
+	if status := <-updates; status.State != PipelineRunning {
+		t.Errorf("ожидалось состояние running, получено %s", status.State)
+	}
+	cancel()
+
+	for range updates {
+		t.Error("после отмены не ожидалось новых статусов")
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("ожидалась ошибка отмены контекста, получено %v", err)
+	}
+}
+
+func TestWaitForPipelineStopsOnManual(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(gitlabPipeline{ID: 456, Status: "manual"})
+	}))
+	defer server.Close()
+
+	adapter := NewCICDAdapter(Config{BaseURL: server.URL, Token: "test-token"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status, err := adapter.WaitForPipeline(ctx, "123", "456", time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForPipeline вернул ошибку: %v", err)
+	}
+	if status.State != PipelineManual {
+		t.Errorf("ожидалось состояние %s, получено %s", PipelineManual, status.State)
+	}
+	if requests != 1 {
+		t.Errorf("ожидался 1 запрос статуса, выполнено %d", requests)
+	}
+}
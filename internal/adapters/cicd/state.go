@@ -0,0 +1,58 @@
+package cicd
+
+// PipelineState обобщенное состояние пайплайна GitLab
+type PipelineState string
+
+const (
+	PipelinePending  PipelineState = "pending"
+	PipelineRunning  PipelineState = "running"
+	PipelineSuccess  PipelineState = "success"
+	PipelineFailed   PipelineState = "failed"
+	PipelineCanceled PipelineState = "canceled"
+	PipelineSkipped  PipelineState = "skipped"
+	PipelineManual   PipelineState = "manual"
+	// PipelineUnknown статус, которого нет в словаре GitLab на момент написания
+	PipelineUnknown PipelineState = "unknown"
+)
+
+// ParsePipelineState разбирает статус пайплайна из ответа GitLab.
+// Статусы ожидания запуска (created, preparing и т.п.) сводятся к Pending.
+func ParsePipelineState(status string) PipelineState {
+	switch status {
+	case "created", "waiting_for_resource", "preparing", "pending", "scheduled":
+		return PipelinePending
+	case "running":
+		return PipelineRunning
+	case "success":
+		return PipelineSuccess
+	case "failed":
+		return PipelineFailed
+	case "canceled":
+		return PipelineCanceled
+	case "skipped":
+		return PipelineSkipped
+	case "manual":
+		return PipelineManual
+	default:
+		return PipelineUnknown
+	}
+}
+
+// IsTerminal сообщает, что пайплайн завершен и его статус больше не изменится
+// без перезапуска. Manual ожидает действия пользователя и не считается завершенным.
+func (s PipelineState) IsTerminal() bool {
+	switch s {
+	case PipelineSuccess, PipelineFailed, PipelineCanceled, PipelineSkipped:
+		return true
+	default:
+		return false
+	}
+}
+
+// settled сообщает, что опрос пайплайна можно прекратить: он завершен,
+// ждет ручного запуска или находится в статусе, которого адаптер не знает.
+// В двух последних случаях статус не изменится сам по себе, поэтому
+// дальнейший опрос только ждал бы отмены ctx.
+func (s PipelineState) settled() bool {
+	return s.IsTerminal() || s == PipelineManual || s == PipelineUnknown
+}
@@ -0,0 +1,37 @@
+package cicd
+
+import "testing"
+
+func TestParsePipelineState(t *testing.T) {
+	tests := []struct {
+		status   string
+		want     PipelineState
+		terminal bool
+	}{
+		{status: "created", want: PipelinePending},
+		{status: "waiting_for_resource", want: PipelinePending},
+		{status: "preparing", want: PipelinePending},
+		{status: "pending", want: PipelinePending},
+		{status: "scheduled", want: PipelinePending},
+		{status: "running", want: PipelineRunning},
+		{status: "success", want: PipelineSuccess, terminal: true},
+		{status: "failed", want: PipelineFailed, terminal: true},
+		{status: "canceled", want: PipelineCanceled, terminal: true},
+		{status: "skipped", want: PipelineSkipped, terminal: true},
+		{status: "manual", want: PipelineManual},
+		{status: "exploded", want: PipelineUnknown},
+		{status: "", want: PipelineUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			got := ParsePipelineState(tt.status)
+			if got != tt.want {
+				t.Errorf("ParsePipelineState(%q) = %q, ожидалось %q", tt.status, got, tt.want)
+			}
+			if got.IsTerminal() != tt.terminal {
+				t.Errorf("%q.IsTerminal() = %v, ожидалось %v", got, got.IsTerminal(), tt.terminal)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package cicd
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ErrNoTestSummary возвращается, когда в логе задачи нет итогов тестов
+// ни в одном из известных форматов
+var ErrNoTestSummary = errors.New("итоги тестов не найдены в логе")
+
+// TestSummary итоги запуска тестов, извлеченные из лога задачи
+type TestSummary struct {
+	// Format формат вывода, из которого получены итоги (например, go)
+	Format  string
+	Passed  int
+	Failed  int
+	Skipped int
+	// FailedTests имена упавших тестов в порядке появления в логе
+	FailedTests []string
+	// FailedPackages пакеты (или модули), тесты которых завершились с ошибкой
+	FailedPackages []string
+}
+
+// Success сообщает, что упавших тестов и пакетов нет
+func (s *TestSummary) Success() bool {
+	return s.Failed == 0 && len(s.FailedPackages) == 0
+}
+
+// testSummaryParser извлекает итоги из лога в своем формате. ok равен
+// false, если лог не похож на этот формат.
+type testSummaryParser func(lines []string) (summary *TestSummary, ok bool)
+
+// testSummaryParsers известные форматы в порядке проверки. Новый формат
+// добавляется сюда.
+var testSummaryParsers = []testSummaryParser{
+	parseGoTestSummary,
+}
+
+// ansiEscape управляющие последовательности цвета в логах GitLab
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// ParseJobTestSummary извлекает итоги тестов из лога задачи. Лог
+// проверяется всеми известными форматами, используется первый подошедший.
+func ParseJobTestSummary(logs string) (*TestSummary, error) {
+	logs = ansiEscape.ReplaceAllString(logs, "")
+	lines := strings.FieldsFunc(logs, func(r rune) bool { return r == '\n' || r == '\r' })
+
+	for _, parse := range testSummaryParsers {
+		if summary, ok := parse(lines); ok {
+			return summary, nil
+		}
+	}
+	return nil, ErrNoTestSummary
+}
+
+// goTestResult строка результата теста go test, в том числе подтеста
+var goTestResult = regexp.MustCompile(`^\s*--- (PASS|FAIL|SKIP): (\S+)`)
+
+// goPackageResult итоговая строка пакета: "ok  	pkg	0.1s", "FAIL	pkg	0.1s",
+// а также "FAIL	pkg [build failed]" и "FAIL	pkg [setup failed]", когда
+// тесты пакета не удалось собрать
+var goPackageResult = regexp.MustCompile(`^(ok|FAIL)\s+(\S+)(\s|$)`)
+
+// parseGoTestSummary разбирает вывод go test. Без -v go test печатает
+// только упавшие тесты, поэтому Passed может быть нулевым при успешных
+// пакетах.
+func parseGoTestSummary(lines []string) (*TestSummary, bool) {
+	summary := &TestSummary{Format: "go"}
+	found := false
+
+	for _, line := range lines {
+		if match := goTestResult.FindStringSubmatch(line); match != nil {
+			found = true
+			switch match[1] {
+			case "PASS":
+				summary.Passed++
+			case "FAIL":
+				summary.Failed++
+				summary.FailedTests = append(summary.FailedTests, match[2])
+			case "SKIP":
+				summary.Skipped++
+			}
+			continue
+		}
+		if match := goPackageResult.FindStringSubmatch(line); match != nil {
+			found = true
+			if match[1] == "FAIL" {
+				summary.FailedPackages = append(summary.FailedPackages, match[2])
+			}
+		}
+	}
+
+	return summary, found
+}
@@ -0,0 +1,88 @@
+package cicd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseJobTestSummary(t *testing.T) {
+	tests := []struct {
+		name string
+		logs string
+		want TestSummary
+	}{
+		{
+			name: "успешный запуск с -v",
+			logs: "\x1b[32;1m$ go test -v ./...\x1b[0;m\n" +
+				"=== RUN   TestParse\n" +
+				"--- PASS: TestParse (0.00s)\n" +
+				"=== RUN   TestFormat\n" +
+				"=== RUN   TestFormat/empty\n" +
+				"    --- PASS: TestFormat/empty (0.00s)\n" +
+				"--- PASS: TestFormat (0.00s)\n" +
+				"=== RUN   TestNetwork\n" +
+				"    net_test.go:12: нет сети\n" +
+				"--- SKIP: TestNetwork (0.00s)\n" +
+				"PASS\n" +
+				"ok  \texample.com/app/parser\t0.012s\n" +
+				"?   \texample.com/app/cmd\t[no test files]\n" +
+				"ok  \texample.com/app/format\t(cached)\n",
+			want: TestSummary{Format: "go", Passed: 3, Skipped: 1},
+		},
+		{
+			name: "упавшие тесты",
+			logs: "$ go test ./...\r\n" +
+				"--- FAIL: TestApply (0.01s)\r\n" +
+				"    --- FAIL: TestApply/conflict (0.00s)\r\n" +
+				"        apply_test.go:40: ожидалась ошибка\r\n" +
+				"FAIL\r\n" +
+				"FAIL\texample.com/app/apply\t0.045s\r\n" +
+				"ok  \texample.com/app/parser\t0.012s\r\n" +
+				"FAIL\r\n" +
+				"ERROR: Job failed: exit code 1\r\n",
+			want: TestSummary{
+				Format:         "go",
+				Failed:         2,
+				FailedTests:    []string{"TestApply", "TestApply/conflict"},
+				FailedPackages: []string{"example.com/app/apply"},
+			},
+		},
+		{
+			name: "ошибка сборки пакета",
+			logs: "$ go test ./...\n" +
+				"# example.com/app/store\n" +
+				"store/store.go:12:2: undefined: cache\n" +
+				"FAIL\texample.com/app/store [build failed]\n" +
+				"FAIL\texample.com/app/config [setup failed]\n" +
+				"ok  \texample.com/app/parser\t0.012s\n" +
+				"FAIL\n",
+			want: TestSummary{
+				Format:         "go",
+				FailedPackages: []string{"example.com/app/store", "example.com/app/config"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := ParseJobTestSummary(tt.logs)
+			if err != nil {
+				t.Fatalf("ParseJobTestSummary вернул ошибку: %v", err)
+			}
+			if !reflect.DeepEqual(*summary, tt.want) {
+				t.Errorf("получено %+v, ожидалось %+v", *summary, tt.want)
+			}
+			if summary.Success() != (tt.want.Failed == 0 && len(tt.want.FailedPackages) == 0) {
+				t.Errorf("Success() = %v", summary.Success())
+			}
+		})
+	}
+}
+
+func TestParseJobTestSummaryNoTests(t *testing.T) {
+	_, err := ParseJobTestSummary("$ make build\nBuild complete\n")
+	if !errors.Is(err, ErrNoTestSummary) {
+		t.Errorf("ожидалась ErrNoTestSummary, получено %v", err)
+	}
+}
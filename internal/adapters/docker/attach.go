@@ -0,0 +1,112 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/localops/devops-manager/internal/tracing"
+	"github.com/moby/term"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// AttachInteractive подключает in, out и errOut к stdin, stdout и stderr
+// контейнера (аналог docker attach). Для контейнера с TTY терминал in на
+// время сессии переводится в raw режим, иначе вывод разделяется на stdout
+// и stderr через stdcopy. Возвращает управление, когда контейнер закрывает
+// поток вывода или отменяется ctx. Чтение in продолжается в фоне, пока
+// in не вернет EOF или ошибку, поэтому вызывающий код передает поток,
+// который закрывает после возврата (например, io.Pipe), а не os.Stdin.
+func (d *DockerAdapter) AttachInteractive(ctx context.Context, containerID string, in io.Reader, out, errOut io.Writer) error {
+	ctx, span := tracing.Start(ctx, "docker", "attach_container", attribute.String("container.id", containerID))
+	start := time.Now()
+	err := d.attachInteractive(ctx, containerID, in, out, errOut)
+	duration := time.Since(start)
+	tracing.End(span, err)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation("attach_container", status, duration)
+		d.monitoring.RecordError("docker", "attach_container", classifyError(err))
+	}
+
+	return err
+}
+
+func (d *DockerAdapter) attachInteractive(ctx context.Context, containerID string, in io.Reader, out, errOut io.Writer) error {
+	inspect, err := withReconnectValue(d, func() (types.ContainerJSON, error) {
+		return d.apiClient().ContainerInspect(ctx, containerID)
+	})
+	if err != nil {
+		return errors.Wrap(err, "ошибка при получении информации о контейнере")
+	}
+	tty := inspect.Config != nil && inspect.Config.Tty
+
+	resp, err := withReconnectValue(d, func() (types.HijackedResponse, error) {
+		return d.apiClient().ContainerAttach(ctx, containerID, types.ContainerAttachOptions{
+			Stream: true,
+			Stdin:  in != nil,
+			Stdout: true,
+			Stderr: true,
+		})
+	})
+	if err != nil {
+		return errors.Wrap(err, "ошибка при подключении к контейнеру")
+	}
+	defer resp.Close()
+
+	if tty && in != nil {
+		if fd, isTerminal := term.GetFdInfo(in); isTerminal {
+			state, err := term.SetRawTerminal(fd)
+			if err != nil {
+				return errors.Wrap(err, "ошибка при переводе терминала в raw режим")
+			}
+			defer term.RestoreTerminal(fd, state)
+
+			// Подгоняем размер TTY контейнера под локальный терминал
+			if size, err := term.GetWinsize(fd); err == nil {
+				_ = d.apiClient().ContainerResize(ctx, containerID, types.ResizeOptions{
+					Height: uint(size.Height),
+					Width:  uint(size.Width),
+				})
+			}
+		}
+	}
+
+	outputDone := make(chan error, 1)
+	go func() {
+		var err error
+		if tty {
+			// В режиме TTY stdout и stderr приходят одним потоком
+			_, err = io.Copy(out, resp.Reader)
+		} else {
+			_, err = stdcopy.StdCopy(out, errOut, resp.Reader)
+		}
+		outputDone <- err
+	}()
+
+	if in != nil {
+		go func() {
+			_, _ = io.Copy(resp.Conn, in)
+			// Сообщаем контейнеру о конце ввода, вывод продолжает читаться
+			_ = resp.CloseWrite()
+		}()
+	}
+
+	select {
+	case err := <-outputDone:
+		if err != nil {
+			return errors.Wrap(err, "ошибка при чтении вывода контейнера")
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
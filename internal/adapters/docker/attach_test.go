@@ -0,0 +1,86 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hijackAttach отвечает на запрос attach как daemon: переключает
+// соединение на сырой поток и передает его serve
+func hijackAttach(t *testing.T, query *url.Values, serve func(conn io.ReadWriter)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*query = r.URL.Query()
+
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+
+		buf.WriteString("HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+		require.NoError(t, buf.Flush())
+
+		serve(struct {
+			io.Reader
+			io.Writer
+		}{buf, conn})
+	}
+}
+
+func TestAttachInteractive(t *testing.T) {
+	t.Run("TTY: единый поток и ввод", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+		fake.addContainer(fakeContainer{Name: "shell", Tty: true})
+
+		var query url.Values
+		fake.handle(http.MethodPost, "/containers/shell-id/attach", hijackAttach(t, &query, func(conn io.ReadWriter) {
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			io.WriteString(conn, "echo: "+line)
+		}))
+
+		var out, errOut bytes.Buffer
+		err := adapter.AttachInteractive(context.Background(), "shell-id", strings.NewReader("ls\n"), &out, &errOut)
+		require.NoError(t, err)
+
+		assert.Equal(t, "1", query.Get("stream"))
+		assert.Equal(t, "1", query.Get("stdin"))
+		assert.Equal(t, "1", query.Get("stdout"))
+		assert.Equal(t, "1", query.Get("stderr"))
+		assert.Equal(t, "echo: ls\n", out.String())
+		assert.Empty(t, errOut.String())
+	})
+
+	t.Run("без TTY: stdout и stderr разделяются", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+		fake.addContainer(fakeContainer{Name: "job"})
+
+		var query url.Values
+		fake.handle(http.MethodPost, "/containers/job-id/attach", hijackAttach(t, &query, func(conn io.ReadWriter) {
+			io.WriteString(stdcopy.NewStdWriter(conn, stdcopy.Stdout), "done\n")
+			io.WriteString(stdcopy.NewStdWriter(conn, stdcopy.Stderr), "warning\n")
+		}))
+
+		var out, errOut bytes.Buffer
+		err := adapter.AttachInteractive(context.Background(), "job-id", nil, &out, &errOut)
+		require.NoError(t, err)
+
+		assert.False(t, query.Has("stdin"))
+		assert.Equal(t, "done\n", out.String())
+		assert.Equal(t, "warning\n", errOut.String())
+	})
+
+	t.Run("контейнер не найден", func(t *testing.T) {
+		_, adapter := newFakeDockerServer(t)
+
+		err := adapter.AttachInteractive(context.Background(), "missing", nil, io.Discard, io.Discard)
+		assert.Error(t, err)
+	})
+}
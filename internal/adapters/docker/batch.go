@@ -0,0 +1,157 @@
+package docker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/localops/devops-manager/internal/tracing"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// defaultBatchConcurrency число одновременных операций по умолчанию
+const defaultBatchConcurrency = 5
+
+// BatchError содержит ошибки массовой операции по каждому контейнеру
+type BatchError struct {
+	Operation string
+	Failed    map[string]error
+}
+
+func (e *BatchError) Error() string {
+	ids := make([]string, 0, len(e.Failed))
+	for id := range e.Failed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprintf("%s: %v", id, e.Failed[id]))
+	}
+	return fmt.Sprintf("%s: ошибка для %d контейнеров: %s", e.Operation, len(e.Failed), strings.Join(parts, "; "))
+}
+
+// StartContainersByLabel запускает все контейнеры с указанными метками.
+// Возвращает ID успешно обработанных контейнеров и *BatchError при
+// частичной неудаче.
+func (d *DockerAdapter) StartContainersByLabel(labels map[string]string) ([]string, error) {
+	return d.applyByLabel("start_containers", labels, true, d.StartContainer)
+}
+
+// StopContainersByLabel останавливает все запущенные контейнеры с указанными метками
+func (d *DockerAdapter) StopContainersByLabel(labels map[string]string) ([]string, error) {
+	return d.applyByLabel("stop_containers", labels, false, d.StopContainer)
+}
+
+// RemoveContainersByLabel удаляет все контейнеры с указанными метками
+func (d *DockerAdapter) RemoveContainersByLabel(labels map[string]string) ([]string, error) {
+	return d.applyByLabel("remove_containers", labels, true, d.RemoveContainer)
+}
+
+// applyByLabel применяет операцию к каждому контейнеру, найденному по меткам
+func (d *DockerAdapter) applyByLabel(operation string, labels map[string]string, all bool, apply func(string) error) ([]string, error) {
+	// Без меток под фильтр попали бы все контейнеры хоста
+	if len(labels) == 0 {
+		return nil, errors.New("не указаны метки для массовой операции")
+	}
+
+	args := filters.NewArgs()
+	for key, value := range labels {
+		if value == "" {
+			args.Add("label", key)
+		} else {
+			args.Add("label", key+"="+value)
+		}
+	}
+
+	ctx, span := tracing.Start(d.ctx, "docker", operation, attribute.StringSlice("labels", args.Get("label")))
+	start := time.Now()
+	containers, err := withReconnectValue(d, func() ([]types.Container, error) {
+		return d.apiClient().ContainerList(ctx, types.ContainerListOptions{All: all, Filters: args})
+	})
+	if err != nil {
+		tracing.End(span, err)
+		if d.monitoring != nil {
+			d.monitoring.RecordDockerOperation(operation, "error", time.Since(start))
+			d.monitoring.RecordError("docker", operation, classifyError(err))
+		}
+		return nil, errors.Wrap(err, "ошибка при получении списка контейнеров")
+	}
+
+	results := runBounded(d.concurrency(), len(containers), func(i int) error {
+		return apply(containers[i].ID)
+	})
+
+	var done []string
+	failed := make(map[string]error)
+	for i, c := range containers {
+		if results[i] != nil {
+			failed[c.ID] = results[i]
+			continue
+		}
+		done = append(done, c.ID)
+	}
+
+	status := "success"
+	if len(failed) > 0 {
+		status = "error"
+		err = &BatchError{Operation: operation, Failed: failed}
+	}
+	span.SetAttributes(attribute.Int("containers.done", len(done)), attribute.Int("containers.failed", len(failed)))
+	tracing.End(span, err)
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation(operation, status, time.Since(start))
+		for _, err := range failed {
+			d.monitoring.RecordError("docker", operation, classifyError(err))
+		}
+	}
+
+	return done, err
+}
+
+// concurrency возвращает число одновременных операций для массовых операций
+func (d *DockerAdapter) concurrency() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.batchConcurrency < 1 {
+		return defaultBatchConcurrency
+	}
+	return d.batchConcurrency
+}
+
+// runBounded вызывает fn для индексов 0..n-1, выполняя не более limit
+// вызовов одновременно. Ошибка i-го вызова возвращается в i-м элементе,
+// поэтому порядок результатов совпадает с порядком элементов.
+func runBounded(limit, n int, fn func(i int) error) []error {
+	results := make([]error, n)
+	if limit < 1 {
+		limit = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(limit, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
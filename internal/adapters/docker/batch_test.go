@@ -0,0 +1,126 @@
+package docker
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopContainersByLabel(t *testing.T) {
+	tests := []struct {
+		name       string
+		failing    string
+		wantDone   []string
+		wantFailed []string
+	}{
+		{
+			name:     "все контейнеры остановлены",
+			wantDone: []string{"c1", "c2", "c3"},
+		},
+		{
+			name:       "частичная неудача",
+			failing:    "c2",
+			wantDone:   []string{"c1", "c3"},
+			wantFailed: []string{"c2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, adapter := newFakeDockerServer(t)
+			web := map[string]string{"app": "web"}
+			fake.addContainer(fakeContainer{ID: "c1", Name: "web-1", Labels: web})
+			fake.addContainer(fakeContainer{ID: "c2", Name: "web-2", Labels: web})
+			fake.addContainer(fakeContainer{ID: "c3", Name: "web-3", Labels: web})
+			// Остановленный контейнер и контейнер с другой меткой не затрагиваются
+			fake.addContainer(fakeContainer{ID: "c4", Name: "web-old", Labels: web, State: "exited"})
+			fake.addContainer(fakeContainer{ID: "c5", Name: "db", Labels: map[string]string{"app": "db"}})
+			if tt.failing != "" {
+				fake.failOn(http.MethodPost, "/containers/"+tt.failing+"/stop", http.StatusInternalServerError, "cannot stop")
+			}
+
+			done, err := adapter.StopContainersByLabel(map[string]string{"app": "web"})
+			assert.Equal(t, tt.wantDone, done)
+			for _, id := range tt.wantDone {
+				assert.Equal(t, "exited", fake.container(id).State, id)
+			}
+			assert.Equal(t, "running", fake.container("c5").State)
+			assert.NotContains(t, fake.requested(), "POST /containers/c4/stop")
+
+			if len(tt.wantFailed) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var batchErr *BatchError
+			require.ErrorAs(t, err, &batchErr)
+			assert.Len(t, batchErr.Failed, len(tt.wantFailed))
+			for _, id := range tt.wantFailed {
+				assert.Contains(t, batchErr.Failed, id)
+			}
+		})
+	}
+}
+
+func TestBatchRequiresLabels(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+
+	_, err := adapter.RemoveContainersByLabel(nil)
+	assert.Error(t, err)
+	assert.Empty(t, fake.requested())
+}
+
+func TestBatchConcurrencyLimit(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	adapter.SetBatchConcurrency(2)
+
+	var inFlight, maxInFlight atomic.Int32
+	ids := []string{"c1", "c2", "c3", "c4", "c5"}
+	for _, id := range ids {
+		fake.addContainer(fakeContainer{ID: id, Name: "web-" + id, Labels: map[string]string{"app": "web"}})
+		fake.handle(http.MethodPost, "/containers/"+id+"/stop", func(w http.ResponseWriter, r *http.Request) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				seen := maxInFlight.Load()
+				if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+
+	done, err := adapter.StopContainersByLabel(map[string]string{"app": "web"})
+	require.NoError(t, err)
+
+	// Результаты идут в порядке контейнеров, а не завершения операций
+	assert.Equal(t, ids, done)
+	assert.Equal(t, int32(2), maxInFlight.Load(), "ожидалось не более двух одновременных операций")
+}
+
+func TestRunBoundedPreservesOrder(t *testing.T) {
+	results := runBounded(3, 6, func(i int) error {
+		// Первые элементы завершаются последними
+		time.Sleep(time.Duration(6-i) * time.Millisecond)
+		if i%2 == 1 {
+			return errors.New("нечетный")
+		}
+		return nil
+	})
+
+	require.Len(t, results, 6)
+	for i, err := range results {
+		if i%2 == 1 {
+			assert.Error(t, err, i)
+		} else {
+			assert.NoError(t, err, i)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/docker/docker/client"
+	"github.com/localops/devops-manager/internal/adapters/monitoring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConcurrentListContainers проверяется с -race: одновременные вызовы,
+// переподключение и смена registry не должны приводить к гонкам
+func TestConcurrentListContainers(t *testing.T) {
+	fake, restarted := newFakeDockerServer(t)
+	fake.addContainer(fakeContainer{Name: "web"})
+	fake.addContainer(fakeContainer{Name: "db"})
+
+	var created atomic.Int32
+	adapter := &DockerAdapter{
+		client: unreachableClient(t),
+		ctx:    context.Background(),
+		monitoring: monitoring.NewMonitoringAdapter(monitoring.Config{
+			Namespace: "test",
+			Logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		}),
+		newClient: func() (*client.Client, error) {
+			created.Add(1)
+			return restarted.client, nil
+		},
+	}
+
+	const callers = 50
+	errs := make(chan error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			containers, err := adapter.ListContainers()
+			if err == nil && len(containers) != 2 {
+				t.Errorf("ожидалось 2 контейнера, получено %d", len(containers))
+			}
+			errs <- err
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		adapter.SetRegistry(&RegistryConfig{URL: "registry.example.com"})
+		assert.Equal(t, "registry.example.com/app", adapter.registryAdapter().QualifyImage("app"))
+	}()
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	// Все вызовы, получившие ошибку соединения, используют один новый клиент
+	assert.Equal(t, int32(1), created.Load())
+}
@@ -0,0 +1,261 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/tlsconfig"
+	"github.com/localops/devops-manager/internal/adapters/monitoring"
+	"github.com/pkg/errors"
+)
+
+// defaultContextName встроенный context docker CLI: адрес и TLS берутся
+// из окружения (DOCKER_HOST, DOCKER_CERT_PATH)
+const defaultContextName = "default"
+
+// DockerContext описывает context docker CLI (docker context ls)
+type DockerContext struct {
+	Name        string
+	Description string
+	// Host адрес Docker daemon, например unix:///var/run/docker.sock или tcp://host:2376
+	Host string
+	// SkipTLSVerify отключает проверку сертификата daemon
+	SkipTLSVerify bool
+	// TLS файлы сертификатов context, nil - context без TLS
+	TLS *ContextTLS
+	// Current отмечает context, выбранный через DOCKER_CONTEXT или docker context use
+	Current bool
+}
+
+// ContextTLS пути к сертификатам context. Отсутствующие файлы остаются пустыми.
+type ContextTLS struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+}
+
+// contextMeta формат файла meta.json хранилища context docker CLI
+type contextMeta struct {
+	Name     string `json:"Name"`
+	Metadata struct {
+		Description string `json:"Description"`
+	} `json:"Metadata"`
+	Endpoints map[string]struct {
+		Host          string `json:"Host"`
+		SkipTLSVerify bool   `json:"SkipTLSVerify"`
+	} `json:"Endpoints"`
+}
+
+// dockerConfigDir возвращает каталог настроек docker CLI: DOCKER_CONFIG
+// или ~/.docker
+func dockerConfigDir() (string, error) {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrap(err, "ошибка при получении домашней директории")
+	}
+	return filepath.Join(home, ".docker"), nil
+}
+
+// ListDockerContexts возвращает context docker CLI, отсортированные по
+// имени. Встроенный context default идет первым.
+func ListDockerContexts() ([]DockerContext, error) {
+	configDir, err := dockerConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return listDockerContexts(configDir)
+}
+
+func listDockerContexts(configDir string) ([]DockerContext, error) {
+	current := currentContextName(configDir)
+	contexts := []DockerContext{{
+		Name:        defaultContextName,
+		Description: "Адрес из DOCKER_HOST или локальный daemon",
+		Host:        os.Getenv("DOCKER_HOST"),
+		Current:     current == defaultContextName,
+	}}
+
+	metaFiles, err := filepath.Glob(filepath.Join(configDir, "contexts", "meta", "*", "meta.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "ошибка при поиске context")
+	}
+
+	var named []DockerContext
+	for _, path := range metaFiles {
+		dockerContext, err := readContextMeta(configDir, path)
+		if err != nil {
+			return nil, err
+		}
+		dockerContext.Current = dockerContext.Name == current
+		named = append(named, *dockerContext)
+	}
+	sort.Slice(named, func(i, j int) bool { return named[i].Name < named[j].Name })
+
+	return append(contexts, named...), nil
+}
+
+// loadDockerContext читает context name из хранилища docker CLI
+func loadDockerContext(configDir, name string) (*DockerContext, error) {
+	path := filepath.Join(configDir, "contexts", "meta", contextDirName(name), "meta.json")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, errors.Errorf("Docker context %s не найден", name)
+	}
+	return readContextMeta(configDir, path)
+}
+
+// readContextMeta разбирает meta.json и находит сертификаты context
+func readContextMeta(configDir, path string) (*DockerContext, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "ошибка при чтении Docker context")
+	}
+
+	var meta contextMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, errors.Wrapf(err, "некорректный Docker context %s", path)
+	}
+	endpoint, ok := meta.Endpoints["docker"]
+	if !ok || endpoint.Host == "" {
+		return nil, errors.Errorf("в Docker context %s не задан адрес daemon", meta.Name)
+	}
+
+	dockerContext := &DockerContext{
+		Name:          meta.Name,
+		Description:   meta.Metadata.Description,
+		Host:          endpoint.Host,
+		SkipTLSVerify: endpoint.SkipTLSVerify,
+	}
+
+	// Сертификаты лежат в contexts/tls/<хеш имени>/docker
+	tlsDir := filepath.Join(configDir, "contexts", "tls", contextDirName(meta.Name), "docker")
+	tls := ContextTLS{
+		CAFile:   existingFile(filepath.Join(tlsDir, "ca.pem")),
+		CertFile: existingFile(filepath.Join(tlsDir, "cert.pem")),
+		KeyFile:  existingFile(filepath.Join(tlsDir, "key.pem")),
+	}
+	if tls != (ContextTLS{}) {
+		dockerContext.TLS = &tls
+	}
+
+	return dockerContext, nil
+}
+
+// contextDirName имя каталога context в хранилище: SHA-256 имени
+func contextDirName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
+
+// existingFile возвращает path, если файл существует, иначе пустую строку
+func existingFile(path string) string {
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// CurrentDockerContext возвращает имя выбранного context docker CLI,
+// default - если context не выбран
+func CurrentDockerContext() string {
+	configDir, err := dockerConfigDir()
+	if err != nil {
+		return currentContextName("")
+	}
+	return currentContextName(configDir)
+}
+
+// currentContextName возвращает выбранный context: DOCKER_CONTEXT, а без
+// него currentContext из config.json. DOCKER_HOST, как и в docker CLI,
+// имеет приоритет над config.json.
+func currentContextName(configDir string) string {
+	if name := os.Getenv("DOCKER_CONTEXT"); name != "" {
+		return name
+	}
+	if os.Getenv("DOCKER_HOST") != "" {
+		return defaultContextName
+	}
+
+	if configDir == "" {
+		return defaultContextName
+	}
+	data, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+	if err != nil {
+		return defaultContextName
+	}
+	var config struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if json.Unmarshal(data, &config) != nil || config.CurrentContext == "" {
+		return defaultContextName
+	}
+	return config.CurrentContext
+}
+
+// clientOptions возвращает параметры клиента для адреса и сертификатов context
+func (c *DockerContext) clientOptions() ([]client.Opt, error) {
+	if strings.HasPrefix(c.Host, "ssh://") {
+		return nil, errors.Errorf("Docker context %s подключается по ssh (%s), такие context не поддерживаются; выберите другой context через DOCKER_CONTEXT, например default", c.Name, c.Host)
+	}
+
+	var opts []client.Opt
+	if c.TLS != nil || c.SkipTLSVerify {
+		options := tlsconfig.Options{InsecureSkipVerify: c.SkipTLSVerify}
+		if c.TLS != nil {
+			options.CAFile = c.TLS.CAFile
+			options.CertFile = c.TLS.CertFile
+			options.KeyFile = c.TLS.KeyFile
+		}
+		tlsConfig, err := tlsconfig.Client(options)
+		if err != nil {
+			return nil, errors.Wrapf(err, "ошибка при загрузке сертификатов Docker context %s", c.Name)
+		}
+		opts = append(opts, client.WithHTTPClient(&http.Client{
+			Transport:     &http.Transport{TLSClientConfig: tlsConfig},
+			CheckRedirect: client.CheckRedirect,
+		}))
+	}
+
+	opts = append(opts,
+		client.WithHost(c.Host),
+		client.WithVersion(os.Getenv("DOCKER_API_VERSION")),
+		client.WithAPIVersionNegotiation(),
+	)
+	return opts, nil
+}
+
+// NewDockerAdapterForContext создает DockerAdapter, подключенный к daemon
+// context name docker CLI. Context default использует окружение, как
+// NewDockerAdapter без DOCKER_CONTEXT. Context с адресом ssh:// не
+// поддерживаются: для них нужен docker CLI в роли транспорта.
+func NewDockerAdapterForContext(name string, registryConfig *RegistryConfig, monitoring *monitoring.MonitoringAdapter) (*DockerAdapter, error) {
+	if name == "" {
+		name = defaultContextName
+	}
+	if name == defaultContextName {
+		return newDockerAdapter(name, clientOptions(), registryConfig, monitoring)
+	}
+
+	configDir, err := dockerConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	dockerContext, err := loadDockerContext(configDir, name)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := dockerContext.clientOptions()
+	if err != nil {
+		return nil, err
+	}
+	return newDockerAdapter(name, opts, registryConfig, monitoring)
+}
@@ -0,0 +1,108 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeContextFixture создает в configDir context name в формате
+// хранилища docker CLI. Непустой tlsFiles создает сертификаты context.
+func writeContextFixture(t *testing.T, configDir, name, meta string, tlsFiles ...string) {
+	metaDir := filepath.Join(configDir, "contexts", "meta", contextDirName(name))
+	require.NoError(t, os.MkdirAll(metaDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(meta), 0o644))
+
+	tlsDir := filepath.Join(configDir, "contexts", "tls", contextDirName(name), "docker")
+	for _, file := range tlsFiles {
+		require.NoError(t, os.MkdirAll(tlsDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(tlsDir, file), []byte("-----BEGIN CERTIFICATE-----\n"), 0o600))
+	}
+}
+
+func TestListDockerContexts(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", configDir)
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("DOCKER_CONTEXT", "")
+
+	writeContextFixture(t, configDir, "remote", `{
+		"Name": "remote",
+		"Metadata": {"Description": "Сервер сборки"},
+		"Endpoints": {"docker": {"Host": "tcp://build.example.com:2376", "SkipTLSVerify": false}}
+	}`, "ca.pem", "cert.pem", "key.pem")
+	writeContextFixture(t, configDir, "insecure", `{
+		"Name": "insecure",
+		"Metadata": {},
+		"Endpoints": {"docker": {"Host": "tcp://10.0.0.5:2376", "SkipTLSVerify": true}}
+	}`)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"currentContext": "remote"}`), 0o600))
+
+	t.Run("адрес и TLS читаются из meta.json", func(t *testing.T) {
+		contexts, err := ListDockerContexts()
+		require.NoError(t, err)
+		require.Len(t, contexts, 3)
+
+		assert.Equal(t, "default", contexts[0].Name)
+		assert.False(t, contexts[0].Current)
+
+		insecure := contexts[1]
+		assert.Equal(t, "insecure", insecure.Name)
+		assert.Equal(t, "tcp://10.0.0.5:2376", insecure.Host)
+		assert.True(t, insecure.SkipTLSVerify)
+		assert.Nil(t, insecure.TLS)
+
+		remote := contexts[2]
+		assert.Equal(t, "remote", remote.Name)
+		assert.Equal(t, "Сервер сборки", remote.Description)
+		assert.Equal(t, "tcp://build.example.com:2376", remote.Host)
+		assert.False(t, remote.SkipTLSVerify)
+		assert.True(t, remote.Current)
+
+		tlsDir := filepath.Join(configDir, "contexts", "tls", contextDirName("remote"), "docker")
+		require.NotNil(t, remote.TLS)
+		assert.Equal(t, ContextTLS{
+			CAFile:   filepath.Join(tlsDir, "ca.pem"),
+			CertFile: filepath.Join(tlsDir, "cert.pem"),
+			KeyFile:  filepath.Join(tlsDir, "key.pem"),
+		}, *remote.TLS)
+	})
+
+	t.Run("DOCKER_CONTEXT важнее config.json", func(t *testing.T) {
+		t.Setenv("DOCKER_CONTEXT", "insecure")
+		assert.Equal(t, "insecure", CurrentDockerContext())
+	})
+
+	t.Run("DOCKER_HOST отменяет context из config.json", func(t *testing.T) {
+		t.Setenv("DOCKER_HOST", "unix:///var/run/docker.sock")
+		assert.Equal(t, "default", CurrentDockerContext())
+	})
+
+	t.Run("адаптер подключается к адресу context", func(t *testing.T) {
+		adapter, err := NewDockerAdapterForContext("insecure", nil, nil)
+		require.NoError(t, err)
+		defer adapter.Close()
+
+		assert.Equal(t, "tcp://10.0.0.5:2376", adapter.apiClient().DaemonHost())
+		assert.Equal(t, []string{"--context", "insecure", "pull", "nginx"}, adapter.cliArgs("pull", "nginx"))
+	})
+
+	t.Run("ssh context отклоняется с понятной ошибкой", func(t *testing.T) {
+		writeContextFixture(t, configDir, "over-ssh", `{
+			"Name": "over-ssh",
+			"Endpoints": {"docker": {"Host": "ssh://user@build.example.com"}}
+		}`)
+
+		_, err := NewDockerAdapterForContext("over-ssh", nil, nil)
+		assert.ErrorContains(t, err, "ssh")
+		assert.ErrorContains(t, err, "не поддерживаются")
+	})
+
+	t.Run("неизвестный context", func(t *testing.T) {
+		_, err := NewDockerAdapterForContext("missing", nil, nil)
+		assert.ErrorContains(t, err, "не найден")
+	})
+}
@@ -0,0 +1,192 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	units "github.com/docker/go-units"
+	"github.com/localops/devops-manager/internal/tracing"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Describe возвращает читаемое описание объекта Docker в духе docker
+// inspect. kind - container, image, network или volume.
+func (d *DockerAdapter) Describe(kind, id string) (string, error) {
+	ctx, span := tracing.Start(d.ctx, "docker", "describe", attribute.String("kind", kind), attribute.String("id", id))
+	start := time.Now()
+	text, err := d.describe(ctx, kind, id)
+	duration := time.Since(start)
+	tracing.End(span, err)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation("describe", status, duration)
+		d.monitoring.RecordError("docker", "describe", classifyError(err))
+	}
+
+	return text, err
+}
+
+// describe получает объект нужного вида и форматирует его. Повторяется
+// только запрос к daemon, а не описание целиком.
+func (d *DockerAdapter) describe(ctx context.Context, kind, id string) (string, error) {
+	switch strings.ToLower(kind) {
+	case "container":
+		summary, err := d.containerSummary(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return formatContainerSummary(summary), nil
+	case "image":
+		summary, err := d.imageSummary(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return formatImageSummary(summary), nil
+	case "network":
+		resource, err := withReconnectValue(d, func() (types.NetworkResource, error) {
+			return d.apiClient().NetworkInspect(ctx, id, types.NetworkInspectOptions{})
+		})
+		if err != nil {
+			return "", errors.Wrap(err, "ошибка при получении информации о сети")
+		}
+		return formatNetwork(resource), nil
+	case "volume":
+		volume, err := withReconnectValue(d, func() (types.Volume, error) {
+			return d.apiClient().VolumeInspect(ctx, id)
+		})
+		if err != nil {
+			return "", errors.Wrap(err, "ошибка при получении информации о томе")
+		}
+		return formatVolume(volume), nil
+	default:
+		return "", errors.Errorf("неизвестный вид объекта %s: ожидается container, image, network или volume", kind)
+	}
+}
+
+// description собирает текст описания: поля "Имя: значение" и списки
+// с отступом. Пустые значения пропускаются.
+type description struct {
+	strings.Builder
+}
+
+func (b *description) field(name, value string) {
+	if value != "" {
+		fmt.Fprintf(b, "%s: %s\n", name, value)
+	}
+}
+
+func (b *description) list(name string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", name)
+	for _, item := range items {
+		fmt.Fprintf(b, "  %s\n", item)
+	}
+}
+
+// pairs форматирует map как отсортированный список key=value
+func (b *description) pairs(name string, values map[string]string) {
+	items := make([]string, 0, len(values))
+	for key, value := range values {
+		items = append(items, key+"="+value)
+	}
+	sort.Strings(items)
+	b.list(name, items)
+}
+
+// formatContainerSummary форматирует сводку о контейнере
+func formatContainerSummary(s *ContainerSummary) string {
+	var b description
+	b.field("ID", s.ID)
+	b.field("Имя", s.Name)
+	b.field("Образ", s.Image)
+	b.field("Состояние", s.State)
+	if !s.StartedAt.IsZero() {
+		b.field("Запущен", s.StartedAt.Format(time.RFC3339))
+	}
+	b.field("Перезапусков", fmt.Sprint(s.RestartCount))
+	b.field("Политика перезапуска", s.RestartPolicy)
+	b.list("Порты", s.Ports)
+	b.list("Сети", s.Networks)
+	b.list("Тома", s.Mounts)
+	b.list("Переменные окружения", s.Env)
+	b.pairs("Метки", s.Labels)
+	return b.String()
+}
+
+// formatImageSummary форматирует сводку об образе
+func formatImageSummary(s *ImageSummary) string {
+	var b description
+	b.field("ID", s.ID)
+	b.list("Теги", s.RepoTags)
+	b.field("Digest", s.Digest)
+	b.field("Размер", units.HumanSize(float64(s.Size)))
+	b.field("Создан", s.Created.Format(time.RFC3339))
+	b.field("Платформа", s.OS+"/"+s.Architecture)
+	b.field("Entrypoint", strings.Join(s.Entrypoint, " "))
+	b.field("Cmd", strings.Join(s.Cmd, " "))
+	b.list("Открытые порты", s.ExposedPorts)
+	b.field("Слоев", fmt.Sprint(s.LayerCount))
+	b.list("Переменные окружения", s.Env)
+	return b.String()
+}
+
+// formatNetwork форматирует сведения о сети
+func formatNetwork(n types.NetworkResource) string {
+	var b description
+	b.field("ID", n.ID)
+	b.field("Имя", n.Name)
+	b.field("Драйвер", n.Driver)
+	b.field("Область", n.Scope)
+	b.field("Внутренняя", fmt.Sprint(n.Internal))
+
+	var subnets []string
+	for _, config := range n.IPAM.Config {
+		subnet := config.Subnet
+		if config.Gateway != "" {
+			subnet += " (шлюз " + config.Gateway + ")"
+		}
+		subnets = append(subnets, subnet)
+	}
+	b.list("Подсети", subnets)
+
+	var containers []string
+	for id, endpoint := range n.Containers {
+		name := endpoint.Name
+		if name == "" {
+			name = id
+		}
+		if endpoint.IPv4Address != "" {
+			name += " " + endpoint.IPv4Address
+		}
+		containers = append(containers, name)
+	}
+	sort.Strings(containers)
+	b.list("Контейнеры", containers)
+	b.pairs("Метки", n.Labels)
+	return b.String()
+}
+
+// formatVolume форматирует сведения о томе
+func formatVolume(v types.Volume) string {
+	var b description
+	b.field("Имя", v.Name)
+	b.field("Драйвер", v.Driver)
+	b.field("Точка монтирования", v.Mountpoint)
+	b.field("Область", v.Scope)
+	b.field("Создан", v.CreatedAt)
+	b.pairs("Опции", v.Options)
+	b.pairs("Метки", v.Labels)
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeContainer(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	fake.addContainer(fakeContainer{
+		Name:       "web",
+		Image:      "nginx:1.25",
+		Labels:     map[string]string{"tier": "frontend", "app": "web"},
+		Env:        []string{"MODE=production"},
+		HostConfig: container.HostConfig{RestartPolicy: container.RestartPolicy{Name: "unless-stopped"}},
+	})
+
+	text, err := adapter.Describe("container", "web")
+	require.NoError(t, err)
+
+	for _, want := range []string{
+		"ID: web-id\n",
+		"Имя: web\n",
+		"Образ: nginx:1.25\n",
+		"Состояние: running\n",
+		"Политика перезапуска: unless-stopped\n",
+		"Переменные окружения:\n  MODE=production\n",
+		"Метки:\n  app=web\n  tier=frontend\n",
+	} {
+		assert.Contains(t, text, want)
+	}
+}
+
+func TestDescribeUnknownKind(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+
+	_, err := adapter.Describe("service", "web")
+	require.Error(t, err)
+	assert.Empty(t, fake.requested())
+}
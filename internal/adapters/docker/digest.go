@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// VerifyImageDigest проверяет, что локальный образ image получен из registry
+// с digest expected. expected задается как sha256:... или repo@sha256:....
+// Проверка защищает от подмены образа под тем же тегом.
+func (d *DockerAdapter) VerifyImageDigest(image, expected string) error {
+	_, err := d.pinnedImage(d.ctx, image, expected)
+	return err
+}
+
+// pinnedImage возвращает ссылку repo@digest на образ image, если один из его
+// RepoDigests совпадает с expected, и ошибку в противном случае
+func (d *DockerAdapter) pinnedImage(ctx context.Context, image, expected string) (string, error) {
+	if at := strings.LastIndex(expected, "@"); at >= 0 {
+		expected = expected[at+1:]
+	}
+	want, err := digest.Parse(expected)
+	if err != nil {
+		return "", errors.Wrapf(err, "некорректный digest %q", expected)
+	}
+
+	inspect, err := withReconnectValue(d, func() (types.ImageInspect, error) {
+		inspect, _, err := d.apiClient().ImageInspectWithRaw(ctx, image)
+		return inspect, err
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "ошибка при получении информации об образе")
+	}
+
+	for _, repoDigest := range inspect.RepoDigests {
+		if _, got, ok := strings.Cut(repoDigest, "@"); ok && got == want.String() {
+			return repoDigest, nil
+		}
+	}
+
+	if len(inspect.RepoDigests) == 0 {
+		return "", errors.Errorf("у образа %s нет digest из registry, ожидался %s", image, want)
+	}
+	return "", errors.Errorf("digest образа %s (%s) не совпадает с ожидаемым %s", image, strings.Join(inspect.RepoDigests, ", "), want)
+}
@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	pinnedDigest = "sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945"
+	otherDigest  = "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+func TestVerifyImageDigest(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	fake.addImage("registry.local/app:1.0", fakeImage{Digests: []string{"registry.local/app@" + pinnedDigest}})
+
+	assert.NoError(t, adapter.VerifyImageDigest("registry.local/app:1.0", pinnedDigest))
+	assert.NoError(t, adapter.VerifyImageDigest("registry.local/app:1.0", "registry.local/app@"+pinnedDigest))
+	assert.Error(t, adapter.VerifyImageDigest("registry.local/app:1.0", otherDigest))
+	assert.Error(t, adapter.VerifyImageDigest("registry.local/app:1.0", "latest"))
+}
+
+func TestRunContainerExpectedDigest(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		wantErr  bool
+	}{
+		{name: "digest совпадает", expected: pinnedDigest},
+		{name: "digest не совпадает", expected: otherDigest, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, adapter := newFakeDockerServer(t)
+			img := fakeImage{Digests: []string{"registry.local/app@" + pinnedDigest}}
+			fake.addImage("registry.local/app:1.0", img)
+			fake.addImage("registry.local/app@"+pinnedDigest, img)
+
+			info, err := adapter.RunContainer(ContainerOptions{
+				Image:          "registry.local/app:1.0",
+				Name:           "app",
+				ExpectedDigest: tt.expected,
+			})
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Nil(t, fake.container("app"))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "registry.local/app@"+pinnedDigest, info.Image)
+			assert.Equal(t, "running", fake.container("app").State)
+		})
+	}
+}
@@ -2,12 +2,15 @@ package docker
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -16,22 +19,93 @@ import (
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/go-connections/nat"
 	"github.com/localops/devops-manager/internal/adapters/monitoring"
+	"github.com/localops/devops-manager/internal/audit"
+	"github.com/localops/devops-manager/internal/tracing"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-// ContainerOptions содержит параметры для создания контейнера
+// ContainerOptions содержит параметры для создания контейнера.
+// Значения Environment могут ссылаться на переменные окружения хоста:
+// $VAR, ${VAR} или ${VAR:-default}.
 type ContainerOptions struct {
-	Image         string
-	Name          string
-	Ports         map[string]string
-	Environment   map[string]string
+	Image       string
+	Name        string
+	Ports       map[string]string
+	Environment map[string]string
+	// EnvFile файл с переменными окружения в формате dotenv (docker run
+	// --env-file). Переменные из Environment переопределяют значения из файла.
+	EnvFile       string
 	Volumes       map[string]string
 	Network       string
 	Command       []string
 	RestartPolicy container.RestartPolicy
 	Labels        map[string]string
+	// AutoRemove удаляет контейнер после завершения (docker run --rm).
+	// Несовместим с политикой перезапуска.
+	AutoRemove bool
+	// ExpectedDigest запрещает запуск, если digest локального образа
+	// отличается от указанного. Контейнер создается из образа по digest.
+	ExpectedDigest string
+	// PullIfMissing скачивает образ, если его нет локально (docker run
+	// --pull missing). Образ из настроенного registry скачивается с его
+	// учетными данными, остальные без аутентификации.
+	PullIfMissing bool
+}
+
+// BuildOptions содержит параметры сборки образа
+type BuildOptions struct {
+	// Tags теги, которыми помечается собранный образ; нужен хотя бы один
+	Tags      []string
+	BuildArgs map[string]*string
+	// Labels метки образа (docker build --label)
+	Labels map[string]string
+	// Target стадия многоэтапного Dockerfile, до которой ведется сборка;
+	// пустая строка собирает весь Dockerfile
+	Target string
+	// CacheFrom образы, слои которых используются как кеш сборки
+	// (docker build --cache-from). Классический сборщик берет кеш только
+	// из локальных образов, поэтому их нужно скачать заранее; BuildKit
+	// скачивает кеш из registry сам, если образ собран с inline кешем.
+	CacheFrom []string
+	// BuildKit включает сборщик BuildKit (DOCKER_BUILDKIT=1) для локальной
+	// сборки и встраивает в образ inline кеш для следующих сборок.
+	// Сборка из git через Docker API всегда выполняется классическим
+	// сборщиком, так как BuildKit через API требует отдельной сессии.
+	BuildKit bool
+}
+
+// imageBuildOptions переводит параметры в формат Docker API
+func (o BuildOptions) imageBuildOptions() types.ImageBuildOptions {
+	return types.ImageBuildOptions{
+		Tags:      o.Tags,
+		BuildArgs: o.BuildArgs,
+		Labels:    o.Labels,
+		Target:    o.Target,
+		CacheFrom: o.CacheFrom,
+		Remove:    true,
+	}
+}
+
+// ContainerSummary содержит основные сведения о контейнере
+type ContainerSummary struct {
+	ID            string
+	Name          string
+	Image         string
+	State         string
+	StartedAt     time.Time
+	RestartCount  int
+	RestartPolicy string
+	Env           []string
+	Labels        map[string]string
+	// Mounts тома в виде source:destination, с суффиксом :ro для томов только для чтения
+	Mounts []string
+	// Ports опубликованные порты в виде host:port->port/proto
+	Ports    []string
+	Networks []string
 }
 
 // ContainerInfo содержит информацию о контейнере
@@ -56,25 +130,92 @@ type ImageInfo struct {
 	Labels   map[string]string
 }
 
-// DockerAdapter предоставляет методы для работы с Docker
+// ImageSummary содержит основные сведения об образе
+type ImageSummary struct {
+	ID           string
+	RepoTags     []string
+	Digest       string
+	Size         int64
+	Created      time.Time
+	Architecture string
+	OS           string
+	Entrypoint   []string
+	Cmd          []string
+	ExposedPorts []string
+	Env          []string
+	LayerCount   int
+}
+
+// DockerAdapter предоставляет методы для работы с Docker.
+//
+// Методы адаптера безопасны для одновременного вызова из нескольких
+// горутин, например из обработчиков HTTP API. Клиент и registry меняются
+// только под mu (переподключение, SetRegistry) и читаются через apiClient и
+// registryAdapter; ctx и monitoring задаются при создании и не меняются.
+// Параллельные операции над одним контейнером не упорядочиваются: их
+// результат определяет Docker daemon.
+//
+// Запросы к daemon выполняются через withReconnect. Исключения: Events и
+// ContainerWait сообщают об ошибке соединения через канал, уже после
+// возврата, а ошибка ContainerResize при attach не влияет на сессию.
 type DockerAdapter struct {
 	client     *client.Client
 	ctx        context.Context
 	registry   *RegistryAdapter
 	monitoring *monitoring.MonitoringAdapter
+
+	// auditLogger журнал изменяющих операций, nil - журнал не ведется
+	auditLogger audit.Logger
+
+	// batchConcurrency число одновременных операций в массовых операциях,
+	// 0 - значение по умолчанию
+	batchConcurrency int
+
+	// mu защищает client, registry, auditLogger и batchConcurrency
+	mu sync.RWMutex
+	// newClient создает клиент при переподключении к daemon
+	newClient func() (*client.Client, error)
+	// contextName context docker CLI, к daemon которого подключен адаптер;
+	// передается вызываемому docker CLI. Пустое имя - адаптер создан не
+	// через NewDockerAdapter.
+	contextName string
+}
+
+// clientOptions возвращает параметры Docker клиента: адрес и TLS берутся
+// из окружения, версия API согласуется с daemon при первом запросе.
+// Переменная DOCKER_API_VERSION фиксирует версию и отключает согласование.
+func clientOptions() []client.Opt {
+	return []client.Opt{
+		client.FromEnv,
+		client.WithAPIVersionNegotiation(),
+	}
 }
 
-// NewDockerAdapter создает новый экземпляр DockerAdapter
+// NewDockerAdapter создает новый экземпляр DockerAdapter. Daemon выбирается
+// как в docker CLI: context из DOCKER_CONTEXT или docker context use, а без
+// него адрес из окружения.
 func NewDockerAdapter(registryConfig *RegistryConfig, monitoring *monitoring.MonitoringAdapter) (*DockerAdapter, error) {
-	cli, err := client.NewClientWithOpts(client.FromEnv)
+	return NewDockerAdapterForContext(CurrentDockerContext(), registryConfig, monitoring)
+}
+
+// newDockerAdapter создает DockerAdapter для context contextName с
+// параметрами клиента opts; они же используются при переподключении
+func newDockerAdapter(contextName string, opts []client.Opt, registryConfig *RegistryConfig, monitoring *monitoring.MonitoringAdapter) (*DockerAdapter, error) {
+	newClient := func() (*client.Client, error) {
+		return client.NewClientWithOpts(opts...)
+	}
+
+	cli, err := newClient()
 	if err != nil {
 		return nil, errors.Wrap(err, "ошибка при создании Docker клиента")
 	}
 
 	adapter := &DockerAdapter{
-		client:     cli,
-		ctx:        context.Background(),
-		monitoring: monitoring,
+		client:      cli,
+		ctx:         context.Background(),
+		monitoring:  monitoring,
+		newClient:   newClient,
+		contextName: contextName,
 	}
 
 	if registryConfig != nil {
@@ -84,10 +225,74 @@ func NewDockerAdapter(registryConfig *RegistryConfig, monitoring *monitoring.Mon
 	return adapter, nil
 }
 
+// SetRegistry заменяет настройки registry, используемые PushImageToRegistry
+// и PullImageFromRegistry. nil отключает registry.
+func (d *DockerAdapter) SetRegistry(config *RegistryConfig) {
+	var registry *RegistryAdapter
+	if config != nil {
+		registry = NewRegistryAdapter(*config)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if registry != nil {
+		registry.SetAuditLogger(d.auditLogger)
+	}
+	d.registry = registry
+}
+
+// SetBatchConcurrency задает, сколько контейнеров массовые операции
+// (StartContainersByLabel и другие) обрабатывают одновременно.
+// Значение меньше 1 возвращает значение по умолчанию.
+func (d *DockerAdapter) SetBatchConcurrency(n int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.batchConcurrency = n
+}
+
+// SetAuditLogger задает журнал аудита изменяющих операций (сборка,
+// запуск, остановка, удаление и другие). nil отключает журнал.
+func (d *DockerAdapter) SetAuditLogger(logger audit.Logger) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.auditLogger = logger
+	if d.registry != nil {
+		d.registry.SetAuditLogger(logger)
+	}
+}
+
+// recordAudit записывает результат изменяющей операции в журнал аудита
+func (d *DockerAdapter) recordAudit(ctx context.Context, operation, target string, err error) {
+	d.mu.RLock()
+	logger := d.auditLogger
+	d.mu.RUnlock()
+
+	audit.Record(ctx, logger, "docker", operation, target, err)
+}
+
+// containerTarget объект аудита для создаваемого контейнера: имя, а для
+// безымянного контейнера образ
+func containerTarget(opts ContainerOptions) string {
+	if opts.Name != "" {
+		return opts.Name
+	}
+	return opts.Image
+}
+
+// registryAdapter возвращает текущий registry или nil
+func (d *DockerAdapter) registryAdapter() *RegistryAdapter {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.registry
+}
+
 // PullImage скачивает Docker образ
+//
+// Deprecated: метод вызывает локальный docker CLI и не отдает прогресс.
+// Используйте PullImageViaSDK.
 func (d *DockerAdapter) PullImage(image string) error {
 	// Создаем команду
-	cmd := exec.Command("docker", "pull", image)
+	cmd := exec.Command("docker", d.cliArgs("pull", image)...)
 
 	// Перенаправляем вывод
 	cmd.Stdout = os.Stdout
@@ -101,11 +306,52 @@ func (d *DockerAdapter) PullImage(image string) error {
 	return nil
 }
 
-// BuildImage собирает Docker образ
-func (d *DockerAdapter) BuildImage(path string, tag string, buildArgs map[string]*string) error {
+// PullImageViaSDK скачивает образ через Docker API и пишет прогресс в out
+// построчно в читаемом виде
+func (d *DockerAdapter) PullImageViaSDK(ctx context.Context, image string, auth types.AuthConfig, out io.Writer) error {
+	ctx, span := tracing.Start(ctx, "docker", "pull_image", attribute.String("image", image))
 	start := time.Now()
-	err := d.buildImage(path, tag, buildArgs)
+	err := d.pullImage(ctx, image, auth, out)
 	duration := time.Since(start)
+	tracing.End(span, err)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation("pull_image", status, duration)
+		d.monitoring.RecordError("docker", "pull_image", classifyError(err))
+	}
+
+	d.recordAudit(ctx, "pull_image", image, err)
+
+	return err
+}
+
+// BuildImage собирает Docker образ. labels добавляются к образу как метки
+// (docker build --label), nil означает отсутствие меток.
+func (d *DockerAdapter) BuildImage(path string, tag string, buildArgs map[string]*string, labels map[string]string) error {
+	return d.BuildImageMultiTag(path, []string{tag}, buildArgs, labels)
+}
+
+// BuildImageMultiTag собирает Docker образ и сразу помечает его всеми тегами
+// из tags (например, :latest и :<sha>), без отдельного шага TagImage
+func (d *DockerAdapter) BuildImageMultiTag(path string, tags []string, buildArgs map[string]*string, labels map[string]string) error {
+	return d.BuildImageWithOptions(path, BuildOptions{Tags: tags, BuildArgs: buildArgs, Labels: labels})
+}
+
+// BuildImageWithOptions собирает Docker образ из директории path
+func (d *DockerAdapter) BuildImageWithOptions(path string, opts BuildOptions) error {
+	ctx, span := tracing.Start(d.ctx, "docker", "build_image", attribute.StringSlice("image", opts.Tags), attribute.String("path", path), attribute.String("target", opts.Target))
+	start := time.Now()
+	err := validateTags(opts.Tags)
+	if err == nil {
+		err = d.withReconnect(func() error { return d.buildImage(path, opts) })
+	}
+	duration := time.Since(start)
+	tracing.End(span, err)
 
 	status := "success"
 	if err != nil {
@@ -114,16 +360,22 @@ func (d *DockerAdapter) BuildImage(path string, tag string, buildArgs map[string
 
 	if d.monitoring != nil {
 		d.monitoring.RecordDockerOperation("build_image", status, duration)
+		d.monitoring.RecordError("docker", "build_image", classifyError(err))
 	}
 
+	d.recordAudit(ctx, "build_image", strings.Join(opts.Tags, ","), err)
+
 	return err
 }
 
 // RunContainer создает и запускает контейнер
 func (d *DockerAdapter) RunContainer(opts ContainerOptions) (*ContainerInfo, error) {
+	ctx, span := tracing.Start(d.ctx, "docker", "run_container", attribute.String("image", opts.Image), attribute.String("container.name", opts.Name))
 	start := time.Now()
-	container, err := d.runContainer(opts)
+	// Шаги создания и запуска повторяются при переподключении по отдельности
+	container, err := d.runContainer(ctx, opts)
 	duration := time.Since(start)
+	tracing.End(span, err)
 
 	status := "success"
 	if err != nil {
@@ -132,16 +384,47 @@ func (d *DockerAdapter) RunContainer(opts ContainerOptions) (*ContainerInfo, err
 
 	if d.monitoring != nil {
 		d.monitoring.RecordDockerOperation("run_container", status, duration)
+		d.monitoring.RecordError("docker", "run_container", classifyError(err))
 	}
 
+	d.recordAudit(ctx, "run_container", containerTarget(opts), err)
+
+	return container, err
+}
+
+// CreateContainer создает контейнер без запуска, чтобы его можно было
+// настроить (например, подключить к сетям) перед первым стартом
+func (d *DockerAdapter) CreateContainer(opts ContainerOptions) (*ContainerInfo, error) {
+	ctx, span := tracing.Start(d.ctx, "docker", "create_container", attribute.String("image", opts.Image), attribute.String("container.name", opts.Name))
+	start := time.Now()
+	container, err := d.createContainerInfo(ctx, opts)
+	duration := time.Since(start)
+	tracing.End(span, err)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation("create_container", status, duration)
+		d.monitoring.RecordError("docker", "create_container", classifyError(err))
+	}
+
+	d.recordAudit(ctx, "create_container", containerTarget(opts), err)
+
 	return container, err
 }
 
 // ListContainers возвращает список всех контейнеров
 func (d *DockerAdapter) ListContainers() ([]ContainerInfo, error) {
+	ctx, span := tracing.Start(d.ctx, "docker", "list_containers")
 	start := time.Now()
-	containers, err := d.client.ContainerList(d.ctx, types.ContainerListOptions{All: true})
+	containers, err := withReconnectValue(d, func() ([]types.Container, error) {
+		return d.apiClient().ContainerList(ctx, types.ContainerListOptions{All: true})
+	})
 	duration := time.Since(start)
+	tracing.End(span, err)
 
 	status := "success"
 	if err != nil {
@@ -150,6 +433,7 @@ func (d *DockerAdapter) ListContainers() ([]ContainerInfo, error) {
 
 	if d.monitoring != nil {
 		d.monitoring.RecordDockerOperation("list_containers", status, duration)
+		d.monitoring.RecordError("docker", "list_containers", classifyError(err))
 	}
 
 	if err != nil {
@@ -172,9 +456,18 @@ func (d *DockerAdapter) ListContainers() ([]ContainerInfo, error) {
 
 // StopContainer останавливает контейнер
 func (d *DockerAdapter) StopContainer(containerID string) error {
+	return d.StopContainerWithOptions(containerID, "", defaultStopTimeout)
+}
+
+// StopContainerWithOptions останавливает контейнер указанным сигналом.
+// Если контейнер не завершился за timeout, он принудительно убивается.
+// Пустой signal означает SIGTERM через docker stop.
+func (d *DockerAdapter) StopContainerWithOptions(containerID string, signal string, timeout time.Duration) error {
+	ctx, span := tracing.Start(d.ctx, "docker", "stop_container", attribute.String("container.id", containerID), attribute.String("signal", signal))
 	start := time.Now()
-	err := d.stopContainer(containerID)
+	err := d.stopContainer(ctx, containerID, signal, timeout)
 	duration := time.Since(start)
+	tracing.End(span, err)
 
 	status := "success"
 	if err != nil {
@@ -183,16 +476,23 @@ func (d *DockerAdapter) StopContainer(containerID string) error {
 
 	if d.monitoring != nil {
 		d.monitoring.RecordDockerOperation("stop_container", status, duration)
+		d.monitoring.RecordError("docker", "stop_container", classifyError(err))
 	}
 
+	d.recordAudit(ctx, "stop_container", containerID, err)
+
 	return err
 }
 
 // RemoveContainer удаляет контейнер
 func (d *DockerAdapter) RemoveContainer(containerID string) error {
+	ctx, span := tracing.Start(d.ctx, "docker", "remove_container", attribute.String("container.id", containerID))
 	start := time.Now()
-	err := d.client.ContainerRemove(d.ctx, containerID, types.ContainerRemoveOptions{Force: true})
+	err := d.withReconnect(func() error {
+		return d.apiClient().ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true})
+	})
 	duration := time.Since(start)
+	tracing.End(span, err)
 
 	status := "success"
 	if err != nil {
@@ -201,8 +501,11 @@ func (d *DockerAdapter) RemoveContainer(containerID string) error {
 
 	if d.monitoring != nil {
 		d.monitoring.RecordDockerOperation("remove_container", status, duration)
+		d.monitoring.RecordError("docker", "remove_container", classifyError(err))
 	}
 
+	d.recordAudit(ctx, "remove_container", containerID, err)
+
 	if err != nil {
 		return errors.Wrap(err, "ошибка при удалении контейнера")
 	}
@@ -212,9 +515,13 @@ func (d *DockerAdapter) RemoveContainer(containerID string) error {
 
 // ListImages возвращает список всех образов
 func (d *DockerAdapter) ListImages() ([]ImageInfo, error) {
+	ctx, span := tracing.Start(d.ctx, "docker", "list_images")
 	start := time.Now()
-	images, err := d.client.ImageList(d.ctx, types.ImageListOptions{})
+	images, err := withReconnectValue(d, func() ([]types.ImageSummary, error) {
+		return d.apiClient().ImageList(ctx, types.ImageListOptions{})
+	})
 	duration := time.Since(start)
+	tracing.End(span, err)
 
 	status := "success"
 	if err != nil {
@@ -223,6 +530,7 @@ func (d *DockerAdapter) ListImages() ([]ImageInfo, error) {
 
 	if d.monitoring != nil {
 		d.monitoring.RecordDockerOperation("list_images", status, duration)
+		d.monitoring.RecordError("docker", "list_images", classifyError(err))
 	}
 
 	if err != nil {
@@ -246,12 +554,17 @@ func (d *DockerAdapter) ListImages() ([]ImageInfo, error) {
 
 // RemoveImage удаляет образ
 func (d *DockerAdapter) RemoveImage(imageID string) error {
+	ctx, span := tracing.Start(d.ctx, "docker", "remove_image", attribute.String("image.id", imageID))
 	start := time.Now()
-	_, err := d.client.ImageRemove(d.ctx, imageID, types.ImageRemoveOptions{
-		Force:         true,
-		PruneChildren: true,
+	err := d.withReconnect(func() error {
+		_, err := d.apiClient().ImageRemove(ctx, imageID, types.ImageRemoveOptions{
+			Force:         true,
+			PruneChildren: true,
+		})
+		return err
 	})
 	duration := time.Since(start)
+	tracing.End(span, err)
 
 	status := "success"
 	if err != nil {
@@ -260,26 +573,39 @@ func (d *DockerAdapter) RemoveImage(imageID string) error {
 
 	if d.monitoring != nil {
 		d.monitoring.RecordDockerOperation("remove_image", status, duration)
+		d.monitoring.RecordError("docker", "remove_image", classifyError(err))
 	}
 
+	d.recordAudit(ctx, "remove_image", imageID, err)
+
 	if err != nil {
 		return errors.Wrap(err, "ошибка при удалении образа")
 	}
 	return nil
 }
 
-// GetContainerLogs возвращает логи контейнера
+// GetContainerLogs возвращает логи контейнера, записанные после since
+// (нулевое время - все логи). tail ограничивает число последних строк,
+// пустая строка или "all" - без ограничения.
 func (d *DockerAdapter) GetContainerLogs(containerID string, since time.Time, tail string) (io.ReadCloser, error) {
+	ctx, span := tracing.Start(d.ctx, "docker", "get_logs", attribute.String("container.id", containerID))
 	start := time.Now()
 	options := types.ContainerLogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
-		Since:      since.Format(time.RFC3339),
 		Timestamps: true,
+		Tail:       tail,
+	}
+	// Нулевое время означает логи с момента запуска контейнера
+	if !since.IsZero() {
+		options.Since = since.Format(time.RFC3339)
 	}
 
-	logs, err := d.client.ContainerLogs(d.ctx, containerID, options)
+	logs, err := withReconnectValue(d, func() (io.ReadCloser, error) {
+		return d.apiClient().ContainerLogs(ctx, containerID, options)
+	})
 	duration := time.Since(start)
+	tracing.End(span, err)
 
 	status := "success"
 	if err != nil {
@@ -288,6 +614,7 @@ func (d *DockerAdapter) GetContainerLogs(containerID string, since time.Time, ta
 
 	if d.monitoring != nil {
 		d.monitoring.RecordDockerOperation("get_logs", status, duration)
+		d.monitoring.RecordError("docker", "get_logs", classifyError(err))
 	}
 
 	if err != nil {
@@ -299,7 +626,9 @@ func (d *DockerAdapter) GetContainerLogs(containerID string, since time.Time, ta
 
 // GetContainerStats возвращает статистику контейнера
 func (d *DockerAdapter) GetContainerStats(containerID string) (*types.Stats, error) {
-	stats, err := d.client.ContainerStats(d.ctx, containerID, false)
+	stats, err := withReconnectValue(d, func() (types.ContainerStats, error) {
+		return d.apiClient().ContainerStats(d.ctx, containerID, false)
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "ошибка при получении статистики контейнера")
 	}
@@ -315,12 +644,16 @@ func (d *DockerAdapter) GetContainerStats(containerID string) (*types.Stats, err
 
 // CreateNetwork создает новую сеть
 func (d *DockerAdapter) CreateNetwork(name string, driver string, options map[string]string) (string, error) {
+	ctx, span := tracing.Start(d.ctx, "docker", "create_network", attribute.String("network.name", name), attribute.String("network.driver", driver))
 	start := time.Now()
-	resp, err := d.client.NetworkCreate(d.ctx, name, types.NetworkCreate{
-		Driver:  driver,
-		Options: options,
+	resp, err := withReconnectValue(d, func() (types.NetworkCreateResponse, error) {
+		return d.apiClient().NetworkCreate(ctx, name, types.NetworkCreate{
+			Driver:  driver,
+			Options: options,
+		})
 	})
 	duration := time.Since(start)
+	tracing.End(span, err)
 
 	status := "success"
 	if err != nil {
@@ -329,8 +662,11 @@ func (d *DockerAdapter) CreateNetwork(name string, driver string, options map[st
 
 	if d.monitoring != nil {
 		d.monitoring.RecordDockerOperation("create_network", status, duration)
+		d.monitoring.RecordError("docker", "create_network", classifyError(err))
 	}
 
+	d.recordAudit(ctx, "create_network", name, err)
+
 	if err != nil {
 		return "", errors.Wrap(err, "ошибка при создании сети")
 	}
@@ -339,9 +675,13 @@ func (d *DockerAdapter) CreateNetwork(name string, driver string, options map[st
 
 // ConnectContainerToNetwork подключает контейнер к сети
 func (d *DockerAdapter) ConnectContainerToNetwork(containerID string, networkID string) error {
+	ctx, span := tracing.Start(d.ctx, "docker", "connect_network", attribute.String("container.id", containerID), attribute.String("network.id", networkID))
 	start := time.Now()
-	err := d.client.NetworkConnect(d.ctx, networkID, containerID, &network.EndpointSettings{})
+	err := d.withReconnect(func() error {
+		return d.apiClient().NetworkConnect(ctx, networkID, containerID, &network.EndpointSettings{})
+	})
 	duration := time.Since(start)
+	tracing.End(span, err)
 
 	status := "success"
 	if err != nil {
@@ -350,8 +690,11 @@ func (d *DockerAdapter) ConnectContainerToNetwork(containerID string, networkID
 
 	if d.monitoring != nil {
 		d.monitoring.RecordDockerOperation("connect_network", status, duration)
+		d.monitoring.RecordError("docker", "connect_network", classifyError(err))
 	}
 
+	d.recordAudit(ctx, "connect_network", containerID+" -> "+networkID, err)
+
 	if err != nil {
 		return errors.Wrap(err, "ошибка при подключении контейнера к сети")
 	}
@@ -360,9 +703,11 @@ func (d *DockerAdapter) ConnectContainerToNetwork(containerID string, networkID
 
 // DisconnectContainerFromNetwork отключает контейнер от сети
 func (d *DockerAdapter) DisconnectContainerFromNetwork(containerID string, networkID string) error {
+	ctx, span := tracing.Start(d.ctx, "docker", "disconnect_network", attribute.String("container.id", containerID), attribute.String("network.id", networkID))
 	start := time.Now()
-	err := d.client.NetworkDisconnect(d.ctx, networkID, containerID, true)
+	err := d.withReconnect(func() error { return d.apiClient().NetworkDisconnect(ctx, networkID, containerID, true) })
 	duration := time.Since(start)
+	tracing.End(span, err)
 
 	status := "success"
 	if err != nil {
@@ -371,32 +716,138 @@ func (d *DockerAdapter) DisconnectContainerFromNetwork(containerID string, netwo
 
 	if d.monitoring != nil {
 		d.monitoring.RecordDockerOperation("disconnect_network", status, duration)
+		d.monitoring.RecordError("docker", "disconnect_network", classifyError(err))
 	}
 
+	d.recordAudit(ctx, "disconnect_network", containerID+" -> "+networkID, err)
+
 	if err != nil {
 		return errors.Wrap(err, "ошибка при отключении контейнера от сети")
 	}
 	return nil
 }
 
+// RemoveNetwork удаляет сеть. Если к сети подключены контейнеры, при
+// disconnectContainers они сначала отключаются, иначе возвращается
+// ошибка со списком мешающих контейнеров.
+func (d *DockerAdapter) RemoveNetwork(networkID string, disconnectContainers bool) error {
+	ctx, span := tracing.Start(d.ctx, "docker", "remove_network", attribute.String("network.id", networkID))
+	start := time.Now()
+	err := d.removeNetwork(ctx, networkID, disconnectContainers)
+	duration := time.Since(start)
+	tracing.End(span, err)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation("remove_network", status, duration)
+		d.monitoring.RecordError("docker", "remove_network", classifyError(err))
+	}
+
+	d.recordAudit(ctx, "remove_network", networkID, err)
+
+	return err
+}
+
+// removeNetwork отключает контейнеры при необходимости и удаляет сеть
+func (d *DockerAdapter) removeNetwork(ctx context.Context, networkID string, disconnectContainers bool) error {
+	resource, err := withReconnectValue(d, func() (types.NetworkResource, error) {
+		return d.apiClient().NetworkInspect(ctx, networkID, types.NetworkInspectOptions{})
+	})
+	if err != nil {
+		return errors.Wrap(err, "ошибка при получении информации о сети")
+	}
+
+	if len(resource.Containers) > 0 && !disconnectContainers {
+		names := make([]string, 0, len(resource.Containers))
+		for id, endpoint := range resource.Containers {
+			name := endpoint.Name
+			if name == "" {
+				name = id
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return errors.Errorf("к сети %s подключены контейнеры: %s", resource.Name, strings.Join(names, ", "))
+	}
+
+	for containerID := range resource.Containers {
+		err := d.withReconnect(func() error { return d.apiClient().NetworkDisconnect(ctx, resource.ID, containerID, true) })
+		if err != nil {
+			return errors.Wrapf(err, "ошибка при отключении контейнера %s от сети", containerID)
+		}
+	}
+
+	if err := d.withReconnect(func() error { return d.apiClient().NetworkRemove(ctx, resource.ID) }); err != nil {
+		return errors.Wrap(err, "ошибка при удалении сети")
+	}
+	return nil
+}
+
 // PruneSystem очищает неиспользуемые ресурсы
 func (d *DockerAdapter) PruneSystem() error {
+	ctx, span := tracing.Start(d.ctx, "docker", "prune_system")
 	start := time.Now()
-	_, err := d.client.ContainersPrune(d.ctx, filters.Args{})
+	err := d.pruneSystem(ctx)
+	duration := time.Since(start)
+	tracing.End(span, err)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation("prune_system", status, duration)
+		d.monitoring.RecordError("docker", "prune_system", classifyError(err))
+	}
+
+	d.recordAudit(ctx, "prune_system", "", err)
+
+	return err
+}
+
+// pruneSystem последовательно очищает контейнеры, образы и сети
+func (d *DockerAdapter) pruneSystem(ctx context.Context) error {
+	_, err := withReconnectValue(d, func() (types.ContainersPruneReport, error) {
+		return d.apiClient().ContainersPrune(ctx, filters.Args{})
+	})
 	if err != nil {
 		return errors.Wrap(err, "ошибка при очистке контейнеров")
 	}
 
-	_, err = d.client.ImagesPrune(d.ctx, filters.Args{})
+	_, err = withReconnectValue(d, func() (types.ImagesPruneReport, error) {
+		return d.apiClient().ImagesPrune(ctx, filters.Args{})
+	})
 	if err != nil {
 		return errors.Wrap(err, "ошибка при очистке образов")
 	}
 
-	_, err = d.client.NetworksPrune(d.ctx, filters.Args{})
+	_, err = withReconnectValue(d, func() (types.NetworksPruneReport, error) {
+		return d.apiClient().NetworksPrune(ctx, filters.Args{})
+	})
 	if err != nil {
 		return errors.Wrap(err, "ошибка при очистке сетей")
 	}
+
+	return nil
+}
+
+// PruneStoppedContainers удаляет остановленные контейнеры, созданные раньше чем olderThan назад.
+// Возвращает ID удаленных контейнеров и объем освобожденного места в байтах
+func (d *DockerAdapter) PruneStoppedContainers(olderThan time.Duration) ([]string, int64, error) {
+	ctx, span := tracing.Start(d.ctx, "docker", "prune_containers")
+	start := time.Now()
+	// Prune затрагивает только остановленные контейнеры, поэтому достаточно фильтра until
+	args := filters.NewArgs(filters.Arg("until", olderThan.String()))
+	report, err := withReconnectValue(d, func() (types.ContainersPruneReport, error) {
+		return d.apiClient().ContainersPrune(ctx, args)
+	})
 	duration := time.Since(start)
+	tracing.End(span, err)
 
 	status := "success"
 	if err != nil {
@@ -404,45 +855,239 @@ func (d *DockerAdapter) PruneSystem() error {
 	}
 
 	if d.monitoring != nil {
-		d.monitoring.RecordDockerOperation("prune_system", status, duration)
+		d.monitoring.RecordDockerOperation("prune_containers", status, duration)
+		d.monitoring.RecordError("docker", "prune_containers", classifyError(err))
 	}
 
-	return nil
+	d.recordAudit(ctx, "prune_containers", "", err)
+
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "ошибка при очистке контейнеров")
+	}
+
+	return report.ContainersDeleted, int64(report.SpaceReclaimed), nil
+}
+
+// PruneImagesKeepLatest оставляет в каждом репозитории perRepo самых новых
+// тегов, а остальные теги удаляет. Образы, используемые контейнерами, не
+// удаляются, но учитываются среди самых новых. Возвращает удаленные теги
+// и объем места, освобожденного образами, у которых не осталось тегов.
+func (d *DockerAdapter) PruneImagesKeepLatest(perRepo int) (removed []string, reclaimed int64, err error) {
+	if perRepo < 0 {
+		return nil, 0, errors.Errorf("некорректное число сохраняемых тегов: %d", perRepo)
+	}
+
+	ctx, span := tracing.Start(d.ctx, "docker", "prune_images", attribute.Int("keep.per_repo", perRepo))
+	start := time.Now()
+	removed, reclaimed, err = d.pruneImagesKeepLatest(ctx, perRepo)
+	duration := time.Since(start)
+	tracing.End(span, err)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation("prune_images", status, duration)
+		d.monitoring.RecordError("docker", "prune_images", classifyError(err))
+	}
+
+	d.recordAudit(ctx, "prune_images", "", err)
+
+	return removed, reclaimed, err
+}
+
+// taggedImage тег локального образа
+type taggedImage struct {
+	ref     string
+	id      string
+	created int64
+	size    int64
+}
+
+// pruneImagesKeepLatest удаляет устаревшие теги по правилам PruneImagesKeepLatest
+func (d *DockerAdapter) pruneImagesKeepLatest(ctx context.Context, perRepo int) ([]string, int64, error) {
+	images, err := withReconnectValue(d, func() ([]types.ImageSummary, error) {
+		return d.apiClient().ImageList(ctx, types.ImageListOptions{})
+	})
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "ошибка при получении списка образов")
+	}
+	containers, err := withReconnectValue(d, func() ([]types.Container, error) {
+		return d.apiClient().ContainerList(ctx, types.ContainerListOptions{All: true})
+	})
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "ошибка при получении списка контейнеров")
+	}
+
+	inUse := make(map[string]bool, len(containers))
+	for _, c := range containers {
+		inUse[c.ImageID] = true
+	}
+
+	byRepo := make(map[string][]taggedImage)
+	for _, img := range images {
+		for _, ref := range img.RepoTags {
+			if ref == "<none>:<none>" {
+				continue
+			}
+			repo := imageRepository(ref)
+			byRepo[repo] = append(byRepo[repo], taggedImage{ref: ref, id: img.ID, created: img.Created, size: img.Size})
+		}
+	}
+
+	var removed []string
+	var reclaimed int64
+	for _, repo := range sortedKeys(byRepo) {
+		tags := byRepo[repo]
+		sort.Slice(tags, func(i, j int) bool {
+			if tags[i].created != tags[j].created {
+				return tags[i].created > tags[j].created
+			}
+			return tags[i].ref > tags[j].ref
+		})
+		if len(tags) <= perRepo {
+			continue
+		}
+
+		for _, tag := range tags[perRepo:] {
+			if inUse[tag.id] {
+				continue
+			}
+			// Удаляем только тег: образ удаляется, если тегов у него не осталось
+			items, err := withReconnectValue(d, func() ([]types.ImageDeleteResponseItem, error) {
+				return d.apiClient().ImageRemove(ctx, tag.ref, types.ImageRemoveOptions{PruneChildren: true})
+			})
+			if err != nil {
+				return removed, reclaimed, errors.Wrapf(err, "ошибка при удалении образа %s", tag.ref)
+			}
+			removed = append(removed, tag.ref)
+			for _, item := range items {
+				if item.Deleted == tag.id {
+					reclaimed += tag.size
+				}
+			}
+		}
+	}
+
+	return removed, reclaimed, nil
+}
+
+// imageRepository возвращает репозиторий из ссылки repo:tag. Двоеточие в
+// адресе registry (host:port/repo) тегом не считается.
+func imageRepository(ref string) string {
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i]
+	}
+	return ref
 }
 
 // Close закрывает соединение с Docker daemon
 func (d *DockerAdapter) Close() error {
-	return d.client.Close()
+	return d.apiClient().Close()
 }
 
-// PushImageToRegistry отправляет образ в registry
+// PushImageToRegistry отправляет образ в настроенный registry через Docker daemon.
+// Если имя образа не содержит адрес registry, образу предварительно ставится
+// соответствующий тег
 func (d *DockerAdapter) PushImageToRegistry(image string, auth types.AuthConfig) error {
-	if d.registry == nil {
+	registry := d.registryAdapter()
+	if registry == nil {
 		return errors.New("registry не настроен")
 	}
 
-	// Отправляем образ в registry
-	return d.registry.PushImage(image, auth)
+	target := registry.QualifyImage(image)
+	if target != image {
+		return d.TagAndPush(image, target, auth)
+	}
+
+	ctx, span := tracing.Start(d.ctx, "docker", "push_image", attribute.String("image", target))
+	start := time.Now()
+	err := d.pushImage(ctx, target, auth, os.Stdout)
+	duration := time.Since(start)
+	tracing.End(span, err)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation("push_image", status, duration)
+		d.monitoring.RecordError("docker", "push_image", classifyError(err))
+	}
+
+	d.recordAudit(ctx, "push_image", target, err)
+
+	return err
+}
+
+// TagAndPush создает тег для образа и отправляет его в registry
+func (d *DockerAdapter) TagAndPush(sourceImage string, targetImage string, auth types.AuthConfig) error {
+	ctx, span := tracing.Start(d.ctx, "docker", "push_image", attribute.String("image.source", sourceImage), attribute.String("image", targetImage))
+	start := time.Now()
+	err := d.tagImage(ctx, sourceImage, targetImage)
+	if err == nil {
+		err = d.pushImage(ctx, targetImage, auth, os.Stdout)
+	}
+	duration := time.Since(start)
+	tracing.End(span, err)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation("push_image", status, duration)
+		d.monitoring.RecordError("docker", "push_image", classifyError(err))
+	}
+
+	d.recordAudit(ctx, "push_image", targetImage, err)
+
+	return err
 }
 
 // PullImageFromRegistry скачивает образ из registry
 func (d *DockerAdapter) PullImageFromRegistry(image string, auth types.AuthConfig) error {
-	if d.registry == nil {
+	registry := d.registryAdapter()
+	if registry == nil {
 		return errors.New("registry не настроен")
 	}
 
 	// Скачиваем образ из registry
-	return d.registry.PullImage(image, auth)
+	err := registry.PullImage(image, auth)
+	d.recordAudit(d.ctx, "pull_image", image, err)
+	return err
+}
+
+// ListRegistryRepositories возвращает список репозиториев настроенного registry
+func (d *DockerAdapter) ListRegistryRepositories() ([]string, error) {
+	registry := d.registryAdapter()
+	if registry == nil {
+		return nil, errors.New("registry не настроен")
+	}
+
+	return registry.ListRepositories()
 }
 
 // TagImage создает новый тег для образа
 func (d *DockerAdapter) TagImage(sourceImage string, targetImage string) error {
-	return d.client.ImageTag(d.ctx, sourceImage, targetImage)
+	return d.tagImage(d.ctx, sourceImage, targetImage)
+}
+
+func (d *DockerAdapter) tagImage(ctx context.Context, sourceImage string, targetImage string) error {
+	err := d.withReconnect(func() error { return d.apiClient().ImageTag(ctx, sourceImage, targetImage) })
+	d.recordAudit(ctx, "tag_image", sourceImage+" -> "+targetImage, err)
+	return err
 }
 
 // GetImageHistory возвращает историю образа
 func (d *DockerAdapter) GetImageHistory(imageID string) ([]image.HistoryResponseItem, error) {
-	history, err := d.client.ImageHistory(d.ctx, imageID)
+	history, err := withReconnectValue(d, func() ([]image.HistoryResponseItem, error) {
+		return d.apiClient().ImageHistory(d.ctx, imageID)
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "ошибка при получении истории образа")
 	}
@@ -451,16 +1096,69 @@ func (d *DockerAdapter) GetImageHistory(imageID string) ([]image.HistoryResponse
 
 // GetImageInspect возвращает детальную информацию об образе
 func (d *DockerAdapter) GetImageInspect(imageID string) (*types.ImageInspect, error) {
-	inspect, _, err := d.client.ImageInspectWithRaw(d.ctx, imageID)
+	inspect, err := withReconnectValue(d, func() (types.ImageInspect, error) {
+		inspect, _, err := d.apiClient().ImageInspectWithRaw(d.ctx, imageID)
+		return inspect, err
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "ошибка при получении информации об образе")
 	}
 	return &inspect, nil
 }
 
+// GetImageSummary возвращает краткую сводку об образе
+func (d *DockerAdapter) GetImageSummary(imageID string) (*ImageSummary, error) {
+	return d.imageSummary(d.ctx, imageID)
+}
+
+func (d *DockerAdapter) imageSummary(ctx context.Context, imageID string) (*ImageSummary, error) {
+	inspect, err := withReconnectValue(d, func() (types.ImageInspect, error) {
+		inspect, _, err := d.apiClient().ImageInspectWithRaw(ctx, imageID)
+		return inspect, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "ошибка при получении информации об образе")
+	}
+
+	created, err := time.Parse(time.RFC3339Nano, inspect.Created)
+	if err != nil {
+		return nil, errors.Wrap(err, "ошибка при парсинге времени создания образа")
+	}
+
+	summary := &ImageSummary{
+		ID:           inspect.ID,
+		RepoTags:     inspect.RepoTags,
+		Size:         inspect.Size,
+		Created:      created,
+		Architecture: inspect.Architecture,
+		OS:           inspect.Os,
+		LayerCount:   len(inspect.RootFS.Layers),
+	}
+
+	// Digest доступен только для образов, скачанных из registry
+	if len(inspect.RepoDigests) > 0 {
+		summary.Digest = inspect.RepoDigests[0]
+	}
+
+	if inspect.Config != nil {
+		summary.Entrypoint = inspect.Config.Entrypoint
+		summary.Cmd = inspect.Config.Cmd
+		summary.Env = inspect.Config.Env
+		for port := range inspect.Config.ExposedPorts {
+			summary.ExposedPorts = append(summary.ExposedPorts, string(port))
+		}
+		sort.Strings(summary.ExposedPorts)
+	}
+
+	return summary, nil
+}
+
 // PruneImages удаляет неиспользуемые образы
 func (d *DockerAdapter) PruneImages() (*types.ImagesPruneReport, error) {
-	report, err := d.client.ImagesPrune(d.ctx, filters.Args{})
+	report, err := withReconnectValue(d, func() (types.ImagesPruneReport, error) {
+		return d.apiClient().ImagesPrune(d.ctx, filters.Args{})
+	})
+	d.recordAudit(d.ctx, "prune_images", "", err)
 	if err != nil {
 		return nil, errors.Wrap(err, "ошибка при очистке образов")
 	}
@@ -469,16 +1167,85 @@ func (d *DockerAdapter) PruneImages() (*types.ImagesPruneReport, error) {
 
 // GetContainerInspect возвращает детальную информацию о контейнере
 func (d *DockerAdapter) GetContainerInspect(containerID string) (*types.ContainerJSON, error) {
-	inspect, err := d.client.ContainerInspect(d.ctx, containerID)
+	inspect, err := withReconnectValue(d, func() (types.ContainerJSON, error) {
+		return d.apiClient().ContainerInspect(d.ctx, containerID)
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "ошибка при получении информации о контейнере")
 	}
 	return &inspect, nil
 }
 
+// GetContainerSummary возвращает краткую сводку о контейнере: окружение,
+// тома, опубликованные порты, сети и политику перезапуска
+func (d *DockerAdapter) GetContainerSummary(containerID string) (*ContainerSummary, error) {
+	return d.containerSummary(d.ctx, containerID)
+}
+
+func (d *DockerAdapter) containerSummary(ctx context.Context, containerID string) (*ContainerSummary, error) {
+	inspect, err := withReconnectValue(d, func() (types.ContainerJSON, error) {
+		return d.apiClient().ContainerInspect(ctx, containerID)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "ошибка при получении информации о контейнере")
+	}
+
+	summary := &ContainerSummary{
+		ID:           inspect.ID,
+		Name:         strings.TrimPrefix(inspect.Name, "/"),
+		Image:        inspect.Image,
+		RestartCount: inspect.RestartCount,
+	}
+
+	if inspect.State != nil {
+		summary.State = inspect.State.Status
+		// Docker возвращает нулевое время для контейнеров, которые не запускались
+		if startedAt, err := time.Parse(time.RFC3339Nano, inspect.State.StartedAt); err == nil && startedAt.Year() > 1 {
+			summary.StartedAt = startedAt
+		}
+	}
+	if inspect.Config != nil {
+		summary.Image = inspect.Config.Image
+		summary.Env = inspect.Config.Env
+		summary.Labels = inspect.Config.Labels
+	}
+	if inspect.HostConfig != nil {
+		summary.RestartPolicy = inspect.HostConfig.RestartPolicy.Name
+	}
+
+	for _, mount := range inspect.Mounts {
+		entry := mount.Source + ":" + mount.Destination
+		if mount.Type == "volume" {
+			entry = mount.Name + ":" + mount.Destination
+		}
+		if !mount.RW {
+			entry += ":ro"
+		}
+		summary.Mounts = append(summary.Mounts, entry)
+	}
+
+	if inspect.NetworkSettings != nil {
+		for port, bindings := range inspect.NetworkSettings.Ports {
+			for _, binding := range bindings {
+				summary.Ports = append(summary.Ports, fmt.Sprintf("%s:%s->%s", binding.HostIP, binding.HostPort, port))
+			}
+		}
+		sort.Strings(summary.Ports)
+
+		for name := range inspect.NetworkSettings.Networks {
+			summary.Networks = append(summary.Networks, name)
+		}
+		sort.Strings(summary.Networks)
+	}
+
+	return summary, nil
+}
+
 // GetContainerProcesses возвращает список процессов в контейнере
 func (d *DockerAdapter) GetContainerProcesses(containerID string) ([][]string, error) {
-	processes, err := d.client.ContainerTop(d.ctx, containerID, nil)
+	processes, err := withReconnectValue(d, func() (container.ContainerTopOKBody, error) {
+		return d.apiClient().ContainerTop(d.ctx, containerID, nil)
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "ошибка при получении списка процессов")
 	}
@@ -487,42 +1254,155 @@ func (d *DockerAdapter) GetContainerProcesses(containerID string) ([][]string, e
 
 // GetContainerChanges возвращает изменения в файловой системе контейнера
 func (d *DockerAdapter) GetContainerChanges(containerID string) ([]container.ContainerChangeResponseItem, error) {
-	changes, err := d.client.ContainerDiff(d.ctx, containerID)
+	changes, err := withReconnectValue(d, func() ([]container.ContainerChangeResponseItem, error) {
+		return d.apiClient().ContainerDiff(d.ctx, containerID)
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "ошибка при получении изменений в контейнере")
 	}
 	return changes, nil
 }
 
+// ContainerChanges изменения в файловой системе контейнера, сгруппированные по виду
+type ContainerChanges struct {
+	Added    []string
+	Modified []string
+	Deleted  []string
+}
+
+// Виды изменений в ответе ContainerDiff
+const (
+	ChangeModified uint8 = 0
+	ChangeAdded    uint8 = 1
+	ChangeDeleted  uint8 = 2
+)
+
+// ChangeKindLetter возвращает букву вида изменения, как в выводе docker diff
+func ChangeKindLetter(kind uint8) string {
+	switch kind {
+	case ChangeAdded:
+		return "A"
+	case ChangeModified:
+		return "C"
+	case ChangeDeleted:
+		return "D"
+	default:
+		return "?"
+	}
+}
+
+// GetContainerChangesByKind возвращает изменения в файловой системе контейнера,
+// сгруппированные на добавленные, измененные и удаленные пути
+func (d *DockerAdapter) GetContainerChangesByKind(containerID string) (*ContainerChanges, error) {
+	changes, err := d.GetContainerChanges(containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := &ContainerChanges{}
+	for _, change := range changes {
+		switch change.Kind {
+		case ChangeAdded:
+			grouped.Added = append(grouped.Added, change.Path)
+		case ChangeModified:
+			grouped.Modified = append(grouped.Modified, change.Path)
+		case ChangeDeleted:
+			grouped.Deleted = append(grouped.Deleted, change.Path)
+		}
+	}
+	return grouped, nil
+}
+
 // PauseContainer приостанавливает контейнер
 func (d *DockerAdapter) PauseContainer(containerID string) error {
-	return d.client.ContainerPause(d.ctx, containerID)
+	err := d.withReconnect(func() error { return d.apiClient().ContainerPause(d.ctx, containerID) })
+	d.recordAudit(d.ctx, "pause_container", containerID, err)
+	return err
 }
 
 // UnpauseContainer возобновляет работу контейнера
 func (d *DockerAdapter) UnpauseContainer(containerID string) error {
-	return d.client.ContainerUnpause(d.ctx, containerID)
+	err := d.withReconnect(func() error { return d.apiClient().ContainerUnpause(d.ctx, containerID) })
+	d.recordAudit(d.ctx, "unpause_container", containerID, err)
+	return err
 }
 
 // RestartContainer перезапускает контейнер
 func (d *DockerAdapter) RestartContainer(containerID string, timeout *time.Duration) error {
-	return d.client.ContainerRestart(d.ctx, containerID, timeout)
+	err := d.withReconnect(func() error { return d.apiClient().ContainerRestart(d.ctx, containerID, timeout) })
+	d.recordAudit(d.ctx, "restart_container", containerID, err)
+	return err
 }
 
 // RenameContainer переименовывает контейнер
 func (d *DockerAdapter) RenameContainer(containerID string, newName string) error {
-	return d.client.ContainerRename(d.ctx, containerID, newName)
+	err := d.withReconnect(func() error { return d.apiClient().ContainerRename(d.ctx, containerID, newName) })
+	d.recordAudit(d.ctx, "rename_container", containerID+" -> "+newName, err)
+	return err
 }
 
 // UpdateContainer обновляет конфигурацию контейнера
 func (d *DockerAdapter) UpdateContainer(containerID string, updateConfig container.UpdateConfig) error {
-	_, err := d.client.ContainerUpdate(d.ctx, containerID, updateConfig)
+	_, err := withReconnectValue(d, func() (container.ContainerUpdateOKBody, error) {
+		return d.apiClient().ContainerUpdate(d.ctx, containerID, updateConfig)
+	})
+	d.recordAudit(d.ctx, "update_container", containerID, err)
 	return err
 }
 
+// UpdateContainerResources меняет лимиты памяти (в байтах) и CPU (в
+// миллиардных долях ядра, 1.5 CPU = 1500000000) работающего контейнера.
+// Нулевое значение оставляет соответствующий лимит без изменений.
+func (d *DockerAdapter) UpdateContainerResources(containerID string, memory int64, nanoCPUs int64) error {
+	ctx, span := tracing.Start(d.ctx, "docker", "update_container", attribute.String("container.id", containerID),
+		attribute.Int64("memory", memory), attribute.Int64("nano_cpus", nanoCPUs))
+	start := time.Now()
+	err := d.updateContainerResources(ctx, containerID, memory, nanoCPUs)
+	duration := time.Since(start)
+	tracing.End(span, err)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation("update_container", status, duration)
+		d.monitoring.RecordError("docker", "update_container", classifyError(err))
+	}
+
+	d.recordAudit(ctx, "update_container", containerID, err)
+
+	return err
+}
+
+func (d *DockerAdapter) updateContainerResources(ctx context.Context, containerID string, memory int64, nanoCPUs int64) error {
+	if memory < 0 || nanoCPUs < 0 {
+		return errors.New("лимиты не могут быть отрицательными")
+	}
+	if memory == 0 && nanoCPUs == 0 {
+		return errors.New("не указан ни один лимит")
+	}
+
+	_, err := withReconnectValue(d, func() (container.ContainerUpdateOKBody, error) {
+		return d.apiClient().ContainerUpdate(ctx, containerID, container.UpdateConfig{
+			Resources: container.Resources{
+				Memory:   memory,
+				NanoCPUs: nanoCPUs,
+			},
+		})
+	})
+	if err != nil {
+		return errors.Wrap(err, "ошибка при изменении лимитов контейнера")
+	}
+	return nil
+}
+
 // ListNetworks возвращает список всех Docker сетей
 func (d *DockerAdapter) ListNetworks() ([]types.NetworkResource, error) {
-	networks, err := d.client.NetworkList(d.ctx, types.NetworkListOptions{})
+	networks, err := withReconnectValue(d, func() ([]types.NetworkResource, error) {
+		return d.apiClient().NetworkList(d.ctx, types.NetworkListOptions{})
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "ошибка при получении списка сетей")
 	}
@@ -531,18 +1411,101 @@ func (d *DockerAdapter) ListNetworks() ([]types.NetworkResource, error) {
 
 // GetSystemInfo возвращает информацию о системе Docker
 func (d *DockerAdapter) GetSystemInfo() (*types.Info, error) {
-	info, err := d.client.Info(d.ctx)
+	info, err := withReconnectValue(d, func() (types.Info, error) { return d.apiClient().Info(d.ctx) })
 	if err != nil {
 		return nil, errors.Wrap(err, "ошибка при получении системной информации")
 	}
 	return &info, nil
 }
 
+// DiskUsageCategory использование диска одной категорией ресурсов Docker
+type DiskUsageCategory struct {
+	// Count всего ресурсов, Active - используемых контейнерами
+	Count       int
+	Active      int
+	Size        int64
+	Reclaimable int64
+}
+
+// DiskUsage использование диска Docker по категориям, как в docker system df
+type DiskUsage struct {
+	Images     DiskUsageCategory
+	Containers DiskUsageCategory
+	Volumes    DiskUsageCategory
+	BuildCache DiskUsageCategory
+}
+
+// GetDiskUsage возвращает объем, занимаемый образами, контейнерами, томами
+// и кешем сборки, и сколько из него можно освободить очисткой
+func (d *DockerAdapter) GetDiskUsage() (*DiskUsage, error) {
+	df, err := withReconnectValue(d, func() (types.DiskUsage, error) { return d.apiClient().DiskUsage(d.ctx) })
+	if err != nil {
+		return nil, errors.Wrap(err, "ошибка при получении использования диска")
+	}
+
+	usage := &DiskUsage{}
+
+	// Слои образов общие, поэтому размер берется из LayersSize, а освободить
+	// можно все, кроме образов запущенных или остановленных контейнеров
+	usage.Images.Size = df.LayersSize
+	var imagesInUse int64
+	for _, img := range df.Images {
+		usage.Images.Count++
+		if img.Containers > 0 {
+			usage.Images.Active++
+			imagesInUse += img.Size
+		}
+	}
+	usage.Images.Reclaimable = max(df.LayersSize-imagesInUse, 0)
+
+	for _, c := range df.Containers {
+		usage.Containers.Count++
+		usage.Containers.Size += c.SizeRw
+		if c.State == "running" {
+			usage.Containers.Active++
+		} else {
+			usage.Containers.Reclaimable += c.SizeRw
+		}
+	}
+
+	for _, v := range df.Volumes {
+		usage.Volumes.Count++
+		// Размер -1 означает, что daemon его не вычислял
+		if v.UsageData == nil || v.UsageData.Size < 0 {
+			continue
+		}
+		usage.Volumes.Size += v.UsageData.Size
+		if v.UsageData.RefCount > 0 {
+			usage.Volumes.Active++
+		} else {
+			usage.Volumes.Reclaimable += v.UsageData.Size
+		}
+	}
+
+	for _, cache := range df.BuildCache {
+		usage.BuildCache.Count++
+		if cache.InUse {
+			usage.BuildCache.Active++
+		}
+		if cache.Shared {
+			continue
+		}
+		usage.BuildCache.Size += cache.Size
+		if !cache.InUse {
+			usage.BuildCache.Reclaimable += cache.Size
+		}
+	}
+
+	return usage, nil
+}
+
 // StartContainer запускает существующий контейнер
 func (d *DockerAdapter) StartContainer(containerID string) error {
+	ctx, span := tracing.Start(d.ctx, "docker", "start_container", attribute.String("container.id", containerID))
 	start := time.Now()
-	err := d.startContainer(containerID)
+	err := d.startContainer(ctx, containerID)
 	duration := time.Since(start)
+	tracing.End(span, err)
 
 	status := "success"
 	if err != nil {
@@ -551,14 +1514,19 @@ func (d *DockerAdapter) StartContainer(containerID string) error {
 
 	if d.monitoring != nil {
 		d.monitoring.RecordDockerOperation("start_container", status, duration)
+		d.monitoring.RecordError("docker", "start_container", classifyError(err))
 	}
 
+	d.recordAudit(ctx, "start_container", containerID, err)
+
 	return err
 }
 
 // GetContainerIDByName возвращает ID контейнера по его имени
 func (d *DockerAdapter) GetContainerIDByName(name string) (string, error) {
-	containers, err := d.client.ContainerList(d.ctx, types.ContainerListOptions{All: true})
+	containers, err := withReconnectValue(d, func() ([]types.Container, error) {
+		return d.apiClient().ContainerList(d.ctx, types.ContainerListOptions{All: true})
+	})
 	if err != nil {
 		return "", errors.Wrap(err, "ошибка при получении списка контейнеров")
 	}
@@ -576,44 +1544,182 @@ func (d *DockerAdapter) GetContainerIDByName(name string) (string, error) {
 }
 
 // buildImage собирает Docker образ
-func (d *DockerAdapter) buildImage(path string, tag string, buildArgs map[string]*string) error {
-	// Формируем команду для сборки
-	args := []string{"build", "-t", tag}
+func (d *DockerAdapter) buildImage(path string, opts BuildOptions) error {
+	// Создаем команду
+	cmd := exec.Command("docker", d.cliArgs(buildCommandArgs(path, opts)...)...)
+	if opts.BuildKit {
+		cmd.Env = append(os.Environ(), "DOCKER_BUILDKIT=1")
+	}
+
+	// Перенаправляем вывод
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Запускаем сборку
+	return cmd.Run()
+}
+
+// cliArgs добавляет к аргументам docker CLI context адаптера, чтобы CLI
+// работал с тем же daemon, что и клиент SDK
+func (d *DockerAdapter) cliArgs(args ...string) []string {
+	if d.contextName == "" {
+		return args
+	}
+	return append([]string{"--context", d.contextName}, args...)
+}
+
+// buildKitInlineCacheArg build-аргумент, с которым BuildKit сохраняет
+// метаданные кеша в самом образе
+const buildKitInlineCacheArg = "BUILDKIT_INLINE_CACHE"
+
+// buildCommandArgs формирует аргументы docker build. Аргументы и метки
+// сортируются по имени, чтобы команда была воспроизводимой.
+func buildCommandArgs(path string, opts BuildOptions) []string {
+	args := []string{"build"}
+
+	// Добавляем теги в порядке передачи
+	for _, tag := range opts.Tags {
+		args = append(args, "-t", tag)
+	}
 
 	// Добавляем build-аргументы
-	for k, v := range buildArgs {
-		if v != nil {
+	for _, k := range sortedKeys(opts.BuildArgs) {
+		if v := opts.BuildArgs[k]; v != nil {
 			args = append(args, "--build-arg", fmt.Sprintf("%s=%s", k, *v))
 		}
 	}
 
-	// Добавляем путь к контексту сборки
-	args = append(args, path)
+	// Добавляем метки образа
+	for _, k := range sortedKeys(opts.Labels) {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", k, opts.Labels[k]))
+	}
 
-	// Создаем команду
-	cmd := exec.Command("docker", args...)
+	// Встраиваем кеш в образ, чтобы BuildKit мог использовать его
+	// в --cache-from следующих сборок
+	if _, ok := opts.BuildArgs[buildKitInlineCacheArg]; opts.BuildKit && !ok {
+		args = append(args, "--build-arg", buildKitInlineCacheArg+"=1")
+	}
 
-	// Перенаправляем вывод
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// Образы-источники кеша
+	for _, image := range opts.CacheFrom {
+		args = append(args, "--cache-from", image)
+	}
 
-	// Запускаем сборку
-	return cmd.Run()
+	// Ограничиваем сборку стадией многоэтапного Dockerfile
+	if opts.Target != "" {
+		args = append(args, "--target", opts.Target)
+	}
+
+	// Добавляем путь к контексту сборки
+	return append(args, path)
+}
+
+// validateTags проверяет, что для сборки указан хотя бы один непустой тег
+func validateTags(tags []string) error {
+	if len(tags) == 0 {
+		return errors.New("не указан тег образа")
+	}
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			return errors.New("пустой тег образа")
+		}
+	}
+	return nil
+}
+
+// sortedKeys возвращает ключи m по возрастанию
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // runContainer создает и запускает контейнер
-func (d *DockerAdapter) runContainer(opts ContainerOptions) (*ContainerInfo, error) {
+func (d *DockerAdapter) runContainer(ctx context.Context, opts ContainerOptions) (*ContainerInfo, error) {
+	containerID, err := d.createContainer(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Запускаем контейнер
+	if err := d.startContainer(ctx, containerID); err != nil {
+		// Если не удалось запустить, удаляем контейнер. С AutoRemove его
+		// удаляет сам daemon.
+		if !opts.AutoRemove {
+			_ = d.withReconnect(func() error {
+				return d.apiClient().ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true})
+			})
+		}
+		return nil, errors.Wrap(err, "ошибка при запуске контейнера")
+	}
+
+	info, err := d.inspectContainerInfo(ctx, containerID)
+	// Короткоживущий контейнер с AutoRemove мог уже завершиться и быть удален
+	if opts.AutoRemove && client.IsErrNotFound(err) {
+		return &ContainerInfo{
+			ID:     containerID,
+			Name:   opts.Name,
+			Image:  opts.Image,
+			Status: "removed",
+			State:  "removed",
+			Labels: opts.Labels,
+		}, nil
+	}
+	return info, err
+}
+
+// createContainer создает контейнер по параметрам и возвращает его ID
+func (d *DockerAdapter) createContainer(ctx context.Context, opts ContainerOptions) (string, error) {
+	if opts.AutoRemove && opts.RestartPolicy.Name != "" && !opts.RestartPolicy.IsNone() {
+		return "", errors.Errorf("автоудаление несовместимо с политикой перезапуска %s", opts.RestartPolicy.Name)
+	}
+
+	if opts.PullIfMissing {
+		if err := d.pullIfMissing(ctx, opts.Image); err != nil {
+			return "", err
+		}
+	}
+
+	// Фиксируем образ по digest, чтобы смена тега не подменила его
+	if opts.ExpectedDigest != "" {
+		pinned, err := d.pinnedImage(ctx, opts.Image, opts.ExpectedDigest)
+		if err != nil {
+			return "", err
+		}
+		opts.Image = pinned
+	}
+
+	// Переменные из EnvFile берутся как есть
+	env := make(map[string]string)
+	if opts.EnvFile != "" {
+		fileEnv, err := readEnvFile(opts.EnvFile)
+		if err != nil {
+			return "", err
+		}
+		env = fileEnv
+	}
+
+	// Явные переменные переопределяют файл, значения подставляются из окружения хоста
+	for k, v := range opts.Environment {
+		value, err := interpolateEnv(v)
+		if err != nil {
+			return "", errors.Wrapf(err, "ошибка в значении переменной %s", k)
+		}
+		env[k] = value
+	}
+
 	// Создаем конфигурацию контейнера
 	config := &container.Config{
 		Image:  opts.Image,
-		Env:    make([]string, 0, len(opts.Environment)),
+		Env:    make([]string, 0, len(env)),
 		Cmd:    opts.Command,
 		Labels: opts.Labels,
 	}
-
-	// Добавляем переменные окружения
-	for k, v := range opts.Environment {
-		config.Env = append(config.Env, fmt.Sprintf("%s=%s", k, v))
+	for _, k := range sortedKeys(env) {
+		config.Env = append(config.Env, fmt.Sprintf("%s=%s", k, env[k]))
 	}
 
 	// Создаем хост-конфигурацию
@@ -621,13 +1727,14 @@ func (d *DockerAdapter) runContainer(opts ContainerOptions) (*ContainerInfo, err
 		PortBindings:  make(map[nat.Port][]nat.PortBinding),
 		Binds:         make([]string, 0, len(opts.Volumes)),
 		RestartPolicy: opts.RestartPolicy,
+		AutoRemove:    opts.AutoRemove,
 	}
 
 	// Настраиваем порты
 	for containerPort, hostPort := range opts.Ports {
 		port, err := nat.NewPort("tcp", containerPort)
 		if err != nil {
-			return nil, errors.Wrap(err, "ошибка при парсинге порта контейнера")
+			return "", errors.Wrap(err, "ошибка при парсинге порта контейнера")
 		}
 		hostConfig.PortBindings[port] = []nat.PortBinding{
 			{HostPort: hostPort},
@@ -639,28 +1746,51 @@ func (d *DockerAdapter) runContainer(opts ContainerOptions) (*ContainerInfo, err
 		hostConfig.Binds = append(hostConfig.Binds, fmt.Sprintf("%s:%s", hostPath, containerPath))
 	}
 
+	// Подключаем контейнер к сети, имя контейнера доступно в ней как DNS-алиас
+	networkingConfig := &network.NetworkingConfig{}
+	if opts.Network != "" {
+		hostConfig.NetworkMode = container.NetworkMode(opts.Network)
+		endpoint := &network.EndpointSettings{}
+		if opts.Name != "" {
+			endpoint.Aliases = []string{opts.Name}
+		}
+		networkingConfig.EndpointsConfig = map[string]*network.EndpointSettings{
+			opts.Network: endpoint,
+		}
+	}
+
 	// Создаем контейнер
-	resp, err := d.client.ContainerCreate(
-		d.ctx,
-		config,
-		hostConfig,
-		&network.NetworkingConfig{},
-		nil,
-		opts.Name,
-	)
+	resp, err := withReconnectValue(d, func() (container.ContainerCreateCreatedBody, error) {
+		return d.apiClient().ContainerCreate(
+			ctx,
+			config,
+			hostConfig,
+			networkingConfig,
+			nil,
+			opts.Name,
+		)
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "ошибка при создании контейнера")
+		return "", errors.Wrap(err, "ошибка при создании контейнера")
 	}
 
-	// Запускаем контейнер
-	if err := d.client.ContainerStart(d.ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
-		// Если не удалось запустить, удаляем контейнер
-		_ = d.client.ContainerRemove(d.ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
-		return nil, errors.Wrap(err, "ошибка при запуске контейнера")
+	return resp.ID, nil
+}
+
+// createContainerInfo создает контейнер и возвращает информацию о нем
+func (d *DockerAdapter) createContainerInfo(ctx context.Context, opts ContainerOptions) (*ContainerInfo, error) {
+	containerID, err := d.createContainer(ctx, opts)
+	if err != nil {
+		return nil, err
 	}
+	return d.inspectContainerInfo(ctx, containerID)
+}
 
-	// Получаем информацию о контейнере
-	container, err := d.client.ContainerInspect(d.ctx, resp.ID)
+// inspectContainerInfo возвращает информацию о контейнере по ID
+func (d *DockerAdapter) inspectContainerInfo(ctx context.Context, containerID string) (*ContainerInfo, error) {
+	container, err := withReconnectValue(d, func() (types.ContainerJSON, error) {
+		return d.apiClient().ContainerInspect(ctx, containerID)
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "ошибка при получении информации о контейнере")
 	}
@@ -682,13 +1812,129 @@ func (d *DockerAdapter) runContainer(opts ContainerOptions) (*ContainerInfo, err
 	}, nil
 }
 
+// pushImage отправляет образ в registry через Docker daemon и выводит прогресс в out
+func (d *DockerAdapter) pushImage(ctx context.Context, image string, auth types.AuthConfig, out io.Writer) error {
+	registryAuth, err := encodeAuthConfig(auth)
+	if err != nil {
+		return err
+	}
+
+	progress, err := withReconnectValue(d, func() (io.ReadCloser, error) {
+		return d.apiClient().ImagePush(ctx, image, types.ImagePushOptions{
+			RegistryAuth: registryAuth,
+		})
+	})
+	if err != nil {
+		return errors.Wrap(err, "ошибка при отправке образа")
+	}
+	defer progress.Close()
+
+	// Ошибки push приходят в потоке прогресса, а не в статусе ответа
+	if err := jsonmessage.DisplayJSONMessagesStream(progress, out, 0, false, nil); err != nil {
+		return errors.Wrap(err, "ошибка при отправке образа")
+	}
+
+	return nil
+}
+
+// pullImage скачивает образ через Docker daemon и выводит прогресс в out
+func (d *DockerAdapter) pullImage(ctx context.Context, image string, auth types.AuthConfig, out io.Writer) error {
+	registryAuth, err := encodeAuthConfig(auth)
+	if err != nil {
+		return err
+	}
+
+	progress, err := withReconnectValue(d, func() (io.ReadCloser, error) {
+		return d.apiClient().ImagePull(ctx, image, types.ImagePullOptions{
+			RegistryAuth: registryAuth,
+		})
+	})
+	if err != nil {
+		return errors.Wrap(err, "ошибка при скачивании образа")
+	}
+	defer progress.Close()
+
+	// Как и при push, ошибки pull приходят в потоке прогресса
+	if err := jsonmessage.DisplayJSONMessagesStream(progress, out, 0, false, nil); err != nil {
+		return errors.Wrap(err, "ошибка при скачивании образа")
+	}
+
+	return nil
+}
+
+// pullIfMissing скачивает образ, если его нет среди локальных. Образ из
+// настроенного registry скачивается с его учетными данными.
+func (d *DockerAdapter) pullIfMissing(ctx context.Context, image string) error {
+	err := d.withReconnect(func() error {
+		_, _, err := d.apiClient().ImageInspectWithRaw(ctx, image)
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+	if !client.IsErrNotFound(err) {
+		return errors.Wrap(err, "ошибка при получении информации об образе")
+	}
+	var auth types.AuthConfig
+	if registry := d.registryAdapter(); registry != nil && registry.Owns(image) {
+		if auth, err = registry.daemonAuth(); err != nil {
+			return err
+		}
+	}
+	err = d.pullImage(ctx, image, auth, io.Discard)
+	d.recordAudit(ctx, "pull_image", image, err)
+	return err
+}
+
+// encodeAuthConfig кодирует данные аутентификации для заголовка X-Registry-Auth
+func encodeAuthConfig(auth types.AuthConfig) (string, error) {
+	data, err := json.Marshal(auth)
+	if err != nil {
+		return "", errors.Wrap(err, "ошибка при кодировании данных аутентификации")
+	}
+	return base64.URLEncoding.EncodeToString(data), nil
+}
+
 // startContainer запускает существующий контейнер
-func (d *DockerAdapter) startContainer(containerID string) error {
-	return d.client.ContainerStart(d.ctx, containerID, types.ContainerStartOptions{})
+func (d *DockerAdapter) startContainer(ctx context.Context, containerID string) error {
+	return d.withReconnect(func() error {
+		return d.apiClient().ContainerStart(ctx, containerID, types.ContainerStartOptions{})
+	})
 }
 
+// defaultStopTimeout время на корректное завершение контейнера
+const defaultStopTimeout = 10 * time.Second
+
 // stopContainer останавливает контейнер
-func (d *DockerAdapter) stopContainer(containerID string) error {
-	timeout := 10 * time.Second
-	return d.client.ContainerStop(d.ctx, containerID, &timeout)
+func (d *DockerAdapter) stopContainer(ctx context.Context, containerID string, signal string, timeout time.Duration) error {
+	// SIGTERM отправляет сам docker stop, он же дожидается завершения
+	switch strings.TrimPrefix(strings.ToUpper(signal), "SIG") {
+	case "", "TERM", "15":
+		return d.withReconnect(func() error { return d.apiClient().ContainerStop(ctx, containerID, &timeout) })
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Подписываемся на завершение до отправки сигнала, чтобы не пропустить выход
+	waitCh, errCh := d.apiClient().ContainerWait(waitCtx, containerID, container.WaitConditionNotRunning)
+
+	if err := d.withReconnect(func() error { return d.apiClient().ContainerKill(ctx, containerID, signal) }); err != nil {
+		return errors.Wrapf(err, "ошибка отправки сигнала %s", signal)
+	}
+
+	select {
+	case <-waitCh:
+		return nil
+	case err := <-errCh:
+		if waitCtx.Err() == nil {
+			return errors.Wrap(err, "ошибка ожидания остановки контейнера")
+		}
+	}
+
+	// Контейнер не завершился за отведенное время
+	if err := d.withReconnect(func() error { return d.apiClient().ContainerKill(ctx, containerID, "SIGKILL") }); err != nil {
+		return errors.Wrap(err, "ошибка принудительной остановки контейнера")
+	}
+	return nil
 }
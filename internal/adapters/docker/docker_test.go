@@ -1,19 +1,35 @@
 package docker
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/localops/devops-manager/internal/audit"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func setupTestServer(t *testing.T, handler http.Handler) (*httptest.Server, *DockerAdapter) {
@@ -34,11 +50,79 @@ func setupTestServer(t *testing.T, handler http.Handler) (*httptest.Server, *Doc
 	return server, adapter
 }
 
-func TestPullImage(t *testing.T) {
+func TestClientAPIVersionNegotiation(t *testing.T) {
+	tests := []struct {
+		name       string
+		envVersion string
+		wantPath   string
+	}{
+		{
+			name:     "версия согласуется с daemon",
+			wantPath: "GET /v1.39/containers/json",
+		},
+		{
+			name:       "версия зафиксирована через DOCKER_API_VERSION",
+			envVersion: "1.41",
+			wantPath:   "GET /v1.41/containers/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DOCKER_API_VERSION", tt.envVersion)
+
+			var mu sync.Mutex
+			var requests []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				mu.Unlock()
+
+				switch {
+				case r.URL.Path == "/_ping":
+					// Старый daemon сообщает о поддержке только API 1.39
+					w.Header().Set("API-Version", "1.39")
+					w.WriteHeader(http.StatusOK)
+				case strings.HasSuffix(r.URL.Path, "/containers/json"):
+					json.NewEncoder(w).Encode([]types.Container{})
+				default:
+					t.Errorf("неожиданный запрос %s", r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			opts := append(clientOptions(),
+				client.WithHost(server.URL),
+				client.WithHTTPClient(server.Client()),
+			)
+			cli, err := client.NewClientWithOpts(opts...)
+			require.NoError(t, err)
+			adapter := &DockerAdapter{client: cli, ctx: context.Background()}
+
+			_, err = adapter.ListContainers()
+			require.NoError(t, err)
+			_, err = adapter.ListContainers()
+			require.NoError(t, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, tt.wantPath, requests[len(requests)-1])
+			assert.Equal(t, tt.wantPath, requests[len(requests)-2])
+			if tt.envVersion != "" {
+				assert.NotContains(t, strings.Join(requests, ","), "/_ping")
+			} else {
+				assert.Contains(t, requests[0], "/_ping")
+			}
+		})
+	}
+}
+
+func TestPullImageViaSDK(t *testing.T) {
 	tests := []struct {
 		name          string
 		image         string
 		serverHandler http.HandlerFunc
+		wantOutput    string
 		wantErr       bool
 	}{
 		{
@@ -47,6 +131,7 @@ func TestPullImage(t *testing.T) {
 			serverHandler: func(w http.ResponseWriter, r *http.Request) {
 				assert.Equal(t, "/v1.41/images/create", r.URL.Path)
 				assert.Equal(t, "test-image", r.URL.Query().Get("fromImage"))
+				assert.Equal(t, "latest", r.URL.Query().Get("tag"))
 
 				// Имитируем успешный ответ Docker API
 				response := map[string]string{
@@ -54,7 +139,24 @@ func TestPullImage(t *testing.T) {
 				}
 				json.NewEncoder(w).Encode(response)
 			},
-			wantErr: false,
+			wantOutput: "Pulling from library/test-image",
+			wantErr:    false,
+		},
+		{
+			name:  "pull образа из приватного registry",
+			image: "registry.local:5000/team/app:1.2",
+			serverHandler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1.41/images/create", r.URL.Path)
+				assert.Equal(t, "registry.local:5000/team/app", r.URL.Query().Get("fromImage"))
+				assert.Equal(t, "1.2", r.URL.Query().Get("tag"))
+				assert.NotEmpty(t, r.Header.Get("X-Registry-Auth"))
+
+				encoder := json.NewEncoder(w)
+				encoder.Encode(map[string]string{"status": "Downloading", "id": "a1b2c3"})
+				encoder.Encode(map[string]string{"status": "Status: Downloaded newer image for registry.local:5000/team/app:1.2"})
+			},
+			wantOutput: "a1b2c3: Downloading",
+			wantErr:    false,
 		},
 		{
 			name:  "ошибка при pull образа",
@@ -68,6 +170,17 @@ func TestPullImage(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:  "ошибка в потоке прогресса",
+			image: "test-image",
+			serverHandler: func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"errorDetail": map[string]string{"message": "manifest unknown"},
+					"error":       "manifest unknown",
+				})
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -75,22 +188,99 @@ func TestPullImage(t *testing.T) {
 			server, adapter := setupTestServer(t, tt.serverHandler)
 			defer server.Close()
 
-			err := adapter.PullImage(tt.image)
+			var out bytes.Buffer
+			err := adapter.PullImageViaSDK(context.Background(), tt.image, types.AuthConfig{Username: "user"}, &out)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Contains(t, out.String(), tt.wantOutput)
 			}
 		})
 	}
 }
 
+func TestBuildCommandArgs(t *testing.T) {
+	version := "1.2"
+	args := buildCommandArgs("/src/app", BuildOptions{
+		Tags:      []string{"app:1.2"},
+		BuildArgs: map[string]*string{"VERSION": &version},
+		Labels: map[string]string{
+			LabelSource:   "https://github.com/localops/app",
+			LabelRevision: "3f78685",
+		},
+	})
+
+	assert.Equal(t, []string{
+		"build", "-t", "app:1.2",
+		"--build-arg", "VERSION=1.2",
+		"--label", "org.opencontainers.image.revision=3f78685",
+		"--label", "org.opencontainers.image.source=https://github.com/localops/app",
+		"/src/app",
+	}, args)
+}
+
+func TestBuildCommandArgsMultipleTags(t *testing.T) {
+	args := buildCommandArgs("/src/app", BuildOptions{Tags: []string{"app:latest", "app:3f78685"}})
+
+	assert.Equal(t, []string{
+		"build", "-t", "app:latest", "-t", "app:3f78685",
+		"/src/app",
+	}, args)
+}
+
+func TestBuildCommandArgsTarget(t *testing.T) {
+	args := buildCommandArgs("/src/app", BuildOptions{Tags: []string{"app:test"}, Target: "test"})
+
+	assert.Equal(t, []string{"build", "-t", "app:test", "--target", "test", "/src/app"}, args)
+}
+
+func TestBuildCommandArgsCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		buildKit bool
+		want     []string
+	}{
+		{
+			name: "классический сборщик",
+			want: []string{"build", "-t", "app:2", "--cache-from", "registry.local/app:1", "/src/app"},
+		},
+		{
+			name:     "BuildKit",
+			buildKit: true,
+			want: []string{
+				"build", "-t", "app:2",
+				"--build-arg", "BUILDKIT_INLINE_CACHE=1",
+				"--cache-from", "registry.local/app:1",
+				"/src/app",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := buildCommandArgs("/src/app", BuildOptions{
+				Tags:      []string{"app:2"},
+				CacheFrom: []string{"registry.local/app:1"},
+				BuildKit:  tt.buildKit,
+			})
+			assert.Equal(t, tt.want, args)
+		})
+	}
+}
+
+func TestBuildImageMultiTagValidation(t *testing.T) {
+	_, adapter := newFakeDockerServer(t)
+
+	assert.Error(t, adapter.BuildImageMultiTag("/src/app", nil, nil, nil))
+	assert.Error(t, adapter.BuildImageMultiTag("/src/app", []string{"app:latest", " "}, nil, nil))
+}
+
 func TestRunContainer(t *testing.T) {
 	tests := []struct {
-		name          string
-		opts          ContainerOptions
-		serverHandler http.HandlerFunc
-		wantErr       bool
+		name    string
+		opts    ContainerOptions
+		wantErr bool
 	}{
 		{
 			name: "успешное создание контейнера",
@@ -104,32 +294,6 @@ func TestRunContainer(t *testing.T) {
 					"ENV_VAR": "value",
 				},
 			},
-			serverHandler: func(w http.ResponseWriter, r *http.Request) {
-				switch r.URL.Path {
-				case "/v1.41/containers/create":
-					response := container.ContainerCreateCreatedBody{
-						ID: "test-container-id",
-					}
-					json.NewEncoder(w).Encode(response)
-				case "/v1.41/containers/test-container-id/start":
-					w.WriteHeader(http.StatusNoContent)
-				case "/v1.41/containers/test-container-id/json":
-					response := types.ContainerJSON{
-						ContainerJSONBase: &types.ContainerJSONBase{
-							ID:      "test-container-id",
-							Name:    "/test-container",
-							Created: strconv.FormatInt(time.Now().Unix(), 10),
-							State: &types.ContainerState{
-								Status: "running",
-							},
-						},
-						Config: &container.Config{
-							Image: "test-image",
-						},
-					}
-					json.NewEncoder(w).Encode(response)
-				}
-			},
 			wantErr: false,
 		},
 		{
@@ -138,21 +302,14 @@ func TestRunContainer(t *testing.T) {
 				Image: "invalid-image",
 				Name:  "test-container",
 			},
-			serverHandler: func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(http.StatusInternalServerError)
-				response := map[string]string{
-					"message": "No such image: invalid-image",
-				}
-				json.NewEncoder(w).Encode(response)
-			},
 			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server, adapter := setupTestServer(t, tt.serverHandler)
-			defer server.Close()
+			fake, adapter := newFakeDockerServer(t)
+			fake.addImage("test-image", fakeImage{})
 
 			info, err := adapter.RunContainer(tt.opts)
 			if tt.wantErr {
@@ -163,49 +320,190 @@ func TestRunContainer(t *testing.T) {
 				assert.NotNil(t, info)
 				assert.Equal(t, "/test-container", info.Name)
 				assert.Equal(t, tt.opts.Image, info.Image)
+				assert.Equal(t, "running", fake.container("test-container").State)
 			}
 		})
 	}
 }
 
+func TestCreateContainer(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	fake.addImage("test-image", fakeImage{})
+
+	info, err := adapter.CreateContainer(ContainerOptions{Image: "test-image", Name: "worker"})
+	require.NoError(t, err)
+	assert.Equal(t, "worker-id", info.ID)
+	assert.Equal(t, "created", info.State)
+	assert.Equal(t, "created", fake.container("worker").State)
+
+	for _, req := range fake.requested() {
+		assert.NotContains(t, req, "/start")
+	}
+}
+
+func TestRunContainerPullIfMissing(t *testing.T) {
+	t.Run("отсутствующий образ скачивается перед созданием", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+
+		info, err := adapter.RunContainer(ContainerOptions{Image: "app:1.0", Name: "app", PullIfMissing: true})
+		require.NoError(t, err)
+		assert.Equal(t, "app-id", info.ID)
+		assert.True(t, fake.hasImage("app:1.0"))
+
+		requests := fake.requested()
+		pull := slices.Index(requests, "POST /images/create")
+		create := slices.Index(requests, "POST /containers/create")
+		require.NotEqual(t, -1, pull)
+		assert.Less(t, pull, create)
+	})
+
+	t.Run("локальный образ не скачивается", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+		fake.addImage("app:1.0", fakeImage{})
+
+		_, err := adapter.RunContainer(ContainerOptions{Image: "app:1.0", Name: "app", PullIfMissing: true})
+		require.NoError(t, err)
+		assert.NotContains(t, fake.requested(), "POST /images/create")
+	})
+
+	t.Run("образ из registry скачивается с его учетными данными", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+		adapter.SetRegistry(&RegistryConfig{URL: "https://registry.example.com", Username: "ci", Password: "secret"})
+
+		auths := make(map[string]types.AuthConfig)
+		fake.handle(http.MethodPost, "/images/create", func(w http.ResponseWriter, r *http.Request) {
+			data, err := base64.URLEncoding.DecodeString(r.Header.Get("X-Registry-Auth"))
+			require.NoError(t, err)
+			var auth types.AuthConfig
+			require.NoError(t, json.Unmarshal(data, &auth))
+			ref := r.URL.Query().Get("fromImage") + ":" + r.URL.Query().Get("tag")
+			auths[ref] = auth
+			fake.addImage(ref, fakeImage{})
+			json.NewEncoder(w).Encode(map[string]string{"status": "Downloaded newer image for " + ref})
+		})
+
+		_, err := adapter.RunContainer(ContainerOptions{Image: "registry.example.com/team/app:1.0", Name: "app", PullIfMissing: true})
+		require.NoError(t, err)
+		_, err = adapter.RunContainer(ContainerOptions{Image: "nginx:1.25", Name: "web", PullIfMissing: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, types.AuthConfig{Username: "ci", Password: "secret", ServerAddress: "registry.example.com"}, auths["registry.example.com/team/app:1.0"])
+		// Учетные данные registry не отправляются в чужой registry
+		assert.Equal(t, types.AuthConfig{}, auths["nginx:1.25"])
+	})
+
+	t.Run("без опции образ не скачивается", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+
+		_, err := adapter.RunContainer(ContainerOptions{Image: "app:1.0", Name: "app"})
+		require.Error(t, err)
+		assert.NotContains(t, fake.requested(), "POST /images/create")
+	})
+}
+
+func TestRunContainerAutoRemove(t *testing.T) {
+	t.Run("AutoRemove передается в HostConfig", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+		fake.addImage("test-image", fakeImage{})
+
+		_, err := adapter.RunContainer(ContainerOptions{Image: "test-image", Name: "job", AutoRemove: true})
+		require.NoError(t, err)
+		assert.True(t, fake.container("job").HostConfig.AutoRemove)
+	})
+
+	t.Run("контейнер уже удален после завершения", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+		fake.addImage("test-image", fakeImage{})
+		fake.failOn(http.MethodGet, "/containers/job-id/json", http.StatusNotFound, "No such container: job-id")
+
+		info, err := adapter.RunContainer(ContainerOptions{Image: "test-image", Name: "job", AutoRemove: true})
+		require.NoError(t, err)
+		assert.Equal(t, "job-id", info.ID)
+		assert.Equal(t, "removed", info.State)
+	})
+
+	t.Run("без повторного удаления при ошибке запуска", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+		fake.addImage("test-image", fakeImage{})
+		fake.failOn(http.MethodPost, "/containers/job-id/start", http.StatusInternalServerError, "start failed")
+
+		_, err := adapter.RunContainer(ContainerOptions{Image: "test-image", Name: "job", AutoRemove: true})
+		require.Error(t, err)
+		for _, req := range fake.requested() {
+			assert.NotContains(t, req, "DELETE")
+		}
+	})
+
+	t.Run("конфликт с политикой перезапуска", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+		fake.addImage("test-image", fakeImage{})
+
+		_, err := adapter.RunContainer(ContainerOptions{
+			Image:         "test-image",
+			AutoRemove:    true,
+			RestartPolicy: container.RestartPolicy{Name: "always"},
+		})
+		require.Error(t, err)
+		assert.Empty(t, fake.requested())
+	})
+}
+
+func TestUpdateContainerResources(t *testing.T) {
+	tests := []struct {
+		name       string
+		memory     int64
+		nanoCPUs   int64
+		wantErr    bool
+		wantMemory int64
+	}{
+		{name: "лимит памяти", memory: 512 * 1024 * 1024, wantMemory: 512 * 1024 * 1024},
+		{name: "лимит CPU", nanoCPUs: 1500000000},
+		{name: "без лимитов", wantErr: true},
+		{name: "отрицательный лимит", memory: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, adapter := newFakeDockerServer(t)
+			fake.addContainer(fakeContainer{Name: "web"})
+
+			err := adapter.UpdateContainerResources("web-id", tt.memory, tt.nanoCPUs)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Empty(t, fake.requested())
+				return
+			}
+
+			require.NoError(t, err)
+			resources := fake.container("web").Resources
+			assert.Equal(t, tt.wantMemory, resources.Memory)
+			assert.Equal(t, tt.nanoCPUs, resources.NanoCPUs)
+		})
+	}
+}
+
 func TestStopAndRemoveContainer(t *testing.T) {
 	tests := []struct {
-		name          string
-		containerID   string
-		serverHandler http.HandlerFunc
-		wantErr       bool
+		name        string
+		containerID string
+		wantErr     bool
 	}{
 		{
 			name:        "успешная остановка и удаление контейнера",
 			containerID: "test-container-id",
-			serverHandler: func(w http.ResponseWriter, r *http.Request) {
-				switch r.URL.Path {
-				case "/v1.41/containers/test-container-id/stop":
-					w.WriteHeader(http.StatusNoContent)
-				case "/v1.41/containers/test-container-id":
-					w.WriteHeader(http.StatusNoContent)
-				}
-			},
-			wantErr: false,
+			wantErr:     false,
 		},
 		{
 			name:        "ошибка при остановке несуществующего контейнера",
 			containerID: "non-existent",
-			serverHandler: func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(http.StatusNotFound)
-				response := map[string]string{
-					"message": "No such container: non-existent",
-				}
-				json.NewEncoder(w).Encode(response)
-			},
-			wantErr: true,
+			wantErr:     true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server, adapter := setupTestServer(t, tt.serverHandler)
-			defer server.Close()
+			fake, adapter := newFakeDockerServer(t)
+			fake.addContainer(fakeContainer{ID: "test-container-id", Name: "test-container"})
 
 			// Тестируем остановку контейнера
 			err := adapter.StopContainer(tt.containerID)
@@ -213,6 +511,7 @@ func TestStopAndRemoveContainer(t *testing.T) {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, "exited", fake.container(tt.containerID).State)
 			}
 
 			// Тестируем удаление контейнера
@@ -221,56 +520,313 @@ func TestStopAndRemoveContainer(t *testing.T) {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Nil(t, fake.container(tt.containerID))
 			}
 		})
 	}
 }
 
+func TestRemoveContainerAudit(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	fake.addContainer(fakeContainer{ID: "web-id", Name: "web"})
+
+	var buf bytes.Buffer
+	adapter.SetAuditLogger(audit.NewJSONLogger(&buf))
+	adapter.ctx = audit.WithActor(context.Background(), "alice")
+
+	require.NoError(t, adapter.RemoveContainer("web-id"))
+	require.Error(t, adapter.RemoveContainer("missing"))
+	// Чтение не изменяет состояние и в журнал не попадает
+	_, err := adapter.ListContainers()
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var entry audit.Entry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "docker", entry.Component)
+	assert.Equal(t, "remove_container", entry.Operation)
+	assert.Equal(t, "web-id", entry.Target)
+	assert.Equal(t, "alice", entry.Actor)
+	assert.Equal(t, "success", entry.Result)
+	assert.Empty(t, entry.Error)
+	assert.WithinDuration(t, time.Now(), entry.Time, time.Minute)
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "missing", entry.Target)
+	assert.Equal(t, "error", entry.Result)
+	assert.NotEmpty(t, entry.Error)
+}
+
+func TestStopContainerWithOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		signal    string
+		timeout   time.Duration
+		wantPaths []string
+		wantQuery map[string]string
+	}{
+		{
+			name:      "SIGTERM через docker stop с таймаутом",
+			signal:    "",
+			timeout:   30 * time.Second,
+			wantPaths: []string{"/v1.41/containers/test-container/stop"},
+			wantQuery: map[string]string{"t": "30"},
+		},
+		{
+			name:      "явный SIGTERM",
+			signal:    "sigterm",
+			timeout:   5 * time.Second,
+			wantPaths: []string{"/v1.41/containers/test-container/stop"},
+			wantQuery: map[string]string{"t": "5"},
+		},
+		{
+			name:    "пользовательский сигнал",
+			signal:  "SIGQUIT",
+			timeout: 5 * time.Second,
+			wantPaths: []string{
+				"/v1.41/containers/test-container/wait",
+				"/v1.41/containers/test-container/kill",
+			},
+			wantQuery: map[string]string{"signal": "SIGQUIT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var paths []string
+			killed := make(chan struct{})
+
+			server, adapter := setupTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				paths = append(paths, r.URL.Path)
+				mu.Unlock()
+
+				switch r.URL.Path {
+				case "/v1.41/containers/test-container/stop":
+					for key, value := range tt.wantQuery {
+						assert.Equal(t, value, r.URL.Query().Get(key))
+					}
+					w.WriteHeader(http.StatusNoContent)
+				case "/v1.41/containers/test-container/kill":
+					for key, value := range tt.wantQuery {
+						assert.Equal(t, value, r.URL.Query().Get(key))
+					}
+					w.WriteHeader(http.StatusNoContent)
+					close(killed)
+				case "/v1.41/containers/test-container/wait":
+					// Docker сразу отдает заголовки, а тело - после завершения контейнера
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusOK)
+					w.(http.Flusher).Flush()
+					<-killed
+					json.NewEncoder(w).Encode(container.ContainerWaitOKBody{StatusCode: 0})
+				default:
+					t.Errorf("неожиданный запрос %s", r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			err := adapter.StopContainerWithOptions("test-container", tt.signal, tt.timeout)
+			require.NoError(t, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			assert.ElementsMatch(t, tt.wantPaths, paths)
+		})
+	}
+}
+
 func TestListContainers(t *testing.T) {
+	t.Run("успешное получение списка контейнеров", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+		fake.addContainer(fakeContainer{Name: "container1", Image: "image1", State: "running"})
+		fake.addContainer(fakeContainer{Name: "container2", Image: "image2", State: "exited"})
+
+		containers, err := adapter.ListContainers()
+		assert.NoError(t, err)
+		assert.Len(t, containers, 2)
+	})
+
+	t.Run("ошибка при получении списка контейнеров", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+		fake.failOn(http.MethodGet, "/containers/json", http.StatusInternalServerError, "Internal server error")
+
+		containers, err := adapter.ListContainers()
+		assert.Error(t, err)
+		assert.Nil(t, containers)
+	})
+}
+
+func TestListContainersTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	fake, adapter := newFakeDockerServer(t)
+	fake.addContainer(fakeContainer{Name: "web"})
+
+	_, err := adapter.ListContainers()
+	require.NoError(t, err)
+
+	fake.failOn(http.MethodGet, "/containers/json", http.StatusInternalServerError, "Internal server error")
+	_, err = adapter.ListContainers()
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		assert.Equal(t, "docker.list_containers", span.Name)
+		assert.Contains(t, span.Attributes, attribute.String("operation", "list_containers"))
+	}
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+}
+
+// spanRecordingTransport запоминает спан из контекста каждого запроса к daemon
+type spanRecordingTransport struct {
+	next  http.RoundTripper
+	mu    sync.Mutex
+	spans []trace.SpanContext
+}
+
+func (t *spanRecordingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.spans = append(t.spans, trace.SpanContextFromContext(r.Context()))
+	t.mu.Unlock()
+	return t.next.RoundTrip(r)
+}
+
+func TestRunContainerTracingContext(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	fake, served := newFakeDockerServer(t)
+	fake.addImage("nginx:latest", fakeImage{})
+
+	transport := &spanRecordingTransport{next: http.DefaultTransport}
+	cli, err := client.NewClientWithOpts(
+		client.WithHost(served.client.DaemonHost()),
+		client.WithHTTPClient(&http.Client{Transport: transport}),
+		client.WithVersion("1.41"),
+	)
+	require.NoError(t, err)
+	adapter := &DockerAdapter{client: cli, ctx: context.Background()}
+
+	_, err = adapter.RunContainer(ContainerOptions{Name: "web", Image: "nginx:latest"})
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	// Запросы create, start и inspect выполняются в контексте спана операции
+	require.Len(t, transport.spans, 3)
+	for _, spanContext := range transport.spans {
+		assert.Equal(t, spans[0].SpanContext.SpanID(), spanContext.SpanID())
+	}
+}
+
+func TestGetImageSummary(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("успешное получение сводки об образе", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+		fake.addImage("test-image:latest", fakeImage{
+			ID:           "sha256:abc",
+			Digests:      []string{"test-image@sha256:def"},
+			Size:         1024,
+			Created:      created,
+			Architecture: "amd64",
+			OS:           "linux",
+			Config: &container.Config{
+				Entrypoint: []string{"/app"},
+				Cmd:        []string{"--serve"},
+				Env:        []string{"PATH=/usr/bin"},
+				ExposedPorts: nat.PortSet{
+					"8080/tcp": struct{}{},
+					"443/tcp":  struct{}{},
+				},
+			},
+			History: make([]image.HistoryResponseItem, 3),
+		})
+
+		summary, err := adapter.GetImageSummary("test-image:latest")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"test-image:latest"}, summary.RepoTags)
+		assert.Equal(t, "test-image@sha256:def", summary.Digest)
+		assert.Equal(t, int64(1024), summary.Size)
+		assert.True(t, created.Equal(summary.Created))
+		assert.Equal(t, "amd64", summary.Architecture)
+		assert.Equal(t, "linux", summary.OS)
+		assert.Equal(t, []string{"/app"}, summary.Entrypoint)
+		assert.Equal(t, []string{"--serve"}, summary.Cmd)
+		assert.Equal(t, []string{"443/tcp", "8080/tcp"}, summary.ExposedPorts)
+		assert.Equal(t, []string{"PATH=/usr/bin"}, summary.Env)
+		assert.Equal(t, 3, summary.LayerCount)
+	})
+
+	t.Run("образ не найден", func(t *testing.T) {
+		_, adapter := newFakeDockerServer(t)
+
+		summary, err := adapter.GetImageSummary("test-image:latest")
+		assert.Error(t, err)
+		assert.Nil(t, summary)
+	})
+}
+
+func TestTagAndPush(t *testing.T) {
+	auth := types.AuthConfig{Username: "user", Password: "secret"}
+
 	tests := []struct {
 		name          string
 		serverHandler http.HandlerFunc
-		wantCount     int
 		wantErr       bool
 	}{
 		{
-			name: "успешное получение списка контейнеров",
+			name: "успешный tag и push",
 			serverHandler: func(w http.ResponseWriter, r *http.Request) {
-				assert.Equal(t, "/v1.41/containers/json", r.URL.Path)
-				containers := []types.Container{
-					{
-						ID:      "container1",
-						Names:   []string{"/container1"},
-						Image:   "image1",
-						Status:  "running",
-						Created: time.Now().Unix(),
-						State:   "running",
-					},
-					{
-						ID:      "container2",
-						Names:   []string{"/container2"},
-						Image:   "image2",
-						Status:  "exited",
-						Created: time.Now().Unix(),
-						State:   "exited",
-					},
+				switch r.URL.Path {
+				case "/v1.41/images/app:latest/tag":
+					assert.Equal(t, "registry.local/app", r.URL.Query().Get("repo"))
+					assert.Equal(t, "v1", r.URL.Query().Get("tag"))
+					w.WriteHeader(http.StatusCreated)
+				case "/v1.41/images/registry.local/app/push":
+					assert.Equal(t, "v1", r.URL.Query().Get("tag"))
+
+					header := r.Header.Get("X-Registry-Auth")
+					decoded, err := base64.URLEncoding.DecodeString(header)
+					require.NoError(t, err)
+					var got types.AuthConfig
+					require.NoError(t, json.Unmarshal(decoded, &got))
+					assert.Equal(t, auth.Username, got.Username)
+					assert.Equal(t, auth.Password, got.Password)
+
+					json.NewEncoder(w).Encode(map[string]string{"status": "Pushed"})
+				default:
+					t.Errorf("неожиданный запрос %s", r.URL.Path)
 				}
-				json.NewEncoder(w).Encode(containers)
 			},
-			wantCount: 2,
-			wantErr:   false,
+			wantErr: false,
 		},
 		{
-			name: "ошибка при получении списка контейнеров",
+			name: "ошибка в потоке прогресса",
 			serverHandler: func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(http.StatusInternalServerError)
-				response := map[string]string{
-					"message": "Internal server error",
+				if r.URL.Path == "/v1.41/images/app:latest/tag" {
+					w.WriteHeader(http.StatusCreated)
+					return
 				}
-				json.NewEncoder(w).Encode(response)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"errorDetail": map[string]string{"message": "denied: access forbidden"},
+					"error":       "denied: access forbidden",
+				})
 			},
-			wantCount: 0,
-			wantErr:   true,
+			wantErr: true,
 		},
 	}
 
@@ -279,14 +835,274 @@ func TestListContainers(t *testing.T) {
 			server, adapter := setupTestServer(t, tt.serverHandler)
 			defer server.Close()
 
-			containers, err := adapter.ListContainers()
+			err := adapter.TagAndPush("app:latest", "registry.local/app:v1", auth)
 			if tt.wantErr {
 				assert.Error(t, err)
-				assert.Nil(t, containers)
 			} else {
 				assert.NoError(t, err)
-				assert.Len(t, containers, tt.wantCount)
 			}
 		})
 	}
 }
+
+func TestPushImageToRegistry(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	fake.addImage("app:v1", fakeImage{})
+
+	pushed := false
+	fake.handle(http.MethodPost, "/images/registry.local:5000/app/push", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "v1", r.URL.Query().Get("tag"))
+		assert.NotEmpty(t, r.Header.Get("X-Registry-Auth"))
+		pushed = true
+		json.NewEncoder(w).Encode(map[string]string{"status": "Pushed"})
+	})
+
+	adapter.registry = NewRegistryAdapter(RegistryConfig{URL: "https://registry.local:5000"})
+
+	err := adapter.PushImageToRegistry("app:v1", types.AuthConfig{Username: "user", Password: "secret"})
+	require.NoError(t, err)
+	assert.True(t, fake.hasImage("registry.local:5000/app:v1"), "ожидалась установка тега с адресом registry")
+	assert.True(t, pushed, "ожидался вызов push")
+}
+
+func TestPushImageToRegistryWithoutRegistry(t *testing.T) {
+	server, adapter := setupTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("неожиданный запрос %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	err := adapter.PushImageToRegistry("app:v1", types.AuthConfig{})
+	assert.Error(t, err)
+}
+
+func TestRemoveNetwork(t *testing.T) {
+	tests := []struct {
+		name        string
+		disconnect  bool
+		wantErr     string
+		wantRemoved bool
+	}{
+		{
+			name:    "сеть занята контейнерами",
+			wantErr: "к сети backend подключены контейнеры: api, db",
+		},
+		{
+			name:        "отключение контейнеров перед удалением",
+			disconnect:  true,
+			wantRemoved: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, adapter := newFakeDockerServer(t)
+			fake.addContainer(fakeContainer{ID: "c1", Name: "api"})
+			fake.addContainer(fakeContainer{ID: "c2", Name: "db"})
+			fake.addNetwork(fakeNetwork{ID: "net123", Name: "backend", Containers: []string{"c1", "c2"}})
+
+			err := adapter.RemoveNetwork("backend", tt.disconnect)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.NotContains(t, fake.requested(), "POST /networks/net123/disconnect")
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantRemoved, fake.network("backend") == nil)
+		})
+	}
+}
+
+func TestPruneStoppedContainers(t *testing.T) {
+	server, adapter := setupTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1.41/containers/prune", r.URL.Path)
+
+		args, err := filters.FromJSON(r.URL.Query().Get("filters"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2h0m0s"}, args.Get("until"))
+
+		json.NewEncoder(w).Encode(types.ContainersPruneReport{
+			ContainersDeleted: []string{"old1", "old2"},
+			SpaceReclaimed:    2048,
+		})
+	}))
+	defer server.Close()
+
+	removed, reclaimed, err := adapter.PruneStoppedContainers(2 * time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old1", "old2"}, removed)
+	assert.Equal(t, int64(2048), reclaimed)
+}
+
+func TestPruneImagesKeepLatest(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, tag := range []string{"1.0", "1.1", "1.2", "1.3"} {
+		fake.addImage("registry.local:5000/app:"+tag, fakeImage{
+			ID:      "sha256:app" + tag,
+			Size:    100,
+			Created: base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	fake.addImage("db:15", fakeImage{Size: 500, Created: base})
+
+	removed, reclaimed, err := adapter.PruneImagesKeepLatest(2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"registry.local:5000/app:1.1", "registry.local:5000/app:1.0"}, removed)
+	assert.Equal(t, int64(200), reclaimed)
+
+	assert.True(t, fake.hasImage("registry.local:5000/app:1.3"))
+	assert.True(t, fake.hasImage("registry.local:5000/app:1.2"))
+	assert.False(t, fake.hasImage("registry.local:5000/app:1.1"))
+	assert.False(t, fake.hasImage("registry.local:5000/app:1.0"))
+	assert.True(t, fake.hasImage("db:15"))
+}
+
+func TestPruneImagesKeepLatestSkipsInUse(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, tag := range []string{"1.0", "1.1", "1.2"} {
+		fake.addImage("app:"+tag, fakeImage{Size: 100, Created: base.Add(time.Duration(i) * time.Hour)})
+	}
+	fake.addContainer(fakeContainer{Name: "legacy", Image: "app:1.0", State: "exited"})
+
+	removed, reclaimed, err := adapter.PruneImagesKeepLatest(1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app:1.1"}, removed)
+	assert.Equal(t, int64(100), reclaimed)
+	assert.True(t, fake.hasImage("app:1.0"))
+}
+
+func TestGetContainerChangesByKind(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	fake.handle(http.MethodGet, "/containers/web/changes", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[
+			{"Path": "/etc", "Kind": 0},
+			{"Path": "/etc/nginx/conf.d/app.conf", "Kind": 1},
+			{"Path": "/var/cache/nginx", "Kind": 1},
+			{"Path": "/etc/nginx/conf.d/default.conf", "Kind": 2}
+		]`)
+	})
+
+	changes, err := adapter.GetContainerChangesByKind("web")
+	require.NoError(t, err)
+	assert.Equal(t, &ContainerChanges{
+		Added:    []string{"/etc/nginx/conf.d/app.conf", "/var/cache/nginx"},
+		Modified: []string{"/etc"},
+		Deleted:  []string{"/etc/nginx/conf.d/default.conf"},
+	}, changes)
+
+	assert.Equal(t, "A", ChangeKindLetter(ChangeAdded))
+	assert.Equal(t, "C", ChangeKindLetter(ChangeModified))
+	assert.Equal(t, "D", ChangeKindLetter(ChangeDeleted))
+}
+
+func TestGetContainerSummary(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	fake.handle(http.MethodGet, "/containers/web/json", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{
+			"Id": "web-id",
+			"Name": "/web",
+			"Image": "sha256:abc",
+			"RestartCount": 3,
+			"State": {"Status": "running", "Running": true, "StartedAt": "2024-05-01T12:00:00.5Z"},
+			"HostConfig": {"RestartPolicy": {"Name": "unless-stopped"}},
+			"Config": {
+				"Image": "nginx:1.25",
+				"Env": ["PATH=/usr/bin", "MODE=prod"],
+				"Labels": {"app": "web"}
+			},
+			"Mounts": [
+				{"Type": "bind", "Source": "/srv/conf", "Destination": "/etc/nginx/conf.d", "RW": false},
+				{"Type": "volume", "Name": "web-cache", "Source": "/var/lib/docker/volumes/web-cache/_data", "Destination": "/var/cache/nginx", "RW": true}
+			],
+			"NetworkSettings": {
+				"Ports": {
+					"80/tcp": [{"HostIp": "0.0.0.0", "HostPort": "8080"}],
+					"443/tcp": null
+				},
+				"Networks": {"frontend": {}, "bridge": {}}
+			}
+		}`)
+	})
+
+	summary, err := adapter.GetContainerSummary("web")
+	require.NoError(t, err)
+	assert.Equal(t, "web-id", summary.ID)
+	assert.Equal(t, "web", summary.Name)
+	assert.Equal(t, "nginx:1.25", summary.Image)
+	assert.Equal(t, "running", summary.State)
+	assert.True(t, time.Date(2024, 5, 1, 12, 0, 0, 5e8, time.UTC).Equal(summary.StartedAt))
+	assert.Equal(t, 3, summary.RestartCount)
+	assert.Equal(t, "unless-stopped", summary.RestartPolicy)
+	assert.Equal(t, []string{"PATH=/usr/bin", "MODE=prod"}, summary.Env)
+	assert.Equal(t, map[string]string{"app": "web"}, summary.Labels)
+	assert.Equal(t, []string{"/srv/conf:/etc/nginx/conf.d:ro", "web-cache:/var/cache/nginx"}, summary.Mounts)
+	assert.Equal(t, []string{"0.0.0.0:8080->80/tcp"}, summary.Ports)
+	assert.Equal(t, []string{"bridge", "frontend"}, summary.Networks)
+}
+
+func TestGetContainerLogsOptions(t *testing.T) {
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		since     time.Time
+		tail      string
+		wantSince string
+		wantTail  string
+	}{
+		{name: "с момента времени", since: since, tail: "100", wantSince: strconv.FormatInt(since.Unix(), 10) + ".000000000", wantTail: "100"},
+		{name: "все логи", tail: "all", wantTail: "all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, adapter := newFakeDockerServer(t)
+			fake.handle(http.MethodGet, "/containers/web/logs", func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.wantSince, r.URL.Query().Get("since"))
+				assert.Equal(t, tt.wantTail, r.URL.Query().Get("tail"))
+				io.WriteString(w, "")
+			})
+
+			logs, err := adapter.GetContainerLogs("web", tt.since, tt.tail)
+			require.NoError(t, err)
+			logs.Close()
+		})
+	}
+}
+
+func TestGetDiskUsage(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	fake.handle(http.MethodGet, "/system/df", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{
+			"LayersSize": 1000,
+			"Images": [
+				{"Id": "sha256:web", "Size": 600, "SharedSize": 100, "Containers": 1},
+				{"Id": "sha256:old", "Size": 300, "SharedSize": 100, "Containers": 0},
+				{"Id": "sha256:tmp", "Size": 100, "SharedSize": 0, "Containers": 0}
+			],
+			"Containers": [
+				{"Id": "web", "State": "running", "SizeRw": 40},
+				{"Id": "job", "State": "exited", "SizeRw": 15}
+			],
+			"Volumes": [
+				{"Name": "data", "UsageData": {"Size": 500, "RefCount": 1}},
+				{"Name": "orphan", "UsageData": {"Size": 70, "RefCount": 0}},
+				{"Name": "remote", "UsageData": {"Size": -1, "RefCount": 0}}
+			],
+			"BuildCache": [
+				{"ID": "a", "Size": 200, "InUse": true},
+				{"ID": "b", "Size": 80},
+				{"ID": "c", "Size": 50, "Shared": true}
+			]
+		}`)
+	})
+
+	usage, err := adapter.GetDiskUsage()
+	require.NoError(t, err)
+	assert.Equal(t, DiskUsageCategory{Count: 3, Active: 1, Size: 1000, Reclaimable: 400}, usage.Images)
+	assert.Equal(t, DiskUsageCategory{Count: 2, Active: 1, Size: 55, Reclaimable: 15}, usage.Containers)
+	assert.Equal(t, DiskUsageCategory{Count: 3, Active: 1, Size: 570, Reclaimable: 70}, usage.Volumes)
+	assert.Equal(t, DiskUsageCategory{Count: 3, Active: 1, Size: 280, Reclaimable: 80}, usage.BuildCache)
+}
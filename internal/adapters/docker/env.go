@@ -0,0 +1,155 @@
+package docker
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// readEnvFile читает переменные окружения из файла в формате dotenv
+func readEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "ошибка при открытии env файла")
+	}
+	defer file.Close()
+
+	env, err := parseEnvFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "ошибка в env файле %s", path)
+	}
+	return env, nil
+}
+
+// parseEnvFile разбирает строки KEY=VALUE в формате dotenv. Пустые строки
+// и строки, начинающиеся с #, пропускаются; допускается префикс export.
+// Значение в двойных кавычках поддерживает экранирование (\n, \"), в
+// одинарных берется как есть. У значения без кавычек отрезаются пробелы
+// по краям и комментарий после " #". Подстановка переменных хоста не
+// выполняется.
+func parseEnvFile(r io.Reader) (map[string]string, error) {
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !isEnvName(key) {
+			return nil, errors.Errorf("строка %d: ожидается KEY=VALUE", lineNum)
+		}
+
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, errors.Errorf("строка %d: некорректное значение в кавычках", lineNum)
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		env[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "ошибка при чтении env файла")
+	}
+	return env, nil
+}
+
+// interpolateEnv подставляет в value переменные окружения процесса:
+// $VAR, ${VAR} и ${VAR:-default}. Значение по умолчанию используется,
+// если переменная не задана или пуста. $$ задает символ $ без подстановки.
+// Незаданная переменная без значения по умолчанию считается ошибкой,
+// чтобы контейнер не запускался с пустым секретом.
+func interpolateEnv(value string) (string, error) {
+	var result strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i == len(value)-1 {
+			result.WriteByte(value[i])
+			continue
+		}
+
+		switch next := value[i+1]; {
+		case next == '$':
+			result.WriteByte('$')
+			i++
+		case next == '{':
+			end := strings.IndexByte(value[i+2:], '}')
+			if end < 0 {
+				return "", errors.Errorf("незакрытая подстановка в %q", value)
+			}
+			expr := value[i+2 : i+2+end]
+			name, def, hasDefault := strings.Cut(expr, ":-")
+			if !isEnvName(name) {
+				return "", errors.Errorf("некорректное имя переменной ${%s}", expr)
+			}
+			resolved, err := lookupEnv(name, def, hasDefault)
+			if err != nil {
+				return "", err
+			}
+			result.WriteString(resolved)
+			i += 2 + end
+		case isEnvNameStart(next):
+			end := i + 2
+			for end < len(value) && isEnvNameChar(value[end]) {
+				end++
+			}
+			resolved, err := lookupEnv(value[i+1:end], "", false)
+			if err != nil {
+				return "", err
+			}
+			result.WriteString(resolved)
+			i = end - 1
+		default:
+			result.WriteByte('$')
+		}
+	}
+	return result.String(), nil
+}
+
+// lookupEnv возвращает значение переменной или значение по умолчанию
+func lookupEnv(name, def string, hasDefault bool) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if hasDefault && value == "" {
+		return def, nil
+	}
+	if !ok {
+		return "", errors.Errorf("переменная окружения %s не задана", name)
+	}
+	return value, nil
+}
+
+// isEnvName проверяет, что name - допустимое имя переменной окружения
+func isEnvName(name string) bool {
+	if name == "" || !isEnvNameStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isEnvNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isEnvNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isEnvNameChar(c byte) bool {
+	return isEnvNameStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,130 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolateEnv(t *testing.T) {
+	t.Setenv("HOST_API_KEY", "secret")
+	t.Setenv("EMPTY", "")
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr string
+	}{
+		{name: "без подстановок", value: "plain", want: "plain"},
+		{name: "фигурные скобки", value: "${HOST_API_KEY}", want: "secret"},
+		{name: "короткая форма", value: "key=$HOST_API_KEY;", want: "key=secret;"},
+		{name: "значение по умолчанию", value: "${MISSING_VAR:-fallback}", want: "fallback"},
+		{name: "значение по умолчанию для пустой", value: "${EMPTY:-fallback}", want: "fallback"},
+		{name: "заданная переменная важнее умолчания", value: "${HOST_API_KEY:-fallback}", want: "secret"},
+		{name: "пустая заданная переменная", value: "${EMPTY}", want: ""},
+		{name: "экранирование", value: "$$HOST_API_KEY", want: "$HOST_API_KEY"},
+		{name: "одиночный доллар", value: "cost: 5$", want: "cost: 5$"},
+		{name: "незаданная переменная", value: "${MISSING_VAR}", wantErr: "MISSING_VAR не задана"},
+		{name: "незаданная переменная в короткой форме", value: "$MISSING_VAR", wantErr: "MISSING_VAR не задана"},
+		{name: "незакрытая скобка", value: "${HOST_API_KEY", wantErr: "незакрытая подстановка"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interpolateEnv(tt.value)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCreateContainerInterpolatesEnvironment(t *testing.T) {
+	t.Setenv("HOST_API_KEY", "secret")
+
+	t.Run("значения подставляются из окружения", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+		fake.addImage("test-image", fakeImage{})
+
+		_, err := adapter.CreateContainer(ContainerOptions{
+			Image:       "test-image",
+			Name:        "api",
+			Environment: map[string]string{"API_KEY": "${HOST_API_KEY}"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"API_KEY=secret"}, fake.container("api").Env)
+	})
+
+	t.Run("незаданная переменная останавливает создание", func(t *testing.T) {
+		fake, adapter := newFakeDockerServer(t)
+		fake.addImage("test-image", fakeImage{})
+
+		_, err := adapter.CreateContainer(ContainerOptions{
+			Image:       "test-image",
+			Name:        "api",
+			Environment: map[string]string{"DB_PASSWORD": "${MISSING_DB_PASSWORD}"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "DB_PASSWORD")
+		assert.Nil(t, fake.container("api"))
+	})
+}
+
+func TestParseEnvFile(t *testing.T) {
+	const content = `# настройки приложения
+MODE=production
+  # отключенная переменная
+# DEBUG=true
+
+export GREETING="Hello, \"world\"\n"
+RAW='$NOT_EXPANDED'
+URL=https://example.com/#anchor # адрес сервиса
+EMPTY=
+`
+
+	env, err := parseEnvFile(strings.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"MODE":     "production",
+		"GREETING": "Hello, \"world\"\n",
+		"RAW":      "$NOT_EXPANDED",
+		"URL":      "https://example.com/#anchor",
+		"EMPTY":    "",
+	}, env)
+}
+
+func TestParseEnvFileInvalidLine(t *testing.T) {
+	_, err := parseEnvFile(strings.NewReader("MODE=production\nnot a variable\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "строка 2")
+}
+
+func TestCreateContainerEnvFile(t *testing.T) {
+	t.Setenv("HOST_TOKEN", "from-host")
+
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte("MODE=staging\nTOKEN=from-file\nLEVEL=info\n"), 0o600))
+
+	fake, adapter := newFakeDockerServer(t)
+	fake.addImage("app:1.0", fakeImage{})
+
+	_, err := adapter.CreateContainer(ContainerOptions{
+		Image:       "app:1.0",
+		Name:        "app",
+		EnvFile:     path,
+		Environment: map[string]string{"MODE": "production", "TOKEN": "${HOST_TOKEN}"},
+	})
+	require.NoError(t, err)
+
+	// Явные переменные важнее файла
+	assert.Equal(t, []string{"LEVEL=info", "MODE=production", "TOKEN=from-host"}, fake.container("app").Env)
+}
@@ -0,0 +1,29 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/localops/devops-manager/internal/adapters/monitoring"
+	"github.com/pkg/errors"
+)
+
+// classifyError относит ошибку Docker API к типу метрики errors_total.
+// Для nil возвращается пустая строка.
+func classifyError(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errdefs.IsNotFound(err):
+		return monitoring.ErrorTypeNotFound
+	case errdefs.IsConflict(err):
+		return monitoring.ErrorTypeConflict
+	case errdefs.IsDeadline(err), errors.Is(err, context.DeadlineExceeded):
+		return monitoring.ErrorTypeTimeout
+	case errdefs.IsUnavailable(err), client.IsErrConnectionFailed(err):
+		return monitoring.ErrorTypeUnavailable
+	default:
+		return monitoring.ErrorTypeOther
+	}
+}
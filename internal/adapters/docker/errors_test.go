@@ -0,0 +1,41 @@
+package docker
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/localops/devops-manager/internal/adapters/monitoring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordErrorsByType(t *testing.T) {
+	server, adapter := newFakeDockerServer(t)
+	server.addContainer(fakeContainer{Name: "web"})
+
+	adapter.monitoring = monitoring.NewMonitoringAdapter(monitoring.Config{
+		Namespace: "test",
+		Logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	require.Error(t, adapter.StopContainer("missing"))
+	require.NoError(t, adapter.StopContainer("web"))
+
+	rec := httptest.NewRecorder()
+	adapter.monitoring.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	assert.Contains(t, body, `test_errors_total{component="docker",error_type="notfound",operation="stop_container"} 1`)
+	assert.NotContains(t, body, `error_type="other"`)
+}
+
+func TestClassifyError(t *testing.T) {
+	_, adapter := newFakeDockerServer(t)
+
+	assert.Empty(t, classifyError(nil))
+	assert.Equal(t, monitoring.ErrorTypeNotFound, classifyError(adapter.StartContainer("missing")))
+	assert.Equal(t, monitoring.ErrorTypeOther, classifyError(io.ErrUnexpectedEOF))
+}
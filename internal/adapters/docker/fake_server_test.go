@@ -0,0 +1,550 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeContainer описывает контейнер, известный fakeDockerServer
+type fakeContainer struct {
+	ID      string
+	Name    string
+	Image   string
+	State   string // running, exited
+	Labels  map[string]string
+	Created time.Time
+	Tty     bool
+	Env     []string
+	// HostConfig из запроса на создание контейнера
+	HostConfig container.HostConfig
+	// Resources из последнего запроса на обновление лимитов
+	Resources container.Resources
+}
+
+// fakeImage описывает образ, известный fakeDockerServer
+type fakeImage struct {
+	ID           string
+	Digests      []string
+	Size         int64
+	Created      time.Time
+	Architecture string
+	OS           string
+	Config       *container.Config
+	// History слои от верхнего к базовому, в порядке ответа Docker
+	History []image.HistoryResponseItem
+}
+
+// fakeNetwork описывает сеть и подключенные к ней контейнеры
+type fakeNetwork struct {
+	ID         string
+	Name       string
+	Containers []string
+}
+
+// fakeDockerServer имитирует Docker Engine API по объявленному состоянию:
+// тест описывает контейнеры, образы и сети, а сервер сам формирует
+// ответы на соответствующие /v1.41/... запросы и меняет состояние.
+type fakeDockerServer struct {
+	t *testing.T
+
+	mu         sync.Mutex
+	containers []*fakeContainer
+	images     map[string]*fakeImage
+	networks   []*fakeNetwork
+	failures   map[string]fakeFailure
+	handlers   map[string]http.HandlerFunc
+	requests   []string
+}
+
+// fakeFailure задает ошибочный ответ на конкретный запрос
+type fakeFailure struct {
+	status  int
+	message string
+}
+
+// apiVersionPrefix отрезает версию API из пути запроса
+var apiVersionPrefix = regexp.MustCompile(`^/v[0-9.]+`)
+
+// newFakeDockerServer запускает fakeDockerServer и возвращает адаптер,
+// подключенный к нему. Сервер останавливается по завершении теста.
+func newFakeDockerServer(t *testing.T) (*fakeDockerServer, *DockerAdapter) {
+	fake := &fakeDockerServer{
+		t:        t,
+		images:   make(map[string]*fakeImage),
+		failures: make(map[string]fakeFailure),
+		handlers: make(map[string]http.HandlerFunc),
+	}
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	cli, err := client.NewClientWithOpts(
+		client.WithHost("tcp://"+server.Listener.Addr().String()),
+		client.WithHTTPClient(server.Client()),
+	)
+	require.NoError(t, err)
+
+	return fake, &DockerAdapter{
+		client: cli,
+		ctx:    context.Background(),
+	}
+}
+
+// addContainer объявляет существующий контейнер. Пустой ID выводится из имени.
+func (f *fakeDockerServer) addContainer(c fakeContainer) *fakeContainer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if c.ID == "" {
+		c.ID = c.Name + "-id"
+	}
+	if c.State == "" {
+		c.State = "running"
+	}
+	if c.Created.IsZero() {
+		c.Created = time.Now()
+	}
+	f.containers = append(f.containers, &c)
+	return &c
+}
+
+// addImage объявляет локальный образ с тегом ref
+func (f *fakeDockerServer) addImage(ref string, img fakeImage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if img.ID == "" {
+		img.ID = "sha256:" + ref
+	}
+	f.images[ref] = &img
+}
+
+// addNetwork объявляет сеть. Пустой ID выводится из имени.
+func (f *fakeDockerServer) addNetwork(n fakeNetwork) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if n.ID == "" {
+		n.ID = n.Name + "-id"
+	}
+	f.networks = append(f.networks, &n)
+}
+
+// failOn заставляет сервер ответить ошибкой на запрос "METHOD /path"
+// (путь без префикса версии API)
+func (f *fakeDockerServer) failOn(method, path string, status int, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures[method+" "+path] = fakeFailure{status: status, message: message}
+}
+
+// handle подменяет обработку запроса "METHOD /path" для сценариев,
+// которые не выражаются через состояние (потоки, ожидание)
+func (f *fakeDockerServer) handle(method, path string, handler http.HandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.handlers[method+" "+path] = handler
+}
+
+// container возвращает текущее состояние контейнера по ID или имени
+func (f *fakeDockerServer) container(idOrName string) *fakeContainer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.findContainer(idOrName)
+}
+
+// hasImage сообщает, есть ли локальный образ с тегом ref
+func (f *fakeDockerServer) hasImage(ref string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, ok := f.images[ref]
+	return ok
+}
+
+// requested возвращает все полученные запросы в виде "METHOD /path"
+func (f *fakeDockerServer) requested() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.requests...)
+}
+
+func (f *fakeDockerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := apiVersionPrefix.ReplaceAllString(r.URL.Path, "")
+	key := r.Method + " " + path
+
+	f.mu.Lock()
+	f.requests = append(f.requests, key)
+	handler, hasHandler := f.handlers[key]
+	failure, hasFailure := f.failures[key]
+	f.mu.Unlock()
+
+	switch {
+	case hasHandler:
+		handler(w, r)
+		return
+	case hasFailure:
+		writeDockerError(w, failure.status, failure.message)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(path, "/containers/"):
+		f.serveContainers(w, r, strings.TrimPrefix(path, "/containers/"))
+	case strings.HasPrefix(path, "/images/"):
+		f.serveImages(w, r, strings.TrimPrefix(path, "/images/"))
+	case r.Method == http.MethodGet && path == "/networks":
+		f.listNetworks(w)
+	case strings.HasPrefix(path, "/networks/"):
+		f.serveNetworks(w, r, strings.TrimPrefix(path, "/networks/"))
+	default:
+		f.unexpected(w, r)
+	}
+}
+
+func (f *fakeDockerServer) serveContainers(w http.ResponseWriter, r *http.Request, rest string) {
+	switch {
+	case r.Method == http.MethodGet && rest == "json":
+		f.listContainers(w, r)
+		return
+	case r.Method == http.MethodPost && rest == "create":
+		f.createContainer(w, r)
+		return
+	}
+
+	idOrName, action, _ := strings.Cut(rest, "/")
+	c := f.findContainer(idOrName)
+	if c == nil {
+		writeDockerError(w, http.StatusNotFound, "No such container: "+idOrName)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && action == "json":
+		json.NewEncoder(w).Encode(types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				ID:         c.ID,
+				Name:       "/" + c.Name,
+				Created:    c.Created.Format(time.RFC3339Nano),
+				State:      &types.ContainerState{Status: c.State, Running: c.State == "running"},
+				HostConfig: &c.HostConfig,
+			},
+			Config: &container.Config{Image: c.Image, Labels: c.Labels, Tty: c.Tty, Env: c.Env},
+		})
+	case r.Method == http.MethodPost && (action == "start" || action == "restart"):
+		c.State = "running"
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && (action == "stop" || action == "kill"):
+		c.State = "exited"
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && action == "update":
+		var update container.UpdateConfig
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&update))
+		c.Resources = update.Resources
+		json.NewEncoder(w).Encode(container.ContainerUpdateOKBody{})
+	case r.Method == http.MethodDelete && action == "":
+		if c.State == "running" && r.URL.Query().Get("force") != "1" {
+			writeDockerError(w, http.StatusConflict, "You cannot remove a running container "+c.ID)
+			return
+		}
+		for i, existing := range f.containers {
+			if existing == c {
+				f.containers = append(f.containers[:i], f.containers[i+1:]...)
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.unexpected(w, r)
+	}
+}
+
+func (f *fakeDockerServer) listContainers(w http.ResponseWriter, r *http.Request) {
+	args, err := filters.FromJSON(r.URL.Query().Get("filters"))
+	require.NoError(f.t, err)
+	all := r.URL.Query().Get("all") == "1"
+
+	result := []types.Container{}
+	for _, c := range f.containers {
+		if !all && c.State != "running" {
+			continue
+		}
+		if args.Contains("label") && !args.MatchKVList("label", c.Labels) {
+			continue
+		}
+		result = append(result, types.Container{
+			ID:      c.ID,
+			Names:   []string{"/" + c.Name},
+			Image:   c.Image,
+			ImageID: f.imageID(c.Image),
+			Labels:  c.Labels,
+			State:   c.State,
+			Status:  c.State,
+			Created: c.Created.Unix(),
+		})
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+func (f *fakeDockerServer) createContainer(w http.ResponseWriter, r *http.Request) {
+	var config struct {
+		container.Config
+		HostConfig container.HostConfig
+	}
+	require.NoError(f.t, json.NewDecoder(r.Body).Decode(&config))
+
+	if _, ok := f.images[config.Image]; !ok {
+		writeDockerError(w, http.StatusNotFound, "No such image: "+config.Image)
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = fmt.Sprintf("container-%d", len(f.containers)+1)
+	}
+	if f.findContainer(name) != nil {
+		writeDockerError(w, http.StatusConflict, "Conflict. The container name \"/"+name+"\" is already in use")
+		return
+	}
+
+	c := &fakeContainer{
+		ID:         name + "-id",
+		Name:       name,
+		Image:      config.Image,
+		State:      "created",
+		Labels:     config.Labels,
+		Created:    time.Now(),
+		Env:        config.Env,
+		HostConfig: config.HostConfig,
+	}
+	f.containers = append(f.containers, c)
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(container.ContainerCreateCreatedBody{ID: c.ID})
+}
+
+func (f *fakeDockerServer) serveImages(w http.ResponseWriter, r *http.Request, rest string) {
+	if r.Method == http.MethodGet && rest == "json" {
+		f.listImages(w)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		f.removeImage(w, rest)
+		return
+	}
+	if r.Method == http.MethodPost && rest == "create" {
+		ref := r.URL.Query().Get("fromImage") + ":" + r.URL.Query().Get("tag")
+		if _, ok := f.images[ref]; !ok {
+			f.images[ref] = &fakeImage{ID: "sha256:" + ref}
+		}
+		json.NewEncoder(w).Encode(map[string]string{"status": "Downloaded newer image for " + ref})
+		return
+	}
+
+	// Ссылка на образ может содержать "/", действие идет последним сегментом
+	idx := strings.LastIndex(rest, "/")
+	if idx < 0 {
+		f.unexpected(w, r)
+		return
+	}
+	ref, action := rest[:idx], rest[idx+1:]
+
+	img, ok := f.images[ref]
+	if !ok {
+		writeDockerError(w, http.StatusNotFound, "No such image: "+ref)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && action == "json":
+		layers := make([]string, 0, len(img.History))
+		for i := len(img.History) - 1; i >= 0; i-- {
+			layers = append(layers, fmt.Sprintf("sha256:layer%d", len(img.History)-i))
+		}
+		json.NewEncoder(w).Encode(types.ImageInspect{
+			ID:           img.ID,
+			RepoTags:     []string{ref},
+			RepoDigests:  img.Digests,
+			Size:         img.Size,
+			Created:      img.Created.Format(time.RFC3339Nano),
+			Architecture: img.Architecture,
+			Os:           img.OS,
+			Config:       img.Config,
+			RootFS:       types.RootFS{Type: "layers", Layers: layers},
+		})
+	case r.Method == http.MethodGet && action == "history":
+		json.NewEncoder(w).Encode(img.History)
+	case r.Method == http.MethodPost && action == "tag":
+		target := r.URL.Query().Get("repo") + ":" + r.URL.Query().Get("tag")
+		tagged := *img
+		f.images[target] = &tagged
+		w.WriteHeader(http.StatusCreated)
+	default:
+		f.unexpected(w, r)
+	}
+}
+
+// listImages отвечает списком образов, объединяя теги с общим ID
+func (f *fakeDockerServer) listImages(w http.ResponseWriter) {
+	byID := make(map[string]*types.ImageSummary)
+	result := []*types.ImageSummary{}
+	for _, ref := range sortedKeys(f.images) {
+		img := f.images[ref]
+		summary, ok := byID[img.ID]
+		if !ok {
+			summary = &types.ImageSummary{ID: img.ID, Created: img.Created.Unix(), Size: img.Size}
+			byID[img.ID] = summary
+			result = append(result, summary)
+		}
+		summary.RepoTags = append(summary.RepoTags, ref)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// removeImage снимает тег ref и удаляет образ, если у него не осталось тегов
+func (f *fakeDockerServer) removeImage(w http.ResponseWriter, ref string) {
+	img, ok := f.images[ref]
+	if !ok {
+		writeDockerError(w, http.StatusNotFound, "No such image: "+ref)
+		return
+	}
+	delete(f.images, ref)
+
+	items := []types.ImageDeleteResponseItem{{Untagged: ref}}
+	deleted := true
+	for _, other := range f.images {
+		if other.ID == img.ID {
+			deleted = false
+		}
+	}
+	if deleted {
+		items = append(items, types.ImageDeleteResponseItem{Deleted: img.ID})
+	}
+	json.NewEncoder(w).Encode(items)
+}
+
+func (f *fakeDockerServer) listNetworks(w http.ResponseWriter) {
+	result := []types.NetworkResource{}
+	for _, n := range f.networks {
+		result = append(result, types.NetworkResource{ID: n.ID, Name: n.Name})
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+func (f *fakeDockerServer) serveNetworks(w http.ResponseWriter, r *http.Request, rest string) {
+	idOrName, action, _ := strings.Cut(rest, "/")
+
+	var n *fakeNetwork
+	for _, existing := range f.networks {
+		if existing.ID == idOrName || existing.Name == idOrName {
+			n = existing
+			break
+		}
+	}
+	if n == nil {
+		writeDockerError(w, http.StatusNotFound, "network "+idOrName+" not found")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && action == "":
+		endpoints := make(map[string]types.EndpointResource, len(n.Containers))
+		for _, id := range n.Containers {
+			resource := types.EndpointResource{}
+			if c := f.findContainer(id); c != nil {
+				resource.Name = c.Name
+			}
+			endpoints[id] = resource
+		}
+		json.NewEncoder(w).Encode(types.NetworkResource{ID: n.ID, Name: n.Name, Containers: endpoints})
+	case r.Method == http.MethodPost && action == "disconnect":
+		var req types.NetworkDisconnect
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&req))
+		for i, id := range n.Containers {
+			if id == req.Container {
+				n.Containers = append(n.Containers[:i], n.Containers[i+1:]...)
+				break
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodDelete && action == "":
+		if len(n.Containers) > 0 {
+			writeDockerError(w, http.StatusForbidden, "error while removing network: network "+n.Name+" has active endpoints")
+			return
+		}
+		for i, existing := range f.networks {
+			if existing == n {
+				f.networks = append(f.networks[:i], f.networks[i+1:]...)
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.unexpected(w, r)
+	}
+}
+
+// findContainer ищет контейнер по ID или имени; вызывается под f.mu
+func (f *fakeDockerServer) findContainer(idOrName string) *fakeContainer {
+	for _, c := range f.containers {
+		if c.ID == idOrName || c.Name == idOrName {
+			return c
+		}
+	}
+	return nil
+}
+
+// imageID возвращает ID образа ref или пустую строку; вызывается под f.mu
+func (f *fakeDockerServer) imageID(ref string) string {
+	if img, ok := f.images[ref]; ok {
+		return img.ID
+	}
+	return ""
+}
+
+// network возвращает сеть по ID или имени, nil если сеть удалена
+func (f *fakeDockerServer) network(idOrName string) *fakeNetwork {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, n := range f.networks {
+		if n.ID == idOrName || n.Name == idOrName {
+			return n
+		}
+	}
+	return nil
+}
+
+func (f *fakeDockerServer) unexpected(w http.ResponseWriter, r *http.Request) {
+	f.t.Errorf("неожиданный запрос %s %s", r.Method, r.URL.Path)
+	writeDockerError(w, http.StatusNotFound, "page not found")
+}
+
+// writeDockerError отвечает ошибкой в формате Docker API
+func writeDockerError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
@@ -0,0 +1,194 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/localops/devops-manager/internal/tracing"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Метки OCI, которыми образ связывается с исходным кодом
+const (
+	LabelRevision = "org.opencontainers.image.revision"
+	LabelSource   = "org.opencontainers.image.source"
+)
+
+// gitOutput выполняет git в директории dir и возвращает вывод без пробелов
+// по краям; подменяется в тестах
+var gitOutput = func(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitImageLabels возвращает OCI метки с коммитом и адресом репозитория,
+// в котором находится dir. Адрес берется из remote origin; если его нет,
+// метка source не добавляется.
+func GitImageLabels(dir string) (map[string]string, error) {
+	revision, err := gitOutput(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, errors.Wrapf(err, "не удалось определить коммит git в %s", dir)
+	}
+
+	labels := map[string]string{LabelRevision: revision}
+	if remote, err := gitOutput(dir, "remote", "get-url", "origin"); err == nil && remote != "" {
+		labels[LabelSource] = sourceURL(remote)
+	}
+
+	return labels, nil
+}
+
+// sourceURL приводит адрес remote к https ссылке на репозиторий:
+// git@host:org/repo.git становится https://host/org/repo, учетные данные
+// из адреса удаляются, чтобы не попасть в метки образа
+func sourceURL(remote string) string {
+	remote = strings.TrimSuffix(remote, ".git")
+
+	if !strings.Contains(remote, "://") {
+		if userHost, path, ok := strings.Cut(remote, ":"); ok {
+			_, host, found := strings.Cut(userHost, "@")
+			if !found {
+				host = userHost
+			}
+			return "https://" + host + "/" + strings.TrimPrefix(path, "/")
+		}
+		return remote
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil {
+		return remote
+	}
+	u.User = nil
+	if u.Scheme == "ssh" || u.Scheme == "git" {
+		u.Scheme = "https"
+		u.Host = u.Hostname()
+	}
+	return u.String()
+}
+
+// BuildImageWithGitInfo собирает образ, помечая его коммитом и адресом
+// репозитория, определенными по git репозиторию, в котором находится path
+func (d *DockerAdapter) BuildImageWithGitInfo(path string, tag string) error {
+	labels, err := GitImageLabels(path)
+	if err != nil {
+		return err
+	}
+	return d.BuildImage(path, tag, nil, labels)
+}
+
+// BuildImageFromGit собирает образ из удаленного git репозитория: daemon
+// сам клонирует gitURL и использует его как контекст сборки. Адрес может
+// содержать фрагмент #ref:dir с веткой или тегом и поддиректорией.
+// Прогресс сборки выводится в out.
+func (d *DockerAdapter) BuildImageFromGit(ctx context.Context, gitURL, tag string, buildArgs map[string]*string, out io.Writer) error {
+	return d.BuildImageFromGitWithOptions(ctx, gitURL, BuildOptions{Tags: []string{tag}, BuildArgs: buildArgs}, out)
+}
+
+// BuildImageFromGitWithOptions собирает образ из удаленного git репозитория
+// с параметрами opts, см. BuildImageFromGit
+func (d *DockerAdapter) BuildImageFromGitWithOptions(ctx context.Context, gitURL string, opts BuildOptions, out io.Writer) error {
+	ctx, span := tracing.Start(ctx, "docker", "build_image", attribute.StringSlice("image", opts.Tags), attribute.String("git.url", sanitizeGitURL(gitURL)), attribute.String("target", opts.Target))
+	start := time.Now()
+	err := validateGitURL(gitURL)
+	if err == nil {
+		err = validateTags(opts.Tags)
+	}
+	if err == nil {
+		err = d.withReconnect(func() error { return d.buildImageFromGit(ctx, gitURL, opts, out) })
+	}
+	duration := time.Since(start)
+	tracing.End(span, err)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation("build_image", status, duration)
+		d.monitoring.RecordError("docker", "build_image", classifyError(err))
+	}
+
+	d.recordAudit(ctx, "build_image", strings.Join(opts.Tags, ","), err)
+
+	return err
+}
+
+// buildImageFromGit запускает сборку с удаленным контекстом и выводит прогресс в out
+func (d *DockerAdapter) buildImageFromGit(ctx context.Context, gitURL string, opts BuildOptions, out io.Writer) error {
+	buildOptions := opts.imageBuildOptions()
+	buildOptions.RemoteContext = gitURL
+
+	response, err := d.apiClient().ImageBuild(ctx, nil, buildOptions)
+	if err != nil {
+		return errors.Wrap(err, "ошибка при сборке образа")
+	}
+	defer response.Body.Close()
+
+	// Ошибки клонирования и сборки приходят в потоке прогресса
+	if err := jsonmessage.DisplayJSONMessagesStream(response.Body, out, 0, false, nil); err != nil {
+		return errors.Wrap(err, "ошибка при сборке образа")
+	}
+
+	return nil
+}
+
+// validateGitURL проверяет, что daemon распознает gitURL как git репозиторий,
+// а не как ссылку на архив или Dockerfile: допускаются схемы git:// и ssh://,
+// адреса вида git@host:path и http(s) адреса, оканчивающиеся на .git
+func validateGitURL(gitURL string) error {
+	repo, _, _ := strings.Cut(gitURL, "#")
+	if repo == "" {
+		return errors.New("не указан адрес git репозитория")
+	}
+
+	if rest, ok := strings.CutPrefix(repo, "git@"); ok {
+		if host, path, found := strings.Cut(rest, ":"); found && host != "" && path != "" {
+			return nil
+		}
+		return errors.Errorf("некорректный адрес git репозитория %s", gitURL)
+	}
+
+	u, err := url.Parse(repo)
+	if err != nil {
+		return errors.Wrapf(err, "некорректный адрес git репозитория %s", sanitizeGitURL(gitURL))
+	}
+	if u.Host == "" || strings.Trim(u.Path, "/") == "" {
+		return errors.Errorf("в адресе git репозитория %s нет хоста или пути", sanitizeGitURL(gitURL))
+	}
+
+	switch u.Scheme {
+	case "git", "ssh":
+		return nil
+	case "http", "https":
+		if strings.HasSuffix(u.Path, ".git") {
+			return nil
+		}
+		return errors.Errorf("http адрес git репозитория %s должен оканчиваться на .git", sanitizeGitURL(gitURL))
+	default:
+		return errors.Errorf("неподдерживаемая схема %q в адресе git репозитория", u.Scheme)
+	}
+}
+
+// sanitizeGitURL убирает из адреса учетные данные, чтобы токен не попал
+// в трассировку и сообщения об ошибках
+func sanitizeGitURL(gitURL string) string {
+	u, err := url.Parse(gitURL)
+	if err != nil || u.User == nil {
+		return gitURL
+	}
+	u.User = nil
+	return u.String()
+}
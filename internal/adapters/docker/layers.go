@@ -0,0 +1,140 @@
+package docker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// LayerInfo содержит информацию о слое образа
+type LayerInfo struct {
+	ID             string
+	CreatedBy      string
+	Created        time.Time
+	Size           int64
+	CumulativeSize int64
+}
+
+// LayerChange описывает слой, размер которого изменился между образами
+type LayerChange struct {
+	CreatedBy string
+	OldSize   int64
+	NewSize   int64
+}
+
+// ImageDiff содержит различия между слоями двух образов
+type ImageDiff struct {
+	Added     []LayerInfo
+	Removed   []LayerInfo
+	Changed   []LayerChange
+	SizeDelta int64
+}
+
+// GetImageLayers возвращает слои образа в порядке сборки (от базового к верхнему)
+// с накопленным размером образа на каждом слое
+func (d *DockerAdapter) GetImageLayers(imageID string) ([]LayerInfo, error) {
+	history, err := d.GetImageHistory(imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Docker возвращает историю от последнего слоя к первому
+	layers := make([]LayerInfo, 0, len(history))
+	var total int64
+	for i := len(history) - 1; i >= 0; i-- {
+		item := history[i]
+		total += item.Size
+		layers = append(layers, LayerInfo{
+			ID:             item.ID,
+			CreatedBy:      item.CreatedBy,
+			Created:        time.Unix(item.Created, 0),
+			Size:           item.Size,
+			CumulativeSize: total,
+		})
+	}
+
+	return layers, nil
+}
+
+// SortLayersBySize сортирует слои по убыванию размера
+func SortLayersBySize(layers []LayerInfo) {
+	sort.SliceStable(layers, func(i, j int) bool {
+		return layers[i].Size > layers[j].Size
+	})
+}
+
+// DiffImages сравнивает историю слоев двух образов.
+// Слои сопоставляются по команде, которой они были созданы
+func (d *DockerAdapter) DiffImages(imageA, imageB string) (*ImageDiff, error) {
+	layersA, err := d.GetImageLayers(imageA)
+	if err != nil {
+		return nil, err
+	}
+	layersB, err := d.GetImageLayers(imageB)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffLayers(layersA, layersB), nil
+}
+
+// diffLayers вычисляет различия между двумя наборами слоев
+func diffLayers(layersA, layersB []LayerInfo) *ImageDiff {
+	diff := &ImageDiff{}
+
+	indexA := indexLayers(layersA)
+	indexB := indexLayers(layersB)
+
+	for _, key := range indexA.keys {
+		a := indexA.layers[key]
+		b, ok := indexB.layers[key]
+		if !ok {
+			diff.Removed = append(diff.Removed, a)
+			continue
+		}
+		if a.Size != b.Size {
+			diff.Changed = append(diff.Changed, LayerChange{
+				CreatedBy: a.CreatedBy,
+				OldSize:   a.Size,
+				NewSize:   b.Size,
+			})
+		}
+	}
+
+	for _, key := range indexB.keys {
+		if _, ok := indexA.layers[key]; !ok {
+			diff.Added = append(diff.Added, indexB.layers[key])
+		}
+	}
+
+	diff.SizeDelta = totalLayerSize(layersB) - totalLayerSize(layersA)
+	return diff
+}
+
+// layerIndex хранит слои по ключу с сохранением порядка
+type layerIndex struct {
+	keys   []string
+	layers map[string]LayerInfo
+}
+
+// indexLayers строит индекс слоев. Повторяющиеся команды различаются порядковым номером
+func indexLayers(layers []LayerInfo) layerIndex {
+	index := layerIndex{layers: make(map[string]LayerInfo, len(layers))}
+	seen := make(map[string]int)
+	for _, layer := range layers {
+		key := fmt.Sprintf("%s#%d", layer.CreatedBy, seen[layer.CreatedBy])
+		seen[layer.CreatedBy]++
+		index.keys = append(index.keys, key)
+		index.layers[key] = layer
+	}
+	return index
+}
+
+// totalLayerSize возвращает суммарный размер слоев
+func totalLayerSize(layers []LayerInfo) int64 {
+	var total int64
+	for _, layer := range layers {
+		total += layer.Size
+	}
+	return total
+}
@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetImageLayers(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	// История отдается от верхнего слоя к базовому
+	fake.addImage("test-image", fakeImage{History: []image.HistoryResponseItem{
+		{ID: "sha256:top", CreatedBy: "CMD [\"/app\"]", Size: 0, Created: 300},
+		{ID: "sha256:deps", CreatedBy: "RUN apt-get install", Size: 500, Created: 200},
+		{ID: "sha256:base", CreatedBy: "ADD rootfs", Size: 100, Created: 100},
+	}})
+
+	layers, err := adapter.GetImageLayers("test-image")
+	require.NoError(t, err)
+	require.Len(t, layers, 3)
+
+	// Слои идут в порядке сборки с накопленным размером
+	assert.Equal(t, "sha256:base", layers[0].ID)
+	assert.Equal(t, int64(100), layers[0].CumulativeSize)
+	assert.Equal(t, "sha256:deps", layers[1].ID)
+	assert.Equal(t, int64(600), layers[1].CumulativeSize)
+	assert.Equal(t, "sha256:top", layers[2].ID)
+	assert.Equal(t, int64(600), layers[2].CumulativeSize)
+
+	SortLayersBySize(layers)
+	assert.Equal(t, "sha256:deps", layers[0].ID)
+	assert.Equal(t, "sha256:base", layers[1].ID)
+	assert.Equal(t, "sha256:top", layers[2].ID)
+}
+
+func TestDiffImages(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	fake.addImage("app:v1", fakeImage{History: []image.HistoryResponseItem{
+		{CreatedBy: "COPY app /app", Size: 200},
+		{CreatedBy: "RUN apt-get install curl", Size: 300},
+		{CreatedBy: "ADD rootfs", Size: 100},
+	}})
+	fake.addImage("app:v2", fakeImage{History: []image.HistoryResponseItem{
+		{CreatedBy: "COPY app /app", Size: 250},
+		{CreatedBy: "RUN pip install -r requirements.txt", Size: 400},
+		{CreatedBy: "ADD rootfs", Size: 100},
+	}})
+
+	diff, err := adapter.DiffImages("app:v1", "app:v2")
+	require.NoError(t, err)
+
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, "RUN pip install -r requirements.txt", diff.Added[0].CreatedBy)
+
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, "RUN apt-get install curl", diff.Removed[0].CreatedBy)
+
+	require.Len(t, diff.Changed, 1)
+	assert.Equal(t, LayerChange{CreatedBy: "COPY app /app", OldSize: 200, NewSize: 250}, diff.Changed[0])
+
+	assert.Equal(t, int64(150), diff.SizeDelta)
+}
@@ -0,0 +1,173 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/localops/devops-manager/internal/tracing"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// multiTailLines число последних строк, с которых начинается слежение
+const multiTailLines = "50"
+
+// multiTailColors цвета ANSI для префиксов контейнеров, по кругу
+var multiTailColors = []int{36, 33, 32, 35, 34, 31}
+
+// MultiTail следит за логами нескольких контейнеров одновременно и
+// сводит их в out. Каждая строка предваряется именем контейнера,
+// выделенным своим цветом. Функция возвращает управление, когда все
+// потоки логов закончились или отменен ctx; отмена ошибкой не считается.
+func (d *DockerAdapter) MultiTail(ctx context.Context, containerIDs []string, out io.Writer) error {
+	ctx, span := tracing.Start(ctx, "docker", "multi_tail", attribute.StringSlice("container.ids", containerIDs))
+	start := time.Now()
+	err := d.multiTail(ctx, containerIDs, out)
+	duration := time.Since(start)
+	tracing.End(span, err)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation("multi_tail", status, duration)
+		d.monitoring.RecordError("docker", "multi_tail", classifyError(err))
+	}
+
+	return err
+}
+
+// tailTarget контейнер, за логами которого следит MultiTail
+type tailTarget struct {
+	id   string
+	name string
+	tty  bool
+}
+
+func (d *DockerAdapter) multiTail(ctx context.Context, containerIDs []string, out io.Writer) error {
+	if len(containerIDs) == 0 {
+		return errors.New("не указаны контейнеры")
+	}
+
+	// Имена нужны заранее, чтобы выровнять префиксы
+	targets := make([]tailTarget, 0, len(containerIDs))
+	width := 0
+	for _, id := range containerIDs {
+		inspect, err := withReconnectValue(d, func() (types.ContainerJSON, error) {
+			return d.apiClient().ContainerInspect(ctx, id)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "ошибка при получении информации о контейнере %s", id)
+		}
+		target := tailTarget{id: inspect.ID, name: strings.TrimPrefix(inspect.Name, "/")}
+		if inspect.Config != nil {
+			target.tty = inspect.Config.Tty
+		}
+		targets = append(targets, target)
+		width = max(width, len(target.name))
+	}
+
+	var mu sync.Mutex
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		prefix := fmt.Sprintf("\x1b[%dm%-*s |\x1b[0m ", multiTailColors[i%len(multiTailColors)], width, target.name)
+		writer := &linePrefixWriter{out: out, mu: &mu, prefix: prefix}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = d.followLogs(ctx, target, writer)
+			writer.Flush()
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// followLogs копирует поток логов контейнера в w до его завершения
+func (d *DockerAdapter) followLogs(ctx context.Context, target tailTarget, w io.Writer) error {
+	logs, err := withReconnectValue(d, func() (io.ReadCloser, error) {
+		return d.apiClient().ContainerLogs(ctx, target.id, types.ContainerLogsOptions{
+			ShowStdout: true,
+			ShowStderr: true,
+			Follow:     true,
+			Tail:       multiTailLines,
+		})
+	})
+	if err != nil {
+		return errors.Wrapf(err, "ошибка при получении логов контейнера %s", target.name)
+	}
+	defer logs.Close()
+
+	// В режиме TTY stdout и stderr приходят одним потоком
+	if target.tty {
+		_, err = io.Copy(w, logs)
+	} else {
+		_, err = stdcopy.StdCopy(w, w, logs)
+	}
+	if err != nil && ctx.Err() == nil {
+		return errors.Wrapf(err, "ошибка при чтении логов контейнера %s", target.name)
+	}
+	return nil
+}
+
+// linePrefixWriter пишет в out целые строки с префиксом. Общий mu не дает
+// строкам разных контейнеров перемешаться.
+type linePrefixWriter struct {
+	out    io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    []byte
+}
+
+func (w *linePrefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if err := w.writeLine(w.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// Flush дописывает последнюю строку без перевода строки
+func (w *linePrefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		_ = w.writeLine(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *linePrefixWriter) writeLine(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := io.WriteString(w.out, w.prefix); err != nil {
+		return err
+	}
+	_, err := w.out.Write(line)
+	return err
+}
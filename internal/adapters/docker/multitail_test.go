@@ -0,0 +1,67 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiTail(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	fake.addContainer(fakeContainer{Name: "web"})
+	fake.addContainer(fakeContainer{Name: "db", Tty: true})
+
+	fake.handle(http.MethodGet, "/containers/web-id/logs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.URL.Query().Get("follow"))
+		io.WriteString(stdcopy.NewStdWriter(w, stdcopy.Stdout), "GET / 200\nGET /health ")
+		io.WriteString(stdcopy.NewStdWriter(w, stdcopy.Stdout), "200\n")
+		io.WriteString(stdcopy.NewStdWriter(w, stdcopy.Stderr), "slow request\n")
+	})
+	fake.handle(http.MethodGet, "/containers/db-id/logs", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ready to accept connections\n")
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, adapter.MultiTail(context.Background(), []string{"web", "db"}, &out))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.ElementsMatch(t, []string{
+		"\x1b[36mweb |\x1b[0m GET / 200",
+		"\x1b[36mweb |\x1b[0m GET /health 200",
+		"\x1b[36mweb |\x1b[0m slow request",
+		"\x1b[33mdb  |\x1b[0m ready to accept connections",
+	}, lines)
+}
+
+func TestMultiTailCanceled(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	fake.addContainer(fakeContainer{Name: "web", Tty: true})
+
+	fake.handle(http.MethodGet, "/containers/web-id/logs", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "started\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	var out bytes.Buffer
+	require.NoError(t, adapter.MultiTail(ctx, []string{"web"}, &out))
+	assert.Contains(t, out.String(), "web |\x1b[0m started\n")
+}
+
+func TestMultiTailUnknownContainer(t *testing.T) {
+	_, adapter := newFakeDockerServer(t)
+
+	err := adapter.MultiTail(context.Background(), []string{"missing"}, io.Discard)
+	require.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Задержки между попытками подключения в WaitForPort
+const (
+	portInitialBackoff = 100 * time.Millisecond
+	portMaxBackoff     = 2 * time.Second
+)
+
+// portProbeWindow сколько WaitForPort читает из установленного соединения,
+// проверяя, что его не закрыли сразу после подключения
+const portProbeWindow = 200 * time.Millisecond
+
+// WaitForPort ждет, пока сервис начнет принимать TCP соединения на
+// localhost:hostPort. Попытки подключения повторяются с экспоненциальной
+// задержкой до успеха, истечения timeout или отмены ctx. Нулевой timeout
+// ограничивает ожидание только ctx.
+//
+// Опубликованный порт слушает docker-proxy еще до запуска сервиса в
+// контейнере: он принимает соединение и тут же закрывает его. Поэтому
+// соединение считается установленным, только если в течение
+// portProbeWindow оно не закрылось: сервис прислал данные или молчит.
+// Сервис, который сам закрывает соединение сразу после подключения,
+// WaitForPort не дождется.
+func (d *DockerAdapter) WaitForPort(ctx context.Context, hostPort int, timeout time.Duration) (err error) {
+	ctx, span := tracing.Start(ctx, "docker", "wait_for_port", attribute.Int("host.port", hostPort))
+	defer func() { tracing.End(span, err) }()
+
+	if hostPort < 1 || hostPort > 65535 {
+		return errors.Errorf("некорректный порт %d", hostPort)
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	address := net.JoinHostPort("localhost", strconv.Itoa(hostPort))
+	var dialer net.Dialer
+	delay := portInitialBackoff
+	for {
+		conn, dialErr := dialer.DialContext(ctx, "tcp", address)
+		if dialErr == nil {
+			dialErr = probeConn(conn)
+			conn.Close()
+			if dialErr == nil {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(dialErr, "порт %d не начал принимать соединения", hostPort)
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, portMaxBackoff)
+	}
+}
+
+// probeConn читает из conn в течение portProbeWindow. Соединение живо,
+// если пришли данные или чтение не дождалось их до дедлайна.
+func probeConn(conn net.Conn) error {
+	if err := conn.SetReadDeadline(time.Now().Add(portProbeWindow)); err != nil {
+		return err
+	}
+	_, err := conn.Read(make([]byte, 1))
+	if err == nil {
+		return nil
+	}
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return nil
+	}
+	if err == io.EOF {
+		return errors.New("соединение закрыто сразу после подключения")
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+package docker
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// freePort возвращает свободный порт на localhost
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestWaitForPort(t *testing.T) {
+	port := freePort(t)
+	adapter := &DockerAdapter{}
+
+	// Сервис начинает слушать порт с задержкой
+	listening := make(chan net.Listener, 1)
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		if err != nil {
+			t.Errorf("ошибка открытия порта: %v", err)
+		}
+		listening <- listener
+	}()
+
+	start := time.Now()
+	require.NoError(t, adapter.WaitForPort(context.Background(), port, 5*time.Second))
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+
+	if listener := <-listening; listener != nil {
+		listener.Close()
+	}
+}
+
+func TestWaitForPortIgnoresClosedConnections(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+	adapter := &DockerAdapter{}
+
+	// Как docker-proxy до запуска сервиса: первые соединения закрываются
+	// сразу, затем сервис отвечает приветствием
+	go func() {
+		for accepted := 0; ; accepted++ {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if accepted >= 3 {
+				conn.Write([]byte("SSH-2.0\r\n"))
+			}
+			conn.Close()
+		}
+	}()
+
+	require.NoError(t, adapter.WaitForPort(context.Background(), port, 5*time.Second))
+
+	// Соединения, закрытые без данных, портом не считаются
+	err = adapter.WaitForPort(context.Background(), freeClosingPort(t), 500*time.Millisecond)
+	assert.ErrorContains(t, err, "закрыто")
+}
+
+// freeClosingPort открывает порт, который закрывает каждое соединение
+// сразу после подключения
+func freeClosingPort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestWaitForPortTimeout(t *testing.T) {
+	port := freePort(t)
+	adapter := &DockerAdapter{}
+
+	start := time.Now()
+	err := adapter.WaitForPort(context.Background(), port, 300*time.Millisecond)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestWaitForPortInvalid(t *testing.T) {
+	adapter := &DockerAdapter{}
+	assert.Error(t, adapter.WaitForPort(context.Background(), 0, time.Second))
+}
@@ -0,0 +1,61 @@
+package docker
+
+import (
+	"github.com/docker/docker/client"
+)
+
+// withReconnect выполняет op. Если daemon недоступен (например, был
+// перезапущен), клиент один раз пересоздается и op повторяется. Повтор
+// безопасен, только пока op отправляет daemon один изменяющий запрос
+// (перед ним допустимы чтения): при ошибке соединения этот запрос не дошел
+// до daemon. Многошаговые операции оборачивают каждый запрос отдельно,
+// иначе повтор выполнил бы уже сделанные шаги еще раз.
+func (d *DockerAdapter) withReconnect(op func() error) error {
+	failed := d.apiClient()
+	err := op()
+	if !client.IsErrConnectionFailed(err) || !d.reconnect(failed) {
+		return err
+	}
+	return op()
+}
+
+// withReconnectValue вариант withReconnect для операций, возвращающих результат
+func withReconnectValue[T any](d *DockerAdapter, op func() (T, error)) (T, error) {
+	var result T
+	err := d.withReconnect(func() error {
+		var err error
+		result, err = op()
+		return err
+	})
+	return result, err
+}
+
+// apiClient возвращает текущий клиент Docker API
+func (d *DockerAdapter) apiClient() *client.Client {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.client
+}
+
+// reconnect заменяет клиент failed новым. Если другой вызов уже заменил
+// клиент, новый не создается. Возвращает false, если переподключение
+// невозможно.
+func (d *DockerAdapter) reconnect(failed *client.Client) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.client != failed {
+		return true
+	}
+	if d.newClient == nil {
+		return false
+	}
+
+	cli, err := d.newClient()
+	if err != nil {
+		return false
+	}
+	failed.Close()
+	d.client = cli
+	return true
+}
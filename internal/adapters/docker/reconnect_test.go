@@ -0,0 +1,129 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unreachableClient возвращает клиент, указывающий на закрытый порт,
+// как после остановки daemon
+func unreachableClient(t *testing.T) *client.Client {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+addr), client.WithVersion("1.41"))
+	require.NoError(t, err)
+	return cli
+}
+
+func TestReconnectAfterDaemonRestart(t *testing.T) {
+	t.Run("клиент пересоздается и операция повторяется", func(t *testing.T) {
+		fake, restarted := newFakeDockerServer(t)
+		fake.addContainer(fakeContainer{Name: "web"})
+
+		created := 0
+		adapter := &DockerAdapter{
+			client: unreachableClient(t),
+			ctx:    context.Background(),
+			newClient: func() (*client.Client, error) {
+				created++
+				return restarted.client, nil
+			},
+		}
+
+		containers, err := adapter.ListContainers()
+		require.NoError(t, err)
+		assert.Len(t, containers, 1)
+		assert.Equal(t, 1, created)
+
+		// Следующие вызовы используют новый клиент без переподключения
+		_, err = adapter.ListContainers()
+		require.NoError(t, err)
+		assert.Equal(t, 1, created)
+	})
+
+	t.Run("поиск контейнера по имени переподключается", func(t *testing.T) {
+		fake, restarted := newFakeDockerServer(t)
+		web := fake.addContainer(fakeContainer{Name: "web"})
+
+		adapter := &DockerAdapter{
+			client: unreachableClient(t),
+			ctx:    context.Background(),
+			newClient: func() (*client.Client, error) {
+				return restarted.client, nil
+			},
+		}
+
+		got, err := adapter.GetContainerIDByName("web")
+		require.NoError(t, err)
+		assert.Equal(t, web.ID, got)
+	})
+
+	t.Run("без фабрики клиента ошибка возвращается", func(t *testing.T) {
+		adapter := &DockerAdapter{
+			client: unreachableClient(t),
+			ctx:    context.Background(),
+		}
+
+		_, err := adapter.ListContainers()
+		require.Error(t, err)
+		assert.True(t, client.IsErrConnectionFailed(err))
+	})
+}
+
+// dropRequestsTransport обрывает соединение на запросах, путь которых
+// оканчивается на suffix, как при остановке daemon посреди операции
+type dropRequestsTransport struct {
+	next   http.RoundTripper
+	suffix string
+}
+
+func (t *dropRequestsTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(r.URL.Path, t.suffix) {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	return t.next.RoundTrip(r)
+}
+
+func TestReconnectRetriesOnlyFailedStep(t *testing.T) {
+	fake, restarted := newFakeDockerServer(t)
+	fake.addImage("nginx:latest", fakeImage{})
+
+	// До перезапуска daemon создание проходит, а запуск обрывается
+	dropping, err := client.NewClientWithOpts(
+		client.WithHost(restarted.client.DaemonHost()),
+		client.WithHTTPClient(&http.Client{Transport: &dropRequestsTransport{next: http.DefaultTransport, suffix: "/start"}}),
+		client.WithVersion("1.41"),
+	)
+	require.NoError(t, err)
+
+	adapter := &DockerAdapter{
+		client: dropping,
+		ctx:    context.Background(),
+		newClient: func() (*client.Client, error) {
+			return restarted.client, nil
+		},
+	}
+
+	_, err = adapter.RunContainer(ContainerOptions{Name: "web", Image: "nginx:latest"})
+	require.NoError(t, err)
+
+	creates := 0
+	for _, request := range fake.requested() {
+		if request == "POST /containers/create" {
+			creates++
+		}
+	}
+	assert.Equal(t, 1, creates, "контейнер не должен создаваться повторно")
+	assert.Equal(t, "running", fake.container("web").State)
+}
@@ -1,14 +1,20 @@
 package docker
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/docker/docker/api/types"
+	"github.com/localops/devops-manager/internal/audit"
+	"github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 )
 
@@ -18,24 +24,145 @@ type RegistryConfig struct {
 	Username string
 	Password string
 	Insecure bool
+	// CatalogPageSize число репозиториев в одной странице /v2/_catalog,
+	// 0 - значение по умолчанию
+	CatalogPageSize int
+	// Headers дополнительные заголовки, добавляемые к каждому запросу
+	Headers map[string]string
+	// TokenProvider, если задан, вызывается перед каждым запросом и его
+	// результат передается как Authorization: Bearer вместо Basic
+	// аутентификации. Подходит для регулярно обновляемых токенов (ECR).
+	TokenProvider func() (string, error)
 }
 
+// defaultCatalogPageSize размер страницы каталога, если CatalogPageSize не задан
+const defaultCatalogPageSize = 100
+
+// maxCatalogPages ограничивает число страниц каталога в ListRepositories
+// на случай registry, чьи ссылки Link не заканчиваются
+const maxCatalogPages = 1000
+
 // RegistryAdapter предоставляет методы для работы с Docker Registry
 type RegistryAdapter struct {
 	config RegistryConfig
 	client *http.Client
+
+	mu          sync.RWMutex
+	auditLogger audit.Logger
 }
 
 // NewRegistryAdapter создает новый экземпляр RegistryAdapter
 func NewRegistryAdapter(config RegistryConfig) *RegistryAdapter {
+	client := &http.Client{}
+	if len(config.Headers) > 0 || config.TokenProvider != nil {
+		client.Transport = &registryTransport{
+			base:          http.DefaultTransport,
+			headers:       config.Headers,
+			tokenProvider: config.TokenProvider,
+		}
+	}
+
 	return &RegistryAdapter{
 		config: config,
-		client: &http.Client{},
+		client: client,
+	}
+}
+
+// SetAuditLogger задает журнал аудита изменяющих запросов к registry
+// (push, удаление и перенос тегов). nil отключает журнал.
+func (r *RegistryAdapter) SetAuditLogger(logger audit.Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.auditLogger = logger
+}
+
+// recordAudit записывает результат изменяющего запроса в журнал аудита.
+// Методы registry не принимают контекст, поэтому исполнителем
+// записывается пользователь ОС.
+func (r *RegistryAdapter) recordAudit(operation, target string, err error) {
+	r.mu.RLock()
+	logger := r.auditLogger
+	r.mu.RUnlock()
+
+	audit.Record(context.Background(), logger, "registry", operation, target, err)
+}
+
+// registryTransport добавляет к запросам в registry заголовки из
+// RegistryConfig.Headers и токен из RegistryConfig.TokenProvider
+type registryTransport struct {
+	base          http.RoundTripper
+	headers       map[string]string
+	tokenProvider func() (string, error)
+}
+
+func (t *registryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrip не должен менять исходный запрос
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+
+	if t.tokenProvider != nil {
+		token, err := t.tokenProvider()
+		if err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, errors.Wrap(err, "ошибка при получении токена registry")
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// Host возвращает адрес registry без схемы, в том виде, в каком он указывается в имени образа
+func (r *RegistryAdapter) Host() string {
+	host := strings.TrimPrefix(r.config.URL, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	return strings.TrimSuffix(host, "/")
+}
+
+// QualifyImage добавляет адрес registry к имени образа, если его там нет
+func (r *RegistryAdapter) QualifyImage(image string) string {
+	host := r.Host()
+	if host == "" || strings.HasPrefix(image, host+"/") {
+		return image
+	}
+	return host + "/" + image
+}
+
+// Owns сообщает, что образ image находится в этом registry
+func (r *RegistryAdapter) Owns(image string) bool {
+	host := r.Host()
+	return host != "" && strings.HasPrefix(image, host+"/")
+}
+
+// daemonAuth возвращает учетные данные registry для запросов к Docker
+// daemon: токен TokenProvider, а без него логин и пароль из конфигурации
+func (r *RegistryAdapter) daemonAuth() (types.AuthConfig, error) {
+	auth := types.AuthConfig{ServerAddress: r.Host()}
+	if r.config.TokenProvider != nil {
+		token, err := r.config.TokenProvider()
+		if err != nil {
+			return types.AuthConfig{}, errors.Wrap(err, "ошибка при получении токена registry")
+		}
+		auth.RegistryToken = token
+		return auth, nil
 	}
+	auth.Username = r.config.Username
+	auth.Password = r.config.Password
+	return auth, nil
 }
 
 // PushImage отправляет образ в registry
-func (r *RegistryAdapter) PushImage(image string, auth types.AuthConfig) error {
+//
+// Deprecated: метод отправляет только пустой манифест и не загружает слои,
+// поэтому образ фактически не публикуется. Используйте DockerAdapter.PushImageToRegistry,
+// который выполняет push через Docker daemon.
+func (r *RegistryAdapter) PushImage(image string, auth types.AuthConfig) (err error) {
+	defer func() { r.recordAudit("push_image", image, err) }()
+
 	// Подготавливаем URL для registry
 	registryURL := r.config.URL
 	if !strings.HasPrefix(registryURL, "http://") && !strings.HasPrefix(registryURL, "https://") {
@@ -143,8 +270,116 @@ func (r *RegistryAdapter) ListTags(image string) ([]string, error) {
 	return result.Tags, nil
 }
 
+// ListRepositories возвращает список репозиториев registry из /v2/_catalog.
+// Каталог запрашивается страницами по CatalogPageSize, следующая страница
+// берется из заголовка Link с rel="next". Повторная ссылка или больше
+// maxCatalogPages страниц считаются ошибкой registry.
+func (r *RegistryAdapter) ListRepositories() ([]string, error) {
+	// Подготавливаем URL для registry
+	registryURL := r.config.URL
+	if !strings.HasPrefix(registryURL, "http://") && !strings.HasPrefix(registryURL, "https://") {
+		registryURL = "https://" + registryURL
+	}
+	base, err := url.Parse(registryURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "некорректный адрес registry")
+	}
+
+	pageSize := r.config.CatalogPageSize
+	if pageSize <= 0 {
+		pageSize = defaultCatalogPageSize
+	}
+	next := fmt.Sprintf("%s/v2/_catalog?n=%d", strings.TrimSuffix(registryURL, "/"), pageSize)
+
+	var repositories []string
+	visited := make(map[string]bool)
+	for next != "" {
+		if visited[next] {
+			return nil, errors.Errorf("registry повторно вернул ссылку на страницу каталога %s", next)
+		}
+		if len(visited) == maxCatalogPages {
+			return nil, errors.Errorf("каталог registry содержит больше %d страниц", maxCatalogPages)
+		}
+		visited[next] = true
+
+		req, err := http.NewRequest("GET", next, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "ошибка при создании запроса на получение каталога")
+		}
+		if r.config.Username != "" {
+			req.SetBasicAuth(r.config.Username, r.config.Password)
+		}
+
+		page, link, err := r.catalogPage(req)
+		if err != nil {
+			return nil, err
+		}
+		repositories = append(repositories, page...)
+
+		next = ""
+		if link != "" {
+			ref, err := url.Parse(link)
+			if err != nil {
+				return nil, errors.Wrapf(err, "некорректная ссылка на следующую страницу каталога %q", link)
+			}
+			next = base.ResolveReference(ref).String()
+		}
+	}
+
+	return repositories, nil
+}
+
+// catalogPage выполняет запрос одной страницы каталога и возвращает
+// репозитории и ссылку на следующую страницу
+func (r *RegistryAdapter) catalogPage(req *http.Request) ([]string, string, error) {
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "ошибка при отправке запроса на получение каталога")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, "", errors.Errorf("ошибка при получении каталога: %s, статус: %d", string(body), resp.StatusCode)
+	}
+
+	var result struct {
+		Repositories []string `json:"repositories"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, "", errors.Wrap(err, "ошибка при декодировании ответа")
+	}
+
+	return result.Repositories, nextLink(resp.Header.Values("Link")), nil
+}
+
+// nextLink возвращает адрес из заголовка Link вида </v2/_catalog?last=a&n=2>; rel="next"
+func nextLink(headers []string) string {
+	for _, header := range headers {
+		for _, link := range strings.Split(header, ",") {
+			target, params, ok := strings.Cut(link, ";")
+			if !ok {
+				continue
+			}
+			target = strings.TrimSpace(target)
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if name == "rel" && strings.Trim(value, `"`) == "next" {
+					return target[1 : len(target)-1]
+				}
+			}
+		}
+	}
+	return ""
+}
+
 // DeleteTag удаляет тег из registry
-func (r *RegistryAdapter) DeleteTag(image string, tag string, auth types.AuthConfig) error {
+func (r *RegistryAdapter) DeleteTag(image string, tag string, auth types.AuthConfig) (err error) {
+	defer func() { r.recordAudit("delete_tag", image+":"+tag, err) }()
+
 	// Подготавливаем URL для registry
 	registryURL := r.config.URL
 	if !strings.HasPrefix(registryURL, "http://") && !strings.HasPrefix(registryURL, "https://") {
@@ -217,3 +452,90 @@ func (r *RegistryAdapter) GetImageDigest(image string, tag string, auth types.Au
 
 	return digest, nil
 }
+
+// manifestMediaTypes типы манифестов, которые принимает RetagRemote.
+// Списки манифестов и OCI индексы копируются как есть, поэтому
+// многоплатформенный образ остается многоплатформенным.
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// RetagRemote ставит образу тег dstTag в registry без скачивания образа:
+// манифест srcTag получается по digest и сохраняется под новым тегом.
+// Слои не копируются, так как остаются в том же репозитории.
+func (r *RegistryAdapter) RetagRemote(image, srcTag, dstTag string, auth types.AuthConfig) (err error) {
+	defer func() { r.recordAudit("retag_image", image+":"+srcTag+" -> "+dstTag, err) }()
+
+	// Подготавливаем URL для registry
+	registryURL := r.config.URL
+	if !strings.HasPrefix(registryURL, "http://") && !strings.HasPrefix(registryURL, "https://") {
+		registryURL = "https://" + registryURL
+	}
+	manifestURL := func(reference string) string {
+		return fmt.Sprintf("%s/v2/%s/manifests/%s", registryURL, image, reference)
+	}
+
+	authStr := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", auth.Username, auth.Password)))
+	send := func(method, url string, body io.Reader, header http.Header) (*http.Response, error) {
+		req, err := http.NewRequest(method, url, body)
+		if err != nil {
+			return nil, errors.Wrap(err, "ошибка при создании запроса манифеста")
+		}
+		req.Header = header
+		req.Header.Set("Authorization", "Basic "+authStr)
+		return r.client.Do(req)
+	}
+	accept := http.Header{"Accept": manifestMediaTypes}
+
+	// Определяем digest исходного тега
+	resp, err := send(http.MethodHead, manifestURL(srcTag), nil, accept.Clone())
+	if err != nil {
+		return errors.Wrap(err, "ошибка при получении digest манифеста")
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("ошибка при получении digest манифеста %s:%s, статус: %d", image, srcTag, resp.StatusCode)
+	}
+	dgst, err := digest.Parse(resp.Header.Get("Docker-Content-Digest"))
+	if err != nil {
+		return errors.Wrapf(err, "registry вернул некорректный digest для %s:%s", image, srcTag)
+	}
+
+	// Получаем манифест по digest, чтобы тег не сменился между запросами
+	resp, err = send(http.MethodGet, manifestURL(dgst.String()), nil, accept.Clone())
+	if err != nil {
+		return errors.Wrap(err, "ошибка при получении манифеста")
+	}
+	defer resp.Body.Close()
+	manifest, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "ошибка при чтении манифеста")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("ошибка при получении манифеста: %s, статус: %d", string(manifest), resp.StatusCode)
+	}
+	if dgst.Algorithm().FromBytes(manifest) != dgst {
+		return errors.Errorf("манифест %s не совпадает с digest %s", image, dgst)
+	}
+	mediaType := resp.Header.Get("Content-Type")
+	if mediaType == "" {
+		return errors.New("registry не вернул тип манифеста")
+	}
+
+	// Сохраняем тот же манифест под новым тегом
+	resp, err = send(http.MethodPut, manifestURL(dstTag), bytes.NewReader(manifest), http.Header{"Content-Type": {mediaType}})
+	if err != nil {
+		return errors.Wrap(err, "ошибка при сохранении манифеста")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("ошибка при сохранении тега %s: %s, статус: %d", dstTag, string(body), resp.StatusCode)
+	}
+
+	return nil
+}
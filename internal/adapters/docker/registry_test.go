@@ -0,0 +1,261 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/localops/devops-manager/internal/audit"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry хранит манифесты одного репозитория по тегам и digest
+type fakeRegistry struct {
+	mu         sync.Mutex
+	manifests  map[string][]byte
+	mediaTypes map[string]string
+	tags       map[string]digest.Digest
+	requests   []string
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, *RegistryAdapter) {
+	registry := &fakeRegistry{
+		manifests:  make(map[string][]byte),
+		mediaTypes: make(map[string]string),
+		tags:       make(map[string]digest.Digest),
+	}
+
+	server := httptest.NewServer(registry)
+	t.Cleanup(server.Close)
+
+	return registry, NewRegistryAdapter(RegistryConfig{URL: server.URL})
+}
+
+// addManifest публикует манифест под тегом
+func (f *fakeRegistry) addManifest(tag, mediaType string, manifest []byte) digest.Digest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dgst := digest.FromBytes(manifest)
+	f.manifests[dgst.String()] = manifest
+	f.mediaTypes[dgst.String()] = mediaType
+	f.tags[tag] = dgst
+	return dgst
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	const prefix = "/v2/team/app/manifests/"
+	reference, ok := strings.CutPrefix(r.URL.Path, prefix)
+	if !ok || reference == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead, http.MethodGet:
+		key := reference
+		if dgst, ok := f.tags[reference]; ok {
+			key = dgst.String()
+		}
+		manifest, ok := f.manifests[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", f.mediaTypes[key])
+		w.Header().Set("Docker-Content-Digest", key)
+		if r.Method == http.MethodGet {
+			w.Write(manifest)
+		}
+	case http.MethodPut:
+		manifest, _ := io.ReadAll(r.Body)
+		dgst := digest.FromBytes(manifest)
+		f.manifests[dgst.String()] = manifest
+		f.mediaTypes[dgst.String()] = r.Header.Get("Content-Type")
+		f.tags[reference] = dgst
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestRetagRemote(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		manifest  string
+	}{
+		{
+			name:      "манифест образа",
+			mediaType: "application/vnd.docker.distribution.manifest.v2+json",
+			manifest:  `{"schemaVersion":2,"config":{"digest":"sha256:aaa"},"layers":[]}`,
+		},
+		{
+			name:      "список манифестов",
+			mediaType: "application/vnd.docker.distribution.manifest.list.v2+json",
+			manifest:  `{"schemaVersion":2,"manifests":[{"digest":"sha256:amd"},{"digest":"sha256:arm"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, adapter := newFakeRegistry(t)
+			srcDigest := registry.addManifest("1.0", tt.mediaType, []byte(tt.manifest))
+
+			require.NoError(t, adapter.RetagRemote("team/app", "1.0", "stable", types.AuthConfig{}))
+
+			// Новый тег указывает на тот же манифест того же типа
+			assert.Equal(t, srcDigest, registry.tags["stable"])
+			assert.Equal(t, tt.manifest, string(registry.manifests[registry.tags["stable"].String()]))
+			assert.Equal(t, tt.mediaType, registry.mediaTypes[registry.tags["stable"].String()])
+
+			assert.Equal(t, []string{
+				"HEAD /v2/team/app/manifests/1.0",
+				"GET /v2/team/app/manifests/" + srcDigest.String(),
+				"PUT /v2/team/app/manifests/stable",
+			}, registry.requests)
+		})
+	}
+}
+
+func TestRetagRemoteMissingTag(t *testing.T) {
+	registry, adapter := newFakeRegistry(t)
+
+	err := adapter.RetagRemote("team/app", "missing", "stable", types.AuthConfig{})
+	require.Error(t, err)
+	assert.Empty(t, registry.tags)
+}
+
+func TestRetagRemoteAudit(t *testing.T) {
+	registry, adapter := newFakeRegistry(t)
+	registry.addManifest("1.0", "application/vnd.docker.distribution.manifest.v2+json", []byte(`{"schemaVersion":2}`))
+
+	var buf bytes.Buffer
+	adapter.SetAuditLogger(audit.NewJSONLogger(&buf))
+
+	require.NoError(t, adapter.RetagRemote("team/app", "1.0", "stable", types.AuthConfig{}))
+	require.Error(t, adapter.RetagRemote("team/app", "missing", "stable", types.AuthConfig{}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var entry audit.Entry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "registry", entry.Component)
+	assert.Equal(t, "retag_image", entry.Operation)
+	assert.Equal(t, "team/app:1.0 -> stable", entry.Target)
+	assert.Equal(t, "success", entry.Result)
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "error", entry.Result)
+	assert.NotEmpty(t, entry.Error)
+}
+
+func TestListRepositories(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/_catalog" {
+			http.NotFound(w, r)
+			return
+		}
+		requests = append(requests, r.URL.RawQuery)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("last") {
+		case "":
+			w.Header().Set("Link", `</v2/_catalog?last=team%2Fapp&n=2>; rel="next"`)
+			io.WriteString(w, `{"repositories":["team/api","team/app"]}`)
+		case "team/app":
+			io.WriteString(w, `{"repositories":["team/worker"]}`)
+		default:
+			http.Error(w, "unexpected page", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	adapter := NewRegistryAdapter(RegistryConfig{URL: server.URL, CatalogPageSize: 2})
+
+	repositories, err := adapter.ListRepositories()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team/api", "team/app", "team/worker"}, repositories)
+	assert.Equal(t, []string{"n=2", "last=team%2Fapp&n=2"}, requests)
+}
+
+func TestListRepositoriesRepeatedLink(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// Registry с ошибкой всегда ссылается на одну и ту же страницу
+		w.Header().Set("Link", `</v2/_catalog?last=team%2Fapp&n=2>; rel="next"`)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"repositories":["team/app"]}`)
+	}))
+	defer server.Close()
+
+	adapter := NewRegistryAdapter(RegistryConfig{URL: server.URL, CatalogPageSize: 2})
+	_, err := adapter.ListRepositories()
+	assert.ErrorContains(t, err, "повторно")
+	assert.Equal(t, 2, requests)
+}
+
+func TestRegistryHeadersAndTokenProvider(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Header.Get("X-Registry-Region")+" "+r.Header.Get("Authorization"))
+		io.WriteString(w, `{"name":"team/app","tags":["1.0"]}`)
+	}))
+	defer server.Close()
+
+	var issued int
+	adapter := NewRegistryAdapter(RegistryConfig{
+		URL:     server.URL,
+		Headers: map[string]string{"X-Registry-Region": "eu-west-1"},
+		TokenProvider: func() (string, error) {
+			issued++
+			return "token-" + strconv.Itoa(issued), nil
+		},
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := adapter.ListTags("team/app")
+		require.NoError(t, err)
+	}
+
+	// Токен запрашивается заново для каждого запроса
+	assert.Equal(t, []string{
+		"eu-west-1 Bearer token-1",
+		"eu-west-1 Bearer token-2",
+	}, requests)
+}
+
+func TestRegistryTokenProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("неожиданный запрос %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	adapter := NewRegistryAdapter(RegistryConfig{
+		URL:           server.URL,
+		TokenProvider: func() (string, error) { return "", errors.New("token expired") },
+	})
+
+	_, err := adapter.ListTags("team/app")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token expired")
+}
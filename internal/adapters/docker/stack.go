@@ -0,0 +1,190 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/localops/devops-manager/internal/tracing"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"sigs.k8s.io/yaml"
+)
+
+// StackSpec описывает набор связанных контейнеров с общей сетью
+type StackSpec struct {
+	Name     string             `json:"name"`
+	Network  string             `json:"network"`
+	Services []ContainerOptions `json:"services"`
+}
+
+// LoadStackSpec читает описание стека из YAML файла
+func LoadStackSpec(path string) (*StackSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "ошибка при чтении описания стека")
+	}
+
+	var spec StackSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, errors.Wrap(err, "ошибка при разборе описания стека")
+	}
+
+	return &spec, nil
+}
+
+// networkName возвращает имя сети стека
+func (s StackSpec) networkName() string {
+	if s.Network != "" {
+		return s.Network
+	}
+	return s.Name + "_default"
+}
+
+// validate проверяет, что стек можно запустить
+func (s StackSpec) validate() error {
+	if s.Name == "" && s.Network == "" {
+		return errors.New("не указано имя стека или сети")
+	}
+	if len(s.Services) == 0 {
+		return errors.New("в стеке нет ни одного сервиса")
+	}
+	return nil
+}
+
+// StackPlan описывает ресурсы, которые создаст RunStack, и конфликты
+// с уже существующими ресурсами
+type StackPlan struct {
+	Network string
+	// NetworkExists сеть с именем Network уже существует
+	NetworkExists bool
+	Containers    []PlannedContainer
+	// Conflicts описания конфликтов; при непустом списке RunStack
+	// завершится ошибкой или создаст вторую сеть с тем же именем
+	Conflicts []string
+}
+
+// PlannedContainer описывает контейнер, который будет создан для сервиса
+type PlannedContainer struct {
+	Name  string
+	Image string
+	// Exists контейнер с таким именем уже существует
+	Exists bool
+}
+
+// PlanStack проверяет, какие сеть и контейнеры создаст RunStack для spec,
+// и сообщает о совпадении имен с существующими контейнерами и сетями.
+// Ничего не создает и не изменяет.
+func (d *DockerAdapter) PlanStack(spec StackSpec) (plan *StackPlan, err error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, span := tracing.Start(d.ctx, "docker", "plan_stack", attribute.String("stack.name", spec.Name))
+	start := time.Now()
+	err = d.withReconnect(func() error {
+		plan, err = d.planStack(ctx, spec)
+		return err
+	})
+	duration := time.Since(start)
+	tracing.End(span, err)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if d.monitoring != nil {
+		d.monitoring.RecordDockerOperation("plan_stack", status, duration)
+		d.monitoring.RecordError("docker", "plan_stack", classifyError(err))
+	}
+
+	return plan, err
+}
+
+// planStack сопоставляет ресурсы стека с существующими по именам
+func (d *DockerAdapter) planStack(ctx context.Context, spec StackSpec) (*StackPlan, error) {
+	containers, err := withReconnectValue(d, func() ([]types.Container, error) {
+		return d.apiClient().ContainerList(ctx, types.ContainerListOptions{All: true})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "ошибка при получении списка контейнеров")
+	}
+	networks, err := withReconnectValue(d, func() ([]types.NetworkResource, error) {
+		return d.apiClient().NetworkList(ctx, types.NetworkListOptions{})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "ошибка при получении списка сетей")
+	}
+
+	existing := make(map[string]bool)
+	for _, c := range containers {
+		for _, name := range c.Names {
+			existing[strings.TrimPrefix(name, "/")] = true
+		}
+	}
+
+	plan := &StackPlan{Network: spec.networkName()}
+	for _, n := range networks {
+		if n.Name == plan.Network {
+			plan.NetworkExists = true
+			plan.Conflicts = append(plan.Conflicts, fmt.Sprintf("сеть %s уже существует", plan.Network))
+			break
+		}
+	}
+
+	planned := make(map[string]bool)
+	for _, service := range spec.Services {
+		container := PlannedContainer{Name: service.Name, Image: service.Image, Exists: existing[service.Name]}
+		switch {
+		case container.Exists:
+			plan.Conflicts = append(plan.Conflicts, fmt.Sprintf("контейнер %s уже существует", service.Name))
+		case service.Name != "" && planned[service.Name]:
+			plan.Conflicts = append(plan.Conflicts, fmt.Sprintf("имя %s указано у нескольких сервисов", service.Name))
+		}
+		planned[service.Name] = true
+		plan.Containers = append(plan.Containers, container)
+	}
+
+	return plan, nil
+}
+
+// RunStack создает сеть и запускает в ней все контейнеры стека.
+// Если какой-либо контейнер не удалось запустить, уже созданные контейнеры и сеть удаляются
+func (d *DockerAdapter) RunStack(spec StackSpec) ([]ContainerInfo, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+
+	networkName := spec.networkName()
+	networkID, err := d.CreateNetwork(networkName, "bridge", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var started []ContainerInfo
+	for _, service := range spec.Services {
+		service.Network = networkName
+
+		info, err := d.RunContainer(service)
+		if err != nil {
+			d.teardownStack(started, networkID)
+			return nil, errors.Wrapf(err, "ошибка при запуске сервиса %s", service.Name)
+		}
+		started = append(started, *info)
+	}
+
+	return started, nil
+}
+
+// teardownStack удаляет контейнеры и сеть частично запущенного стека
+func (d *DockerAdapter) teardownStack(containers []ContainerInfo, networkID string) {
+	for _, c := range containers {
+		_ = d.RemoveContainer(c.ID)
+	}
+	err := d.withReconnect(func() error { return d.apiClient().NetworkRemove(d.ctx, networkID) })
+	d.recordAudit(d.ctx, "remove_network", networkID, err)
+}
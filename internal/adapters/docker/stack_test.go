@@ -0,0 +1,194 @@
+package docker
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stackServer имитирует Docker API для тестов стека
+type stackServer struct {
+	t        *testing.T
+	failName string
+
+	mu       sync.Mutex
+	created  map[string]string
+	removed  []string
+	networks []string
+}
+
+func (s *stackServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1.41")
+	switch {
+	case r.Method == http.MethodPost && path == "/networks/create":
+		var req types.NetworkCreateRequest
+		require.NoError(s.t, json.NewDecoder(r.Body).Decode(&req))
+		s.networks = append(s.networks, req.Name)
+		json.NewEncoder(w).Encode(types.NetworkCreateResponse{ID: "net-id"})
+	case r.Method == http.MethodDelete && path == "/networks/net-id":
+		s.networks = nil
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && path == "/containers/create":
+		name := r.URL.Query().Get("name")
+		if name == s.failName {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"message": "No such image"})
+			return
+		}
+
+		var body struct {
+			container.Config
+			HostConfig       *container.HostConfig
+			NetworkingConfig *network.NetworkingConfig
+		}
+		require.NoError(s.t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(s.t, "app_net", string(body.HostConfig.NetworkMode))
+		require.Contains(s.t, body.NetworkingConfig.EndpointsConfig, "app_net")
+		assert.Equal(s.t, []string{name}, body.NetworkingConfig.EndpointsConfig["app_net"].Aliases)
+
+		s.created[name+"-id"] = name
+		json.NewEncoder(w).Encode(container.ContainerCreateCreatedBody{ID: name + "-id"})
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/start"):
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && strings.HasSuffix(path, "/json"):
+		id := strings.TrimSuffix(strings.TrimPrefix(path, "/containers/"), "/json")
+		json.NewEncoder(w).Encode(types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				ID:      id,
+				Name:    "/" + s.created[id],
+				Created: time.Now().Format(time.RFC3339Nano),
+				State:   &types.ContainerState{Status: "running"},
+			},
+			Config: &container.Config{Image: "test-image"},
+		})
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "/containers/"):
+		s.removed = append(s.removed, strings.TrimPrefix(path, "/containers/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s.t.Errorf("неожиданный запрос %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestRunStack(t *testing.T) {
+	spec := StackSpec{
+		Name:    "app",
+		Network: "app_net",
+		Services: []ContainerOptions{
+			{Name: "db", Image: "postgres"},
+			{Name: "web", Image: "nginx"},
+		},
+	}
+
+	t.Run("оба сервиса запущены в общей сети", func(t *testing.T) {
+		fake := &stackServer{t: t, created: map[string]string{}}
+		server, adapter := setupTestServer(t, fake)
+		defer server.Close()
+
+		containers, err := adapter.RunStack(spec)
+		require.NoError(t, err)
+		require.Len(t, containers, 2)
+		assert.Equal(t, "db-id", containers[0].ID)
+		assert.Equal(t, "web-id", containers[1].ID)
+		assert.Equal(t, []string{"app_net"}, fake.networks)
+		assert.Empty(t, fake.removed)
+	})
+
+	t.Run("ошибка второго сервиса откатывает первый", func(t *testing.T) {
+		fake := &stackServer{t: t, created: map[string]string{}, failName: "web"}
+		server, adapter := setupTestServer(t, fake)
+		defer server.Close()
+
+		containers, err := adapter.RunStack(spec)
+		assert.Error(t, err)
+		assert.Nil(t, containers)
+		assert.Equal(t, []string{"db-id"}, fake.removed)
+		assert.Empty(t, fake.networks)
+	})
+}
+
+func TestLoadStackSpec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stack.yaml")
+	content := `name: app
+network: app_net
+services:
+  - name: db
+    image: postgres:16
+    environment:
+      POSTGRES_PASSWORD: secret
+  - name: web
+    image: nginx
+    ports:
+      "80": "8080"
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	spec, err := LoadStackSpec(path)
+	require.NoError(t, err)
+	assert.Equal(t, "app", spec.Name)
+	require.Len(t, spec.Services, 2)
+	assert.Equal(t, "postgres:16", spec.Services[0].Image)
+	assert.Equal(t, "secret", spec.Services[0].Environment["POSTGRES_PASSWORD"])
+	assert.Equal(t, "8080", spec.Services[1].Ports["80"])
+}
+
+func TestPlanStack(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	fake.addContainer(fakeContainer{Name: "web", State: "exited"})
+	fake.addContainer(fakeContainer{Name: "cache"})
+
+	plan, err := adapter.PlanStack(StackSpec{
+		Name: "app",
+		Services: []ContainerOptions{
+			{Name: "db", Image: "postgres"},
+			{Name: "web", Image: "nginx"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "app_default", plan.Network)
+	assert.False(t, plan.NetworkExists)
+	assert.Equal(t, []PlannedContainer{
+		{Name: "db", Image: "postgres"},
+		{Name: "web", Image: "nginx", Exists: true},
+	}, plan.Containers)
+	assert.Equal(t, []string{"контейнер web уже существует"}, plan.Conflicts)
+
+	// План только читает состояние
+	assert.Equal(t, []string{"GET /containers/json", "GET /networks"}, fake.requested())
+}
+
+func TestPlanStackNetworkAndDuplicateNames(t *testing.T) {
+	fake, adapter := newFakeDockerServer(t)
+	fake.addNetwork(fakeNetwork{Name: "app_net"})
+
+	plan, err := adapter.PlanStack(StackSpec{
+		Name:    "app",
+		Network: "app_net",
+		Services: []ContainerOptions{
+			{Name: "worker", Image: "app"},
+			{Name: "worker", Image: "app"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, plan.NetworkExists)
+	assert.Equal(t, []string{
+		"сеть app_net уже существует",
+		"имя worker указано у нескольких сервисов",
+	}, plan.Conflicts)
+}
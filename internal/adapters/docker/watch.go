@@ -0,0 +1,167 @@
+package docker
+
+import (
+	"context"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/localops/devops-manager/internal/tracing"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrMaxRestartsExceeded возвращается, когда контейнер падает чаще,
+// чем разрешено политикой перезапуска
+var ErrMaxRestartsExceeded = errors.New("превышено максимальное число перезапусков")
+
+// RestartPolicyConfig задает политику перезапуска для WatchAndRestart
+type RestartPolicyConfig struct {
+	// MaxRetries максимальное число перезапусков подряд (по умолчанию 5)
+	MaxRetries int
+	// InitialBackoff задержка перед первым перезапуском (по умолчанию 1s)
+	InitialBackoff time.Duration
+	// MaxBackoff верхняя граница задержки (по умолчанию 1m)
+	MaxBackoff time.Duration
+	// StableAfter время работы, после которого счетчик перезапусков
+	// сбрасывается (по умолчанию 5m)
+	StableAfter time.Duration
+	// OnRestart вызывается после каждой попытки перезапуска
+	OnRestart func(containerID string, attempt int, err error)
+}
+
+// withDefaults заполняет незаданные поля политики значениями по умолчанию
+func (p RestartPolicyConfig) withDefaults() RestartPolicyConfig {
+	if p.MaxRetries <= 0 {
+		p.MaxRetries = 5
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = time.Second
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = time.Minute
+	}
+	if p.StableAfter <= 0 {
+		p.StableAfter = 5 * time.Minute
+	}
+	return p
+}
+
+// backoff возвращает задержку перед попыткой attempt (начиная с 1)
+func (p RestartPolicyConfig) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// WatchAndRestart следит за событиями контейнера и перезапускает его
+// после падения с экспоненциальной задержкой. Функция блокируется до
+// отмены ctx, исчерпания лимита перезапусков или ручной остановки
+// контейнера; в последнем случае возвращается nil.
+func (d *DockerAdapter) WatchAndRestart(ctx context.Context, containerID string, policy RestartPolicyConfig) error {
+	policy = policy.withDefaults()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, errs := d.apiClient().Events(ctx, types.EventsOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("type", "container"),
+			filters.Arg("container", containerID),
+			filters.Arg("event", "die"),
+			filters.Arg("event", "stop"),
+			filters.Arg("event", "destroy"),
+		),
+	})
+
+	var (
+		attempt     int
+		lastRestart time.Time
+		pending     <-chan time.Time
+	)
+
+	// scheduleRestart планирует следующую попытку или сообщает, что лимит исчерпан
+	scheduleRestart := func() error {
+		attempt++
+		if attempt > policy.MaxRetries {
+			if policy.OnRestart != nil {
+				policy.OnRestart(containerID, attempt, ErrMaxRestartsExceeded)
+			}
+			return errors.Wrapf(ErrMaxRestartsExceeded, "контейнер %s", containerID)
+		}
+		pending = time.After(policy.backoff(attempt))
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case err := <-errs:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return errors.Wrap(err, "ошибка чтения событий Docker")
+
+		case msg := <-events:
+			switch msg.Action {
+			case "die":
+				if pending != nil {
+					continue
+				}
+				// Контейнер проработал достаточно долго, начинаем отсчет заново
+				if !lastRestart.IsZero() && time.Since(lastRestart) > policy.StableAfter {
+					attempt = 0
+				}
+				if err := scheduleRestart(); err != nil {
+					return err
+				}
+
+			case "stop", "destroy":
+				// docker stop выдает die, а затем stop: это не падение
+				return nil
+			}
+
+		case <-pending:
+			pending = nil
+
+			spanCtx, span := tracing.Start(ctx, "docker", "auto_restart_container",
+				attribute.String("container.id", containerID), attribute.Int("attempt", attempt))
+			start := time.Now()
+			err := d.withReconnect(func() error {
+				return d.apiClient().ContainerStart(spanCtx, containerID, types.ContainerStartOptions{})
+			})
+			duration := time.Since(start)
+			tracing.End(span, err)
+			lastRestart = time.Now()
+
+			status := "success"
+			if err != nil {
+				status = "error"
+			}
+
+			if d.monitoring != nil {
+				d.monitoring.RecordDockerOperation("auto_restart_container", status, duration)
+				d.monitoring.RecordError("docker", "auto_restart_container", classifyError(err))
+			}
+			d.recordAudit(ctx, "auto_restart_container", containerID, err)
+
+			if policy.OnRestart != nil {
+				policy.OnRestart(containerID, attempt, err)
+			}
+
+			// Неудачный запуск не порождает новое событие die, повторяем сами
+			if err != nil {
+				if err := scheduleRestart(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventsServer отдает заданные события и записывает запуски контейнера
+type eventsServer struct {
+	t      *testing.T
+	events []events.Message
+
+	mu     sync.Mutex
+	starts int
+}
+
+func (s *eventsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/v1.41/events":
+		filters := r.URL.Query().Get("filters")
+		assert.Contains(s.t, filters, "test-container")
+		assert.Contains(s.t, filters, "die")
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		for _, msg := range s.events {
+			encoder.Encode(msg)
+		}
+		w.(http.Flusher).Flush()
+
+		// Держим поток открытым, как это делает Docker
+		<-r.Context().Done()
+	case "/v1.41/containers/test-container/start":
+		assert.Equal(s.t, http.MethodPost, r.Method)
+		s.mu.Lock()
+		s.starts++
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s.t.Errorf("неожиданный запрос %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestWatchAndRestart(t *testing.T) {
+	handler := &eventsServer{
+		t: t,
+		events: []events.Message{
+			{Type: "container", Action: "die", Actor: events.Actor{ID: "test-container"}},
+		},
+	}
+	server, adapter := setupTestServer(t, handler)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var attempts []int
+	err := adapter.WatchAndRestart(ctx, "test-container", RestartPolicyConfig{
+		MaxRetries:     3,
+		InitialBackoff: 10 * time.Millisecond,
+		OnRestart: func(containerID string, attempt int, err error) {
+			assert.Equal(t, "test-container", containerID)
+			assert.NoError(t, err)
+			attempts = append(attempts, attempt)
+			cancel()
+		},
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []int{1}, attempts)
+	assert.Equal(t, 1, handler.starts)
+}
+
+func TestWatchAndRestartStopsOnManualStop(t *testing.T) {
+	handler := &eventsServer{
+		t: t,
+		events: []events.Message{
+			{Type: "container", Action: "die", Actor: events.Actor{ID: "test-container"}},
+			{Type: "container", Action: "stop", Actor: events.Actor{ID: "test-container"}},
+		},
+	}
+	server, adapter := setupTestServer(t, handler)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := adapter.WatchAndRestart(ctx, "test-container", RestartPolicyConfig{
+		InitialBackoff: time.Second,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, handler.starts)
+}
+
+func TestRestartPolicyBackoff(t *testing.T) {
+	policy := RestartPolicyConfig{
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+	}.withDefaults()
+
+	assert.Equal(t, time.Second, policy.backoff(1))
+	assert.Equal(t, 2*time.Second, policy.backoff(2))
+	assert.Equal(t, 4*time.Second, policy.backoff(3))
+	assert.Equal(t, 5*time.Second, policy.backoff(4))
+	assert.Equal(t, 5, policy.MaxRetries)
+}
@@ -0,0 +1,292 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// AppliedResource описывает результат применения одного ресурса
+type AppliedResource struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Source    string
+	Action    string // created или updated
+	Error     error
+}
+
+// kindOrder задает порядок применения ресурсов: сначала то,
+// от чего зависят остальные объекты
+var kindOrder = map[string]int{
+	"Namespace":                0,
+	"CustomResourceDefinition": 1,
+	"PriorityClass":            2,
+	"StorageClass":             2,
+	"ClusterRole":              2,
+	"ClusterRoleBinding":       2,
+	"ServiceAccount":           3,
+	"Role":                     3,
+	"RoleBinding":              3,
+	"Secret":                   4,
+	"ConfigMap":                4,
+	"PersistentVolume":         4,
+	"PersistentVolumeClaim":    4,
+	"Service":                  5,
+}
+
+// kindWeight возвращает приоритет вида ресурса; неизвестные виды
+// (рабочие нагрузки и custom resources) применяются последними
+func kindWeight(kind string) int {
+	if weight, ok := kindOrder[kind]; ok {
+		return weight
+	}
+	return len(kindOrder)
+}
+
+// templateVarPattern находит подстановки вида ${VAR}
+var templateVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ApplyManifestTemplate подставляет переменные ${VAR} в манифест и
+// применяет результат. Неизвестная переменная считается ошибкой.
+func (k *K8sAdapter) ApplyManifestTemplate(manifestPath string, vars map[string]string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "apply_manifest_template", attribute.String("manifest.path", manifestPath))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "apply_manifest_template", manifestPath, err)
+	}()
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("ошибка при чтении манифеста: %w", err)
+	}
+
+	rendered, err := renderManifest(data, vars)
+	if err != nil {
+		return err
+	}
+
+	return k.applyManifestData(ctx, rendered)
+}
+
+// renderManifest выполняет подстановку переменных и собирает все
+// неразрешенные имена в одну ошибку
+func renderManifest(data []byte, vars map[string]string) ([]byte, error) {
+	var missing []string
+	seen := make(map[string]bool)
+
+	rendered := templateVarPattern.ReplaceAllFunc(data, func(match []byte) []byte {
+		name := string(templateVarPattern.FindSubmatch(match)[1])
+		value, ok := vars[name]
+		if !ok {
+			if !seen[name] {
+				seen[name] = true
+				missing = append(missing, name)
+			}
+			return match
+		}
+		return []byte(value)
+	})
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("не заданы переменные шаблона: %s", strings.Join(missing, ", "))
+	}
+
+	return rendered, nil
+}
+
+// manifestObject связывает объект с файлом, из которого он прочитан
+type manifestObject struct {
+	obj    *unstructured.Unstructured
+	source string
+}
+
+// SetContinueOnError задает поведение ApplyDirectory при ошибке:
+// продолжить применение остальных ресурсов или остановиться
+func (k *K8sAdapter) SetContinueOnError(continueOnError bool) {
+	k.continueOnError = continueOnError
+}
+
+// ApplyDirectory применяет все YAML манифесты из директории.
+// Namespace и CRD применяются раньше зависящих от них ресурсов.
+func (k *K8sAdapter) ApplyDirectory(dir string) (_ []AppliedResource, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "apply_directory", attribute.String("dir", dir))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "apply_directory", dir, err)
+	}()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении директории: %w", err)
+	}
+
+	var objects []manifestObject
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении манифеста %s: %w", path, err)
+		}
+
+		decoded, err := decodeManifest(data)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка в манифесте %s: %w", path, err)
+		}
+		for _, obj := range decoded {
+			objects = append(objects, manifestObject{obj: obj, source: path})
+		}
+	}
+
+	// Стабильная сортировка сохраняет порядок файлов внутри одного вида
+	sort.SliceStable(objects, func(i, j int) bool {
+		return kindWeight(objects[i].obj.GetKind()) < kindWeight(objects[j].obj.GetKind())
+	})
+
+	// Общий дедлайн на применение всей директории
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	var mapper meta.RESTMapper
+	var results []AppliedResource
+	var failed int
+	for _, item := range objects {
+		if mapper == nil {
+			if mapper, err = k.newRESTMapper(); err != nil {
+				return results, err
+			}
+		}
+
+		result, err := k.applyObject(ctx, mapper, item.obj)
+		result.Source = item.source
+		if err != nil {
+			result.Error = err
+			results = append(results, result)
+			if !k.continueOnError {
+				return results, err
+			}
+			failed++
+			continue
+		}
+		results = append(results, result)
+
+		// Новый CRD добавляет типы, которых еще нет в mapper
+		if item.obj.GetKind() == "CustomResourceDefinition" {
+			mapper = nil
+		}
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("не удалось применить %d из %d ресурсов", failed, len(objects))
+	}
+
+	return results, nil
+}
+
+// decodeManifest разбирает многодокументный YAML на отдельные объекты
+func decodeManifest(data []byte) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var raw runtime.RawExtension
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("ошибка при разборе YAML: %w", err)
+		}
+		// Пустые документы между разделителями пропускаем
+		raw.Raw = bytes.TrimSpace(raw.Raw)
+		if len(raw.Raw) == 0 || bytes.Equal(raw.Raw, []byte("null")) {
+			continue
+		}
+
+		// UnmarshalJSON сохраняет целые числа как int64
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw.Raw); err != nil {
+			return nil, fmt.Errorf("ошибка при разборе YAML: %w", err)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// newRESTMapper строит RESTMapper по ресурсам, которые отдает API сервер
+func (k *K8sAdapter) newRESTMapper() (meta.RESTMapper, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(k.clientset.Discovery())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении API групп: %w", err)
+	}
+	return restmapper.NewDiscoveryRESTMapper(groupResources), nil
+}
+
+// resourceFor возвращает dynamic клиент для ресурса obj. Namespaced
+// ресурсам без namespace проставляется default.
+func (k *K8sAdapter) resourceFor(mapper meta.RESTMapper, obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении mapping: %w", err)
+	}
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace && obj.GetNamespace() == "" {
+		obj.SetNamespace("default")
+	}
+
+	return k.dynamic.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+}
+
+// applyObject создает ресурс или обновляет существующий
+func (k *K8sAdapter) applyObject(ctx context.Context, mapper meta.RESTMapper, obj *unstructured.Unstructured) (AppliedResource, error) {
+	result := AppliedResource{
+		Kind: obj.GetKind(),
+		Name: obj.GetName(),
+	}
+
+	dynamicResource, err := k.resourceFor(mapper, obj)
+	if err != nil {
+		return result, err
+	}
+	result.Namespace = obj.GetNamespace()
+
+	existing, err := dynamicResource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return result, fmt.Errorf("ошибка при получении ресурса %s: %w", obj.GetName(), err)
+		}
+		if _, err := dynamicResource.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+			return result, fmt.Errorf("ошибка при создании ресурса %s: %w", obj.GetName(), err)
+		}
+		result.Action = "created"
+		return result, nil
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	if _, err := dynamicResource.Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return result, fmt.Errorf("ошибка при обновлении ресурса %s: %w", obj.GetName(), err)
+	}
+	result.Action = "updated"
+	return result, nil
+}
@@ -0,0 +1,203 @@
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeApplyAdapter создает адаптер на fake клиентах, которые знают
+// о Namespace, ConfigMap и Deployment
+func newFakeApplyAdapter(t *testing.T) (*K8sAdapter, *dynamicfake.FakeDynamicClient) {
+	clientset := fake.NewSimpleClientset()
+	discovery, ok := clientset.Discovery().(*fakediscovery.FakeDiscovery)
+	require.True(t, ok)
+	discovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "namespaces", Kind: "Namespace", Namespaced: false},
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true},
+			},
+		},
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	return &K8sAdapter{
+		clientset: clientset,
+		dynamic:   dynamicClient,
+		ctx:       context.Background(),
+	}, dynamicClient
+}
+
+func TestApplyDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	// Файл с ConfigMap идет первым по алфавиту
+	configMap := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+  namespace: app
+data:
+  key: value
+`
+	namespace := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: app
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a-configmap.yaml"), []byte(configMap), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b-namespace.yml"), []byte(namespace), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("не манифест"), 0644))
+
+	adapter, dynamicClient := newFakeApplyAdapter(t)
+
+	results, err := adapter.ApplyDirectory(dir)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "Namespace", results[0].Kind)
+	assert.Equal(t, "ConfigMap", results[1].Kind)
+	assert.Equal(t, "app", results[1].Namespace)
+	assert.Equal(t, "created", results[1].Action)
+
+	var created []string
+	for _, action := range dynamicClient.Actions() {
+		if create, ok := action.(k8stesting.CreateAction); ok {
+			created = append(created, create.GetResource().Resource)
+		}
+	}
+	assert.Equal(t, []string{"namespaces", "configmaps"}, created)
+}
+
+func TestApplyDirectoryContinueOnError(t *testing.T) {
+	dir := t.TempDir()
+
+	widgets := `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: first
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: second
+`
+	namespace := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: app
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "resources.yaml"), []byte(widgets+"---\n"+namespace), 0644))
+
+	tests := []struct {
+		name            string
+		continueOnError bool
+		wantResults     int
+	}{
+		{
+			name:            "остановка на первой ошибке",
+			continueOnError: false,
+			wantResults:     2,
+		},
+		{
+			name:            "продолжение после ошибки",
+			continueOnError: true,
+			wantResults:     3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, _ := newFakeApplyAdapter(t)
+			adapter.SetContinueOnError(tt.continueOnError)
+
+			// Widget неизвестен API серверу: такие ресурсы применяются последними и падают
+			results, err := adapter.ApplyDirectory(dir)
+			assert.Error(t, err)
+			require.Len(t, results, tt.wantResults)
+			assert.NoError(t, results[0].Error)
+			assert.Error(t, results[1].Error)
+		})
+	}
+}
+
+const deploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ${APP}
+  namespace: default
+spec:
+  replicas: ${REPLICAS}
+  selector:
+    matchLabels:
+      app: ${APP}
+  template:
+    metadata:
+      labels:
+        app: ${APP}
+    spec:
+      containers:
+      - name: ${APP}
+        image: nginx:${TAG}
+`
+
+func TestApplyManifestTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployment.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(deploymentTemplate), 0644))
+
+	adapter, dynamicClient := newFakeApplyAdapter(t)
+
+	err := adapter.ApplyManifestTemplate(path, map[string]string{
+		"APP":      "web",
+		"REPLICAS": "3",
+		"TAG":      "1.25",
+	})
+	require.NoError(t, err)
+
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	deployment, err := dynamicClient.Resource(gvr).Namespace("default").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	replicas, found, err := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, int64(3), replicas)
+
+	containers, _, err := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	require.NoError(t, err)
+	require.Len(t, containers, 1)
+	assert.Equal(t, "nginx:1.25", containers[0].(map[string]interface{})["image"])
+}
+
+func TestApplyManifestTemplateMissingVars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployment.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(deploymentTemplate), 0644))
+
+	adapter, dynamicClient := newFakeApplyAdapter(t)
+
+	err := adapter.ApplyManifestTemplate(path, map[string]string{"APP": "web"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "REPLICAS, TAG")
+	assert.Empty(t, dynamicClient.Actions())
+}
@@ -0,0 +1,218 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// rollDebounce задает паузу после последнего изменения конфигурации,
+// после которой деплойменты перезапускаются. Несколько изменений подряд
+// дают один перезапуск.
+var rollDebounce = 2 * time.Second
+
+// watchRestartBackoff пауза перед повторным наблюдением, когда сервер
+// закрыл предыдущее или вернул ошибку
+var watchRestartBackoff = time.Second
+
+// configSource ConfigMap или Secret, за которым следит WatchAndRollOnChange
+type configSource struct {
+	kind  string
+	get   func(ctx context.Context) (runtime.Object, error)
+	watch func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+}
+
+// WatchAndRollOnChange следит за ConfigMap или Secret configName и после
+// каждого изменения данных перезапускает deployments, как RestartDeployment.
+// Изменения только метаданных перезапуска не вызывают. Функция блокируется
+// до отмены ctx (тогда возвращается nil), удаления конфигурации или ошибки
+// перезапуска.
+func (k *K8sAdapter) WatchAndRollOnChange(ctx context.Context, namespace, configName string, deployments []string) (err error) {
+	ctx, span := tracing.Start(ctx, "kubernetes", "watch_and_roll_on_change", attribute.String("namespace", namespace), attribute.String("config", configName), attribute.StringSlice("deployments", deployments))
+	defer func() { tracing.End(span, err) }()
+
+	if len(deployments) == 0 {
+		return fmt.Errorf("не указаны деплойменты для перезапуска")
+	}
+
+	source, err := k.configSource(ctx, namespace, configName)
+	if err != nil {
+		return err
+	}
+
+	var lastHash string
+	var pending <-chan time.Time
+	for first := true; ; first = false {
+		obj, err := source.get(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("ошибка при получении %s %s: %w", source.kind, configName, err)
+		}
+		hash, resourceVersion := configDataHash(obj)
+		// Изменение, пропущенное между наблюдениями, тоже вызывает перезапуск
+		if !first && hash != lastHash {
+			pending = time.After(rollDebounce)
+		}
+		lastHash = hash
+
+		watcher, err := source.watch(ctx, metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", configName).String(),
+			ResourceVersion: resourceVersion,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("ошибка при наблюдении за %s %s: %w", source.kind, configName, err)
+		}
+
+		err = k.rollOnEvents(ctx, watcher, source.kind, configName, namespace, deployments, &lastHash, &pending)
+		watcher.Stop()
+		if err != nil || ctx.Err() != nil {
+			return err
+		}
+
+		// Сервер закрыл наблюдение, конфигурация перечитывается заново.
+		// Пауза не дает повторяющимся ошибкам наблюдения нагружать API server.
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watchRestartBackoff):
+		}
+	}
+}
+
+// rollOnEvents обрабатывает события watcher до закрытия канала. Изменение
+// данных откладывает перезапуск на rollDebounce.
+func (k *K8sAdapter) rollOnEvents(ctx context.Context, watcher watch.Interface, kind, configName, namespace string, deployments []string, lastHash *string, pending *<-chan time.Time) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-*pending:
+			*pending = nil
+			if err := k.restartDeployments(ctx, namespace, deployments); err != nil {
+				return err
+			}
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+
+			switch event.Type {
+			case watch.Deleted:
+				return fmt.Errorf("%s %s удален", kind, configName)
+			case watch.Error:
+				// Например, устаревшая resourceVersion: наблюдение начнется заново
+				return nil
+			case watch.Modified:
+				hash, _ := configDataHash(event.Object)
+				if hash != *lastHash {
+					*lastHash = hash
+					*pending = time.After(rollDebounce)
+				}
+			}
+		}
+	}
+}
+
+// restartDeployments перезапускает деплойменты с одной меткой времени
+func (k *K8sAdapter) restartDeployments(ctx context.Context, namespace string, deployments []string) error {
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	now := k.now()
+	for _, name := range deployments {
+		err := k.restartDeployment(ctx, namespace, name, now)
+		k.recordAudit(ctx, "restart_deployment", namespace+"/"+name, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configSource определяет, ConfigMap или Secret называется name.
+// При совпадении имен используется ConfigMap.
+func (k *K8sAdapter) configSource(ctx context.Context, namespace, name string) (*configSource, error) {
+	configMaps := k.clientset.CoreV1().ConfigMaps(namespace)
+	secrets := k.clientset.CoreV1().Secrets(namespace)
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	_, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return &configSource{
+			kind: "ConfigMap",
+			get: func(ctx context.Context) (runtime.Object, error) {
+				return configMaps.Get(ctx, name, metav1.GetOptions{})
+			},
+			watch: configMaps.Watch,
+		}, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("ошибка при получении ConfigMap %s: %w", name, err)
+	}
+
+	_, err = secrets.Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return &configSource{
+			kind: "Secret",
+			get: func(ctx context.Context) (runtime.Object, error) {
+				return secrets.Get(ctx, name, metav1.GetOptions{})
+			},
+			watch: secrets.Watch,
+		}, nil
+	}
+	if errors.IsNotFound(err) {
+		return nil, fmt.Errorf("ConfigMap или Secret %s не найден в namespace %s", name, namespace)
+	}
+	return nil, fmt.Errorf("ошибка при получении Secret %s: %w", name, err)
+}
+
+// configDataHash возвращает хеш данных ConfigMap или Secret и версию объекта
+func configDataHash(obj runtime.Object) (hash, resourceVersion string) {
+	var data map[string][]byte
+	switch config := obj.(type) {
+	case *corev1.ConfigMap:
+		data = make(map[string][]byte, len(config.Data)+len(config.BinaryData))
+		for key, value := range config.Data {
+			data[key] = []byte(value)
+		}
+		for key, value := range config.BinaryData {
+			data[key] = value
+		}
+		resourceVersion = config.ResourceVersion
+	case *corev1.Secret:
+		data = config.Data
+		resourceVersion = config.ResourceVersion
+	}
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s=%d:", key, len(data[key]))
+		h.Write(data[key])
+	}
+	return hex.EncodeToString(h.Sum(nil)), resourceVersion
+}
@@ -0,0 +1,146 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// startRollWatch запускает WatchAndRollOnChange и ждет начала наблюдения.
+// Возвращает канал с результатом и функцию остановки.
+func startRollWatch(t *testing.T, clientset *fake.Clientset, configName string) (<-chan error, context.CancelFunc) {
+	previous := rollDebounce
+	rollDebounce = 50 * time.Millisecond
+	t.Cleanup(func() { rollDebounce = previous })
+
+	adapter := &K8sAdapter{clientset: clientset, ctx: context.Background()}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	done := make(chan error, 1)
+	go func() { done <- adapter.WatchAndRollOnChange(ctx, "default", configName, []string{"web"}) }()
+
+	require.Eventually(t, func() bool {
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "watch" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 5*time.Millisecond)
+	return done, cancel
+}
+
+// restartPatches считает патчи деплоймента web
+func restartPatches(clientset *fake.Clientset) int {
+	count := 0
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "patch" && action.GetResource().Resource == "deployments" {
+			count++
+		}
+	}
+	return count
+}
+
+func restartedAt(t *testing.T, clientset *fake.Clientset) string {
+	deployment, err := clientset.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	return deployment.Spec.Template.Annotations[restartedAtAnnotation]
+}
+
+func TestWatchAndRollOnChangeConfigMap(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "default"}, Data: map[string]string{"mode": "a"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+	)
+	done, cancel := startRollWatch(t, clientset, "web-config")
+	configMaps := clientset.CoreV1().ConfigMaps("default")
+
+	// Изменение только меток не вызывает перезапуск
+	config, err := configMaps.Get(context.Background(), "web-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	config.Labels = map[string]string{"team": "web"}
+	_, err = configMaps.Update(context.Background(), config, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// Два изменения данных подряд дают один перезапуск
+	for _, mode := range []string{"b", "c"} {
+		config.Data["mode"] = mode
+		_, err = configMaps.Update(context.Background(), config, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	assert.Eventually(t, func() bool { return restartedAt(t, clientset) != "" }, time.Second, 10*time.Millisecond)
+	time.Sleep(3 * rollDebounce)
+	assert.Equal(t, 1, restartPatches(clientset))
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("наблюдение не остановилось после отмены")
+	}
+}
+
+func TestWatchAndRollOnChangeSecret(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "web-secret", Namespace: "default"}, Data: map[string][]byte{"token": []byte("old")}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+	)
+	done, cancel := startRollWatch(t, clientset, "web-secret")
+
+	secret, err := clientset.CoreV1().Secrets("default").Get(context.Background(), "web-secret", metav1.GetOptions{})
+	require.NoError(t, err)
+	secret.Data["token"] = []byte("new")
+	_, err = clientset.CoreV1().Secrets("default").Update(context.Background(), secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return restartedAt(t, clientset) != "" }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestWatchAndRollOnChangeMissingConfig(t *testing.T) {
+	adapter := &K8sAdapter{clientset: fake.NewSimpleClientset(), ctx: context.Background()}
+
+	err := adapter.WatchAndRollOnChange(context.Background(), "default", "missing", []string{"web"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "не найден")
+}
+
+func TestWatchAndRollOnChangeRestartBackoff(t *testing.T) {
+	previous := watchRestartBackoff
+	watchRestartBackoff = 50 * time.Millisecond
+	t.Cleanup(func() { watchRestartBackoff = previous })
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "default"}},
+	)
+	// Сервер сразу закрывает каждое наблюдение
+	var watches int
+	clientset.PrependWatchReactor("configmaps", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watches++
+		watcher := watch.NewFake()
+		watcher.Stop()
+		return true, watcher, nil
+	})
+	done, cancel := startRollWatch(t, clientset, "web-config")
+
+	time.Sleep(5 * watchRestartBackoff)
+	cancel()
+	require.NoError(t, <-done)
+
+	// Без паузы наблюдение перезапускалось бы тысячи раз
+	assert.LessOrEqual(t, watches, 7)
+}
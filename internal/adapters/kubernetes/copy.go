@@ -0,0 +1,192 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// CopyConfigMap копирует ConfigMap name из srcNamespace в dstNamespace под
+// именем newName (пустое имя сохраняет исходное). Копируются данные, метки и
+// аннотации; служебные метаданные источника (uid, resourceVersion, владельцы)
+// не переносятся. Существующий ConfigMap назначения заменяется только при
+// overwrite.
+func (k *K8sAdapter) CopyConfigMap(srcNamespace, name, dstNamespace, newName string, overwrite bool) (err error) {
+	if newName == "" {
+		newName = name
+	}
+
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "copy_config_map", attribute.String("namespace", srcNamespace), attribute.String("name", name),
+		attribute.String("dst.namespace", dstNamespace), attribute.String("dst.name", newName), attribute.Bool("overwrite", overwrite))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "copy_config_map", srcNamespace+"/"+name+" -> "+dstNamespace+"/"+newName, err)
+	}()
+
+	if srcNamespace == dstNamespace && name == newName {
+		return fmt.Errorf("ConfigMap %s/%s нельзя скопировать сам в себя", srcNamespace, name)
+	}
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	configMaps := k.clientset.CoreV1().ConfigMaps(dstNamespace)
+	source, err := k.clientset.CoreV1().ConfigMaps(srcNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ошибка при получении ConfigMap %s/%s: %w", srcNamespace, name, err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: copyObjectMeta(source.ObjectMeta, dstNamespace, newName),
+		Data:       source.Data,
+		BinaryData: source.BinaryData,
+		Immutable:  source.Immutable,
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := configMaps.Get(ctx, newName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			if _, err := configMaps.Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("ошибка при создании ConfigMap: %w", err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка при получении ConfigMap %s/%s: %w", dstNamespace, newName, err)
+		}
+		if !overwrite {
+			return fmt.Errorf("ConfigMap %s/%s уже существует", dstNamespace, newName)
+		}
+
+		configMap.ResourceVersion = existing.ResourceVersion
+		if _, err := configMaps.Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("ошибка при обновлении ConfigMap: %w", err)
+		}
+		return nil
+	})
+}
+
+// CopySecret копирует Secret так же, как CopyConfigMap копирует ConfigMap.
+// Токены service account не копируются: Kubernetes выпускает их для
+// конкретного service account своего namespace.
+func (k *K8sAdapter) CopySecret(srcNamespace, name, dstNamespace, newName string, overwrite bool) (err error) {
+	if newName == "" {
+		newName = name
+	}
+
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "copy_secret", attribute.String("namespace", srcNamespace), attribute.String("name", name),
+		attribute.String("dst.namespace", dstNamespace), attribute.String("dst.name", newName), attribute.Bool("overwrite", overwrite))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "copy_secret", srcNamespace+"/"+name+" -> "+dstNamespace+"/"+newName, err)
+	}()
+
+	if srcNamespace == dstNamespace && name == newName {
+		return fmt.Errorf("Secret %s/%s нельзя скопировать сам в себя", srcNamespace, name)
+	}
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	secrets := k.clientset.CoreV1().Secrets(dstNamespace)
+	source, err := k.clientset.CoreV1().Secrets(srcNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ошибка при получении Secret %s/%s: %w", srcNamespace, name, err)
+	}
+	if source.Type == corev1.SecretTypeServiceAccountToken {
+		return fmt.Errorf("Secret %s/%s содержит токен service account и не копируется", srcNamespace, name)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: copyObjectMeta(source.ObjectMeta, dstNamespace, newName),
+		Type:       source.Type,
+		Data:       source.Data,
+		Immutable:  source.Immutable,
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := secrets.Get(ctx, newName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			if _, err := secrets.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("ошибка при создании Secret: %w", err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка при получении Secret %s/%s: %w", dstNamespace, newName, err)
+		}
+		if !overwrite {
+			return fmt.Errorf("Secret %s/%s уже существует", dstNamespace, newName)
+		}
+
+		secret.ResourceVersion = existing.ResourceVersion
+		if _, err := secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("ошибка при обновлении Secret: %w", err)
+		}
+		return nil
+	})
+}
+
+// ConfigMapExists сообщает, существует ли ConfigMap namespace/name
+func (k *K8sAdapter) ConfigMapExists(namespace, name string) (_ bool, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "config_map_exists", attribute.String("namespace", namespace), attribute.String("name", name))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	_, err = k.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("ошибка при получении ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	return true, nil
+}
+
+// SecretExists сообщает, существует ли Secret namespace/name
+func (k *K8sAdapter) SecretExists(namespace, name string) (_ bool, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "secret_exists", attribute.String("namespace", namespace), attribute.String("name", name))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	_, err = k.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("ошибка при получении Secret %s/%s: %w", namespace, name, err)
+	}
+	return true, nil
+}
+
+// copyObjectMeta возвращает метаданные копии объекта: только имя, namespace,
+// метки и аннотации. Аннотация kubectl apply не копируется: в ней остались
+// бы исходные namespace и имя.
+func copyObjectMeta(source metav1.ObjectMeta, namespace, name string) metav1.ObjectMeta {
+	var annotations map[string]string
+	for key, value := range source.Annotations {
+		if key == lastAppliedAnnotation {
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]string, len(source.Annotations))
+		}
+		annotations[key] = value
+	}
+
+	return metav1.ObjectMeta{
+		Name:        name,
+		Namespace:   namespace,
+		Labels:      source.Labels,
+		Annotations: annotations,
+	}
+}
@@ -0,0 +1,121 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCopySecret(t *testing.T) {
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "db-credentials",
+			Namespace:       "staging",
+			UID:             "0b7c1d9e",
+			ResourceVersion: "42",
+			Labels:          map[string]string{"app": "db"},
+			Annotations: map[string]string{
+				"owner":               "platform",
+				lastAppliedAnnotation: `{"kind":"Secret"}`,
+			},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "SealedSecret", Name: "db-credentials"}},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{"password": []byte("s3cret")},
+	}
+	adapter := &K8sAdapter{
+		clientset: fake.NewSimpleClientset(source),
+		ctx:       context.Background(),
+	}
+
+	require.NoError(t, adapter.CopySecret("staging", "db-credentials", "production", "", false))
+
+	copied, err := adapter.clientset.CoreV1().Secrets("production").Get(context.Background(), "db-credentials", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, source.Data, copied.Data)
+	assert.Equal(t, corev1.SecretTypeOpaque, copied.Type)
+	assert.Equal(t, "production", copied.Namespace)
+	assert.Equal(t, map[string]string{"app": "db"}, copied.Labels)
+	assert.Equal(t, map[string]string{"owner": "platform"}, copied.Annotations)
+	assert.NotEqual(t, source.UID, copied.UID)
+	assert.Empty(t, copied.OwnerReferences)
+
+	// Без overwrite существующий Secret не заменяется
+	err = adapter.CopySecret("staging", "db-credentials", "production", "", false)
+	assert.ErrorContains(t, err, "уже существует")
+
+	source.Data = map[string][]byte{"password": []byte("rotated")}
+	_, err = adapter.clientset.CoreV1().Secrets("staging").Update(context.Background(), source, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, adapter.CopySecret("staging", "db-credentials", "production", "", true))
+
+	copied, err = adapter.clientset.CoreV1().Secrets("production").Get(context.Background(), "db-credentials", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("rotated"), copied.Data["password"])
+}
+
+func TestCopySecretRejectsServiceAccountToken(t *testing.T) {
+	adapter := &K8sAdapter{
+		clientset: fake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "default-token", Namespace: "staging"},
+			Type:       corev1.SecretTypeServiceAccountToken,
+		}),
+		ctx: context.Background(),
+	}
+
+	err := adapter.CopySecret("staging", "default-token", "production", "", false)
+	assert.ErrorContains(t, err, "service account")
+}
+
+func TestCopyConfigMap(t *testing.T) {
+	adapter := &K8sAdapter{
+		clientset: fake.NewSimpleClientset(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "staging", ResourceVersion: "7"},
+			Data:       map[string]string{"LOG_LEVEL": "debug"},
+			BinaryData: map[string][]byte{"logo.png": {0x89, 0x50}},
+		}),
+		ctx: context.Background(),
+	}
+
+	require.NoError(t, adapter.CopyConfigMap("staging", "app-config", "staging", "app-config-backup", false))
+
+	copied, err := adapter.clientset.CoreV1().ConfigMaps("staging").Get(context.Background(), "app-config-backup", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "debug"}, copied.Data)
+	assert.Equal(t, map[string][]byte{"logo.png": {0x89, 0x50}}, copied.BinaryData)
+
+	err = adapter.CopyConfigMap("staging", "app-config", "staging", "", true)
+	assert.ErrorContains(t, err, "сам в себя")
+
+	err = adapter.CopyConfigMap("staging", "missing", "production", "", false)
+	assert.Error(t, err)
+}
+
+func TestConfigMapAndSecretExists(t *testing.T) {
+	adapter := &K8sAdapter{
+		clientset: fake.NewSimpleClientset(
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "production"}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "production"}},
+		),
+		ctx: context.Background(),
+	}
+
+	exists, err := adapter.ConfigMapExists("production", "app-config")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = adapter.ConfigMapExists("staging", "app-config")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = adapter.SecretExists("production", "db-credentials")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = adapter.SecretExists("production", "missing")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
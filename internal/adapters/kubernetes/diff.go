@@ -0,0 +1,253 @@
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"github.com/pmezard/go-difflib/difflib"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// lastAppliedAnnotation хранит конфигурацию последнего kubectl apply
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// serverManagedFields поля metadata, которые заполняет API сервер
+var serverManagedFields = []string{
+	"uid",
+	"resourceVersion",
+	"generation",
+	"creationTimestamp",
+	"managedFields",
+	"selfLink",
+}
+
+// KeyChange описывает изменение одного ключа ConfigMap
+type KeyChange struct {
+	Key      string
+	OldValue string
+	NewValue string
+}
+
+// ConfigMapDiff содержит изменения, которые внесет замена данных ConfigMap
+type ConfigMapDiff struct {
+	Exists  bool
+	Added   []KeyChange
+	Removed []KeyChange
+	Changed []KeyChange
+}
+
+// HasChanges сообщает, изменит ли замена хотя бы один ключ
+func (d *ConfigMapDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// SecretDiff содержит изменения ключей Secret. Значения не
+// включаются, чтобы не раскрывать секретные данные.
+type SecretDiff struct {
+	Exists  bool
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// HasChanges сообщает, изменит ли замена хотя бы один ключ
+func (d *SecretDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// DiffConfigMap сравнивает текущие данные ConfigMap с новыми.
+// Если ConfigMap не существует, все ключи считаются добавленными.
+func (k *K8sAdapter) DiffConfigMap(namespace, name string, newData map[string]string) (_ *ConfigMapDiff, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "diff_config_map", attribute.String("namespace", namespace), attribute.String("name", name))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	diff := &ConfigMapDiff{}
+	oldData := map[string]string{}
+
+	configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("ошибка при получении ConfigMap: %w", err)
+	}
+	if err == nil {
+		diff.Exists = true
+		oldData = configMap.Data
+	}
+
+	for _, key := range sortedKeys(oldData, newData) {
+		oldValue, inOld := oldData[key]
+		newValue, inNew := newData[key]
+		change := KeyChange{Key: key, OldValue: oldValue, NewValue: newValue}
+
+		switch {
+		case !inOld:
+			diff.Added = append(diff.Added, change)
+		case !inNew:
+			diff.Removed = append(diff.Removed, change)
+		case oldValue != newValue:
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+
+	return diff, nil
+}
+
+// DiffSecret сравнивает текущие данные Secret с новыми и
+// возвращает только имена измененных ключей
+func (k *K8sAdapter) DiffSecret(namespace, name string, newData map[string][]byte) (_ *SecretDiff, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "diff_secret", attribute.String("namespace", namespace), attribute.String("name", name))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	diff := &SecretDiff{}
+	oldData := map[string][]byte{}
+
+	secret, err := k.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("ошибка при получении Secret: %w", err)
+	}
+	if err == nil {
+		diff.Exists = true
+		oldData = secret.Data
+	}
+
+	for _, key := range sortedKeys(oldData, newData) {
+		oldValue, inOld := oldData[key]
+		newValue, inNew := newData[key]
+
+		switch {
+		case !inOld:
+			diff.Added = append(diff.Added, key)
+		case !inNew:
+			diff.Removed = append(diff.Removed, key)
+		case !bytes.Equal(oldValue, newValue):
+			diff.Changed = append(diff.Changed, key)
+		}
+	}
+
+	return diff, nil
+}
+
+// DiffManifest сравнивает ресурсы манифеста с текущими объектами кластера
+// и возвращает unified diff их YAML представлений. Поля, которые заполняет
+// API сервер, и status не сравниваются; отсутствующий в кластере ресурс
+// показывается целиком как добавленный. Значения по умолчанию, которые
+// сервер проставляет сам, попадают в diff как удаляемые строки. Пустая
+// строка означает, что манифест совпадает с кластером.
+func (k *K8sAdapter) DiffManifest(path string) (_ string, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "diff_manifest", attribute.String("manifest.path", path))
+	defer func() { tracing.End(span, err) }()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("ошибка при чтении манифеста: %w", err)
+	}
+
+	objects, err := decodeManifest(data)
+	if err != nil {
+		return "", err
+	}
+	if len(objects) == 0 {
+		return "", nil
+	}
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	mapper, err := k.newRESTMapper()
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	for _, desired := range objects {
+		dynamicResource, err := k.resourceFor(mapper, desired)
+		if err != nil {
+			return "", err
+		}
+
+		var live []byte
+		existing, err := dynamicResource.Get(ctx, desired.GetName(), metav1.GetOptions{})
+		switch {
+		case errors.IsNotFound(err):
+		case err != nil:
+			return "", fmt.Errorf("ошибка при получении ресурса %s: %w", desired.GetName(), err)
+		default:
+			if live, err = manifestYAML(existing); err != nil {
+				return "", err
+			}
+		}
+
+		want, err := manifestYAML(desired)
+		if err != nil {
+			return "", err
+		}
+
+		name := desired.GetKind() + "/" + desired.GetName()
+		if desired.GetNamespace() != "" {
+			name = desired.GetKind() + "/" + desired.GetNamespace() + "/" + desired.GetName()
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(live)),
+			B:        difflib.SplitLines(string(want)),
+			FromFile: "live/" + name,
+			ToFile:   "desired/" + name,
+			Context:  3,
+		})
+		if err != nil {
+			return "", fmt.Errorf("ошибка при построении diff %s: %w", name, err)
+		}
+		result.WriteString(diff)
+	}
+
+	return result.String(), nil
+}
+
+// manifestYAML возвращает YAML объекта без полей, которые заполняет сервер
+func manifestYAML(obj *unstructured.Unstructured) ([]byte, error) {
+	obj = obj.DeepCopy()
+	for _, field := range serverManagedFields {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(obj.Object, "metadata", "annotations", lastAppliedAnnotation)
+	if len(obj.GetAnnotations()) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "annotations")
+	}
+	unstructured.RemoveNestedField(obj.Object, "status")
+
+	data, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при сериализации %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return data, nil
+}
+
+// sortedKeys возвращает отсортированное объединение ключей двух карт
+func sortedKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	for key := range a {
+		seen[key] = struct{}{}
+	}
+	for key := range b {
+		seen[key] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,124 @@
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDiffConfigMap(t *testing.T) {
+	adapter, _ := newFakeConfigMapAdapter()
+
+	diff, err := adapter.DiffConfigMap("default", "app-config", map[string]string{
+		"LOG_LEVEL": "debug",
+		"TIMEOUT":   "30s",
+	})
+	require.NoError(t, err)
+
+	assert.True(t, diff.Exists)
+	assert.True(t, diff.HasChanges())
+	assert.Equal(t, []KeyChange{{Key: "TIMEOUT", NewValue: "30s"}}, diff.Added)
+	assert.Equal(t, []KeyChange{{Key: "PORT", OldValue: "8080"}}, diff.Removed)
+	assert.Equal(t, []KeyChange{{Key: "LOG_LEVEL", OldValue: "info", NewValue: "debug"}}, diff.Changed)
+}
+
+func TestDiffConfigMapNotFound(t *testing.T) {
+	adapter, _ := newFakeConfigMapAdapter()
+
+	diff, err := adapter.DiffConfigMap("default", "missing", map[string]string{"KEY": "value"})
+	require.NoError(t, err)
+
+	assert.False(t, diff.Exists)
+	assert.Equal(t, []KeyChange{{Key: "KEY", NewValue: "value"}}, diff.Added)
+	assert.Empty(t, diff.Removed)
+	assert.Empty(t, diff.Changed)
+}
+
+func TestDiffSecret(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "default"},
+		Data: map[string][]byte{
+			"username": []byte("admin"),
+			"password": []byte("old-password"),
+			"host":     []byte("db"),
+		},
+	})
+	adapter := &K8sAdapter{
+		clientset: clientset,
+		ctx:       context.Background(),
+	}
+
+	diff, err := adapter.DiffSecret("default", "db-credentials", map[string][]byte{
+		"username": []byte("admin"),
+		"password": []byte("new-password"),
+		"port":     []byte("5432"),
+	})
+	require.NoError(t, err)
+
+	assert.True(t, diff.Exists)
+	assert.Equal(t, []string{"port"}, diff.Added)
+	assert.Equal(t, []string{"host"}, diff.Removed)
+	assert.Equal(t, []string{"password"}, diff.Changed)
+}
+
+func TestDiffManifest(t *testing.T) {
+	adapter, dynamicClient := newFakeApplyAdapter(t)
+
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":              "app-config",
+			"namespace":         "default",
+			"uid":               "0b5e1c2a",
+			"resourceVersion":   "42",
+			"creationTimestamp": "2024-01-01T00:00:00Z",
+		},
+		"data": map[string]interface{}{"LOG_LEVEL": "info", "PORT": "8080"},
+	}}
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	_, err := dynamicClient.Resource(gvr).Namespace("default").Create(context.Background(), live, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	writeManifest := func(logLevel string) string {
+		path := filepath.Join(t.TempDir(), "configmap.yaml")
+		manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  LOG_LEVEL: ` + logLevel + `
+  PORT: "8080"
+`
+		require.NoError(t, os.WriteFile(path, []byte(manifest), 0644))
+		return path
+	}
+
+	t.Run("измененное поле попадает в diff", func(t *testing.T) {
+		diff, err := adapter.DiffManifest(writeManifest("debug"))
+		require.NoError(t, err)
+
+		assert.Contains(t, diff, "--- live/ConfigMap/default/app-config")
+		assert.Contains(t, diff, "+++ desired/ConfigMap/default/app-config")
+		assert.Contains(t, diff, "-  LOG_LEVEL: info")
+		assert.Contains(t, diff, "+  LOG_LEVEL: debug")
+		// Поля, заполняемые сервером, не сравниваются
+		assert.NotContains(t, diff, "resourceVersion")
+		assert.NotContains(t, diff, "uid")
+	})
+
+	t.Run("совпадающий манифест дает пустой diff", func(t *testing.T) {
+		diff, err := adapter.DiffManifest(writeManifest("info"))
+		require.NoError(t, err)
+		assert.Empty(t, diff)
+	})
+}
@@ -0,0 +1,214 @@
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// CreateConfigMapFromDir создает ConfigMap из файлов директории, как
+// kubectl create configmap --from-file=dir. Имя файла становится ключом,
+// поддиректории пропускаются. Файлы, не являющиеся текстом UTF-8,
+// сохраняются в binaryData.
+func (k *K8sAdapter) CreateConfigMapFromDir(namespace, name, dir string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "create_config_map_from_dir", attribute.String("namespace", namespace), attribute.String("name", name), attribute.String("dir", dir))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "create_config_map_from_dir", namespace+"/"+name, err)
+	}()
+
+	files, err := readKeyFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	for key, value := range files {
+		if utf8.Valid(value) {
+			if configMap.Data == nil {
+				configMap.Data = make(map[string]string)
+			}
+			configMap.Data[key] = string(value)
+			continue
+		}
+		if configMap.BinaryData == nil {
+			configMap.BinaryData = make(map[string][]byte)
+		}
+		configMap.BinaryData[key] = value
+	}
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	if _, err := k.clientset.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("ошибка при создании ConfigMap: %w", err)
+	}
+
+	return nil
+}
+
+// CreateSecretFromDir создает Secret из файлов директории. Содержимое
+// файлов сохраняется как есть, поддиректории пропускаются.
+func (k *K8sAdapter) CreateSecretFromDir(namespace, name, secretType, dir string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "create_secret_from_dir", attribute.String("namespace", namespace), attribute.String("name", name), attribute.String("secret.type", secretType), attribute.String("dir", dir))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "create_secret_from_dir", namespace+"/"+name, err)
+	}()
+
+	files, err := readKeyFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	if secretType == "" {
+		secretType = string(corev1.SecretTypeOpaque)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretType(secretType),
+		Data: files,
+	}
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	if _, err := k.clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("ошибка при создании Secret: %w", err)
+	}
+
+	return nil
+}
+
+// readKeyFiles читает файлы первого уровня директории в map имя -> содержимое
+func readKeyFiles(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении директории: %w", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		// Stat следует по символическим ссылкам
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении файла %s: %w", path, err)
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		if errs := validation.IsConfigMapKey(entry.Name()); len(errs) > 0 {
+			return nil, fmt.Errorf("имя файла %s не подходит для ключа: %s", entry.Name(), strings.Join(errs, "; "))
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении файла %s: %w", path, err)
+		}
+		files[entry.Name()] = data
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("в директории %s нет файлов", dir)
+	}
+
+	return files, nil
+}
+
+// ExportConfigMap записывает каждый ключ ConfigMap в отдельный файл
+// директории destDir. Имя файла совпадает с ключом.
+func (k *K8sAdapter) ExportConfigMap(namespace, name, destDir string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "export_config_map", attribute.String("namespace", namespace), attribute.String("name", name), attribute.String("dest.dir", destDir))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ошибка при получении ConfigMap: %w", err)
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("ошибка при создании директории: %w", err)
+	}
+
+	for key, value := range configMap.Data {
+		if err := writeKeyFile(destDir, key, []byte(value), 0644); err != nil {
+			return err
+		}
+	}
+	for key, value := range configMap.BinaryData {
+		if err := writeKeyFile(destDir, key, value, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExportSecret записывает декодированные значения Secret в файлы
+// директории destDir с правами 0600
+func (k *K8sAdapter) ExportSecret(namespace, name, destDir string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "export_secret", attribute.String("namespace", namespace), attribute.String("name", name), attribute.String("dest.dir", destDir))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	secret, err := k.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ошибка при получении Secret: %w", err)
+	}
+
+	if err := os.MkdirAll(destDir, 0700); err != nil {
+		return fmt.Errorf("ошибка при создании директории: %w", err)
+	}
+
+	// client-go уже декодирует base64 из ответа API в Data
+	for key, value := range secret.Data {
+		if err := writeKeyFile(destDir, key, value, 0600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeKeyFile записывает значение ключа в файл, не допуская выхода
+// за пределы директории
+func writeKeyFile(dir, key string, data []byte, perm os.FileMode) error {
+	if key == "" || key == "." || key == ".." || filepath.Base(key) != key {
+		return fmt.Errorf("недопустимое имя ключа для файла: %q", key)
+	}
+
+	path := filepath.Join(dir, key)
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return fmt.Errorf("ошибка при записи файла %s: %w", path, err)
+	}
+	// WriteFile не меняет права уже существующего файла
+	if err := os.Chmod(path, perm); err != nil {
+		return fmt.Errorf("ошибка при установке прав на файл %s: %w", path, err)
+	}
+
+	return nil
+}
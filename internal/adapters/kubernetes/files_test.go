@@ -0,0 +1,106 @@
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestExportConfigMap(t *testing.T) {
+	adapter, _ := newFakeConfigMapAdapter()
+	dir := filepath.Join(t.TempDir(), "export")
+
+	require.NoError(t, adapter.ExportConfigMap("default", "app-config", dir))
+
+	for key, want := range map[string]string{"LOG_LEVEL": "info", "PORT": "8080"} {
+		data, err := os.ReadFile(filepath.Join(dir, key))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(data))
+	}
+
+	err := adapter.ExportConfigMap("default", "missing", dir)
+	assert.Error(t, err)
+}
+
+func TestExportSecret(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "default"},
+		Type:       corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"username": []byte("admin"),
+			"tls.key":  {0x00, 0xff, 0x10},
+		},
+	})
+	adapter := &K8sAdapter{
+		clientset: clientset,
+		ctx:       context.Background(),
+	}
+	dir := t.TempDir()
+
+	require.NoError(t, adapter.ExportSecret("default", "db-credentials", dir))
+
+	for key, want := range map[string][]byte{"username": []byte("admin"), "tls.key": {0x00, 0xff, 0x10}} {
+		path := filepath.Join(dir, key)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, data)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), key)
+	}
+}
+
+// newKeyFilesDir создает директорию с текстовым и бинарным файлом и поддиректорией
+func newKeyFilesDir(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.properties"), []byte("mode=prod\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "logo.png"), []byte{0x89, 0x50, 0x4e, 0x47, 0xff}, 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "ignored.txt"), []byte("x"), 0644))
+	return dir
+}
+
+func TestCreateConfigMapFromDir(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	adapter := &K8sAdapter{
+		clientset: clientset,
+		ctx:       context.Background(),
+	}
+
+	require.NoError(t, adapter.CreateConfigMapFromDir("default", "app-files", newKeyFilesDir(t)))
+
+	configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.Background(), "app-files", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app.properties": "mode=prod\n"}, configMap.Data)
+	assert.Equal(t, map[string][]byte{"logo.png": {0x89, 0x50, 0x4e, 0x47, 0xff}}, configMap.BinaryData)
+
+	// Пустая директория
+	err = adapter.CreateConfigMapFromDir("default", "empty", t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestCreateSecretFromDir(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	adapter := &K8sAdapter{
+		clientset: clientset,
+		ctx:       context.Background(),
+	}
+
+	require.NoError(t, adapter.CreateSecretFromDir("default", "app-files", "", newKeyFilesDir(t)))
+
+	secret, err := clientset.CoreV1().Secrets("default").Get(context.Background(), "app-files", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeOpaque, secret.Type)
+	assert.Equal(t, map[string][]byte{
+		"app.properties": []byte("mode=prod\n"),
+		"logo.png":       {0x89, 0x50, 0x4e, 0x47, 0xff},
+	}, secret.Data)
+}
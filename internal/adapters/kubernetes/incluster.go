@@ -0,0 +1,38 @@
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/client-go/rest"
+)
+
+// inClusterConfig загружает конфигурацию service account пода.
+// Переменная, чтобы тесты могли подменить окружение пода.
+var inClusterConfig = rest.InClusterConfig
+
+// InCluster сообщает, запущен ли процесс внутри пода: заданы адрес API
+// сервера и смонтирован токен service account
+func InCluster() bool {
+	_, err := inClusterConfig()
+	return err == nil
+}
+
+// NewK8sAdapterInCluster создает K8sAdapter с конфигурацией service
+// account пода, в котором запущен процесс
+func NewK8sAdapterInCluster() (*K8sAdapter, error) {
+	config, err := inClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при загрузке in-cluster конфигурации: %w", err)
+	}
+	return newK8sAdapterForConfig(config)
+}
+
+// fileExists проверяет, что path указывает на существующий файл
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
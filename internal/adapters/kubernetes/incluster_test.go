@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+// fakeServiceAccount имитирует окружение пода: rest.InClusterConfig
+// подменяется конфигурацией с токеном service account и CA сервера
+func fakeServiceAccount(t *testing.T, server *httptest.Server, token string) {
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	previous := inClusterConfig
+	inClusterConfig = func() (*rest.Config, error) {
+		return &rest.Config{
+			Host:            server.URL,
+			TLSClientConfig: rest.TLSClientConfig{CAData: ca},
+			BearerToken:     token,
+		}, nil
+	}
+	t.Cleanup(func() { inClusterConfig = previous })
+}
+
+func TestNewK8sAdapterInCluster(t *testing.T) {
+	var authorization string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"major":"1","minor":"29","gitVersion":"v1.29.0"}`)
+	}))
+	defer server.Close()
+
+	fakeServiceAccount(t, server, "sa-token")
+	require.True(t, InCluster())
+
+	adapter, err := NewK8sAdapterInCluster()
+	require.NoError(t, err)
+
+	// Клиент доверяет CA service account и передает его токен
+	version, err := adapter.clientset.Discovery().ServerVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "v1.29.0", version.GitVersion)
+	assert.Equal(t, "Bearer sa-token", authorization)
+}
+
+func TestNewK8sAdapterFallsBackToInCluster(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"gitVersion":"v1.29.0"}`)
+	}))
+	defer server.Close()
+
+	fakeServiceAccount(t, server, "sa-token")
+
+	for _, path := range []string{"", filepath.Join(t.TempDir(), "missing")} {
+		adapter, err := NewK8sAdapter(path)
+		require.NoError(t, err)
+
+		version, err := adapter.clientset.Discovery().ServerVersion()
+		require.NoError(t, err)
+		assert.Equal(t, "v1.29.0", version.GitVersion)
+	}
+}
+
+func TestNewK8sAdapterInClusterOutsidePod(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "")
+
+	assert.False(t, InCluster())
+	_, err := NewK8sAdapterInCluster()
+	assert.Error(t, err)
+}
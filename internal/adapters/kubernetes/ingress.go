@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// CreateIngress создает Ingress с одним правилом: запросы к host с путем,
+// начинающимся с pathPrefix (по умолчанию /), направляются на
+// serviceName:servicePort. Если задан tlsSecret, для host включается TLS
+// с сертификатом из этого секрета.
+func (k *K8sAdapter) CreateIngress(namespace, name, host, serviceName string, servicePort int, pathPrefix string, tlsSecret string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "create_ingress", attribute.String("namespace", namespace), attribute.String("name", name), attribute.String("host", host))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "create_ingress", namespace+"/"+name, err)
+	}()
+
+	if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+		if wildcardErrs := validation.IsWildcardDNS1123Subdomain(host); len(wildcardErrs) > 0 {
+			return fmt.Errorf("некорректное имя хоста %s: %s", host, strings.Join(errs, "; "))
+		}
+	}
+	if serviceName == "" {
+		return fmt.Errorf("не указан сервис для Ingress %s", name)
+	}
+	if errs := validation.IsValidPortNum(servicePort); len(errs) > 0 {
+		return fmt.Errorf("некорректный порт сервиса %d: %s", servicePort, strings.Join(errs, "; "))
+	}
+	if pathPrefix == "" {
+		pathPrefix = "/"
+	}
+	if !strings.HasPrefix(pathPrefix, "/") {
+		return fmt.Errorf("путь %s должен начинаться с /", pathPrefix)
+	}
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	pathType := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{
+				Host: host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     pathPrefix,
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: serviceName,
+									Port: networkingv1.ServiceBackendPort{Number: int32(servicePort)},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	if tlsSecret != "" {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{
+			Hosts:      []string{host},
+			SecretName: tlsSecret,
+		}}
+	}
+
+	if _, err := k.clientset.NetworkingV1().Ingresses(namespace).Create(ctx, ingress, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("ошибка при создании Ingress: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCreateIngress(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	adapter := &K8sAdapter{clientset: clientset, ctx: context.Background()}
+
+	err := adapter.CreateIngress("default", "web", "app.example.com", "web-svc", 8080, "/api", "web-tls")
+	require.NoError(t, err)
+
+	ingress, err := clientset.NetworkingV1().Ingresses("default").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, ingress.Spec.Rules, 1)
+	rule := ingress.Spec.Rules[0]
+	assert.Equal(t, "app.example.com", rule.Host)
+	require.NotNil(t, rule.HTTP)
+	require.Len(t, rule.HTTP.Paths, 1)
+
+	path := rule.HTTP.Paths[0]
+	assert.Equal(t, "/api", path.Path)
+	require.NotNil(t, path.PathType)
+	assert.Equal(t, networkingv1.PathTypePrefix, *path.PathType)
+	require.NotNil(t, path.Backend.Service)
+	assert.Equal(t, "web-svc", path.Backend.Service.Name)
+	assert.Equal(t, int32(8080), path.Backend.Service.Port.Number)
+
+	assert.Equal(t, []networkingv1.IngressTLS{{Hosts: []string{"app.example.com"}, SecretName: "web-tls"}}, ingress.Spec.TLS)
+}
+
+func TestCreateIngressDefaults(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	adapter := &K8sAdapter{clientset: clientset, ctx: context.Background()}
+
+	require.NoError(t, adapter.CreateIngress("default", "web", "*.example.com", "web-svc", 80, "", ""))
+
+	ingress, err := clientset.NetworkingV1().Ingresses("default").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "/", ingress.Spec.Rules[0].HTTP.Paths[0].Path)
+	assert.Empty(t, ingress.Spec.TLS)
+}
+
+func TestCreateIngressValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		service string
+		port    int
+		path    string
+	}{
+		{name: "хост с подчеркиванием", host: "my_app.example.com", service: "web", port: 80},
+		{name: "хост с заглавными буквами", host: "App.example.com", service: "web", port: 80},
+		{name: "хост с портом", host: "app.example.com:8080", service: "web", port: 80},
+		{name: "пустой сервис", host: "app.example.com", port: 80},
+		{name: "порт вне диапазона", host: "app.example.com", service: "web", port: 70000},
+		{name: "путь без слеша", host: "app.example.com", service: "web", port: 80, path: "api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			adapter := &K8sAdapter{clientset: clientset, ctx: context.Background()}
+
+			err := adapter.CreateIngress("default", "web", tt.host, tt.service, tt.port, tt.path, "")
+			assert.Error(t, err)
+			assert.Empty(t, clientset.Actions())
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// WaitForJob ждет, пока у Job появится условие Complete или Failed.
+// succeeded равен true для Complete и false для Failed; ошибка
+// возвращается, если Job удален, не найден или не завершился за timeout.
+func (k *K8sAdapter) WaitForJob(ctx context.Context, namespace, name string, timeout time.Duration) (succeeded bool, err error) {
+	ctx, span := tracing.Start(ctx, "kubernetes", "wait_for_job", attribute.String("namespace", namespace), attribute.String("name", name))
+	defer func() {
+		span.SetAttributes(attribute.Bool("job.succeeded", succeeded))
+		tracing.End(span, err)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	jobs := k.clientset.BatchV1().Jobs(namespace)
+	for {
+		job, err := jobs.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("ошибка при получении Job %s: %w", name, err)
+		}
+		if finished, succeeded := jobFinished(job); finished {
+			return succeeded, nil
+		}
+
+		watcher, err := jobs.Watch(ctx, metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+			ResourceVersion: job.ResourceVersion,
+		})
+		if err != nil {
+			return false, fmt.Errorf("ошибка при наблюдении за Job %s: %w", name, err)
+		}
+
+		finished, succeeded, err := waitJobEvents(ctx, watcher, name)
+		watcher.Stop()
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, fmt.Errorf("Job %s не завершился за %s: %w", name, timeout, ctx.Err())
+			}
+			return false, err
+		}
+		if finished {
+			return succeeded, nil
+		}
+		// Сервер закрыл наблюдение, статус перечитывается заново
+	}
+}
+
+// waitJobEvents читает события watcher до завершения Job или закрытия канала
+func waitJobEvents(ctx context.Context, watcher watch.Interface, name string) (finished, succeeded bool, err error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return false, false, ctx.Err()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return false, false, nil
+			}
+
+			switch event.Type {
+			case watch.Deleted:
+				return false, false, fmt.Errorf("Job %s удален до завершения", name)
+			case watch.Error:
+				return false, false, fmt.Errorf("ошибка при наблюдении за Job %s: %w", name, errors.FromObject(event.Object))
+			}
+
+			job, ok := event.Object.(*batchv1.Job)
+			if !ok || job.Name != name {
+				continue
+			}
+			if finished, succeeded := jobFinished(job); finished {
+				return true, succeeded, nil
+			}
+		}
+	}
+}
+
+// jobFinished проверяет условия Complete и Failed в статусе Job
+func jobFinished(job *batchv1.Job) (finished, succeeded bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, true
+		case batchv1.JobFailed:
+			return true, false
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,82 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// finishJobAfterWatch выставляет условие Job, как только WaitForJob
+// начинает наблюдение. Вызывается в отдельной горутине, поэтому
+// использует assert вместо require.
+func finishJobAfterWatch(t *testing.T, clientset *fake.Clientset, conditionType batchv1.JobConditionType) {
+	watching := assert.Eventually(t, func() bool {
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "watch" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 5*time.Millisecond)
+	if !watching {
+		return
+	}
+
+	job, err := clientset.BatchV1().Jobs("default").Get(context.Background(), "migrate", metav1.GetOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+		Type:   conditionType,
+		Status: corev1.ConditionTrue,
+	})
+	_, err = clientset.BatchV1().Jobs("default").UpdateStatus(context.Background(), job, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+}
+
+func TestWaitForJob(t *testing.T) {
+	tests := []struct {
+		name          string
+		conditionType batchv1.JobConditionType
+		wantSucceeded bool
+	}{
+		{name: "job завершен успешно", conditionType: batchv1.JobComplete, wantSucceeded: true},
+		{name: "job завершен с ошибкой", conditionType: batchv1.JobFailed, wantSucceeded: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(&batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default"},
+			})
+			adapter := &K8sAdapter{clientset: clientset, ctx: context.Background()}
+
+			go finishJobAfterWatch(t, clientset, tt.conditionType)
+
+			succeeded, err := adapter.WaitForJob(context.Background(), "default", "migrate", 5*time.Second)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSucceeded, succeeded)
+		})
+	}
+}
+
+func TestWaitForJobTimeout(t *testing.T) {
+	adapter := &K8sAdapter{
+		clientset: fake.NewSimpleClientset(&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default"},
+		}),
+		ctx: context.Background(),
+	}
+
+	succeeded, err := adapter.WaitForJob(context.Background(), "default", "migrate", 50*time.Millisecond)
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, succeeded)
+}
@@ -1,22 +1,24 @@
 package kubernetes
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
 	"time"
 
+	"github.com/localops/devops-manager/internal/audit"
+	"github.com/localops/devops-manager/internal/clock"
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/retry"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 // PodStatus содержит информацию о состоянии пода
@@ -109,19 +111,40 @@ type SecretListItem struct {
 
 // K8sAdapter предоставляет методы для работы с Kubernetes
 type K8sAdapter struct {
-	clientset *kubernetes.Clientset
-	dynamic   dynamic.Interface
-	ctx       context.Context
+	clientset       kubernetes.Interface
+	dynamic         dynamic.Interface
+	metrics         metricsclientset.Interface
+	ctx             context.Context
+	timeout         time.Duration
+	continueOnError bool
+	clock           clock.Clock
+	// auditLogger журнал изменяющих операций, nil - журнал не ведется
+	auditLogger audit.Logger
 }
 
-// NewK8sAdapter создает новый экземпляр K8sAdapter
+// DefaultTimeout ограничивает время одной операции с API сервером
+const DefaultTimeout = 30 * time.Second
+
+// NewK8sAdapter создает новый экземпляр K8sAdapter. Если kubeconfigPath
+// пуст или файл отсутствует, а процесс запущен внутри пода, используется
+// конфигурация service account.
 func NewK8sAdapter(kubeconfigPath string) (*K8sAdapter, error) {
+	// Без файла конфигурации внутри пода используется service account
+	if !fileExists(kubeconfigPath) && InCluster() {
+		return NewK8sAdapterInCluster()
+	}
+
 	// Загружаем конфигурацию из файла
 	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при загрузке конфигурации: %w", err)
 	}
 
+	return newK8sAdapterForConfig(config)
+}
+
+// newK8sAdapterForConfig создает клиенты адаптера по конфигурации
+func newK8sAdapterForConfig(config *rest.Config) (*K8sAdapter, error) {
 	// Создаем typed клиент
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -134,94 +157,160 @@ func NewK8sAdapter(kubeconfigPath string) (*K8sAdapter, error) {
 		return nil, fmt.Errorf("ошибка при создании dynamic клиента: %w", err)
 	}
 
+	// Создаем клиент metrics.k8s.io
+	metricsClient, err := metricsclientset.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании клиента метрик: %w", err)
+	}
+
 	return &K8sAdapter{
 		clientset: clientset,
 		dynamic:   dynamicClient,
+		metrics:   metricsClient,
 		ctx:       context.Background(),
+		timeout:   DefaultTimeout,
+		clock:     clock.Real{},
 	}, nil
 }
 
+// SetTimeout задает таймаут для каждой операции адаптера.
+// Нулевое значение отключает таймаут.
+func (k *K8sAdapter) SetTimeout(timeout time.Duration) {
+	k.timeout = timeout
+}
+
+// SetClock задает источник текущего времени для расчета возраста ресурсов
+func (k *K8sAdapter) SetClock(c clock.Clock) {
+	k.clock = c
+}
+
+// SetAuditLogger задает журнал аудита изменяющих операций (apply,
+// масштабирование, удаление и другие). nil отключает журнал.
+func (k *K8sAdapter) SetAuditLogger(logger audit.Logger) {
+	k.auditLogger = logger
+}
+
+// recordAudit записывает результат изменяющей операции в журнал аудита
+func (k *K8sAdapter) recordAudit(ctx context.Context, operation, target string, err error) {
+	audit.Record(ctx, k.auditLogger, "kubernetes", operation, target, err)
+}
+
+// now возвращает текущее время по часам адаптера.
+// Без настроенных часов используется системное время.
+func (k *K8sAdapter) now() time.Time {
+	if k.clock == nil {
+		return time.Now()
+	}
+	return k.clock.Now()
+}
+
+// since возвращает время, прошедшее с t, по часам адаптера
+func (k *K8sAdapter) since(t time.Time) time.Duration {
+	return k.now().Sub(t)
+}
+
+// opContext возвращает контекст одной операции, производный от ctx, с учетом
+// таймаута
+func (k *K8sAdapter) opContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if k.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, k.timeout)
+}
+
 // ApplyManifest применяет YAML манифест к кластеру
-func (k *K8sAdapter) ApplyManifest(manifestPath string) error {
+func (k *K8sAdapter) ApplyManifest(manifestPath string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "apply_manifest", attribute.String("manifest.path", manifestPath))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "apply_manifest", manifestPath, err)
+	}()
+
 	// Читаем YAML файл
 	data, err := ioutil.ReadFile(manifestPath)
 	if err != nil {
 		return fmt.Errorf("ошибка при чтении манифеста: %w", err)
 	}
 
-	// Разделяем манифест на отдельные ресурсы
-	resources := bytes.Split(data, []byte("---"))
+	return k.applyManifestData(ctx, data)
+}
 
-	for _, resourceData := range resources {
-		if len(bytes.TrimSpace(resourceData)) == 0 {
-			continue
-		}
+// applyManifestData применяет ресурсы из содержимого манифеста
+func (k *K8sAdapter) applyManifestData(ctx context.Context, data []byte) error {
+	_, err := k.applyManifestObjects(ctx, data)
+	return err
+}
 
-		// Декодируем YAML в Unstructured
-		obj := &unstructured.Unstructured{}
-		if err := yaml.Unmarshal(resourceData, obj); err != nil {
-			return fmt.Errorf("ошибка при разборе YAML: %w", err)
-		}
+// applyManifestObjects применяет ресурсы манифеста по порядку и
+// возвращает результаты успешно примененных до первой ошибки
+func (k *K8sAdapter) applyManifestObjects(ctx context.Context, data []byte) ([]AppliedResource, error) {
+	// Разделяем манифест на отдельные ресурсы
+	objects, err := decodeManifest(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, nil
+	}
 
-		// Получаем GVR (GroupVersionResource) для объекта
-		gvk := obj.GetObjectKind().GroupVersionKind()
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
 
-		// Создаем RESTMapper
-		discoveryClient := k.clientset.Discovery()
-		groupResources, err := restmapper.GetAPIGroupResources(discoveryClient)
-		if err != nil {
-			return fmt.Errorf("ошибка при получении API групп: %w", err)
-		}
-		mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+	// Создаем RESTMapper
+	mapper, err := k.newRESTMapper()
+	if err != nil {
+		return nil, err
+	}
 
-		// Получаем mapping для ресурса
-		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	var results []AppliedResource
+	for _, obj := range objects {
+		result, err := k.applyObject(ctx, mapper, obj)
 		if err != nil {
-			return fmt.Errorf("ошибка при получении mapping: %w", err)
+			return results, err
 		}
-
-		// Получаем dynamic client для конкретного ресурса
-		dynamicResource := k.dynamic.Resource(mapping.Resource)
-
-		// Проверяем существование ресурса
-		_, err = dynamicResource.Namespace(obj.GetNamespace()).Get(k.ctx, obj.GetName(), metav1.GetOptions{})
-		if err != nil {
-			// Если ресурс не существует, создаем его
-			_, err = dynamicResource.Namespace(obj.GetNamespace()).Create(k.ctx, obj, metav1.CreateOptions{})
-			if err != nil {
-				return fmt.Errorf("ошибка при создании ресурса %s: %w", obj.GetName(), err)
-			}
-			fmt.Printf("Создан ресурс: %s/%s\n", obj.GetKind(), obj.GetName())
+		if result.Action == "created" {
+			fmt.Printf("Создан ресурс: %s/%s\n", result.Kind, result.Name)
 		} else {
-			// Если ресурс существует, обновляем его
-			_, err = dynamicResource.Namespace(obj.GetNamespace()).Update(k.ctx, obj, metav1.UpdateOptions{})
-			if err != nil {
-				return fmt.Errorf("ошибка при обновлении ресурса %s: %w", obj.GetName(), err)
-			}
-			fmt.Printf("Обновлен ресурс: %s/%s\n", obj.GetKind(), obj.GetName())
+			fmt.Printf("Обновлен ресурс: %s/%s\n", result.Kind, result.Name)
 		}
+		results = append(results, result)
 	}
 
-	return nil
+	return results, nil
 }
 
 // Scale изменяет количество реплик для деплоймента
-func (k *K8sAdapter) Scale(namespace, name string, replicas int32) error {
+func (k *K8sAdapter) Scale(namespace, name string, replicas int32) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "scale", attribute.String("namespace", namespace), attribute.String("name", name))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "scale", namespace+"/"+name, err)
+	}()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		deployment, err := k.clientset.AppsV1().Deployments(namespace).Get(k.ctx, name, metav1.GetOptions{})
+		deployment, err := k.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
 			return err
 		}
 
 		deployment.Spec.Replicas = &replicas
-		_, err = k.clientset.AppsV1().Deployments(namespace).Update(k.ctx, deployment, metav1.UpdateOptions{})
+		_, err = k.clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
 		return err
 	})
 }
 
 // GetPodStatus возвращает статус конкретного пода
-func (k *K8sAdapter) GetPodStatus(namespace, name string) (*PodStatus, error) {
-	pod, err := k.clientset.CoreV1().Pods(namespace).Get(k.ctx, name, metav1.GetOptions{})
+func (k *K8sAdapter) GetPodStatus(namespace, name string) (_ *PodStatus, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "get_pod_status", attribute.String("namespace", namespace), attribute.String("name", name))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	pod, err := k.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении пода: %w", err)
 	}
@@ -232,7 +321,7 @@ func (k *K8sAdapter) GetPodStatus(namespace, name string) (*PodStatus, error) {
 		Status:    string(pod.Status.Phase),
 		IP:        pod.Status.PodIP,
 		Node:      pod.Spec.NodeName,
-		Age:       time.Since(pod.CreationTimestamp.Time),
+		Age:       k.since(pod.CreationTimestamp.Time),
 	}
 
 	// Проверяем готовность пода
@@ -248,11 +337,37 @@ func (k *K8sAdapter) GetPodStatus(namespace, name string) (*PodStatus, error) {
 	return status, nil
 }
 
-// GetPodStatuses возвращает статусы всех подов в указанном namespace
-func (k *K8sAdapter) GetPodStatuses(namespace string) ([]PodStatus, error) {
-	pods, err := k.clientset.CoreV1().Pods(namespace).List(k.ctx, metav1.ListOptions{})
+// GetPodStatuses возвращает статусы всех подов в указанном namespace.
+// AllNamespaces возвращает поды всех namespace.
+func (k *K8sAdapter) GetPodStatuses(namespace string) (_ []PodStatus, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "get_pod_statuses", attribute.String("namespace", namespace))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	return listAll(func(page ListPage) ([]PodStatus, string, error) {
+		return k.podStatusesPage(ctx, namespace, page)
+	})
+}
+
+// GetPodStatusesPage возвращает одну страницу статусов подов и токен
+// следующей страницы. Пустой токен означает, что страница последняя.
+func (k *K8sAdapter) GetPodStatusesPage(namespace string, page ListPage) (_ []PodStatus, next string, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "get_pod_statuses_page", attribute.String("namespace", namespace), attribute.Int64("page.limit", page.Limit))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	return k.podStatusesPage(ctx, namespace, page)
+}
+
+// podStatusesPage получает страницу подов и преобразует их в PodStatus
+func (k *K8sAdapter) podStatusesPage(ctx context.Context, namespace string, page ListPage) ([]PodStatus, string, error) {
+	pods, err := k.clientset.CoreV1().Pods(namespace).List(ctx, page.listOptions())
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении списка подов: %w", err)
+		return nil, "", fmt.Errorf("ошибка при получении списка подов: %w", err)
 	}
 
 	var statuses []PodStatus
@@ -263,7 +378,7 @@ func (k *K8sAdapter) GetPodStatuses(namespace string) ([]PodStatus, error) {
 			Status:    string(pod.Status.Phase),
 			IP:        pod.Status.PodIP,
 			Node:      pod.Spec.NodeName,
-			Age:       time.Since(pod.CreationTimestamp.Time),
+			Age:       k.since(pod.CreationTimestamp.Time),
 		}
 
 		// Проверяем готовность пода
@@ -279,28 +394,43 @@ func (k *K8sAdapter) GetPodStatuses(namespace string) ([]PodStatus, error) {
 		statuses = append(statuses, status)
 	}
 
-	return statuses, nil
+	return statuses, pods.Continue, nil
 }
 
 // DeleteResource удаляет ресурс указанного типа и имени
-func (k *K8sAdapter) DeleteResource(namespace, resourceType, name string) error {
+func (k *K8sAdapter) DeleteResource(namespace, resourceType, name string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "delete_resource", attribute.String("namespace", namespace), attribute.String("resource.type", resourceType), attribute.String("name", name))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "delete_resource", namespace+"/"+resourceType+"/"+name, err)
+	}()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
 	switch resourceType {
 	case "deployment":
-		return k.clientset.AppsV1().Deployments(namespace).Delete(k.ctx, name, metav1.DeleteOptions{})
+		return k.clientset.AppsV1().Deployments(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	case "service":
-		return k.clientset.CoreV1().Services(namespace).Delete(k.ctx, name, metav1.DeleteOptions{})
+		return k.clientset.CoreV1().Services(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	case "pod":
-		return k.clientset.CoreV1().Pods(namespace).Delete(k.ctx, name, metav1.DeleteOptions{})
+		return k.clientset.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	case "configmap":
-		return k.clientset.CoreV1().ConfigMaps(namespace).Delete(k.ctx, name, metav1.DeleteOptions{})
+		return k.clientset.CoreV1().ConfigMaps(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	default:
 		return fmt.Errorf("неподдерживаемый тип ресурса: %s", resourceType)
 	}
 }
 
 // GetDeploymentStatus возвращает статус деплоймента
-func (k *K8sAdapter) GetDeploymentStatus(namespace, name string) (*DeploymentStatus, error) {
-	deployment, err := k.clientset.AppsV1().Deployments(namespace).Get(k.ctx, name, metav1.GetOptions{})
+func (k *K8sAdapter) GetDeploymentStatus(namespace, name string) (_ *DeploymentStatus, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "get_deployment_status", attribute.String("namespace", namespace), attribute.String("name", name))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	deployment, err := k.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении деплоймента: %w", err)
 	}
@@ -323,10 +453,17 @@ func (k *K8sAdapter) GetDeploymentStatus(namespace, name string) (*DeploymentSta
 	return status, nil
 }
 
-// GetServicesAndIngresses возвращает информацию о сервисах и ингрессах
-func (k *K8sAdapter) GetServicesAndIngresses(namespace string) ([]ServiceInfo, []IngressInfo, error) {
+// GetServicesAndIngresses возвращает информацию о сервисах и ингрессах.
+// AllNamespaces возвращает ресурсы всех namespace.
+func (k *K8sAdapter) GetServicesAndIngresses(namespace string) (_ []ServiceInfo, _ []IngressInfo, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "get_services_and_ingresses", attribute.String("namespace", namespace))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
 	// Получаем список сервисов
-	services, err := k.clientset.CoreV1().Services(namespace).List(k.ctx, metav1.ListOptions{})
+	services, err := k.clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, nil, fmt.Errorf("ошибка при получении списка сервисов: %w", err)
 	}
@@ -338,7 +475,7 @@ func (k *K8sAdapter) GetServicesAndIngresses(namespace string) ([]ServiceInfo, [
 			Namespace: svc.Namespace,
 			Type:      string(svc.Spec.Type),
 			ClusterIP: svc.Spec.ClusterIP,
-			Age:       time.Since(svc.CreationTimestamp.Time),
+			Age:       k.since(svc.CreationTimestamp.Time),
 		}
 
 		// Добавляем внешние IP
@@ -363,7 +500,7 @@ func (k *K8sAdapter) GetServicesAndIngresses(namespace string) ([]ServiceInfo, [
 	}
 
 	// Получаем список ингрессов
-	ingresses, err := k.clientset.NetworkingV1().Ingresses(namespace).List(k.ctx, metav1.ListOptions{})
+	ingresses, err := k.clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		// Если ошибка связана с тем, что API не поддерживается, возвращаем только сервисы
 		if errors.IsNotFound(err) {
@@ -377,7 +514,7 @@ func (k *K8sAdapter) GetServicesAndIngresses(namespace string) ([]ServiceInfo, [
 		info := IngressInfo{
 			Name:      ing.Name,
 			Namespace: ing.Namespace,
-			Age:       time.Since(ing.CreationTimestamp.Time),
+			Age:       k.since(ing.CreationTimestamp.Time),
 		}
 
 		// Добавляем хосты
@@ -404,7 +541,16 @@ func (k *K8sAdapter) GetServicesAndIngresses(namespace string) ([]ServiceInfo, [
 }
 
 // CreateOrUpdateConfigMap создает или обновляет ConfigMap
-func (k *K8sAdapter) CreateOrUpdateConfigMap(namespace, name string, data map[string]string) error {
+func (k *K8sAdapter) CreateOrUpdateConfigMap(namespace, name string, data map[string]string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "create_or_update_config_map", attribute.String("namespace", namespace), attribute.String("name", name))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "create_or_update_config_map", namespace+"/"+name, err)
+	}()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
 	configMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -413,26 +559,40 @@ func (k *K8sAdapter) CreateOrUpdateConfigMap(namespace, name string, data map[st
 		Data: data,
 	}
 
-	_, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(k.ctx, name, metav1.GetOptions{})
-	if err != nil {
-		// Если ConfigMap не существует, создаем его
-		_, err = k.clientset.CoreV1().ConfigMaps(namespace).Create(k.ctx, configMap, metav1.CreateOptions{})
+	// При конфликте версий ConfigMap перечитывается и обновление повторяется
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			// Если ConfigMap не существует, создаем его
+			if _, err := k.clientset.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("ошибка при создании ConfigMap: %w", err)
+			}
+			return nil
+		}
 		if err != nil {
-			return fmt.Errorf("ошибка при создании ConfigMap: %w", err)
+			return fmt.Errorf("ошибка при получении ConfigMap: %w", err)
 		}
-	} else {
+
 		// Если ConfigMap существует, обновляем его
-		_, err = k.clientset.CoreV1().ConfigMaps(namespace).Update(k.ctx, configMap, metav1.UpdateOptions{})
-		if err != nil {
+		configMap.ResourceVersion = existing.ResourceVersion
+		if _, err := k.clientset.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
 			return fmt.Errorf("ошибка при обновлении ConfigMap: %w", err)
 		}
-	}
-
-	return nil
+		return nil
+	})
 }
 
 // CreateOrUpdateSecret создает или обновляет Secret
-func (k *K8sAdapter) CreateOrUpdateSecret(namespace, name, secretType string, data map[string][]byte) error {
+func (k *K8sAdapter) CreateOrUpdateSecret(namespace, name, secretType string, data map[string][]byte) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "create_or_update_secret", attribute.String("namespace", namespace), attribute.String("name", name), attribute.String("secret.type", secretType))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "create_or_update_secret", namespace+"/"+name, err)
+	}()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -442,27 +602,115 @@ func (k *K8sAdapter) CreateOrUpdateSecret(namespace, name, secretType string, da
 		Data: data,
 	}
 
-	_, err := k.clientset.CoreV1().Secrets(namespace).Get(k.ctx, name, metav1.GetOptions{})
-	if err != nil {
-		// Если Secret не существует, создаем его
-		_, err = k.clientset.CoreV1().Secrets(namespace).Create(k.ctx, secret, metav1.CreateOptions{})
+	// При конфликте версий Secret перечитывается и обновление повторяется
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := k.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			// Если Secret не существует, создаем его
+			if _, err := k.clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("ошибка при создании Secret: %w", err)
+			}
+			return nil
+		}
 		if err != nil {
-			return fmt.Errorf("ошибка при создании Secret: %w", err)
+			return fmt.Errorf("ошибка при получении Secret: %w", err)
 		}
-	} else {
+
 		// Если Secret существует, обновляем его
-		_, err = k.clientset.CoreV1().Secrets(namespace).Update(k.ctx, secret, metav1.UpdateOptions{})
-		if err != nil {
+		secret.ResourceVersion = existing.ResourceVersion
+		if _, err := k.clientset.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
 			return fmt.Errorf("ошибка при обновлении Secret: %w", err)
 		}
+		return nil
+	})
+}
+
+// SetSecretKey изменяет один ключ Secret, сохраняя остальные ключи и тип.
+// Если Secret не существует, создается Secret типа Opaque.
+func (k *K8sAdapter) SetSecretKey(namespace, name, key string, value []byte) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "set_secret_key", attribute.String("namespace", namespace), attribute.String("name", name), attribute.String("key", key))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "set_secret_key", namespace+"/"+name+"/"+key, err)
+	}()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := k.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: map[string][]byte{key: value},
+			}
+			_, err = k.clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[key] = value
+
+		_, err = k.clientset.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении ключа %s в Secret: %w", key, err)
+	}
+
+	return nil
+}
+
+// DeleteSecretKey удаляет один ключ Secret, сохраняя остальные
+func (k *K8sAdapter) DeleteSecretKey(namespace, name, key string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "delete_secret_key", attribute.String("namespace", namespace), attribute.String("name", name), attribute.String("key", key))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "delete_secret_key", namespace+"/"+name+"/"+key, err)
+	}()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := k.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if _, ok := secret.Data[key]; !ok {
+			return fmt.Errorf("ключ %s не найден", key)
+		}
+		delete(secret.Data, key)
+
+		_, err = k.clientset.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении ключа %s из Secret: %w", key, err)
 	}
 
 	return nil
 }
 
 // GetConfigMapInfo возвращает информацию о ConfigMap
-func (k *K8sAdapter) GetConfigMapInfo(namespace, name string) (*ConfigMapInfo, error) {
-	configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(k.ctx, name, metav1.GetOptions{})
+func (k *K8sAdapter) GetConfigMapInfo(namespace, name string) (_ *ConfigMapInfo, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "get_config_map_info", attribute.String("namespace", namespace), attribute.String("name", name))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении ConfigMap: %w", err)
 	}
@@ -471,13 +719,116 @@ func (k *K8sAdapter) GetConfigMapInfo(namespace, name string) (*ConfigMapInfo, e
 		Name:      configMap.Name,
 		Namespace: configMap.Namespace,
 		Data:      configMap.Data,
-		Age:       time.Since(configMap.CreationTimestamp.Time),
+		Age:       k.since(configMap.CreationTimestamp.Time),
 	}, nil
 }
 
+// GetConfigMapData возвращает значение одного ключа ConfigMap
+func (k *K8sAdapter) GetConfigMapData(namespace, name, key string) (_ string, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "get_config_map_data", attribute.String("namespace", namespace), attribute.String("name", name), attribute.String("key", key))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("ошибка при получении ConfigMap: %w", err)
+	}
+
+	value, ok := configMap.Data[key]
+	if !ok {
+		return "", fmt.Errorf("ключ %s не найден в ConfigMap %s", key, name)
+	}
+
+	return value, nil
+}
+
+// SetConfigMapKey изменяет один ключ ConfigMap, сохраняя остальные.
+// Если ConfigMap не существует, он создается.
+func (k *K8sAdapter) SetConfigMapKey(namespace, name, key, value string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "set_config_map_key", attribute.String("namespace", namespace), attribute.String("name", name), attribute.String("key", key))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "set_config_map_key", namespace+"/"+name+"/"+key, err)
+	}()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Data: map[string]string{key: value},
+			}
+			_, err = k.clientset.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[key] = value
+
+		_, err = k.clientset.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении ключа %s в ConfigMap: %w", key, err)
+	}
+
+	return nil
+}
+
+// DeleteConfigMapKey удаляет один ключ ConfigMap, сохраняя остальные
+func (k *K8sAdapter) DeleteConfigMapKey(namespace, name, key string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "delete_config_map_key", attribute.String("namespace", namespace), attribute.String("name", name), attribute.String("key", key))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "delete_config_map_key", namespace+"/"+name+"/"+key, err)
+	}()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if _, ok := configMap.Data[key]; !ok {
+			return fmt.Errorf("ключ %s не найден", key)
+		}
+		delete(configMap.Data, key)
+
+		_, err = k.clientset.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении ключа %s из ConfigMap: %w", key, err)
+	}
+
+	return nil
+}
+
 // GetSecretInfo возвращает информацию о Secret
-func (k *K8sAdapter) GetSecretInfo(namespace, name string) (*SecretInfo, error) {
-	secret, err := k.clientset.CoreV1().Secrets(namespace).Get(k.ctx, name, metav1.GetOptions{})
+func (k *K8sAdapter) GetSecretInfo(namespace, name string) (_ *SecretInfo, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "get_secret_info", attribute.String("namespace", namespace), attribute.String("name", name))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	secret, err := k.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении Secret: %w", err)
 	}
@@ -492,13 +843,19 @@ func (k *K8sAdapter) GetSecretInfo(namespace, name string) (*SecretInfo, error)
 		Namespace: secret.Namespace,
 		Type:      string(secret.Type),
 		Keys:      keys,
-		Age:       time.Since(secret.CreationTimestamp.Time),
+		Age:       k.since(secret.CreationTimestamp.Time),
 	}, nil
 }
 
 // GetNginxConfig возвращает текущую конфигурацию nginx
-func (k *K8sAdapter) GetNginxConfig(namespace, configMapName string) (*NginxConfig, error) {
-	configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(k.ctx, configMapName, metav1.GetOptions{})
+func (k *K8sAdapter) GetNginxConfig(namespace, configMapName string) (_ *NginxConfig, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "get_nginx_config", attribute.String("namespace", namespace), attribute.String("config.map.name", configMapName))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении ConfigMap: %w", err)
 	}
@@ -524,12 +881,15 @@ func (k *K8sAdapter) GetNginxConfig(namespace, configMapName string) (*NginxConf
 }
 
 // UpdateNginxConfig обновляет конфигурацию nginx
-func (k *K8sAdapter) UpdateNginxConfig(namespace, configMapName string, config *NginxConfig) error {
-	// Получаем текущий ConfigMap
-	configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(k.ctx, configMapName, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("ошибка при получении ConfigMap: %w", err)
-	}
+func (k *K8sAdapter) UpdateNginxConfig(namespace, configMapName string, config *NginxConfig) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "update_nginx_config", attribute.String("namespace", namespace), attribute.String("config.map.name", configMapName))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "update_nginx_config", namespace+"/"+configMapName, err)
+	}()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
 
 	// Формируем новую конфигурацию nginx
 	nginxConf := fmt.Sprintf(`user nginx;
@@ -559,23 +919,59 @@ http {
 }`, config.WorkerProcesses, config.WorkerConnections, config.KeepaliveTimeout,
 		config.ServerName, config.RootPath, config.IndexFile)
 
-	// Обновляем ConfigMap
-	configMap.Data["nginx.conf"] = nginxConf
+	// При конфликте версий ConfigMap перечитывается и обновление повторяется
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// Получаем текущий ConfigMap
+		configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("ошибка при получении ConfigMap: %w", err)
+		}
+
+		// Обновляем ConfigMap
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data["nginx.conf"] = nginxConf
 
-	// Сохраняем изменения
-	_, err = k.clientset.CoreV1().ConfigMaps(namespace).Update(k.ctx, configMap, metav1.UpdateOptions{})
-	if err != nil {
-		return fmt.Errorf("ошибка при обновлении ConfigMap: %w", err)
-	}
+		// Сохраняем изменения
+		if _, err := k.clientset.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("ошибка при обновлении ConfigMap: %w", err)
+		}
+		return nil
+	})
+}
 
-	return nil
+// ListConfigMaps возвращает список всех ConfigMap в указанном namespace.
+// AllNamespaces возвращает ConfigMap всех namespace.
+func (k *K8sAdapter) ListConfigMaps(namespace string) (_ []ConfigMapListItem, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "list_config_maps", attribute.String("namespace", namespace))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	return listAll(func(page ListPage) ([]ConfigMapListItem, string, error) {
+		return k.configMapsPage(ctx, namespace, page)
+	})
 }
 
-// ListConfigMaps возвращает список всех ConfigMap в указанном namespace
-func (k *K8sAdapter) ListConfigMaps(namespace string) ([]ConfigMapListItem, error) {
-	configMaps, err := k.clientset.CoreV1().ConfigMaps(namespace).List(k.ctx, metav1.ListOptions{})
+// ListConfigMapsPage возвращает одну страницу ConfigMap и токен следующей
+// страницы. Пустой токен означает, что страница последняя.
+func (k *K8sAdapter) ListConfigMapsPage(namespace string, page ListPage) (_ []ConfigMapListItem, next string, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "list_config_maps_page", attribute.String("namespace", namespace), attribute.Int64("page.limit", page.Limit))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	return k.configMapsPage(ctx, namespace, page)
+}
+
+// configMapsPage получает страницу ConfigMap
+func (k *K8sAdapter) configMapsPage(ctx context.Context, namespace string, page ListPage) ([]ConfigMapListItem, string, error) {
+	configMaps, err := k.clientset.CoreV1().ConfigMaps(namespace).List(ctx, page.listOptions())
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении списка ConfigMap: %w", err)
+		return nil, "", fmt.Errorf("ошибка при получении списка ConfigMap: %w", err)
 	}
 
 	var items []ConfigMapListItem
@@ -588,19 +984,45 @@ func (k *K8sAdapter) ListConfigMaps(namespace string) ([]ConfigMapListItem, erro
 		items = append(items, ConfigMapListItem{
 			Name:      cm.Name,
 			Namespace: cm.Namespace,
-			Age:       time.Since(cm.CreationTimestamp.Time),
+			Age:       k.since(cm.CreationTimestamp.Time),
 			Keys:      keys,
 		})
 	}
 
-	return items, nil
+	return items, configMaps.Continue, nil
+}
+
+// ListSecrets возвращает список всех секретов в указанном namespace.
+// AllNamespaces возвращает секреты всех namespace.
+func (k *K8sAdapter) ListSecrets(namespace string) (_ []SecretListItem, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "list_secrets", attribute.String("namespace", namespace))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	return listAll(func(page ListPage) ([]SecretListItem, string, error) {
+		return k.secretsPage(ctx, namespace, page)
+	})
+}
+
+// ListSecretsPage возвращает одну страницу секретов и токен следующей
+// страницы. Пустой токен означает, что страница последняя.
+func (k *K8sAdapter) ListSecretsPage(namespace string, page ListPage) (_ []SecretListItem, next string, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "list_secrets_page", attribute.String("namespace", namespace), attribute.Int64("page.limit", page.Limit))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	return k.secretsPage(ctx, namespace, page)
 }
 
-// ListSecrets возвращает список всех секретов в указанном namespace
-func (k *K8sAdapter) ListSecrets(namespace string) ([]SecretListItem, error) {
-	secrets, err := k.clientset.CoreV1().Secrets(namespace).List(k.ctx, metav1.ListOptions{})
+// secretsPage получает страницу секретов
+func (k *K8sAdapter) secretsPage(ctx context.Context, namespace string, page ListPage) ([]SecretListItem, string, error) {
+	secrets, err := k.clientset.CoreV1().Secrets(namespace).List(ctx, page.listOptions())
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении списка секретов: %w", err)
+		return nil, "", fmt.Errorf("ошибка при получении списка секретов: %w", err)
 	}
 
 	var items []SecretListItem
@@ -614,10 +1036,10 @@ func (k *K8sAdapter) ListSecrets(namespace string) ([]SecretListItem, error) {
 			Name:      secret.Name,
 			Namespace: secret.Namespace,
 			Type:      string(secret.Type),
-			Age:       time.Since(secret.CreationTimestamp.Time),
+			Age:       k.since(secret.CreationTimestamp.Time),
 			Keys:      keys,
 		})
 	}
 
-	return items, nil
+	return items, secrets.Continue, nil
 }
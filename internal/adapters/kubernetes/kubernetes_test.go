@@ -2,16 +2,27 @@ package kubernetes
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/localops/devops-manager/internal/clock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -20,6 +31,11 @@ const (
 )
 
 func setupKindCluster(t *testing.T) (string, func()) {
+	// Без kind поднять тестовый кластер невозможно
+	if _, err := exec.LookPath("kind"); err != nil {
+		t.Skip("kind не найден в PATH, пропускаем тест с кластером")
+	}
+
 	// Создаем конфигурацию для kind
 	config := `kind: Cluster
 apiVersion: kind.x-k8s.io/v1alpha4
@@ -122,3 +138,300 @@ func TestK8sAdapter_DeployScaleDelete(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestK8sAdapter_Timeout(t *testing.T) {
+	// API сервер, который отвечает дольше таймаута адаптера
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+
+	adapter := &K8sAdapter{
+		clientset: clientset,
+		ctx:       context.Background(),
+	}
+	adapter.SetTimeout(100 * time.Millisecond)
+
+	start := time.Now()
+	_, err = adapter.GetPodStatus("default", "test-pod")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "ожидалась ошибка deadline exceeded, получено: %v", err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func newFakeConfigMapAdapter() (*K8sAdapter, *fake.Clientset) {
+	clientset := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "default"},
+		Data: map[string]string{
+			"LOG_LEVEL": "info",
+			"PORT":      "8080",
+		},
+	})
+
+	return &K8sAdapter{
+		clientset: clientset,
+		ctx:       context.Background(),
+	}, clientset
+}
+
+func TestPodAgeUsesClock(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "web",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+
+	fakeClock := clock.NewFake(created.Add(90 * time.Minute))
+	adapter := &K8sAdapter{
+		clientset: fake.NewSimpleClientset(pod),
+		ctx:       context.Background(),
+		clock:     fakeClock,
+	}
+
+	status, err := adapter.GetPodStatus("default", "web")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, status.Age)
+
+	fakeClock.Advance(30 * time.Minute)
+	statuses, err := adapter.GetPodStatuses("default")
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, 2*time.Hour, statuses[0].Age)
+}
+
+func TestSetConfigMapKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmName   string
+		key      string
+		value    string
+		expected map[string]string
+	}{
+		{
+			name:   "изменение существующего ключа",
+			cmName: "app-config",
+			key:    "LOG_LEVEL",
+			value:  "debug",
+			expected: map[string]string{
+				"LOG_LEVEL": "debug",
+				"PORT":      "8080",
+			},
+		},
+		{
+			name:   "добавление нового ключа",
+			cmName: "app-config",
+			key:    "TIMEOUT",
+			value:  "30s",
+			expected: map[string]string{
+				"LOG_LEVEL": "info",
+				"PORT":      "8080",
+				"TIMEOUT":   "30s",
+			},
+		},
+		{
+			name:     "создание нового ConfigMap",
+			cmName:   "new-config",
+			key:      "KEY",
+			value:    "value",
+			expected: map[string]string{"KEY": "value"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, clientset := newFakeConfigMapAdapter()
+
+			err := adapter.SetConfigMapKey("default", tt.cmName, tt.key, tt.value)
+			require.NoError(t, err)
+
+			configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.Background(), tt.cmName, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, configMap.Data)
+
+			value, err := adapter.GetConfigMapData("default", tt.cmName, tt.key)
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, value)
+		})
+	}
+}
+
+func TestDeleteConfigMapKey(t *testing.T) {
+	adapter, clientset := newFakeConfigMapAdapter()
+
+	err := adapter.DeleteConfigMapKey("default", "app-config", "LOG_LEVEL")
+	require.NoError(t, err)
+
+	configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.Background(), "app-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"PORT": "8080"}, configMap.Data)
+
+	// Повторное удаление того же ключа возвращает ошибку
+	err = adapter.DeleteConfigMapKey("default", "app-config", "LOG_LEVEL")
+	assert.Error(t, err)
+
+	_, err = adapter.GetConfigMapData("default", "app-config", "LOG_LEVEL")
+	assert.Error(t, err)
+}
+
+func TestSetSecretKey(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "default"},
+		Type:       corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			"username": []byte("admin"),
+			"password": []byte("old-password"),
+		},
+	})
+	adapter := &K8sAdapter{
+		clientset: clientset,
+		ctx:       context.Background(),
+	}
+
+	err := adapter.SetSecretKey("default", "db-credentials", "password", []byte("new-password"))
+	require.NoError(t, err)
+
+	secret, err := clientset.CoreV1().Secrets("default").Get(context.Background(), "db-credentials", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeBasicAuth, secret.Type)
+	assert.Equal(t, map[string][]byte{
+		"username": []byte("admin"),
+		"password": []byte("new-password"),
+	}, secret.Data)
+
+	err = adapter.DeleteSecretKey("default", "db-credentials", "password")
+	require.NoError(t, err)
+
+	secret, err = clientset.CoreV1().Secrets("default").Get(context.Background(), "db-credentials", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"username": []byte("admin")}, secret.Data)
+	assert.Equal(t, corev1.SecretTypeBasicAuth, secret.Type)
+}
+
+func TestUpdateRetriesOnConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		update   func(adapter *K8sAdapter) error
+		check    func(t *testing.T, clientset *fake.Clientset)
+	}{
+		{
+			name:     "CreateOrUpdateConfigMap",
+			resource: "configmaps",
+			update: func(adapter *K8sAdapter) error {
+				return adapter.CreateOrUpdateConfigMap("default", "app-config", map[string]string{"LOG_LEVEL": "debug"})
+			},
+			check: func(t *testing.T, clientset *fake.Clientset) {
+				configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.Background(), "app-config", metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, map[string]string{"LOG_LEVEL": "debug"}, configMap.Data)
+			},
+		},
+		{
+			name:     "CreateOrUpdateSecret",
+			resource: "secrets",
+			update: func(adapter *K8sAdapter) error {
+				return adapter.CreateOrUpdateSecret("default", "db-credentials", "Opaque", map[string][]byte{"password": []byte("new")})
+			},
+			check: func(t *testing.T, clientset *fake.Clientset) {
+				secret, err := clientset.CoreV1().Secrets("default").Get(context.Background(), "db-credentials", metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, []byte("new"), secret.Data["password"])
+			},
+		},
+		{
+			name:     "UpdateNginxConfig",
+			resource: "configmaps",
+			update: func(adapter *K8sAdapter) error {
+				return adapter.UpdateNginxConfig("default", "app-config", &NginxConfig{WorkerProcesses: "4", ServerName: "example.com"})
+			},
+			check: func(t *testing.T, clientset *fake.Clientset) {
+				configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.Background(), "app-config", metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Contains(t, configMap.Data["nginx.conf"], "server_name example.com;")
+				assert.Equal(t, "info", configMap.Data["LOG_LEVEL"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "default"},
+					Data:       map[string]string{"LOG_LEVEL": "info"},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "default"},
+					Data:       map[string][]byte{"password": []byte("old")},
+				},
+			)
+
+			// Первое обновление завершается конфликтом, как при одновременном изменении
+			updates := 0
+			clientset.PrependReactor("update", tt.resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+				updates++
+				if updates == 1 {
+					return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: tt.resource}, "conflict", errors.New("объект изменен"))
+				}
+				return false, nil, nil
+			})
+
+			adapter := &K8sAdapter{clientset: clientset, ctx: context.Background()}
+			require.NoError(t, tt.update(adapter))
+			assert.Equal(t, 2, updates)
+			tt.check(t, clientset)
+		})
+	}
+}
+
+func TestListAllNamespaces(t *testing.T) {
+	adapter := &K8sAdapter{
+		clientset: fake.NewSimpleClientset(
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "frontend"}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "backend"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "frontend"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "api-config", Namespace: "backend"}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api-token", Namespace: "backend"}},
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "frontend"}},
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "backend"}},
+		),
+		ctx: context.Background(),
+	}
+
+	pods, err := adapter.GetPodStatuses(AllNamespaces)
+	require.NoError(t, err)
+	var podNamespaces []string
+	for _, pod := range pods {
+		podNamespaces = append(podNamespaces, pod.Namespace+"/"+pod.Name)
+	}
+	assert.ElementsMatch(t, []string{"frontend/web", "backend/api"}, podNamespaces)
+
+	configMaps, err := adapter.ListConfigMaps(AllNamespaces)
+	require.NoError(t, err)
+	assert.Len(t, configMaps, 2)
+
+	secrets, err := adapter.ListSecrets(AllNamespaces)
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, "backend", secrets[0].Namespace)
+
+	services, _, err := adapter.GetServicesAndIngresses(AllNamespaces)
+	require.NoError(t, err)
+	assert.Len(t, services, 2)
+
+	// Конкретный namespace по-прежнему ограничивает список
+	pods, err = adapter.GetPodStatuses("frontend")
+	require.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "web", pods[0].Name)
+}
@@ -0,0 +1,147 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+)
+
+// maxAnnotationsSize ограничение Kubernetes на суммарный размер аннотаций
+const maxAnnotationsSize = 256 * 1024
+
+// AddLabels добавляет метки ресурсу kind/name. Существующие метки
+// сохраняются, метки с теми же ключами перезаписываются. kind задается
+// как в kubectl: Deployment, deployment или deployments.
+func (k *K8sAdapter) AddLabels(namespace, kind, name string, labels map[string]string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "add_labels", attribute.String("namespace", namespace), attribute.String("kind", kind), attribute.String("name", name))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "add_labels", namespace+"/"+kind+"/"+name, err)
+	}()
+
+	if err := validateLabels(labels); err != nil {
+		return err
+	}
+	return k.patchMetadata(ctx, namespace, kind, name, "labels", labels)
+}
+
+// AddAnnotations добавляет аннотации ресурсу kind/name, как AddLabels
+func (k *K8sAdapter) AddAnnotations(namespace, kind, name string, annotations map[string]string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "add_annotations", attribute.String("namespace", namespace), attribute.String("kind", kind), attribute.String("name", name))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "add_annotations", namespace+"/"+kind+"/"+name, err)
+	}()
+
+	if err := validateAnnotations(annotations); err != nil {
+		return err
+	}
+	return k.patchMetadata(ctx, namespace, kind, name, "annotations", annotations)
+}
+
+// patchMetadata объединяет values с полем metadata.<field> ресурса через
+// merge patch, который поддерживают и встроенные, и custom ресурсы
+func (k *K8sAdapter) patchMetadata(ctx context.Context, namespace, kind, name, field string, values map[string]string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			field: values,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании патча: %w", err)
+	}
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	resource, err := k.resourceForKind(namespace, kind)
+	if err != nil {
+		return err
+	}
+
+	if _, err := resource.Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("ошибка при обновлении %s %s: %w", kind, name, err)
+	}
+	return nil
+}
+
+// resourceForKind возвращает dynamic клиент для вида ресурса kind.
+// Для namespaced ресурсов пустой namespace заменяется на default.
+func (k *K8sAdapter) resourceForKind(namespace, kind string) (dynamic.ResourceInterface, error) {
+	mapper, err := k.newRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+
+	gvk, err := mapper.KindFor(schema.GroupVersionResource{Resource: strings.ToLower(kind)})
+	if err != nil {
+		return nil, fmt.Errorf("неизвестный вид ресурса %s: %w", kind, err)
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении mapping: %w", err)
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return k.dynamic.Resource(mapping.Resource), nil
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	return k.dynamic.Resource(mapping.Resource).Namespace(namespace), nil
+}
+
+// validateLabels проверяет ключи и значения меток по правилам Kubernetes
+func validateLabels(labels map[string]string) error {
+	if len(labels) == 0 {
+		return fmt.Errorf("не указаны метки")
+	}
+
+	var problems []string
+	for _, key := range sortedKeys(labels, nil) {
+		for _, msg := range validation.IsQualifiedName(key) {
+			problems = append(problems, fmt.Sprintf("ключ %q: %s", key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(labels[key]) {
+			problems = append(problems, fmt.Sprintf("значение %q: %s", labels[key], msg))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("некорректные метки: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateAnnotations проверяет ключи аннотаций и их суммарный размер.
+// Значения аннотаций могут быть произвольными.
+func validateAnnotations(annotations map[string]string) error {
+	if len(annotations) == 0 {
+		return fmt.Errorf("не указаны аннотации")
+	}
+
+	var problems []string
+	var size int
+	for _, key := range sortedKeys(annotations, nil) {
+		for _, msg := range validation.IsQualifiedName(strings.ToLower(key)) {
+			problems = append(problems, fmt.Sprintf("ключ %q: %s", key, msg))
+		}
+		size += len(key) + len(annotations[key])
+	}
+	if size > maxAnnotationsSize {
+		problems = append(problems, fmt.Sprintf("суммарный размер %d байт превышает %d", size, maxAnnotationsSize))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("некорректные аннотации: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var deploymentsResource = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func TestAddLabels(t *testing.T) {
+	adapter, dynamicClient := newFakeApplyAdapter(t)
+
+	deployment := &unstructured.Unstructured{}
+	deployment.SetAPIVersion("apps/v1")
+	deployment.SetKind("Deployment")
+	deployment.SetName("web")
+	deployment.SetNamespace("default")
+	deployment.SetLabels(map[string]string{"app": "web", "owner": "team-a"})
+	deployment.SetAnnotations(map[string]string{"note": "keep"})
+	_, err := dynamicClient.Resource(deploymentsResource).Namespace("default").Create(context.Background(), deployment, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, adapter.AddLabels("default", "Deployment", "web", map[string]string{"owner": "team-x", "tier": "frontend"}))
+	require.NoError(t, adapter.AddAnnotations("default", "deployments", "web", map[string]string{"example.com/ticket": "OPS-42"}))
+
+	updated, err := dynamicClient.Resource(deploymentsResource).Namespace("default").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// Существующие метки сохраняются, совпадающие ключи перезаписываются
+	assert.Equal(t, map[string]string{"app": "web", "owner": "team-x", "tier": "frontend"}, updated.GetLabels())
+	assert.Equal(t, map[string]string{"note": "keep", "example.com/ticket": "OPS-42"}, updated.GetAnnotations())
+}
+
+func TestAddLabelsValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+	}{
+		{name: "без меток"},
+		{name: "ключ с пробелом", labels: map[string]string{"owner team": "x"}},
+		{name: "ключ с пустым префиксом", labels: map[string]string{"/owner": "x"}},
+		{name: "значение со слешем", labels: map[string]string{"owner": "team/x"}},
+		{name: "слишком длинное значение", labels: map[string]string{"owner": strings.Repeat("a", 64)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, dynamicClient := newFakeApplyAdapter(t)
+
+			assert.Error(t, adapter.AddLabels("default", "Deployment", "web", tt.labels))
+			assert.Empty(t, dynamicClient.Actions())
+		})
+	}
+}
+
+func TestAddLabelsUnknownKind(t *testing.T) {
+	adapter, _ := newFakeApplyAdapter(t)
+
+	err := adapter.AddLabels("default", "Widget", "web", map[string]string{"owner": "team-x"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,263 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// mirrorPodAnnotation ставится kubelet на статические (mirror) поды
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// drainPollInterval задает частоту повторов вытеснения и проверки удаления пода
+var drainPollInterval = 2 * time.Second
+
+// NodeInfo представляет информацию об узле кластера
+type NodeInfo struct {
+	Name           string
+	Ready          bool
+	Schedulable    bool
+	Roles          []string
+	KubeletVersion string
+	Age            time.Duration
+}
+
+// nodeRolePrefix префикс меток, которыми kubeadm помечает роли узлов
+const nodeRolePrefix = "node-role.kubernetes.io/"
+
+// ListNodes возвращает список узлов кластера
+func (k *K8sAdapter) ListNodes() (_ []NodeInfo, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "list_nodes")
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	nodes, err := k.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списка узлов: %w", err)
+	}
+
+	var result []NodeInfo
+	for _, node := range nodes.Items {
+		info := NodeInfo{
+			Name:           node.Name,
+			Schedulable:    !node.Spec.Unschedulable,
+			KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+			Age:            k.since(node.CreationTimestamp.Time),
+		}
+
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady {
+				info.Ready = condition.Status == corev1.ConditionTrue
+				break
+			}
+		}
+
+		for label := range node.Labels {
+			if strings.HasPrefix(label, nodeRolePrefix) {
+				info.Roles = append(info.Roles, strings.TrimPrefix(label, nodeRolePrefix))
+			}
+		}
+		sort.Strings(info.Roles)
+
+		result = append(result, info)
+	}
+
+	return result, nil
+}
+
+// CordonNode запрещает планирование новых подов на узел.
+// Уже запущенные поды продолжают работать.
+func (k *K8sAdapter) CordonNode(name string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "cordon_node", attribute.String("name", name))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "cordon_node", name, err)
+	}()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	return k.setUnschedulable(ctx, name, true)
+}
+
+// UncordonNode снова разрешает планирование подов на узел
+func (k *K8sAdapter) UncordonNode(name string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "uncordon_node", attribute.String("name", name))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "uncordon_node", name, err)
+	}()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	return k.setUnschedulable(ctx, name, false)
+}
+
+// DrainOptions задает параметры вывода узла на обслуживание
+type DrainOptions struct {
+	// GracePeriodSeconds переопределяет terminationGracePeriodSeconds подов
+	GracePeriodSeconds *int64
+	// Timeout общее время на вытеснение всех подов (по умолчанию 5m)
+	Timeout time.Duration
+	// Progress вызывается для каждого пода узла
+	Progress func(PodDrainStatus)
+}
+
+// PodDrainStatus описывает результат обработки одного пода при drain
+type PodDrainStatus struct {
+	Namespace string
+	Name      string
+	Action    string // evicted, skipped или failed
+	Reason    string
+	Err       error
+}
+
+// DrainNode помечает узел как недоступный для планирования и вытесняет
+// его поды через Eviction API с учетом PodDisruptionBudget. Поды
+// DaemonSet и mirror поды пропускаются.
+func (k *K8sAdapter) DrainNode(ctx context.Context, nodeName string, opts DrainOptions) (err error) {
+	ctx, span := tracing.Start(ctx, "kubernetes", "drain_node", attribute.String("node.name", nodeName))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "drain_node", nodeName, err)
+	}()
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	if err := k.setUnschedulable(ctx, nodeName, true); err != nil {
+		return err
+	}
+
+	pods, err := k.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка при получении подов узла %s: %w", nodeName, err)
+	}
+
+	report := func(status PodDrainStatus) {
+		if opts.Progress != nil {
+			opts.Progress(status)
+		}
+	}
+
+	var failed int
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != nodeName {
+			continue
+		}
+
+		status := PodDrainStatus{Namespace: pod.Namespace, Name: pod.Name}
+		if reason := drainSkipReason(pod); reason != "" {
+			status.Action = "skipped"
+			status.Reason = reason
+			report(status)
+			continue
+		}
+
+		if err := k.evictPod(ctx, pod, opts.GracePeriodSeconds); err != nil {
+			status.Action = "failed"
+			status.Err = err
+			report(status)
+			failed++
+			continue
+		}
+
+		status.Action = "evicted"
+		report(status)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("не удалось вытеснить %d подов с узла %s", failed, nodeName)
+	}
+
+	return nil
+}
+
+// drainSkipReason возвращает причину, по которой под не нужно вытеснять
+func drainSkipReason(pod *corev1.Pod) string {
+	if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+		return "mirror под"
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Controller != nil && *owner.Controller && owner.Kind == "DaemonSet" {
+			return "управляется DaemonSet"
+		}
+	}
+	return ""
+}
+
+// evictPod вытесняет под и ждет его удаления. Пока PodDisruptionBudget
+// не разрешает вытеснение (429), запрос повторяется.
+func (k *K8sAdapter) evictPod(ctx context.Context, pod *corev1.Pod, gracePeriod *int64) error {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+		DeleteOptions: &metav1.DeleteOptions{GracePeriodSeconds: gracePeriod},
+	}
+
+	err := wait.PollUntilContextCancel(ctx, drainPollInterval, true, func(ctx context.Context) (bool, error) {
+		err := k.clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+		switch {
+		case err == nil, errors.IsNotFound(err):
+			return true, nil
+		case errors.IsTooManyRequests(err):
+			// PodDisruptionBudget пока не разрешает вытеснение
+			return false, nil
+		default:
+			return false, err
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка при вытеснении пода %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	// Ждем, пока под будет удален или заменен новым с тем же именем
+	err = wait.PollUntilContextCancel(ctx, drainPollInterval, true, func(ctx context.Context) (bool, error) {
+		current, err := k.clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return current.UID != pod.UID, nil
+	})
+	if err != nil {
+		return fmt.Errorf("под %s/%s не удален: %w", pod.Namespace, pod.Name, err)
+	}
+
+	return nil
+}
+
+// setUnschedulable меняет признак spec.unschedulable узла
+func (k *K8sAdapter) setUnschedulable(ctx context.Context, nodeName string, unschedulable bool) error {
+	patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+	_, err := k.clientset.CoreV1().Nodes().Patch(ctx, nodeName, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении планирования узла %s: %w", nodeName, err)
+	}
+	return nil
+}
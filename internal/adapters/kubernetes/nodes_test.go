@@ -0,0 +1,139 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestPod(name, node string, mutate func(*corev1.Pod)) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name)},
+		Spec:       corev1.PodSpec{NodeName: node},
+	}
+	if mutate != nil {
+		mutate(pod)
+	}
+	return pod
+}
+
+func TestCordonUncordonNode(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+	)
+	adapter := &K8sAdapter{
+		clientset: clientset,
+		ctx:       context.Background(),
+	}
+
+	schedulable := func() bool {
+		nodes, err := adapter.ListNodes()
+		require.NoError(t, err)
+		require.Len(t, nodes, 1)
+		return nodes[0].Schedulable
+	}
+
+	assert.True(t, schedulable())
+
+	require.NoError(t, adapter.CordonNode("node-1"))
+	node, err := clientset.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, node.Spec.Unschedulable)
+	assert.False(t, schedulable())
+
+	require.NoError(t, adapter.UncordonNode("node-1"))
+	node, err = clientset.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, node.Spec.Unschedulable)
+	assert.True(t, schedulable())
+
+	assert.Error(t, adapter.CordonNode("missing"))
+}
+
+func TestDrainNode(t *testing.T) {
+	oldInterval := drainPollInterval
+	drainPollInterval = 10 * time.Millisecond
+	defer func() { drainPollInterval = oldInterval }()
+
+	isController := true
+	clientset := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		newTestPod("web", "node-1", nil),
+		newTestPod("worker", "node-1", nil),
+		newTestPod("fluentd", "node-1", func(pod *corev1.Pod) {
+			pod.OwnerReferences = []metav1.OwnerReference{
+				{Kind: "DaemonSet", Name: "fluentd", Controller: &isController},
+			}
+		}),
+		newTestPod("kube-apiserver", "node-1", func(pod *corev1.Pod) {
+			pod.Annotations = map[string]string{mirrorPodAnnotation: "hash"}
+		}),
+		newTestPod("other", "node-2", nil),
+	)
+
+	// Fake клиент не реализует вытеснение, имитируем его удалением пода.
+	// Первое вытеснение worker блокируется PodDisruptionBudget.
+	var evicted []string
+	blocked := false
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		create := action.(k8stesting.CreateAction)
+		if create.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := create.GetObject().(*policyv1.Eviction)
+		if eviction.Name == "worker" && !blocked {
+			blocked = true
+			return true, nil, newTooManyRequests()
+		}
+		evicted = append(evicted, eviction.Name)
+		err := clientset.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+		return true, nil, err
+	})
+
+	adapter := &K8sAdapter{
+		clientset: clientset,
+		ctx:       context.Background(),
+	}
+
+	actions := map[string]string{}
+	err := adapter.DrainNode(context.Background(), "node-1", DrainOptions{
+		Timeout: 5 * time.Second,
+		Progress: func(status PodDrainStatus) {
+			actions[status.Name] = status.Action
+		},
+	})
+	require.NoError(t, err)
+
+	node, err := clientset.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, node.Spec.Unschedulable)
+
+	assert.Equal(t, []string{"web", "worker"}, evicted)
+	assert.True(t, blocked)
+	assert.Equal(t, map[string]string{
+		"web":            "evicted",
+		"worker":         "evicted",
+		"fluentd":        "skipped",
+		"kube-apiserver": "skipped",
+	}, actions)
+
+	// Под с другого узла не затронут
+	_, err = clientset.CoreV1().Pods("default").Get(context.Background(), "other", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+// newTooManyRequests возвращает ошибку, которую API сервер отдает при блокировке PDB
+func newTooManyRequests() error {
+	return errors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+}
@@ -0,0 +1,44 @@
+package kubernetes
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AllNamespaces передается вместо namespace в методы списков, чтобы
+// получить ресурсы всех namespace кластера
+const AllNamespaces = metav1.NamespaceAll
+
+// listChunkSize размер порции, которой методы без пагинации получают
+// полный список, как kubectl --chunk-size
+var listChunkSize int64 = 500
+
+// ListPage задает страницу списка. Limit ограничивает число элементов
+// (0 - без ограничения), Continue - токен, полученный с предыдущей
+// страницей. Токен действует ограниченное время; после его истечения
+// API сервер возвращает ошибку 410 Gone и список нужно начать заново.
+type ListPage struct {
+	Limit    int64
+	Continue string
+}
+
+// listOptions возвращает параметры запроса для страницы
+func (p ListPage) listOptions() metav1.ListOptions {
+	return metav1.ListOptions{Limit: p.Limit, Continue: p.Continue}
+}
+
+// listAll получает все страницы списка порциями по listChunkSize
+func listAll[T any](fetch func(page ListPage) ([]T, string, error)) ([]T, error) {
+	var result []T
+	page := ListPage{Limit: listChunkSize}
+	for {
+		items, next, err := fetch(page)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+		if next == "" {
+			return result, nil
+		}
+		page.Continue = next
+	}
+}
@@ -0,0 +1,111 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// newPagedPodsAdapter создает адаптер на API сервере, который отдает
+// поды a, b, c страницами по limit и возвращает токен следующей страницы.
+// Fake clientset не поддерживает limit и continue, поэтому используется
+// настоящий клиент.
+func newPagedPodsAdapter(t *testing.T) (*K8sAdapter, func() []string) {
+	names := []string{"a", "b", "c"}
+
+	var mu sync.Mutex
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/default/pods" {
+			http.NotFound(w, r)
+			return
+		}
+
+		query := r.URL.Query()
+		mu.Lock()
+		requests = append(requests, "limit="+query.Get("limit")+" continue="+query.Get("continue"))
+		mu.Unlock()
+
+		start := 0
+		if token := query.Get("continue"); token != "" {
+			start, _ = strconv.Atoi(token)
+		}
+		end := len(names)
+		if limit, _ := strconv.Atoi(query.Get("limit")); limit > 0 && start+limit < end {
+			end = start + limit
+		}
+
+		list := corev1.PodList{TypeMeta: metav1.TypeMeta{Kind: "PodList", APIVersion: "v1"}}
+		for _, name := range names[start:end] {
+			list.Items = append(list.Items, corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+			})
+		}
+		if end < len(names) {
+			list.Continue = strconv.Itoa(end)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(list)
+	}))
+	t.Cleanup(server.Close)
+
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+
+	return &K8sAdapter{clientset: clientset, ctx: context.Background()}, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func podNames(pods []PodStatus) []string {
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func TestGetPodStatusesPage(t *testing.T) {
+	adapter, requests := newPagedPodsAdapter(t)
+
+	first, next, err := adapter.GetPodStatusesPage("default", ListPage{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, podNames(first))
+	require.NotEmpty(t, next)
+
+	second, next, err := adapter.GetPodStatusesPage("default", ListPage{Limit: 2, Continue: next})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, podNames(second))
+	assert.Empty(t, next)
+
+	assert.Equal(t, []string{"limit=2 continue=", "limit=2 continue=2"}, requests())
+}
+
+func TestGetPodStatusesFollowsContinue(t *testing.T) {
+	oldChunkSize := listChunkSize
+	listChunkSize = 2
+	defer func() { listChunkSize = oldChunkSize }()
+
+	adapter, requests := newPagedPodsAdapter(t)
+
+	pods, err := adapter.GetPodStatuses("default")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, podNames(pods))
+	assert.Equal(t, []string{"limit=2 continue=", "limit=2 continue=2"}, requests())
+}
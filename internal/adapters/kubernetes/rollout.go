@@ -0,0 +1,354 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// rolloutPollInterval задает частоту опроса статуса деплоймента
+var rolloutPollInterval = 2 * time.Second
+
+// restartedAtAnnotation та же аннотация шаблона пода, что ставит kubectl rollout restart
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// ErrProtectedNamespace возвращается при попытке массового перезапуска
+// во всех namespace или в системном namespace без force
+var ErrProtectedNamespace = errors.New("массовый перезапуск в этом namespace требует force")
+
+// protectedNamespaces перечисляет namespace, где массовый перезапуск
+// разрешен только с force
+var protectedNamespaces = map[string]bool{
+	"kube-system": true,
+}
+
+// RestartDeployment выполняет rolling restart деплоймента, обновляя
+// аннотацию шаблона пода
+func (k *K8sAdapter) RestartDeployment(namespace, name string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "restart_deployment", attribute.String("namespace", namespace), attribute.String("name", name))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "restart_deployment", namespace+"/"+name, err)
+	}()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	return k.restartDeployment(ctx, namespace, name, k.now())
+}
+
+// RestartAllDeployments перезапускает все деплойменты namespace и
+// возвращает имена перезапущенных. Пустой namespace и kube-system
+// отклоняются, для них нужен ForceRestartAllDeployments.
+func (k *K8sAdapter) RestartAllDeployments(namespace string) (_ []string, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "restart_all_deployments", attribute.String("namespace", namespace))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "restart_all_deployments", namespace, err)
+	}()
+
+	if namespace == "" || protectedNamespaces[namespace] {
+		return nil, fmt.Errorf("namespace %q: %w", namespace, ErrProtectedNamespace)
+	}
+	return k.restartAllDeployments(ctx, namespace)
+}
+
+// ForceRestartAllDeployments работает как RestartAllDeployments, но без
+// защиты системных namespace. Пустой namespace означает все namespace.
+func (k *K8sAdapter) ForceRestartAllDeployments(namespace string) (_ []string, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "force_restart_all_deployments", attribute.String("namespace", namespace))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "force_restart_all_deployments", namespace, err)
+	}()
+
+	return k.restartAllDeployments(ctx, namespace)
+}
+
+func (k *K8sAdapter) restartAllDeployments(ctx context.Context, namespace string) ([]string, error) {
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	deployments, err := k.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списка деплойментов: %w", err)
+	}
+
+	// Одна метка времени на всю операцию
+	now := k.now()
+
+	var restarted, failed []string
+	for _, deployment := range deployments.Items {
+		name := deployment.Name
+		if namespace == "" {
+			name = deployment.Namespace + "/" + deployment.Name
+		}
+		if err := k.restartDeployment(ctx, deployment.Namespace, deployment.Name, now); err != nil {
+			failed = append(failed, name)
+			continue
+		}
+		restarted = append(restarted, name)
+	}
+
+	if len(failed) > 0 {
+		return restarted, fmt.Errorf("не удалось перезапустить деплойменты: %s", strings.Join(failed, ", "))
+	}
+
+	return restarted, nil
+}
+
+// restartDeployment ставит аннотацию restartedAt в шаблон пода
+func (k *K8sAdapter) restartDeployment(ctx context.Context, namespace, name string, at time.Time) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{
+						restartedAtAnnotation: at.Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании патча: %w", err)
+	}
+
+	_, err = k.clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("ошибка при перезапуске деплоймента %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// SetDeploymentImage меняет образ одного контейнера деплоймента,
+// остальные контейнеры не затрагиваются. Изменение запускает rollout.
+func (k *K8sAdapter) SetDeploymentImage(namespace, deployment, container, image string) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "set_deployment_image", attribute.String("namespace", namespace), attribute.String("deployment", deployment), attribute.String("container", container), attribute.String("image", image))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "set_deployment_image", namespace+"/"+deployment, err)
+	}()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	current, err := k.clientset.AppsV1().Deployments(namespace).Get(ctx, deployment, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ошибка при получении деплоймента: %w", err)
+	}
+
+	found := false
+	var names []string
+	for _, c := range current.Spec.Template.Spec.Containers {
+		names = append(names, c.Name)
+		if c.Name == container {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("контейнер %s не найден в деплойменте %s (доступные: %v)", container, deployment, names)
+	}
+
+	// Strategic merge patch объединяет список контейнеров по имени
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []map[string]string{
+						{"name": container, "image": image},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании патча: %w", err)
+	}
+
+	_, err = k.clientset.AppsV1().Deployments(namespace).Patch(ctx, deployment, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении образа: %w", err)
+	}
+
+	return nil
+}
+
+// WaitForRollout ждет, пока все реплики деплоймента обновятся и станут
+// доступны, или пока не истечет timeout
+func (k *K8sAdapter) WaitForRollout(namespace, name string, timeout time.Duration) error {
+	return k.waitForRollout(k.ctx, namespace, name, timeout)
+}
+
+func (k *K8sAdapter) waitForRollout(ctx context.Context, namespace, name string, timeout time.Duration) (err error) {
+	ctx, span := tracing.Start(ctx, "kubernetes", "wait_for_rollout", attribute.String("namespace", namespace), attribute.String("name", name))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err = wait.PollUntilContextCancel(ctx, rolloutPollInterval, true, func(ctx context.Context) (bool, error) {
+		deployment, err := k.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return rolloutComplete(deployment)
+	})
+	if err != nil {
+		return fmt.Errorf("rollout деплоймента %s не завершен: %w", name, err)
+	}
+
+	return nil
+}
+
+// rolloutComplete проверяет статус деплоймента так же, как kubectl rollout status
+func rolloutComplete(deployment *appsv1.Deployment) (bool, error) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return false, nil
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return false, fmt.Errorf("превышен progressDeadlineSeconds: %s", condition.Message)
+		}
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	status := deployment.Status
+	return status.UpdatedReplicas >= replicas &&
+		status.Replicas == status.UpdatedReplicas &&
+		status.AvailableReplicas >= status.UpdatedReplicas, nil
+}
+
+// ApplyAndWait применяет манифест и ждет готовности каждого Deployment,
+// StatefulSet и DaemonSet из него. timeout общий на ожидание всех ресурсов.
+// Ошибки ожидания отдельных ресурсов объединяются в одну.
+func (k *K8sAdapter) ApplyAndWait(manifestPath string, timeout time.Duration) (err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "apply_and_wait", attribute.String("manifest.path", manifestPath))
+	defer func() {
+		tracing.End(span, err)
+		k.recordAudit(ctx, "apply_and_wait", manifestPath, err)
+	}()
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("ошибка при чтении манифеста: %w", err)
+	}
+
+	applied, err := k.applyManifestObjects(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	var errs []error
+	for _, resource := range applied {
+		var wait func(ctx context.Context, namespace, name string, timeout time.Duration) error
+		switch resource.Kind {
+		case "Deployment":
+			wait = k.waitForRollout
+		case "StatefulSet":
+			wait = k.waitForStatefulSet
+		case "DaemonSet":
+			wait = k.waitForDaemonSet
+		default:
+			continue
+		}
+
+		if err := wait(ctx, resource.Namespace, resource.Name, time.Until(deadline)); err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", resource.Kind, resource.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// waitForStatefulSet ждет, пока все реплики StatefulSet обновятся и будут готовы
+func (k *K8sAdapter) waitForStatefulSet(ctx context.Context, namespace, name string, timeout time.Duration) error {
+	return k.pollRollout(ctx, "StatefulSet", name, timeout, func(ctx context.Context) (bool, error) {
+		statefulSet, err := k.clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return statefulSetRolloutComplete(statefulSet), nil
+	})
+}
+
+// waitForDaemonSet ждет, пока DaemonSet обновится и станет доступен на всех узлах
+func (k *K8sAdapter) waitForDaemonSet(ctx context.Context, namespace, name string, timeout time.Duration) error {
+	return k.pollRollout(ctx, "DaemonSet", name, timeout, func(ctx context.Context) (bool, error) {
+		daemonSet, err := k.clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return daemonSetRolloutComplete(daemonSet), nil
+	})
+}
+
+// pollRollout опрашивает condition ресурса kind/name до успеха или истечения timeout
+func (k *K8sAdapter) pollRollout(ctx context.Context, kind, name string, timeout time.Duration, condition wait.ConditionWithContextFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := wait.PollUntilContextCancel(ctx, rolloutPollInterval, true, condition); err != nil {
+		return fmt.Errorf("rollout %s %s не завершен: %w", kind, name, err)
+	}
+	return nil
+}
+
+// statefulSetRolloutComplete проверяет статус StatefulSet так же, как
+// kubectl rollout status. При стратегии OnDelete ждать нечего.
+func statefulSetRolloutComplete(statefulSet *appsv1.StatefulSet) bool {
+	if statefulSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return true
+	}
+	if statefulSet.Generation > statefulSet.Status.ObservedGeneration {
+		return false
+	}
+
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+
+	status := statefulSet.Status
+	if status.ReadyReplicas < replicas {
+		return false
+	}
+	// При partition обновляются только реплики с номером не меньше partition
+	if rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil && *rollingUpdate.Partition > 0 {
+		return status.UpdatedReplicas >= replicas-*rollingUpdate.Partition
+	}
+	return status.UpdateRevision == status.CurrentRevision
+}
+
+// daemonSetRolloutComplete проверяет статус DaemonSet так же, как
+// kubectl rollout status. При стратегии OnDelete ждать нечего.
+func daemonSetRolloutComplete(daemonSet *appsv1.DaemonSet) bool {
+	if daemonSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+		return true
+	}
+	if daemonSet.Generation > daemonSet.Status.ObservedGeneration {
+		return false
+	}
+
+	status := daemonSet.Status
+	return status.UpdatedNumberScheduled >= status.DesiredNumberScheduled &&
+		status.NumberAvailable >= status.DesiredNumberScheduled
+}
@@ -0,0 +1,269 @@
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestDeployment(status appsv1.DeploymentStatus) *appsv1.Deployment {
+	replicas := int32(2)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 1},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "app", Image: "nginx:1.24"},
+						{Name: "sidecar", Image: "envoy:1.28"},
+					},
+				},
+			},
+		},
+		Status: status,
+	}
+}
+
+func TestSetDeploymentImage(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestDeployment(appsv1.DeploymentStatus{}))
+	adapter := &K8sAdapter{
+		clientset: clientset,
+		ctx:       context.Background(),
+	}
+
+	err := adapter.SetDeploymentImage("default", "web", "app", "nginx:1.25")
+	require.NoError(t, err)
+
+	deployment, err := clientset.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	containers := deployment.Spec.Template.Spec.Containers
+	require.Len(t, containers, 2)
+	assert.Equal(t, "nginx:1.25", containers[0].Image)
+	assert.Equal(t, "envoy:1.28", containers[1].Image)
+
+	// Несуществующий контейнер
+	err = adapter.SetDeploymentImage("default", "web", "missing", "nginx:1.25")
+	assert.ErrorContains(t, err, "контейнер missing не найден")
+}
+
+func TestRestartAllDeployments(t *testing.T) {
+	api := newTestDeployment(appsv1.DeploymentStatus{})
+	api.Name = "api"
+	other := newTestDeployment(appsv1.DeploymentStatus{})
+	other.Namespace = "staging"
+
+	clientset := fake.NewSimpleClientset(newTestDeployment(appsv1.DeploymentStatus{}), api, other)
+	adapter := &K8sAdapter{
+		clientset: clientset,
+		ctx:       context.Background(),
+	}
+
+	restarted, err := adapter.RestartAllDeployments("default")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"web", "api"}, restarted)
+
+	for _, name := range []string{"web", "api"} {
+		deployment, err := clientset.AppsV1().Deployments("default").Get(context.Background(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, deployment.Spec.Template.Annotations[restartedAtAnnotation], name)
+	}
+
+	// Деплоймент из другого namespace не затронут
+	deployment, err := clientset.AppsV1().Deployments("staging").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, deployment.Spec.Template.Annotations[restartedAtAnnotation])
+
+	// Защищенные namespace без force
+	for _, namespace := range []string{"", "kube-system"} {
+		_, err := adapter.RestartAllDeployments(namespace)
+		assert.ErrorIs(t, err, ErrProtectedNamespace)
+	}
+}
+
+func TestWaitForRollout(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  appsv1.DeploymentStatus
+		wantErr bool
+	}{
+		{
+			name: "rollout завершен",
+			status: appsv1.DeploymentStatus{
+				ObservedGeneration: 1,
+				Replicas:           2,
+				UpdatedReplicas:    2,
+				AvailableReplicas:  2,
+			},
+		},
+		{
+			name: "реплики еще обновляются",
+			status: appsv1.DeploymentStatus{
+				ObservedGeneration: 1,
+				Replicas:           3,
+				UpdatedReplicas:    1,
+				AvailableReplicas:  2,
+			},
+			wantErr: true,
+		},
+		{
+			name: "превышен progress deadline",
+			status: appsv1.DeploymentStatus{
+				ObservedGeneration: 1,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &K8sAdapter{
+				clientset: fake.NewSimpleClientset(newTestDeployment(tt.status)),
+				ctx:       context.Background(),
+			}
+
+			err := adapter.WaitForRollout("default", "web", 200*time.Millisecond)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+const applyAndWaitManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  replicas: 2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: default
+`
+
+func TestApplyAndWait(t *testing.T) {
+	oldInterval := rolloutPollInterval
+	rolloutPollInterval = 10 * time.Millisecond
+	defer func() { rolloutPollInterval = oldInterval }()
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(applyAndWaitManifest), 0644))
+
+	t.Run("деплоймент стал готов", func(t *testing.T) {
+		adapter, dynamicClient := newFakeApplyAdapter(t)
+		deployments := adapter.clientset.AppsV1().Deployments("default")
+		_, err := deployments.Create(context.Background(), newTestDeployment(appsv1.DeploymentStatus{ObservedGeneration: 1}), metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		// Контроллер обновляет реплики через некоторое время после применения
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			ready := newTestDeployment(appsv1.DeploymentStatus{
+				ObservedGeneration: 1,
+				Replicas:           2,
+				UpdatedReplicas:    2,
+				AvailableReplicas:  2,
+			})
+			deployments.UpdateStatus(context.Background(), ready, metav1.UpdateOptions{})
+		}()
+
+		require.NoError(t, adapter.ApplyAndWait(path, 2*time.Second))
+
+		_, err = dynamicClient.Resource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}).
+			Namespace("default").Get(context.Background(), "web", metav1.GetOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("деплоймент не стал готов", func(t *testing.T) {
+		adapter, _ := newFakeApplyAdapter(t)
+		_, err := adapter.clientset.AppsV1().Deployments("default").
+			Create(context.Background(), newTestDeployment(appsv1.DeploymentStatus{ObservedGeneration: 1}), metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		err = adapter.ApplyAndWait(path, 100*time.Millisecond)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Deployment/web")
+	})
+}
+
+func TestStatefulSetRolloutComplete(t *testing.T) {
+	replicas := int32(3)
+	partition := int32(2)
+	tests := []struct {
+		name        string
+		statefulSet appsv1.StatefulSet
+		want        bool
+	}{
+		{
+			name: "все реплики обновлены",
+			statefulSet: appsv1.StatefulSet{
+				Spec:   appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, CurrentRevision: "web-2", UpdateRevision: "web-2"},
+			},
+			want: true,
+		},
+		{
+			name: "ревизия еще обновляется",
+			statefulSet: appsv1.StatefulSet{
+				Spec:   appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, CurrentRevision: "web-1", UpdateRevision: "web-2"},
+			},
+		},
+		{
+			name: "обновлены реплики выше partition",
+			statefulSet: appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: &replicas,
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+						Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+					},
+				},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "web-1", UpdateRevision: "web-2"},
+			},
+			want: true,
+		},
+		{
+			name: "не все реплики готовы",
+			statefulSet: appsv1.StatefulSet{
+				Spec:   appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, statefulSetRolloutComplete(&tt.statefulSet))
+		})
+	}
+}
+
+func TestDaemonSetRolloutComplete(t *testing.T) {
+	assert.True(t, daemonSetRolloutComplete(&appsv1.DaemonSet{
+		Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 3},
+	}))
+	assert.False(t, daemonSetRolloutComplete(&appsv1.DaemonSet{
+		Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 2},
+	}))
+}
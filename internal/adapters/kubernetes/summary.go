@@ -0,0 +1,181 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// summaryWarningWindow за какой период в сводку попадают предупреждения
+const summaryWarningWindow = time.Hour
+
+// summaryWarningLimit сколько последних предупреждений показывает сводка
+const summaryWarningLimit = 10
+
+// WarningEvent описывает событие Kubernetes с типом Warning
+type WarningEvent struct {
+	Object   string
+	Reason   string
+	Message  string
+	Count    int32
+	LastSeen time.Time
+}
+
+// NamespaceSummary содержит краткую сводку по ресурсам namespace
+type NamespaceSummary struct {
+	Namespace        string
+	Deployments      int
+	ReadyDeployments int
+	Pods             int
+	RunningPods      int
+	Services         int
+	// NotReadyPods поды, которые не запущены или не готовы.
+	// Успешно завершенные поды сюда не попадают.
+	NotReadyPods []PodStatus
+	// Warnings последние предупреждения за summaryWarningWindow, новые первыми
+	Warnings []WarningEvent
+}
+
+// GetNamespaceSummary собирает сводку по namespace: количество деплойментов,
+// подов и сервисов, неготовые поды и последние предупреждения
+func (k *K8sAdapter) GetNamespaceSummary(namespace string) (_ *NamespaceSummary, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "get_namespace_summary", attribute.String("namespace", namespace))
+	defer func() { tracing.End(span, err) }()
+
+	pods, err := k.GetPodStatuses(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	summary := &NamespaceSummary{Namespace: namespace, Pods: len(pods)}
+	for _, pod := range pods {
+		switch {
+		case pod.Status == string(corev1.PodSucceeded):
+		case pod.Status == string(corev1.PodRunning) && pod.Ready:
+			summary.RunningPods++
+		default:
+			summary.NotReadyPods = append(summary.NotReadyPods, pod)
+		}
+	}
+
+	deployments, err := k.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списка деплойментов: %w", err)
+	}
+	summary.Deployments = len(deployments.Items)
+	for _, deployment := range deployments.Items {
+		if deployment.Status.ReadyReplicas >= desiredReplicas(&deployment) {
+			summary.ReadyDeployments++
+		}
+	}
+
+	services, err := k.clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списка сервисов: %w", err)
+	}
+	summary.Services = len(services.Items)
+
+	events, err := k.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("type", corev1.EventTypeWarning).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении событий: %w", err)
+	}
+
+	since := k.now().Add(-summaryWarningWindow)
+	for _, event := range events.Items {
+		lastSeen := eventTime(&event)
+		if event.Type != corev1.EventTypeWarning || lastSeen.Before(since) {
+			continue
+		}
+		summary.Warnings = append(summary.Warnings, WarningEvent{
+			Object:   event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+			Reason:   event.Reason,
+			Message:  event.Message,
+			Count:    event.Count,
+			LastSeen: lastSeen,
+		})
+	}
+	sort.Slice(summary.Warnings, func(i, j int) bool {
+		return summary.Warnings[i].LastSeen.After(summary.Warnings[j].LastSeen)
+	})
+	if len(summary.Warnings) > summaryWarningLimit {
+		summary.Warnings = summary.Warnings[:summaryWarningLimit]
+	}
+
+	return summary, nil
+}
+
+// DeploymentHealth сравнивает число готовых реплик деплоймента с желаемым
+type DeploymentHealth struct {
+	Name      string
+	Namespace string
+	Desired   int32
+	Ready     int32
+	// Healthy равен false, если готовых реплик меньше желаемого
+	Healthy bool
+}
+
+// GetDeploymentsHealth возвращает здоровье всех деплойментов namespace,
+// отсортированных по имени. Деплоймент деградировал, если готовых реплик
+// меньше желаемого.
+func (k *K8sAdapter) GetDeploymentsHealth(namespace string) (_ []DeploymentHealth, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "get_deployments_health", attribute.String("namespace", namespace))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	deployments, err := k.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списка деплойментов: %w", err)
+	}
+
+	health := make([]DeploymentHealth, 0, len(deployments.Items))
+	for _, deployment := range deployments.Items {
+		desired := desiredReplicas(&deployment)
+		health = append(health, DeploymentHealth{
+			Name:      deployment.Name,
+			Namespace: deployment.Namespace,
+			Desired:   desired,
+			Ready:     deployment.Status.ReadyReplicas,
+			Healthy:   deployment.Status.ReadyReplicas >= desired,
+		})
+	}
+	sort.Slice(health, func(i, j int) bool {
+		return health[i].Name < health[j].Name
+	})
+
+	return health, nil
+}
+
+// desiredReplicas возвращает желаемое число реплик деплоймента.
+// Без явного значения Kubernetes создает одну реплику.
+func desiredReplicas(deployment *appsv1.Deployment) int32 {
+	if deployment.Spec.Replicas != nil {
+		return *deployment.Spec.Replicas
+	}
+	return 1
+}
+
+// eventTime возвращает время последнего появления события
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
@@ -0,0 +1,125 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/localops/devops-manager/internal/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newSummaryPod(name string, phase corev1.PodPhase, ready bool) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+		Status: corev1.PodStatus{
+			Phase:             phase,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Ready: ready}},
+		},
+	}
+}
+
+func newSummaryEvent(name, eventType string, lastSeen time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "shop"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "api-2"},
+		Type:           eventType,
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Count:          3,
+		LastTimestamp:  metav1.NewTime(lastSeen),
+	}
+}
+
+func TestGetNamespaceSummary(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	replicas := int32(2)
+
+	objects := []runtime.Object{
+		newSummaryPod("api-1", corev1.PodRunning, true),
+		newSummaryPod("api-2", corev1.PodRunning, false),
+		newSummaryPod("worker-1", corev1.PodPending, false),
+		newSummaryPod("migrate-1", corev1.PodSucceeded, false),
+		newSummaryPod("other", corev1.PodFailed, false),
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "shop"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 2},
+		},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"}},
+		newSummaryEvent("recent", corev1.EventTypeWarning, now.Add(-5*time.Minute)),
+		newSummaryEvent("latest", corev1.EventTypeWarning, now.Add(-time.Minute)),
+		newSummaryEvent("old", corev1.EventTypeWarning, now.Add(-3*time.Hour)),
+		newSummaryEvent("normal", corev1.EventTypeNormal, now),
+		// Ресурсы другого namespace не учитываются
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "other"}},
+	}
+
+	adapter := &K8sAdapter{
+		clientset: fake.NewSimpleClientset(objects...),
+		ctx:       context.Background(),
+	}
+	adapter.SetClock(clock.NewFake(now))
+
+	summary, err := adapter.GetNamespaceSummary("shop")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, summary.Deployments)
+	assert.Equal(t, 1, summary.ReadyDeployments)
+	assert.Equal(t, 5, summary.Pods)
+	assert.Equal(t, 1, summary.RunningPods)
+	assert.Equal(t, 1, summary.Services)
+
+	var notReady []string
+	for _, pod := range summary.NotReadyPods {
+		notReady = append(notReady, pod.Name)
+	}
+	assert.ElementsMatch(t, []string{"api-2", "worker-1", "other"}, notReady)
+
+	require.Len(t, summary.Warnings, 2)
+	assert.Equal(t, now.Add(-time.Minute), summary.Warnings[0].LastSeen)
+	assert.Equal(t, now.Add(-5*time.Minute), summary.Warnings[1].LastSeen)
+	assert.Equal(t, "Pod/api-2", summary.Warnings[0].Object)
+	assert.Equal(t, "BackOff", summary.Warnings[0].Reason)
+}
+
+func TestGetDeploymentsHealth(t *testing.T) {
+	replicas := int32(3)
+	adapter := &K8sAdapter{
+		clientset: fake.NewSimpleClientset(
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status:     appsv1.DeploymentStatus{ReadyReplicas: 3},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "other"},
+			},
+		),
+		ctx: context.Background(),
+	}
+
+	health, err := adapter.GetDeploymentsHealth("shop")
+	require.NoError(t, err)
+
+	assert.Equal(t, []DeploymentHealth{
+		{Name: "api", Namespace: "shop", Desired: 3, Ready: 3, Healthy: true},
+		{Name: "web", Namespace: "shop", Desired: 3, Ready: 1, Healthy: false},
+	}, health)
+}
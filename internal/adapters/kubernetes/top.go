@@ -0,0 +1,123 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/localops/devops-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrMetricsUnavailable возвращается, если в кластере не установлен metrics-server
+var ErrMetricsUnavailable = errors.New("metrics.k8s.io недоступен: установите metrics-server")
+
+// PodMetrics содержит текущее потребление ресурсов подом
+type PodMetrics struct {
+	Name        string
+	Namespace   string
+	CPUMilli    int64 // миллиядра
+	MemoryBytes int64
+}
+
+// NodeMetrics содержит текущее потребление ресурсов узлом
+type NodeMetrics struct {
+	Name          string
+	CPUMilli      int64 // миллиядра
+	MemoryBytes   int64
+	CPUPercent    float64 // доля от allocatable, 0 если неизвестна
+	MemoryPercent float64
+}
+
+// TopPods возвращает потребление CPU и памяти подами namespace,
+// отсортированное по CPU по убыванию
+func (k *K8sAdapter) TopPods(namespace string) (_ []PodMetrics, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "top_pods", attribute.String("namespace", namespace))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	list, err := k.metrics.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, metricsError(err)
+	}
+
+	pods := make([]PodMetrics, 0, len(list.Items))
+	for _, item := range list.Items {
+		pod := PodMetrics{
+			Name:      item.Name,
+			Namespace: item.Namespace,
+		}
+		for _, container := range item.Containers {
+			pod.CPUMilli += container.Usage.Cpu().MilliValue()
+			pod.MemoryBytes += container.Usage.Memory().Value()
+		}
+		pods = append(pods, pod)
+	}
+
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].CPUMilli > pods[j].CPUMilli
+	})
+
+	return pods, nil
+}
+
+// TopNodes возвращает потребление CPU и памяти узлами кластера
+func (k *K8sAdapter) TopNodes() (_ []NodeMetrics, err error) {
+	ctx, span := tracing.Start(k.ctx, "kubernetes", "top_nodes")
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := k.opContext(ctx)
+	defer cancel()
+
+	list, err := k.metrics.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, metricsError(err)
+	}
+
+	// Allocatable нужен только для процентов, поэтому ошибку не считаем фатальной
+	allocatable := make(map[string][2]int64)
+	if nodes, err := k.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{}); err == nil {
+		for _, node := range nodes.Items {
+			allocatable[node.Name] = [2]int64{
+				node.Status.Allocatable.Cpu().MilliValue(),
+				node.Status.Allocatable.Memory().Value(),
+			}
+		}
+	}
+
+	result := make([]NodeMetrics, 0, len(list.Items))
+	for _, item := range list.Items {
+		node := NodeMetrics{
+			Name:        item.Name,
+			CPUMilli:    item.Usage.Cpu().MilliValue(),
+			MemoryBytes: item.Usage.Memory().Value(),
+		}
+		if capacity, ok := allocatable[item.Name]; ok {
+			if capacity[0] > 0 {
+				node.CPUPercent = float64(node.CPUMilli) / float64(capacity[0]) * 100
+			}
+			if capacity[1] > 0 {
+				node.MemoryPercent = float64(node.MemoryBytes) / float64(capacity[1]) * 100
+			}
+		}
+		result = append(result, node)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// metricsError превращает отсутствие metrics API в ErrMetricsUnavailable
+func metricsError(err error) error {
+	if apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+		return fmt.Errorf("%w: %v", ErrMetricsUnavailable, err)
+	}
+	return fmt.Errorf("ошибка при получении метрик: %w", err)
+}
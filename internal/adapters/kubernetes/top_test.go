@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func newPodMetrics(name string, cpu, memory string) *metricsv1beta1.PodMetrics {
+	return &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{
+				Name: "app",
+				Usage: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				},
+			},
+			{
+				Name: "sidecar",
+				Usage: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10m"),
+					corev1.ResourceMemory: resource.MustParse("16Mi"),
+				},
+			},
+		},
+	}
+}
+
+func TestTopPods(t *testing.T) {
+	metricsClient := metricsfake.NewSimpleClientset()
+
+	// Fake клиент ищет PodMetrics по ресурсу pods, поэтому кладем объекты в трекер явно
+	gvr := metricsv1beta1.SchemeGroupVersion.WithResource("pods")
+	require.NoError(t, metricsClient.Tracker().Create(gvr, newPodMetrics("web", "100m", "64Mi"), "default"))
+	require.NoError(t, metricsClient.Tracker().Create(gvr, newPodMetrics("worker", "250m", "128Mi"), "default"))
+
+	adapter := &K8sAdapter{
+		clientset: fake.NewSimpleClientset(),
+		metrics:   metricsClient,
+		ctx:       context.Background(),
+	}
+
+	pods, err := adapter.TopPods("default")
+	require.NoError(t, err)
+	require.Len(t, pods, 2)
+
+	// Сортировка по CPU по убыванию, потребление суммируется по контейнерам
+	assert.Equal(t, "worker", pods[0].Name)
+	assert.Equal(t, int64(260), pods[0].CPUMilli)
+	assert.Equal(t, int64(144*1024*1024), pods[0].MemoryBytes)
+	assert.Equal(t, "web", pods[1].Name)
+	assert.Equal(t, int64(110), pods[1].CPUMilli)
+}
+
+func TestTopPodsWithoutMetricsServer(t *testing.T) {
+	metricsClient := metricsfake.NewSimpleClientset()
+	metricsClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "metrics.k8s.io", Resource: "pods"}, "")
+	})
+
+	adapter := &K8sAdapter{
+		clientset: fake.NewSimpleClientset(),
+		metrics:   metricsClient,
+		ctx:       context.Background(),
+	}
+
+	_, err := adapter.TopPods("default")
+	assert.True(t, errors.Is(err, ErrMetricsUnavailable), "ожидалась ошибка ErrMetricsUnavailable, получено: %v", err)
+}
@@ -0,0 +1,232 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Comparator задает способ сравнения значения метрики с порогом
+type Comparator string
+
+const (
+	GreaterThan    Comparator = ">"
+	GreaterOrEqual Comparator = ">="
+	LessThan       Comparator = "<"
+	LessOrEqual    Comparator = "<="
+	Equal          Comparator = "=="
+)
+
+// defaultAlertCooldown минимальный интервал между повторными уведомлениями
+const defaultAlertCooldown = 5 * time.Minute
+
+// compare проверяет, пересекает ли значение порог
+func (c Comparator) compare(value, threshold float64) bool {
+	switch c {
+	case GreaterThan:
+		return value > threshold
+	case GreaterOrEqual:
+		return value >= threshold
+	case LessThan:
+		return value < threshold
+	case LessOrEqual:
+		return value <= threshold
+	case Equal:
+		return value == threshold
+	default:
+		return false
+	}
+}
+
+// alertRule описывает правило оповещения и его текущее состояние
+type alertRule struct {
+	name       string
+	query      string
+	threshold  float64
+	cmp        Comparator
+	webhookURL string
+
+	firing   bool
+	lastSent time.Time
+}
+
+// AlertPayload тело запроса, отправляемого на webhook.
+// Поле Text позволяет использовать incoming webhook Slack напрямую.
+type AlertPayload struct {
+	Alert      string     `json:"alert"`
+	Query      string     `json:"query"`
+	Value      float64    `json:"value"`
+	Threshold  float64    `json:"threshold"`
+	Comparator Comparator `json:"comparator"`
+	Timestamp  time.Time  `json:"timestamp"`
+	Text       string     `json:"text"`
+}
+
+// alerting хранит правила оповещений адаптера
+type alerting struct {
+	mu       sync.Mutex
+	rules    map[string]*alertRule
+	cooldown time.Duration
+	client   *http.Client
+}
+
+// RegisterAlert регистрирует правило: когда значение query пересекает
+// threshold, на webhookURL отправляется JSON уведомление. query - имя
+// метрики с необязательным фильтром меток, например
+// devops_manager_docker_operations_total{status="error"}; значения
+// подходящих рядов суммируются. Повторное уведомление отправляется не
+// чаще раза в cooldown, пока условие выполняется.
+func (a *MonitoringAdapter) RegisterAlert(name string, query string, threshold float64, cmp Comparator, webhookURL string) {
+	a.alerts.mu.Lock()
+	defer a.alerts.mu.Unlock()
+
+	a.alerts.rules[name] = &alertRule{
+		name:       name,
+		query:      query,
+		threshold:  threshold,
+		cmp:        cmp,
+		webhookURL: webhookURL,
+	}
+}
+
+// StartAlerting запускает горутину, которая проверяет правила каждые
+// interval до отмены ctx
+func (a *MonitoringAdapter) StartAlerting(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.EvaluateAlerts(ctx)
+			}
+		}
+	}()
+}
+
+// EvaluateAlerts выполняет одну проверку всех правил
+func (a *MonitoringAdapter) EvaluateAlerts(ctx context.Context) {
+	a.alerts.mu.Lock()
+	rules := make([]*alertRule, 0, len(a.alerts.rules))
+	for _, rule := range a.alerts.rules {
+		rules = append(rules, rule)
+	}
+	a.alerts.mu.Unlock()
+
+	for _, rule := range rules {
+		a.evaluateAlert(ctx, rule)
+	}
+}
+
+// evaluateAlert проверяет одно правило и при необходимости отправляет уведомление
+func (a *MonitoringAdapter) evaluateAlert(ctx context.Context, rule *alertRule) {
+	name, labels := parseAlertQuery(rule.query)
+
+	now := time.Now()
+	values, err := a.query(ctx, name, now.Add(-time.Minute), now)
+	if err != nil {
+		a.logger.Warn("не удалось получить метрику для оповещения", "alert", rule.name, "query", rule.query, "error", err)
+		return
+	}
+
+	var value float64
+	matched := false
+	for _, v := range values {
+		if matchLabels(v.Labels, labels) {
+			value += v.Value
+			matched = true
+		}
+	}
+	if !matched {
+		return
+	}
+
+	a.alerts.mu.Lock()
+	if !rule.cmp.compare(value, rule.threshold) {
+		rule.firing = false
+		a.alerts.mu.Unlock()
+		return
+	}
+	if rule.firing && now.Sub(rule.lastSent) < a.alerts.cooldown {
+		a.alerts.mu.Unlock()
+		return
+	}
+	rule.firing = true
+	rule.lastSent = now
+	a.alerts.mu.Unlock()
+
+	payload := AlertPayload{
+		Alert:      rule.name,
+		Query:      rule.query,
+		Value:      value,
+		Threshold:  rule.threshold,
+		Comparator: rule.cmp,
+		Timestamp:  now,
+		Text:       fmt.Sprintf("[%s] %s = %g (порог %s %g)", rule.name, rule.query, value, rule.cmp, rule.threshold),
+	}
+	if err := a.sendAlert(ctx, rule.webhookURL, payload); err != nil {
+		a.logger.Error("не удалось отправить оповещение", "alert", rule.name, "error", err)
+	}
+}
+
+// sendAlert отправляет уведомление на webhook
+func (a *MonitoringAdapter) sendAlert(ctx context.Context, webhookURL string, payload AlertPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("ошибка при сериализации оповещения: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("ошибка при создании запроса: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.alerts.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка при отправке оповещения: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("неожиданный статус ответа webhook: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// parseAlertQuery разбирает запрос вида name{label="value",...}
+func parseAlertQuery(query string) (string, map[string]string) {
+	name, selector, found := strings.Cut(strings.TrimSpace(query), "{")
+	if !found {
+		return name, nil
+	}
+
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(strings.TrimSuffix(selector, "}"), ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		labels[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), "\"")
+	}
+	return name, labels
+}
+
+// matchLabels проверяет, что ряд содержит все метки фильтра
+func matchLabels(series, filter map[string]string) bool {
+	for key, value := range filter {
+		if series[key] != value {
+			return false
+		}
+	}
+	return true
+}
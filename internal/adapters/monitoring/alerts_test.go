@@ -0,0 +1,87 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateAlerts(t *testing.T) {
+	var mu sync.Mutex
+	var received []AlertPayload
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload AlertPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		mu.Lock()
+		received = append(received, payload)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer webhook.Close()
+
+	adapter := NewMonitoringAdapter(Config{
+		Namespace: "test",
+		Subsystem: "alerts",
+	})
+
+	// Заготовленные значения метрики вместо запроса к /metrics
+	errorsCount := 3.0
+	adapter.query = func(ctx context.Context, name string, start, end time.Time) ([]MetricValue, error) {
+		assert.Equal(t, "devops_manager_docker_operations_total", name)
+		return []MetricValue{
+			{Name: name, Value: errorsCount, Labels: map[string]string{"operation": "start_container", "status": "error"}},
+			{Name: name, Value: 2, Labels: map[string]string{"operation": "stop_container", "status": "error"}},
+			{Name: name, Value: 100, Labels: map[string]string{"operation": "start_container", "status": "success"}},
+		}, nil
+	}
+
+	adapter.RegisterAlert("docker-errors", `devops_manager_docker_operations_total{status="error"}`, 10, GreaterThan, webhook.URL)
+	adapter.RegisterAlert("docker-errors-low", `devops_manager_docker_operations_total{status="error"}`, 1, LessThan, webhook.URL)
+
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received)
+	}
+
+	// 3 + 2 = 5 ошибок: ни одно правило не срабатывает
+	adapter.EvaluateAlerts(context.Background())
+	assert.Equal(t, 0, count())
+
+	// 12 + 2 = 14 > 10: срабатывает первое правило
+	errorsCount = 12
+	adapter.EvaluateAlerts(context.Background())
+	require.Equal(t, 1, count())
+	assert.Equal(t, "docker-errors", received[0].Alert)
+	assert.Equal(t, 14.0, received[0].Value)
+	assert.Equal(t, GreaterThan, received[0].Comparator)
+	assert.NotEmpty(t, received[0].Text)
+
+	// Повторная проверка в пределах cooldown не дублирует оповещение
+	adapter.EvaluateAlerts(context.Background())
+	assert.Equal(t, 1, count())
+
+	// После возврата ниже порога правило снова может сработать
+	errorsCount = 3
+	adapter.EvaluateAlerts(context.Background())
+	errorsCount = 20
+	adapter.EvaluateAlerts(context.Background())
+	assert.Equal(t, 2, count())
+}
+
+func TestParseAlertQuery(t *testing.T) {
+	name, labels := parseAlertQuery(`http_requests_total{code="500", method="GET"}`)
+	assert.Equal(t, "http_requests_total", name)
+	assert.Equal(t, map[string]string{"code": "500", "method": "GET"}, labels)
+
+	name, labels = parseAlertQuery("cpu_usage_percent")
+	assert.Equal(t, "cpu_usage_percent", name)
+	assert.Nil(t, labels)
+}
@@ -0,0 +1,100 @@
+package monitoring
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// MetricFamily метрика из текстового формата Prometheus со всеми значениями
+type MetricFamily struct {
+	Name    string         `json:"name"`
+	Help    string         `json:"help,omitempty"`
+	Type    string         `json:"type"`
+	Metrics []MetricSample `json:"metrics"`
+}
+
+// MetricSample значение метрики с определенным набором меток. Для counter,
+// gauge и untyped заполняется Value, для histogram и summary - Count, Sum
+// и Buckets или Quantiles соответственно.
+type MetricSample struct {
+	Labels    map[string]string  `json:"labels,omitempty"`
+	Value     *float64           `json:"value,omitempty"`
+	Count     *uint64            `json:"count,omitempty"`
+	Sum       *float64           `json:"sum,omitempty"`
+	Buckets   map[string]uint64  `json:"buckets,omitempty"`
+	Quantiles map[string]float64 `json:"quantiles,omitempty"`
+}
+
+// ParseMetrics разбирает метрики в текстовом формате Prometheus.
+// Метрики возвращаются отсортированными по имени.
+func ParseMetrics(text string) ([]MetricFamily, error) {
+	var parser expfmt.TextParser
+	parsed, err := parser.TextToMetricFamilies(strings.NewReader(text))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при разборе метрик: %w", err)
+	}
+
+	families := make([]MetricFamily, 0, len(parsed))
+	for _, family := range parsed {
+		result := MetricFamily{
+			Name:    family.GetName(),
+			Help:    family.GetHelp(),
+			Type:    strings.ToLower(family.GetType().String()),
+			Metrics: make([]MetricSample, 0, len(family.GetMetric())),
+		}
+		for _, metric := range family.GetMetric() {
+			result.Metrics = append(result.Metrics, metricSample(family.GetType(), metric))
+		}
+		families = append(families, result)
+	}
+
+	sort.Slice(families, func(i, j int) bool { return families[i].Name < families[j].Name })
+	return families, nil
+}
+
+// metricSample переводит значение метрики из dto в MetricSample
+func metricSample(metricType dto.MetricType, metric *dto.Metric) MetricSample {
+	var sample MetricSample
+	if len(metric.GetLabel()) > 0 {
+		sample.Labels = make(map[string]string, len(metric.GetLabel()))
+		for _, label := range metric.GetLabel() {
+			sample.Labels[label.GetName()] = label.GetValue()
+		}
+	}
+
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		sample.Value = metric.GetCounter().Value
+	case dto.MetricType_GAUGE:
+		sample.Value = metric.GetGauge().Value
+	case dto.MetricType_HISTOGRAM:
+		histogram := metric.GetHistogram()
+		sample.Count, sample.Sum = histogram.SampleCount, histogram.SampleSum
+		sample.Buckets = make(map[string]uint64, len(histogram.GetBucket()))
+		for _, bucket := range histogram.GetBucket() {
+			sample.Buckets[formatBound(bucket.GetUpperBound())] = bucket.GetCumulativeCount()
+		}
+	case dto.MetricType_SUMMARY:
+		summary := metric.GetSummary()
+		sample.Count, sample.Sum = summary.SampleCount, summary.SampleSum
+		sample.Quantiles = make(map[string]float64, len(summary.GetQuantile()))
+		for _, quantile := range summary.GetQuantile() {
+			sample.Quantiles[formatBound(quantile.GetQuantile())] = quantile.GetValue()
+		}
+	default:
+		sample.Value = metric.GetUntyped().Value
+	}
+
+	return sample
+}
+
+// formatBound форматирует границу bucket или квантиль так же, как в тексте
+// Prometheus: 0.5, 1, +Inf
+func formatBound(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
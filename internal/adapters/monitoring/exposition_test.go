@@ -0,0 +1,61 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleExposition = `# HELP devops_manager_docker_operations_total Количество операций Docker
+# TYPE devops_manager_docker_operations_total counter
+devops_manager_docker_operations_total{operation="list_containers",status="success"} 3
+devops_manager_docker_operations_total{operation="run_container",status="error"} 0
+# HELP devops_manager_docker_operation_duration_seconds Длительность операций Docker
+# TYPE devops_manager_docker_operation_duration_seconds histogram
+devops_manager_docker_operation_duration_seconds_bucket{operation="build_image",le="0.5"} 1
+devops_manager_docker_operation_duration_seconds_bucket{operation="build_image",le="+Inf"} 2
+devops_manager_docker_operation_duration_seconds_sum{operation="build_image"} 4.25
+devops_manager_docker_operation_duration_seconds_count{operation="build_image"} 2
+# TYPE go_goroutines gauge
+go_goroutines 12
+`
+
+func TestParseMetrics(t *testing.T) {
+	families, err := ParseMetrics(sampleExposition)
+	require.NoError(t, err)
+	require.Len(t, families, 3)
+
+	histogram := families[0]
+	assert.Equal(t, "devops_manager_docker_operation_duration_seconds", histogram.Name)
+	assert.Equal(t, "histogram", histogram.Type)
+	require.Len(t, histogram.Metrics, 1)
+	assert.Equal(t, map[string]string{"operation": "build_image"}, histogram.Metrics[0].Labels)
+	assert.Equal(t, uint64(2), *histogram.Metrics[0].Count)
+	assert.Equal(t, 4.25, *histogram.Metrics[0].Sum)
+	assert.Equal(t, map[string]uint64{"0.5": 1, "+Inf": 2}, histogram.Metrics[0].Buckets)
+
+	counter := families[1]
+	assert.Equal(t, "devops_manager_docker_operations_total", counter.Name)
+	assert.Equal(t, "Количество операций Docker", counter.Help)
+	assert.Equal(t, "counter", counter.Type)
+	require.Len(t, counter.Metrics, 2)
+	assert.Equal(t, map[string]string{"operation": "list_containers", "status": "success"}, counter.Metrics[0].Labels)
+	assert.Equal(t, 3.0, *counter.Metrics[0].Value)
+
+	gauge := families[2]
+	assert.Equal(t, "go_goroutines", gauge.Name)
+	assert.Nil(t, gauge.Metrics[0].Labels)
+	assert.Equal(t, 12.0, *gauge.Metrics[0].Value)
+
+	// Нулевое значение счетчика сохраняется в JSON
+	data, err := json.Marshal(counter.Metrics[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"labels":{"operation":"run_container","status":"error"},"value":0}`, string(data))
+}
+
+func TestParseMetricsInvalid(t *testing.T) {
+	_, err := ParseMetrics("metric{label=\"unterminated} 1\n")
+	assert.Error(t, err)
+}
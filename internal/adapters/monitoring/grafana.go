@@ -0,0 +1,177 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// grafanaDatasource ссылается на переменную дашборда с источником Prometheus
+var grafanaDatasource = map[string]string{
+	"type": "prometheus",
+	"uid":  "${datasource}",
+}
+
+// grafanaTarget запрос PromQL одной панели
+type grafanaTarget struct {
+	RefID        string            `json:"refId"`
+	Expr         string            `json:"expr"`
+	LegendFormat string            `json:"legendFormat,omitempty"`
+	Datasource   map[string]string `json:"datasource"`
+}
+
+// grafanaPanel панель дашборда
+type grafanaPanel struct {
+	ID          int                    `json:"id"`
+	Title       string                 `json:"title"`
+	Type        string                 `json:"type"`
+	Description string                 `json:"description,omitempty"`
+	Datasource  map[string]string      `json:"datasource"`
+	GridPos     map[string]int         `json:"gridPos"`
+	Targets     []grafanaTarget        `json:"targets"`
+	FieldConfig map[string]interface{} `json:"fieldConfig"`
+}
+
+// GenerateGrafanaDashboard формирует JSON дашборда Grafana с панелью на
+// каждую зарегистрированную метрику: rate() для счетчиков, квантили
+// p50/p95/p99 для гистограмм и готовые квантили для сводок. Имена метрик включают namespace и
+// subsystem адаптера.
+func (a *MonitoringAdapter) GenerateGrafanaDashboard() ([]byte, error) {
+	var panels []grafanaPanel
+
+	addPanel := func(panel grafanaPanel) {
+		// Две панели в ряд
+		index := len(panels)
+		panel.ID = index + 1
+		panel.Datasource = grafanaDatasource
+		panel.GridPos = map[string]int{"h": 8, "w": 12, "x": (index % 2) * 12, "y": (index / 2) * 8}
+		panels = append(panels, panel)
+	}
+
+	for _, name := range sortedMetricNames(a.counters) {
+		fqName := prometheus.BuildFQName(a.config.Namespace, a.config.Subsystem, name)
+		by := groupBy(a.metricLabels[name])
+		addPanel(grafanaPanel{
+			Title:       name,
+			Type:        "timeseries",
+			Description: "Скорость роста счетчика " + fqName,
+			Targets: []grafanaTarget{{
+				RefID:        "A",
+				Expr:         fmt.Sprintf("sum%s(rate(%s[5m]))", by, fqName),
+				LegendFormat: legendFormat(a.metricLabels[name]),
+				Datasource:   grafanaDatasource,
+			}},
+			FieldConfig: map[string]interface{}{
+				"defaults": map[string]string{"unit": "ops"},
+			},
+		})
+	}
+
+	for _, name := range sortedMetricNames(a.histograms) {
+		fqName := prometheus.BuildFQName(a.config.Namespace, a.config.Subsystem, name)
+		labels := append([]string{"le"}, a.metricLabels[name]...)
+		var targets []grafanaTarget
+		for i, quantile := range []struct{ value, legend string }{
+			{"0.5", "p50"}, {"0.95", "p95"}, {"0.99", "p99"},
+		} {
+			targets = append(targets, grafanaTarget{
+				RefID:        string(rune('A' + i)),
+				Expr:         fmt.Sprintf("histogram_quantile(%s, sum%s(rate(%s_bucket[5m])))", quantile.value, groupBy(labels), fqName),
+				LegendFormat: strings.TrimSpace(quantile.legend + " " + legendFormat(a.metricLabels[name])),
+				Datasource:   grafanaDatasource,
+			})
+		}
+		addPanel(grafanaPanel{
+			Title:       name,
+			Type:        "timeseries",
+			Description: "Квантили гистограммы " + fqName,
+			Targets:     targets,
+			FieldConfig: map[string]interface{}{
+				"defaults": map[string]string{"unit": "s"},
+			},
+		})
+	}
+
+	for _, name := range sortedMetricNames(a.summaries) {
+		fqName := prometheus.BuildFQName(a.config.Namespace, a.config.Subsystem, name)
+		addPanel(grafanaPanel{
+			Title:       name,
+			Type:        "timeseries",
+			Description: "Квантили сводки " + fqName,
+			Targets: []grafanaTarget{{
+				RefID:        "A",
+				Expr:         fqName,
+				LegendFormat: strings.TrimSpace("q{{quantile}} " + legendFormat(a.metricLabels[name])),
+				Datasource:   grafanaDatasource,
+			}},
+			FieldConfig: map[string]interface{}{
+				"defaults": map[string]string{"unit": "s"},
+			},
+		})
+	}
+
+	title := "DevOps Manager"
+	var scope []string
+	for _, part := range []string{a.config.Namespace, a.config.Subsystem} {
+		if part != "" {
+			scope = append(scope, part)
+		}
+	}
+	if len(scope) > 0 {
+		title += " (" + strings.Join(scope, "_") + ")"
+	}
+
+	dashboard := map[string]interface{}{
+		"title":         title,
+		"tags":          []string{"devops-manager"},
+		"timezone":      "browser",
+		"schemaVersion": 39,
+		"refresh":       "30s",
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"templating": map[string]interface{}{
+			"list": []map[string]interface{}{{
+				"name":  "datasource",
+				"label": "Prometheus",
+				"type":  "datasource",
+				"query": "prometheus",
+			}},
+		},
+		"panels": panels,
+	}
+
+	data, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании дашборда: %v", err)
+	}
+	return data, nil
+}
+
+// sortedMetricNames возвращает имена метрик в алфавитном порядке
+func sortedMetricNames[V any](metrics map[string]V) []string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// groupBy формирует модификатор " by (a, b)" для агрегации
+func groupBy(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return " by (" + strings.Join(labels, ", ") + ")"
+}
+
+// legendFormat формирует подпись ряда из значений меток
+func legendFormat(labels []string) string {
+	parts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		parts = append(parts, "{{"+label+"}}")
+	}
+	return strings.Join(parts, " ")
+}
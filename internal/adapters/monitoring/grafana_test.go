@@ -0,0 +1,56 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateGrafanaDashboard(t *testing.T) {
+	adapter := NewMonitoringAdapter(Config{
+		Namespace: "devops",
+		Subsystem: "grafana",
+	})
+	adapter.RegisterCounters([]string{"requests_total"}, []string{"code"})
+	adapter.RegisterHistograms([]string{"request_duration_seconds"}, []string{"route"}, []float64{0.1, 1})
+	adapter.RegisterSummaries([]string{"response_size_bytes"}, nil, map[float64]float64{0.5: 0.05})
+
+	data, err := adapter.GenerateGrafanaDashboard()
+	require.NoError(t, err)
+
+	var dashboard struct {
+		Title  string `json:"title"`
+		Panels []struct {
+			Title   string `json:"title"`
+			Targets []struct {
+				Expr string `json:"expr"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}
+	require.NoError(t, json.Unmarshal(data, &dashboard))
+	assert.Equal(t, "DevOps Manager (devops_grafana)", dashboard.Title)
+
+	// Панель на каждую зарегистрированную метрику
+	require.Len(t, dashboard.Panels, len(adapter.counters)+len(adapter.histograms)+len(adapter.summaries))
+
+	panels := make(map[string][]string)
+	for _, panel := range dashboard.Panels {
+		for _, target := range panel.Targets {
+			panels[panel.Title] = append(panels[panel.Title], target.Expr)
+		}
+	}
+	for name := range adapter.counters {
+		require.Contains(t, panels, name)
+	}
+	for name := range adapter.histograms {
+		require.Contains(t, panels, name)
+	}
+	assert.Equal(t, []string{"devops_grafana_response_size_bytes"}, panels["response_size_bytes"])
+
+	assert.Equal(t, []string{"sum by (code)(rate(devops_grafana_requests_total[5m]))"}, panels["requests_total"])
+	require.Len(t, panels["request_duration_seconds"], 3)
+	assert.Equal(t, "histogram_quantile(0.95, sum by (le, route)(rate(devops_grafana_request_duration_seconds_bucket[5m])))",
+		panels["request_duration_seconds"][1])
+}
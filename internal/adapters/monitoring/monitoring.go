@@ -2,8 +2,10 @@ package monitoring
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -18,8 +20,20 @@ type Config struct {
 	Namespace string
 	Subsystem string
 	Port      int
+	// Logger логгер для служебных сообщений (по умолчанию логирование отключено)
+	Logger *slog.Logger
+	// AlertCooldown минимальный интервал между повторными оповещениями
+	// одного правила (по умолчанию 5m)
+	AlertCooldown time.Duration
+	// HistogramBuckets границы bucket гистограмм длительности по подсистемам:
+	// docker, kubernetes, cicd. Для подсистем без настройки используется
+	// DefaultHistogramBuckets.
+	HistogramBuckets map[string][]float64
 }
 
+// DefaultHistogramBuckets границы bucket гистограмм длительности по умолчанию
+var DefaultHistogramBuckets = []float64{0.1, 0.5, 1.0, 2.0, 5.0}
+
 // MetricValue представляет значение метрики
 type MetricValue struct {
 	Name      string
@@ -45,18 +59,45 @@ type MonitoringAdapter struct {
 	counters map[string]*prometheus.CounterVec
 	// Гистограммы
 	histograms map[string]*prometheus.HistogramVec
+	// Сводки (квантили на стороне клиента)
+	summaries map[string]*prometheus.SummaryVec
+	// Имена меток каждой зарегистрированной метрики
+	metricLabels map[string][]string
 	// HTTP сервер
 	server *http.Server
+	// Логгер
+	logger *slog.Logger
+	// Правила оповещений
+	alerts alerting
+	// query получает значения метрики; подменяется в тестах
+	query func(ctx context.Context, name string, start, end time.Time) ([]MetricValue, error)
 }
 
 // NewMonitoringAdapter создает новый экземпляр MonitoringAdapter
 func NewMonitoringAdapter(config Config) *MonitoringAdapter {
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
 	adapter := &MonitoringAdapter{
-		config:     config,
-		registry:   prometheus.NewRegistry(),
-		counters:   make(map[string]*prometheus.CounterVec),
-		histograms: make(map[string]*prometheus.HistogramVec),
+		config:       config,
+		registry:     prometheus.NewRegistry(),
+		counters:     make(map[string]*prometheus.CounterVec),
+		histograms:   make(map[string]*prometheus.HistogramVec),
+		summaries:    make(map[string]*prometheus.SummaryVec),
+		metricLabels: make(map[string][]string),
+		logger:       logger,
+		alerts: alerting{
+			rules:    make(map[string]*alertRule),
+			cooldown: config.AlertCooldown,
+			client:   &http.Client{Timeout: 10 * time.Second},
+		},
+	}
+	if adapter.alerts.cooldown <= 0 {
+		adapter.alerts.cooldown = defaultAlertCooldown
 	}
+	adapter.query = adapter.QueryMetric
 
 	// Регистрируем метрики для Docker операций
 	adapter.RegisterCounters(
@@ -87,35 +128,88 @@ func NewMonitoringAdapter(config Config) *MonitoringAdapter {
 		[]string{"operation", "status"},
 	)
 
-	// Регистрируем гистограммы для длительности операций
-	adapter.RegisterHistograms(
-		[]string{
-			"docker_operation_duration_seconds",
-			"kubernetes_operation_duration_seconds",
-			"cicd_operation_duration_seconds",
-		},
-		[]string{"operation"},
-		[]float64{0.1, 0.5, 1.0, 2.0, 5.0},
+	// Регистрируем счетчик ошибок по типам
+	adapter.RegisterCounters(
+		[]string{"errors_total"},
+		[]string{"component", "operation", "error_type"},
 	)
 
-	// Запускаем HTTP сервер для метрик
-	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.HandlerFor(adapter.registry, promhttp.HandlerOpts{}))
+	// Регистрируем гистограммы для длительности операций
+	for _, subsystem := range []string{"docker", "kubernetes", "cicd"} {
+		buckets, ok := config.HistogramBuckets[subsystem]
+		if !ok {
+			buckets = DefaultHistogramBuckets
+		}
+		adapter.RegisterHistograms(
+			[]string{subsystem + "_operation_duration_seconds"},
+			[]string{"operation"},
+			buckets,
+		)
+	}
 
+	// Запускаем HTTP сервер для метрик и проверок здоровья
 	adapter.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", config.Port),
-		Handler: mux,
+		Handler: adapter.routes(),
 	}
 
 	go func() {
 		if err := adapter.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			fmt.Printf("Ошибка запуска HTTP сервера: %v\n", err)
+			adapter.logger.Error("ошибка запуска HTTP сервера метрик", "addr", adapter.server.Addr, "error", err)
 		}
 	}()
 
 	return adapter
 }
 
+// routes возвращает обработчики HTTP сервера мониторинга
+func (a *MonitoringAdapter) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(a.registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", a.healthzHandler)
+	mux.HandleFunc("/ready", a.readyHandler)
+	return mux
+}
+
+// healthzHandler отвечает 200, пока процесс жив (liveness probe)
+func (a *MonitoringAdapter) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyHandler отражает результат GetServiceHealth (readiness probe):
+// 503, если хотя бы одна проверка не прошла. Состояние degraded
+// готовности не снимает.
+func (a *MonitoringAdapter) readyHandler(w http.ResponseWriter, r *http.Request) {
+	checks, err := a.GetServiceHealth(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"status": "not ready",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	status, code := "ready", http.StatusOK
+	for _, check := range checks {
+		if check.Status != "healthy" && check.Status != "degraded" {
+			status, code = "not ready", http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	writeJSON(w, code, map[string]interface{}{
+		"status": status,
+		"checks": checks,
+	})
+}
+
+// writeJSON записывает ответ в формате JSON
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
 // RegisterCounters регистрирует счетчики с заданными именами и метками
 func (a *MonitoringAdapter) RegisterCounters(names []string, labels []string) {
 	for _, name := range names {
@@ -129,6 +223,7 @@ func (a *MonitoringAdapter) RegisterCounters(names []string, labels []string) {
 			labels,
 		)
 		a.registry.MustRegister(a.counters[name])
+		a.metricLabels[name] = labels
 	}
 }
 
@@ -146,9 +241,36 @@ func (a *MonitoringAdapter) RegisterHistograms(names []string, labels []string,
 			labels,
 		)
 		a.registry.MustRegister(a.histograms[name])
+		a.metricLabels[name] = labels
+	}
+}
+
+// RegisterSummaries регистрирует сводки с заданными именами и метками.
+// objectives задает квантили и допустимую погрешность, например
+// {0.5: 0.05, 0.99: 0.001}.
+func (a *MonitoringAdapter) RegisterSummaries(names []string, labels []string, objectives map[float64]float64) {
+	for _, name := range names {
+		a.summaries[name] = prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace:  a.config.Namespace,
+				Subsystem:  a.config.Subsystem,
+				Name:       name,
+				Help:       "Summary " + name,
+				Objectives: objectives,
+			},
+			labels,
+		)
+		a.registry.MustRegister(a.summaries[name])
+		a.metricLabels[name] = labels
 	}
 }
 
+// HasMetric сообщает, зарегистрирована ли метрика name
+func (a *MonitoringAdapter) HasMetric(name string) bool {
+	_, ok := a.metricLabels[name]
+	return ok
+}
+
 // IncCounter увеличивает значение счетчика
 func (a *MonitoringAdapter) IncCounter(name string, labels map[string]string) {
 	if counter, ok := a.counters[name]; ok {
@@ -163,6 +285,13 @@ func (a *MonitoringAdapter) ObserveDuration(name string, duration time.Duration,
 	}
 }
 
+// ObserveSummary записывает значение в сводку
+func (a *MonitoringAdapter) ObserveSummary(name string, value float64, labels map[string]string) {
+	if summary, ok := a.summaries[name]; ok {
+		summary.With(labels).Observe(value)
+	}
+}
+
 // MetricsHandler возвращает HTTP-обработчик для метрик Prometheus
 func (a *MonitoringAdapter) MetricsHandler() http.Handler {
 	return promhttp.HandlerFor(a.registry, promhttp.HandlerOpts{})
@@ -290,6 +419,28 @@ func (a *MonitoringAdapter) RecordDockerOperation(operation string, status strin
 	})
 }
 
+// Значения метки error_type метрики errors_total
+const (
+	ErrorTypeNotFound    = "notfound"
+	ErrorTypeConflict    = "conflict"
+	ErrorTypeTimeout     = "timeout"
+	ErrorTypeUnavailable = "unavailable"
+	ErrorTypeOther       = "other"
+)
+
+// RecordError увеличивает errors_total для неудачной операции компонента.
+// Пустой errorType означает успешную операцию и игнорируется.
+func (a *MonitoringAdapter) RecordError(component string, operation string, errorType string) {
+	if errorType == "" {
+		return
+	}
+	a.IncCounter("errors_total", map[string]string{
+		"component":  component,
+		"operation":  operation,
+		"error_type": errorType,
+	})
+}
+
 // RecordKubernetesOperation записывает метрики для Kubernetes операций
 func (a *MonitoringAdapter) RecordKubernetesOperation(operation string, resourceType string, status string, duration time.Duration) {
 	a.IncCounter("kubernetes_operations_total", map[string]string{
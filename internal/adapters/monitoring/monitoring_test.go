@@ -1,6 +1,7 @@
 package monitoring
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -75,6 +76,37 @@ func TestMonitoringAdapter_RegisterAndObserveHistograms(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestMonitoringAdapter_RegisterAndObserveSummaries(t *testing.T) {
+	adapter := NewMonitoringAdapter(Config{
+		Namespace: "test",
+		Subsystem: "test",
+	})
+
+	// Регистрируем сводки
+	adapter.RegisterSummaries(
+		[]string{"test_summary_1"},
+		[]string{"label1"},
+		map[float64]float64{0.5: 0.05, 0.99: 0.001},
+	)
+
+	// Записываем значения
+	for _, value := range []float64{1, 2, 3} {
+		adapter.ObserveSummary("test_summary_1", value, map[string]string{"label1": "value1"})
+	}
+
+	// Проверяем квантили сводки
+	expected := `
+		# HELP test_test_test_summary_1 Summary test_summary_1
+		# TYPE test_test_test_summary_1 summary
+		test_test_test_summary_1{label1="value1",quantile="0.5"} 2
+		test_test_test_summary_1{label1="value1",quantile="0.99"} 3
+		test_test_test_summary_1_sum{label1="value1"} 6
+		test_test_test_summary_1_count{label1="value1"} 3
+	`
+	err := testutil.GatherAndCompare(adapter.registry, strings.NewReader(expected), "test_test_test_summary_1")
+	require.NoError(t, err)
+}
+
 func TestMonitoringAdapter_MetricsHandler(t *testing.T) {
 	adapter := NewMonitoringAdapter(Config{
 		Namespace: "test",
@@ -104,6 +136,39 @@ func TestMonitoringAdapter_MetricsHandler(t *testing.T) {
 	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
 }
 
+func TestMonitoringAdapter_Healthz(t *testing.T) {
+	adapter := NewMonitoringAdapter(Config{
+		Namespace: "test",
+		Subsystem: "test",
+	})
+
+	// Используем тот же обработчик, что и HTTP сервер адаптера
+	server := httptest.NewServer(adapter.server.Handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/healthz")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "ok", body["status"])
+
+	// /ready отражает GetServiceHealth, а /metrics продолжает работать
+	ready, err := http.Get(server.URL + "/ready")
+	require.NoError(t, err)
+	defer ready.Body.Close()
+	assert.Equal(t, http.StatusOK, ready.StatusCode)
+
+	metrics, err := http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	defer metrics.Body.Close()
+	assert.Equal(t, http.StatusOK, metrics.StatusCode)
+}
+
 func TestMonitoringAdapter_UnknownMetrics(t *testing.T) {
 	adapter := NewMonitoringAdapter(Config{
 		Namespace: "test",
@@ -119,3 +184,51 @@ func TestMonitoringAdapter_UnknownMetrics(t *testing.T) {
 	err := testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "test_test_unknown_counter", "test_test_unknown_histogram")
 	require.NoError(t, err)
 }
+
+func TestMonitoringAdapter_HistogramBuckets(t *testing.T) {
+	adapter := NewMonitoringAdapter(Config{
+		Namespace: "test",
+		Subsystem: "buckets",
+		HistogramBuckets: map[string][]float64{
+			"docker": {0.01, 0.05},
+			"cicd":   {60, 600},
+		},
+	})
+
+	adapter.ObserveDuration("docker_operation_duration_seconds", 20*time.Millisecond, map[string]string{"operation": "list_containers"})
+	adapter.ObserveDuration("cicd_operation_duration_seconds", 5*time.Minute, map[string]string{"operation": "wait_pipeline"})
+	adapter.ObserveDuration("kubernetes_operation_duration_seconds", 300*time.Millisecond, map[string]string{"operation": "apply"})
+
+	expected := `
+		# HELP test_buckets_cicd_operation_duration_seconds Histogram cicd_operation_duration_seconds
+		# TYPE test_buckets_cicd_operation_duration_seconds histogram
+		test_buckets_cicd_operation_duration_seconds_bucket{operation="wait_pipeline",le="60"} 0
+		test_buckets_cicd_operation_duration_seconds_bucket{operation="wait_pipeline",le="600"} 1
+		test_buckets_cicd_operation_duration_seconds_bucket{operation="wait_pipeline",le="+Inf"} 1
+		test_buckets_cicd_operation_duration_seconds_sum{operation="wait_pipeline"} 300
+		test_buckets_cicd_operation_duration_seconds_count{operation="wait_pipeline"} 1
+		# HELP test_buckets_docker_operation_duration_seconds Histogram docker_operation_duration_seconds
+		# TYPE test_buckets_docker_operation_duration_seconds histogram
+		test_buckets_docker_operation_duration_seconds_bucket{operation="list_containers",le="0.01"} 0
+		test_buckets_docker_operation_duration_seconds_bucket{operation="list_containers",le="0.05"} 1
+		test_buckets_docker_operation_duration_seconds_bucket{operation="list_containers",le="+Inf"} 1
+		test_buckets_docker_operation_duration_seconds_sum{operation="list_containers"} 0.02
+		test_buckets_docker_operation_duration_seconds_count{operation="list_containers"} 1
+		# HELP test_buckets_kubernetes_operation_duration_seconds Histogram kubernetes_operation_duration_seconds
+		# TYPE test_buckets_kubernetes_operation_duration_seconds histogram
+		test_buckets_kubernetes_operation_duration_seconds_bucket{operation="apply",le="0.1"} 0
+		test_buckets_kubernetes_operation_duration_seconds_bucket{operation="apply",le="0.5"} 1
+		test_buckets_kubernetes_operation_duration_seconds_bucket{operation="apply",le="1"} 1
+		test_buckets_kubernetes_operation_duration_seconds_bucket{operation="apply",le="2"} 1
+		test_buckets_kubernetes_operation_duration_seconds_bucket{operation="apply",le="5"} 1
+		test_buckets_kubernetes_operation_duration_seconds_bucket{operation="apply",le="+Inf"} 1
+		test_buckets_kubernetes_operation_duration_seconds_sum{operation="apply"} 0.3
+		test_buckets_kubernetes_operation_duration_seconds_count{operation="apply"} 1
+	`
+	err := testutil.GatherAndCompare(adapter.registry, strings.NewReader(expected),
+		"test_buckets_docker_operation_duration_seconds",
+		"test_buckets_kubernetes_operation_duration_seconds",
+		"test_buckets_cicd_operation_duration_seconds",
+	)
+	require.NoError(t, err)
+}
@@ -0,0 +1,131 @@
+// Package audit ведет журнал изменяющих операций адаптеров: кто, когда и
+// над чем выполнил операцию и чем она закончилась. Пока адаптеру не задан
+// Logger, журнал не ведется.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+)
+
+// Entry запись журнала аудита
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Component адаптер, выполнивший операцию: docker, registry, kubernetes или cicd
+	Component string `json:"component"`
+	// Operation имя операции, как в метриках и трассировке (remove_container)
+	Operation string `json:"operation"`
+	// Target объект операции, например ID контейнера или namespace/имя
+	Target string `json:"target"`
+	// Actor кто выполнил операцию, см. Actor
+	Actor string `json:"actor"`
+	// Result success или error
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Logger получает записи о каждой изменяющей операции. Реализация должна
+// быть безопасной для вызова из нескольких горутин; ошибки записи не
+// прерывают саму операцию.
+type Logger interface {
+	Log(entry Entry)
+}
+
+// JSONLogger пишет записи в формате JSON lines, по одной на строку
+type JSONLogger struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+	// err первая ошибка записи, возвращается из Close
+	err error
+}
+
+// NewJSONLogger создает журнал, пишущий в w
+func NewJSONLogger(w io.Writer) *JSONLogger {
+	return &JSONLogger{enc: json.NewEncoder(w)}
+}
+
+// OpenFile открывает файл журнала для дозаписи, создавая его при
+// необходимости. Файл доступен только владельцу.
+func OpenFile(path string) (*JSONLogger, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	logger := NewJSONLogger(file)
+	logger.closer = file
+	return logger, nil
+}
+
+// Log записывает entry отдельной строкой
+func (l *JSONLogger) Log(entry Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.enc.Encode(entry); err != nil && l.err == nil {
+		l.err = err
+	}
+}
+
+// Close закрывает файл журнала и возвращает первую ошибку записи, если она была
+func (l *JSONLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	err := l.err
+	if l.closer != nil {
+		err = errors.Join(err, l.closer.Close())
+	}
+	return err
+}
+
+type actorKey struct{}
+
+// WithActor возвращает контекст, в котором операции выполняются от имени actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// processUser имя пользователя ОС, от которого запущен процесс
+var processUser = sync.OnceValue(func() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+})
+
+// Actor возвращает исполнителя из контекста, а без него - пользователя ОС,
+// запустившего процесс
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return processUser()
+}
+
+// Record записывает в logger результат операции. nil logger ничего не пишет.
+func Record(ctx context.Context, logger Logger, component, operation, target string, err error) {
+	if logger == nil {
+		return
+	}
+
+	entry := Entry{
+		Time:      time.Now().UTC(),
+		Component: component,
+		Operation: operation,
+		Target:    target,
+		Actor:     Actor(ctx),
+		Result:    "success",
+	}
+	if err != nil {
+		entry.Result = "error"
+		entry.Error = err.Error()
+	}
+	logger.Log(entry)
+}
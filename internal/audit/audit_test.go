@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingWriter возвращает err на каждую запись
+type failingWriter struct {
+	err error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestJSONLogger(t *testing.T) {
+	var buf strings.Builder
+	logger := NewJSONLogger(&buf)
+	ctx := WithActor(context.Background(), "alice")
+
+	Record(ctx, logger, "docker", "remove_container", "web", nil)
+	Record(ctx, logger, "kubernetes", "scale", "default/api", errors.New("нет доступа"))
+	require.NoError(t, logger.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var entry Entry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "docker", entry.Component)
+	assert.Equal(t, "remove_container", entry.Operation)
+	assert.Equal(t, "web", entry.Target)
+	assert.Equal(t, "alice", entry.Actor)
+	assert.Equal(t, "success", entry.Result)
+	assert.Empty(t, entry.Error)
+	assert.False(t, entry.Time.IsZero())
+	// Поле error не выводится для успешных операций
+	assert.NotContains(t, lines[0], `"error"`)
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "error", entry.Result)
+	assert.Equal(t, "нет доступа", entry.Error)
+}
+
+func TestJSONLoggerCloseReportsWriteError(t *testing.T) {
+	writer := &failingWriter{err: errors.New("диск заполнен")}
+	logger := NewJSONLogger(writer)
+
+	logger.Log(Entry{Operation: "first"})
+	logger.Log(Entry{Operation: "second"})
+
+	// Ошибка записи не возвращается из Log, ее сообщает Close
+	assert.ErrorIs(t, logger.Close(), writer.err)
+}
+
+func TestOpenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0o600))
+
+	logger, err := OpenFile(path)
+	require.NoError(t, err)
+	logger.Log(Entry{Operation: "apply_manifest"})
+	require.NoError(t, logger.Close())
+
+	// Файл дописывается, а не перезаписывается
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], `"operation":"apply_manifest"`)
+
+	// Закрытый файл: ошибка закрытия попадает в результат Close
+	assert.Error(t, logger.Close())
+}
+
+func TestActor(t *testing.T) {
+	assert.Equal(t, "bob", Actor(WithActor(context.Background(), "bob")))
+
+	// Без исполнителя в контексте используется пользователь ОС
+	assert.Equal(t, processUser(), Actor(context.Background()))
+	assert.Equal(t, processUser(), Actor(WithActor(context.Background(), "")))
+}
+
+func TestRecordNilLogger(t *testing.T) {
+	assert.NotPanics(t, func() {
+		Record(context.Background(), nil, "cicd", "trigger_pipeline", "123@main", nil)
+	})
+}
@@ -0,0 +1,60 @@
+// Package clock позволяет подменять текущее время в адаптерах, чтобы
+// возраст ресурсов и длительности в тестах были детерминированными.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock источник текущего времени
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+}
+
+// Real использует системное время
+type Real struct{}
+
+// Now возвращает текущее системное время
+func (Real) Now() time.Time { return time.Now() }
+
+// Since возвращает время, прошедшее с t
+func (Real) Since(t time.Time) time.Duration { return time.Since(t) }
+
+// Fake показывает фиксированное время, которое меняется только явно
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFake создает часы, остановленные на now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now возвращает установленное время
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since возвращает разницу между установленным временем и t
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// Set переводит часы на указанное время
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// Advance сдвигает часы вперед на d
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
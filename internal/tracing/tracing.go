@@ -0,0 +1,34 @@
+// Package tracing содержит общие функции трассировки OpenTelemetry для адаптеров.
+// Трейсер берется из глобального провайдера: пока приложение не вызвало
+// otel.SetTracerProvider, спаны ничего не делают.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName имя библиотеки инструментирования в спанах
+const instrumentationName = "github.com/localops/devops-manager"
+
+// Start открывает спан операции компонента, например docker.list_containers
+func Start(ctx context.Context, component string, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		attribute.String("component", component),
+		attribute.String("operation", operation),
+	)
+	return otel.Tracer(instrumentationName).Start(ctx, component+"."+operation, trace.WithAttributes(attrs...))
+}
+
+// End отмечает ошибку в спане и завершает его
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
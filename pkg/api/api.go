@@ -1,30 +1,200 @@
 package api
 
 import (
-	"log"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"strconv"
 	"time"
 
 	restfulspec "github.com/emicklei/go-restful-openapi/v2"
 	restful "github.com/emicklei/go-restful/v3"
 	"github.com/go-openapi/spec"
+	"github.com/localops/devops-manager/internal/adapters/monitoring"
+	"github.com/localops/devops-manager/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
 )
 
+// requestIDHeader заголовок с идентификатором запроса
+const requestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestIDFromContext возвращает идентификатор запроса из контекста
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequestID генерирует случайный идентификатор запроса
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+// RequestIDMiddleware берет X-Request-ID из запроса или генерирует новый,
+// кладет его в контекст и возвращает в ответе
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" {
+			id = newRequestID()
+			r.Header.Set(requestIDHeader, id)
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// statusRecorder запоминает код ответа для логирования
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
 // LoggingMiddleware логирует информацию о запросе
-func LoggingMiddleware(next http.Handler) http.Handler {
+func LoggingMiddleware(logger *slog.Logger, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		logger.InfoContext(r.Context(), "http request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"request_id", RequestIDFromContext(r.Context()),
+		)
+	})
+}
+
+// TracingMiddleware продолжает трассировку из заголовков запроса через
+// глобальный propagator и открывает серверный спан, доступный обработчикам
+// через r.Context(). Спаны адаптеров становятся его дочерними, только если
+// обработчик передает этот контекст в методы с параметром ctx (например,
+// PullImageViaSDK или методы CI/CD адаптера); остальные методы Docker и
+// Kubernetes адаптеров открывают спаны от контекста самого адаптера.
+func TracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Start(ctx, "http", r.Method+" "+r.URL.Path,
+			attribute.String("http.method", r.Method),
+			attribute.String("http.target", r.URL.Path),
+			attribute.String("request_id", RequestIDFromContext(ctx)),
+		)
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.status_code", recorder.status))
+		var err error
+		if recorder.status >= http.StatusInternalServerError {
+			err = errors.New(http.StatusText(recorder.status))
+		}
+		tracing.End(span, err)
+	})
+}
+
+// Метрики HTTP запросов к API
+const (
+	httpRequestsTotal   = "http_requests_total"
+	httpRequestDuration = "http_request_duration_seconds"
+)
+
+// httpMetrics регистрирует и записывает метрики запросов
+type httpMetrics interface {
+	HasMetric(name string) bool
+	RegisterCounters(names []string, labels []string)
+	RegisterHistograms(names []string, labels []string, buckets []float64)
+	IncCounter(name string, labels map[string]string)
+	ObserveDuration(name string, duration time.Duration, labels map[string]string)
+}
+
+// unmatchedRoute метка path для запросов, не совпавших ни с одним маршрутом
+const unmatchedRoute = "unmatched"
+
+type routeKey struct{}
+
+// setRoute сообщает MetricsMiddleware шаблон маршрута, совпавшего с r
+func setRoute(r *http.Request, route string) {
+	if holder, ok := r.Context().Value(routeKey{}).(*string); ok {
+		*holder = route
+	}
+}
+
+// routeFilter записывает шаблон выбранного маршрута go-restful, например
+// /api/docker/containers/{id}
+func routeFilter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	if route := req.SelectedRoutePath(); route != "" {
+		setRoute(req.Request, route)
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+// withRoute записывает route для обработчиков, подключенных в обход маршрутов go-restful
+func withRoute(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setRoute(r, route)
 		next.ServeHTTP(w, r)
-		log.Printf("%s %s %s", r.Method, r.RequestURI, time.Since(start))
+	})
+}
+
+// MetricsMiddleware считает запросы и их длительность по методу, шаблону
+// маршрута и коду ответа. Метка path берет шаблон, а не URL запроса, чтобы
+// идентификаторы в пути не плодили временные ряды; запросы без маршрута
+// получают path="unmatched". Метрики регистрируются при первом вызове для metrics.
+func MetricsMiddleware(metrics httpMetrics, next http.Handler) http.Handler {
+	labels := []string{"method", "path", "status"}
+	if !metrics.HasMetric(httpRequestsTotal) {
+		metrics.RegisterCounters([]string{httpRequestsTotal}, labels)
+	}
+	if !metrics.HasMetric(httpRequestDuration) {
+		metrics.RegisterHistograms([]string{httpRequestDuration}, labels, prometheus.DefBuckets)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		route := unmatchedRoute
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), routeKey{}, &route)))
+
+		values := map[string]string{
+			"method": r.Method,
+			"path":   route,
+			"status": strconv.Itoa(recorder.status),
+		}
+		metrics.IncCounter(httpRequestsTotal, values)
+		metrics.ObserveDuration(httpRequestDuration, time.Since(start), values)
 	})
 }
 
 // RecoverMiddleware обрабатывает паники
-func RecoverMiddleware(next http.Handler) http.Handler {
+func RecoverMiddleware(logger *slog.Logger, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Printf("panic: %v", err)
+				logger.ErrorContext(r.Context(), "panic",
+					"error", fmt.Sprint(err),
+					"method", r.Method,
+					"path", r.URL.Path,
+					"request_id", RequestIDFromContext(r.Context()),
+				)
 				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			}
 		}()
@@ -42,14 +212,26 @@ type ContainerOptions struct {
 	Network string            `json:"network,omitempty"`
 }
 
+// metricsQuerier выполняет запросы значений метрик
+type metricsQuerier interface {
+	QueryMetric(ctx context.Context, name string, start, end time.Time) ([]monitoring.MetricValue, error)
+}
+
 // PortMapping описывает маппинг портов
 type PortMapping struct {
 	HostPort      int `json:"hostPort"`
 	ContainerPort int `json:"containerPort"`
 }
 
-func NewAPI(dockerAdapter, k8sAdapter, ciAdapter, monitoringAdapter interface{}) http.Handler {
+// NewAPI собирает HTTP API. Если logger не задан, запросы логируются
+// в формате JSON в stdout.
+func NewAPI(dockerAdapter, k8sAdapter, ciAdapter, monitoringAdapter interface{}, logger *slog.Logger) http.Handler {
+	if logger == nil {
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	}
+
 	wsContainer := restful.NewContainer()
+	wsContainer.Filter(routeFilter)
 
 	// Docker endpoints
 	dockerWS := new(restful.WebService)
@@ -94,10 +276,27 @@ func NewAPI(dockerAdapter, k8sAdapter, ciAdapter, monitoringAdapter interface{})
 
 	wsContainer.Add(ciWS)
 
+	// Metrics query endpoints
+	if querier, ok := monitoringAdapter.(metricsQuerier); ok {
+		metricsWS := new(restful.WebService)
+		metricsWS.
+			Path("/api/metrics").
+			Produces(restful.MIME_JSON)
+
+		metricsWS.Route(metricsWS.GET("/query").To(metricsQueryHandler(querier)).
+			Doc("Query metric values").
+			Param(metricsWS.QueryParameter("name", "Metric name").Required(true)).
+			Param(metricsWS.QueryParameter("start", "Start time (RFC3339 or unix seconds)")).
+			Param(metricsWS.QueryParameter("end", "End time (RFC3339 or unix seconds)")).
+			Operation("metricsQuery"))
+
+		wsContainer.Add(metricsWS)
+	}
+
 	// Metrics endpoint (Prometheus)
 	if monitoringAdapter != nil {
 		if handler, ok := monitoringAdapter.(interface{ MetricsHandler() http.Handler }); ok {
-			wsContainer.Handle("/metrics", handler.MetricsHandler())
+			wsContainer.Handle("/metrics", withRoute("/metrics", handler.MetricsHandler()))
 		}
 	}
 
@@ -110,7 +309,11 @@ func NewAPI(dockerAdapter, k8sAdapter, ciAdapter, monitoringAdapter interface{})
 	wsContainer.Add(restfulspec.NewOpenAPIService(config))
 
 	// Применяем middleware
-	handler := LoggingMiddleware(RecoverMiddleware(wsContainer))
+	handler := LoggingMiddleware(logger, RecoverMiddleware(logger, wsContainer))
+	if metrics, ok := monitoringAdapter.(httpMetrics); ok {
+		handler = MetricsMiddleware(metrics, handler)
+	}
+	handler = RequestIDMiddleware(TracingMiddleware(handler))
 
 	return handler
 }
@@ -221,3 +424,55 @@ func ciTriggerHandler(req *restful.Request, resp *restful.Response) {
 		"ref":     trigger.Ref,
 	})
 }
+
+// metricsQueryHandler возвращает обработчик запроса значений метрики
+func metricsQueryHandler(querier metricsQuerier) restful.RouteFunction {
+	return func(req *restful.Request, resp *restful.Response) {
+		name := req.QueryParameter("name")
+		if name == "" {
+			resp.WriteErrorString(http.StatusBadRequest, "name parameter is required")
+			return
+		}
+
+		end := time.Now()
+		if value := req.QueryParameter("end"); value != "" {
+			parsed, err := parseQueryTime(value)
+			if err != nil {
+				resp.WriteErrorString(http.StatusBadRequest, "invalid end time: "+err.Error())
+				return
+			}
+			end = parsed
+		}
+
+		start := end.Add(-time.Hour)
+		if value := req.QueryParameter("start"); value != "" {
+			parsed, err := parseQueryTime(value)
+			if err != nil {
+				resp.WriteErrorString(http.StatusBadRequest, "invalid start time: "+err.Error())
+				return
+			}
+			start = parsed
+		}
+
+		if start.After(end) {
+			resp.WriteErrorString(http.StatusBadRequest, "start must not be after end")
+			return
+		}
+
+		values, err := querier.QueryMetric(req.Request.Context(), name, start, end)
+		if err != nil {
+			resp.WriteErrorString(http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		resp.WriteEntity(values)
+	}
+}
+
+// parseQueryTime разбирает время в формате RFC3339 или unix секундах
+func parseQueryTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
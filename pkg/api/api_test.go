@@ -0,0 +1,205 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/localops/devops-manager/internal/adapters/monitoring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeMonitoring возвращает заранее заданные значения метрики
+type fakeMonitoring struct {
+	values []monitoring.MetricValue
+	name   string
+	start  time.Time
+	end    time.Time
+}
+
+func (f *fakeMonitoring) QueryMetric(ctx context.Context, name string, start, end time.Time) ([]monitoring.MetricValue, error) {
+	f.name, f.start, f.end = name, start, end
+	return f.values, nil
+}
+
+func TestMetricsQuery(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeMonitoring{
+		values: []monitoring.MetricValue{
+			{Name: "docker_operations_total", Value: 3, Timestamp: now, Labels: map[string]string{"status": "success"}},
+			{Name: "docker_operations_total", Value: 1, Timestamp: now, Labels: map[string]string{"status": "error"}},
+		},
+	}
+
+	server := httptest.NewServer(NewAPI(nil, nil, nil, fake, slog.New(slog.NewJSONHandler(io.Discard, nil))))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{
+			name:       "успешный запрос",
+			query:      "?name=docker_operations_total&start=2024-05-01T11:00:00Z&end=2024-05-01T12:00:00Z",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "время в unix секундах",
+			query:      "?name=docker_operations_total&start=1714561200&end=1714564800",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "неверный формат start",
+			query:      "?name=docker_operations_total&start=вчера",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "неверный формат end",
+			query:      "?name=docker_operations_total&end=2024-13-01",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "без имени метрики",
+			query:      "?start=2024-05-01T11:00:00Z",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(server.URL + "/api/metrics/query" + tt.query)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var values []monitoring.MetricValue
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&values))
+			assert.Equal(t, fake.values, values)
+			assert.Equal(t, "docker_operations_total", fake.name)
+			assert.True(t, fake.start.Equal(now.Add(-time.Hour)), "неверный start: %s", fake.start)
+			assert.True(t, fake.end.Equal(now), "неверный end: %s", fake.end)
+		})
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	server := httptest.NewServer(NewAPI(nil, nil, nil, nil, logger))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/docker/ping", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Request-ID", "test-request-id")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "test-request-id", resp.Header.Get("X-Request-ID"))
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry), "лог не является JSON: %s", logs.String())
+
+	assert.Equal(t, "http request", entry["msg"])
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/api/docker/ping", entry["path"])
+	assert.Equal(t, float64(http.StatusOK), entry["status"])
+	assert.Equal(t, "test-request-id", entry["request_id"])
+	assert.Contains(t, entry, "duration_ms")
+}
+
+func TestRequestIDMiddlewareGeneratesID(t *testing.T) {
+	var seen string
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.NotEmpty(t, seen)
+	assert.Equal(t, seen, recorder.Header().Get("X-Request-ID"))
+}
+
+func TestTracingMiddlewareContinuesTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	var handlerSpan trace.SpanContext
+	handler := TracingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/docker/containers", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Equal(t, spans[0].SpanContext.SpanID(), handlerSpan.SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	adapter := monitoring.NewMonitoringAdapter(monitoring.Config{Namespace: "devops", Subsystem: "manager"})
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	// Повторная сборка API с тем же адаптером не регистрирует метрики заново
+	NewAPI(nil, nil, nil, adapter, logger)
+	server := httptest.NewServer(NewAPI(nil, nil, nil, adapter, logger))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, err := http.Get(server.URL + "/api/docker/ping")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	for _, path := range []string{"/api/docker/missing", "/random/1"} {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+
+	resp, err := http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `devops_manager_http_requests_total{method="GET",path="/api/docker/ping",status="200"} 2`)
+	assert.Contains(t, string(body), `devops_manager_http_request_duration_seconds_count{method="GET",path="/api/docker/ping",status="200"} 2`)
+	// Пути без маршрута сводятся к одной метке
+	assert.Contains(t, string(body), `devops_manager_http_requests_total{method="GET",path="unmatched",status="404"} 2`)
+	assert.NotContains(t, string(body), `path="/random/1"`)
+}